@@ -0,0 +1,168 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagFeedKind flagName = "kind"
+
+	// activityFeedKey is the codereview config key giving where release
+	// critical events are recorded: either "#N" for the body of issue N,
+	// or "gist:ID" for the gist with that ID.
+	activityFeedKey = "activity-feed"
+)
+
+// activityMu serializes appends to the activity feed, which are a read
+// followed by a write of the whole issue body or gist file, such that
+// concurrent events under serve do not drop each other's lines.
+var activityMu sync.Mutex
+
+// newFeedCmd creates a new feed command
+func newFeedCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "feed",
+		Short: "append a line to the repository's activity feed",
+		Long: `
+Usage of feed:
+
+	feed [--kind KIND] TEXT...
+
+feed appends a line to the activity feed of the repository, a chronological
+log of release-critical events such as release builds, backports and reverts
+kept for the benefit of release managers. The feed is configured by the
+` + activityFeedKey + ` entry in codereview.cfg, which is either "#N" to append to the
+body of issue N, such as the tracking issue of a release, or "gist:ID" to
+append to the gist with that ID.
+
+Each line records the time in UTC, the KIND of event, and TEXT:
+
+	cueckoo feed --kind release "v0.15.0 built: https://github.com/..."
+
+Other commands add to the feed themselves: revert does so with --feed, and
+serve does so for workflow runs matching --feed-workflows.
+`,
+		RunE: mkRunE(c, feedDef),
+	}
+	c.registerFlags(cmd).String(flagFeedKind, "note", "the kind of event, such as release, backport or revert")
+	return cmd
+}
+
+func feedDef(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected the text of the line to append")
+	}
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if cfg.activityFeed == "" {
+		return fmt.Errorf("no activity feed configured; add an %s entry to codereview.cfg", activityFeedKey)
+	}
+	return cfg.recordActivity(cmd.Context(), flagFeedKind.String(cmd), strings.Join(args, " "))
+}
+
+// parseActivityFeed parses the activity-feed entry of the codereview config,
+// returning either an issue number or a gist ID.
+func parseActivityFeed(s string) (issue int, gist string, _ error) {
+	if n, ok := strings.CutPrefix(s, "#"); ok {
+		issue, err := strconv.Atoi(n)
+		if err != nil || issue <= 0 {
+			return 0, "", fmt.Errorf("invalid %s entry %q: want #N or gist:ID", activityFeedKey, s)
+		}
+		return issue, "", nil
+	}
+	if id, ok := strings.CutPrefix(s, "gist:"); ok && id != "" {
+		return 0, id, nil
+	}
+	return 0, "", fmt.Errorf("invalid %s entry %q: want #N or gist:ID", activityFeedKey, s)
+}
+
+// activityLine formats the feed line for an event of the given kind at t.
+func activityLine(t time.Time, kind, text string) string {
+	return fmt.Sprintf("- %s UTC **%s** %s", t.UTC().Format("2006-01-02 15:04"), kind, firstLine(text))
+}
+
+// appendLine returns content with line appended as a line of its own.
+func appendLine(content, line string) string {
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return line + "\n"
+	}
+	return content + "\n" + line + "\n"
+}
+
+// recordActivity appends a line for an event of the given kind to the
+// activity feed of the repository. It does nothing if no feed is
+// configured.
+func (c *config) recordActivity(ctx context.Context, kind, text string) error {
+	if c.activityFeed == "" {
+		return nil
+	}
+	issue, gistID, err := parseActivityFeed(c.activityFeed)
+	if err != nil {
+		return err
+	}
+	line := activityLine(time.Now(), kind, text)
+
+	activityMu.Lock()
+	defer activityMu.Unlock()
+	if issue != 0 {
+		is, _, err := c.githubClient.Issues.Get(ctx, c.githubOwner, c.githubRepo, issue)
+		if err != nil {
+			return fmt.Errorf("failed to get activity feed issue %d: %w", issue, err)
+		}
+		body := appendLine(is.GetBody(), line)
+		if _, _, err := c.githubClient.Issues.Edit(ctx, c.githubOwner, c.githubRepo, issue, &github.IssueRequest{Body: &body}); err != nil {
+			return fmt.Errorf("failed to update activity feed issue %d: %w", issue, err)
+		}
+		return nil
+	}
+	gist, _, err := c.githubClient.Gists.Get(ctx, gistID)
+	if err != nil {
+		return fmt.Errorf("failed to get activity feed gist %s: %w", gistID, err)
+	}
+	// Append to the first file of the gist by name, which for a gist
+	// created for the purpose is its only file.
+	var names []string
+	for name := range gist.Files {
+		names = append(names, string(name))
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("activity feed gist %s has no files", gistID)
+	}
+	sort.Strings(names)
+	name := github.GistFilename(names[0])
+	file := gist.Files[name]
+	content := appendLine(file.GetContent(), line)
+	edit := &github.Gist{Files: map[github.GistFilename]github.GistFile{
+		name: {Content: &content},
+	}}
+	if _, _, err := c.githubClient.Gists.Edit(ctx, gistID, edit); err != nil {
+		return fmt.Errorf("failed to update activity feed gist %s: %w", gistID, err)
+	}
+	return nil
+}
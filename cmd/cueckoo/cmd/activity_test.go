@@ -0,0 +1,63 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseActivityFeed(t *testing.T) {
+	cases := []struct {
+		in    string
+		issue int
+		gist  string
+		err   bool
+	}{
+		{in: "#42", issue: 42},
+		{in: "gist:0123abcd", gist: "0123abcd"},
+		{in: "#", err: true},
+		{in: "#-1", err: true},
+		{in: "gist:", err: true},
+		{in: "42", err: true},
+	}
+	for _, c := range cases {
+		issue, gist, err := parseActivityFeed(c.in)
+		if (err != nil) != c.err {
+			t.Errorf("parseActivityFeed(%q): got error %v; want error %v", c.in, err, c.err)
+			continue
+		}
+		if issue != c.issue || gist != c.gist {
+			t.Errorf("parseActivityFeed(%q) = %d, %q; want %d, %q", c.in, issue, gist, c.issue, c.gist)
+		}
+	}
+}
+
+func TestAppendActivity(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	line := activityLine(at, "revert", "CL 123 broke the build\nmore detail")
+	if want := "- 2026-10-16 09:30 UTC **revert** CL 123 broke the build"; line != want {
+		t.Fatalf("got line %q; want %q", line, want)
+	}
+	for _, c := range []struct{ in, want string }{
+		{"", line + "\n"},
+		{"# Activity", "# Activity\n" + line + "\n"},
+		{"# Activity\n\n", "# Activity\n" + line + "\n"},
+	} {
+		if got := appendLine(c.in, line); got != c.want {
+			t.Errorf("appendLine(%q) = %q; want %q", c.in, got, c.want)
+		}
+	}
+}
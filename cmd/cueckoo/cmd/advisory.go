@@ -0,0 +1,310 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const flagAdvisoryDryRun flagName = "dry-run"
+
+// newAdvisoryCmd creates a new advisory command, which groups the
+// subcommands for handling security advisories.
+func newAdvisoryCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "advisory",
+		Short: "help with handling security advisories",
+	}
+	subCommands := []*cobra.Command{
+		newAdvisoryNewCmd(c),
+	}
+	for _, sub := range subCommands {
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
+
+// newAdvisoryNewCmd creates a new advisory new command
+func newAdvisoryNewCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "create a draft security advisory from a vulnerability record",
+		Long: `
+Usage of advisory new:
+
+	advisory new [--dry-run] FILE
+
+advisory new creates a draft GitHub security advisory for the repository from
+the vulnerability record in FILE, a CUE or JSON file such as:
+
+	summary:     "Stack exhaustion when evaluating deeply nested lists"
+	description: "Evaluating a deeply nested list can ..."
+	severity:    "high"
+	cwes: ["CWE-674"]
+	cls: [1234, 1235]
+	affected: [{
+		package:    "cuelang.org/go"
+		vulnerable: "< v0.9.1"
+	}]
+	credits: [{login: "octocat", type: "reporter"}]
+
+The optional fields are cveID, cwes, credits, and the ecosystem and patched
+fields of each affected package, whose ecosystem defaults to "go". When
+patched is not given, it is the next patch release after the latest release
+tag reachable from HEAD, as the fix is released as a patch release.
+
+The description of the advisory links the fixing CLs, listed in cls, and ends
+with the checklist for the embargoed release. The draft remains private to
+the maintainers until it is published, and release suggest-version lists the
+draft advisories awaiting a release. With --dry-run, the advisory is printed
+rather than created.
+`,
+		RunE: mkRunE(c, advisoryNewDef),
+	}
+	c.registerFlags(cmd).Bool(flagAdvisoryDryRun, false, "print the advisory rather than creating it")
+	return cmd
+}
+
+// vulnRecord is the CUE description of a vulnerability given to advisory
+// new.
+type vulnRecord struct {
+	Summary     string         `json:"summary"`
+	Description string         `json:"description"`
+	Severity    string         `json:"severity"`
+	CVEID       string         `json:"cveID"`
+	CWEs        []string       `json:"cwes"`
+	CLs         []int          `json:"cls"`
+	Affected    []vulnAffected `json:"affected"`
+	Credits     []vulnCredit   `json:"credits"`
+}
+
+type vulnAffected struct {
+	Ecosystem  string `json:"ecosystem"`
+	Package    string `json:"package"`
+	Vulnerable string `json:"vulnerable"`
+	Patched    string `json:"patched"`
+}
+
+type vulnCredit struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// check reports the first problem with r, if any.
+func (r *vulnRecord) check() error {
+	switch {
+	case r.Summary == "":
+		return fmt.Errorf("the record has no summary")
+	case r.Description == "":
+		return fmt.Errorf("the record has no description")
+	case len(r.Affected) == 0:
+		return fmt.Errorf("the record has no affected packages")
+	}
+	switch r.Severity {
+	case "low", "medium", "high", "critical":
+	default:
+		return fmt.Errorf("severity must be low, medium, high or critical; got %q", r.Severity)
+	}
+	for _, a := range r.Affected {
+		if a.Package == "" || a.Vulnerable == "" {
+			return fmt.Errorf("each affected package needs a package and a vulnerable version range")
+		}
+	}
+	return nil
+}
+
+// advisoryInput is the subset of the body of GitHub's request to create a
+// repository security advisory which we use. go-github lacks support for
+// repository security advisories.
+type advisoryInput struct {
+	Summary         string               `json:"summary"`
+	Description     string               `json:"description"`
+	CVEID           string               `json:"cve_id,omitempty"`
+	Severity        string               `json:"severity"`
+	CWEIDs          []string             `json:"cwe_ids,omitempty"`
+	Vulnerabilities []advisoryVuln       `json:"vulnerabilities"`
+	Credits         []advisoryCreditInfo `json:"credits,omitempty"`
+}
+
+type advisoryVuln struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	VulnerableVersionRange string `json:"vulnerable_version_range"`
+	PatchedVersions        string `json:"patched_versions,omitempty"`
+}
+
+type advisoryCreditInfo struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// advisory is the subset of GitHub's repository security advisory which we
+// use.
+type advisory struct {
+	GHSAID          string         `json:"ghsa_id"`
+	HTMLURL         string         `json:"html_url"`
+	Summary         string         `json:"summary"`
+	State           string         `json:"state"`
+	Vulnerabilities []advisoryVuln `json:"vulnerabilities"`
+}
+
+func advisoryNewDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single vulnerability record")
+	}
+	ctx := cmd.Context()
+	var rec vulnRecord
+	if err := loadCUEFile(ctx, args[0], &rec); err != nil {
+		return err
+	}
+	if err := rec.check(); err != nil {
+		return fmt.Errorf("invalid vulnerability record %s: %v", args[0], err)
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	var patched string
+	for _, a := range rec.Affected {
+		if a.Patched == "" {
+			last, err := latestReleaseTag(ctx, "HEAD")
+			if err != nil {
+				return err
+			}
+			if patched, err = nextVersion(last, bumpPatch); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	var fixes []string
+	for _, cl := range rec.CLs {
+		ch, _, err := cfg.gerritClient.Changes.GetChange(fmt.Sprint(cl), nil)
+		if err != nil {
+			return fmt.Errorf("failed to get fixing CL %d: %v", cl, err)
+		}
+		fixes = append(fixes, advisoryFixLine(cfg.changeURL(cl), *ch))
+	}
+	in := rec.advisoryInput(patched, fixes)
+
+	if flagAdvisoryDryRun.Bool(cmd) {
+		data, err := json.MarshalIndent(in, "", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n", data)
+		return nil
+	}
+	var adv advisory
+	if err := cfg.githubAdvisoryDo(ctx, http.MethodPost, "", in, &adv); err != nil {
+		return fmt.Errorf("failed to create the advisory: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "created draft advisory %s: %s\n", adv.GHSAID, adv.HTMLURL)
+	return nil
+}
+
+// advisoryFixLine returns the line of the description of an advisory which
+// links the fixing CL ch at url.
+func advisoryFixLine(url string, ch gerrit.ChangeInfo) string {
+	return fmt.Sprintf("* [CL %d](%s): %s", ch.Number, url, ch.Subject)
+}
+
+// advisoryInput returns the request to create a draft advisory for r. The
+// affected packages without a patched version are patched in the version
+// patched, and fixes are the lines linking the fixing CLs.
+func (r *vulnRecord) advisoryInput(patched string, fixes []string) advisoryInput {
+	in := advisoryInput{
+		Summary:  r.Summary,
+		CVEID:    r.CVEID,
+		Severity: r.Severity,
+		CWEIDs:   r.CWEs,
+	}
+	var versions []string
+	for _, a := range r.Affected {
+		var v advisoryVuln
+		v.Package.Ecosystem = a.Ecosystem
+		if v.Package.Ecosystem == "" {
+			v.Package.Ecosystem = "go"
+		}
+		v.Package.Name = a.Package
+		v.VulnerableVersionRange = a.Vulnerable
+		v.PatchedVersions = a.Patched
+		if v.PatchedVersions == "" {
+			v.PatchedVersions = patched
+		}
+		if !slicesContains(versions, v.PatchedVersions) {
+			versions = append(versions, v.PatchedVersions)
+		}
+		in.Vulnerabilities = append(in.Vulnerabilities, v)
+	}
+	for _, c := range r.Credits {
+		in.Credits = append(in.Credits, advisoryCreditInfo(c))
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(r.Description))
+	b.WriteString("\n")
+	if len(fixes) > 0 {
+		b.WriteString("\n### Fixes\n\n")
+		for _, line := range fixes {
+			b.WriteString(line + "\n")
+		}
+	}
+	release := strings.Join(versions, ", ")
+	b.WriteString("\n### Embargoed release checklist\n\n")
+	if r.CVEID == "" {
+		b.WriteString("- [ ] Request a CVE for this advisory\n")
+	}
+	b.WriteString("- [ ] Review the fixes without disclosing the vulnerability in the CL descriptions\n")
+	b.WriteString("- [ ] Notify downstream users under embargo, if agreed with the reporter\n")
+	fmt.Fprintf(&b, "- [ ] Submit the fixes and tag %s\n", release)
+	fmt.Fprintf(&b, "- [ ] Publish this advisory once %s is released\n", release)
+	b.WriteString("- [ ] Announce the release, linking this advisory\n")
+	in.Description = b.String()
+	return in
+}
+
+// githubAdvisoryDo makes a request to the repository security advisories
+// API of the GitHub repository, at path relative to the advisories, decoding
+// the response into v.
+func (c *config) githubAdvisoryDo(ctx context.Context, method, path string, body, v any) error {
+	u := fmt.Sprintf("repos/%s/%s/security-advisories%s", c.githubOwner, c.githubRepo, path)
+	req, err := c.githubClient.NewRequest(method, u, body)
+	if err != nil {
+		return err
+	}
+	_, err = c.githubClient.Do(ctx, req, v)
+	return err
+}
+
+// draftAdvisories returns the draft security advisories of the GitHub
+// repository.
+func (c *config) draftAdvisories(ctx context.Context) ([]advisory, error) {
+	var advs []advisory
+	if err := c.githubAdvisoryDo(ctx, http.MethodGet, "?state=draft&per_page=100", nil, &advs); err != nil {
+		return nil, fmt.Errorf("failed to list draft advisories: %w", err)
+	}
+	return advs, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVulnRecordAdvisoryInput(t *testing.T) {
+	rec := vulnRecord{
+		Summary:     "Stack exhaustion",
+		Description: "Evaluating deeply nested lists exhausts the stack.\n",
+		Severity:    "high",
+		CLs:         []int{1234},
+		Affected: []vulnAffected{
+			{Package: "cuelang.org/go", Vulnerable: "< v0.9.1"},
+			{Ecosystem: "npm", Package: "cue-wasm", Vulnerable: "< 1.2.0", Patched: "1.2.0"},
+		},
+		Credits: []vulnCredit{{Login: "octocat", Type: "reporter"}},
+	}
+	if err := rec.check(); err != nil {
+		t.Fatal(err)
+	}
+	in := rec.advisoryInput("v0.9.1", []string{"* [CL 1234](https://review.example.com/c/cue/+/1234): cue: fix it"})
+
+	var got []string
+	for _, v := range in.Vulnerabilities {
+		got = append(got, v.Package.Ecosystem+" "+v.Package.Name+" "+v.VulnerableVersionRange+" "+v.PatchedVersions)
+	}
+	want := []string{"go cuelang.org/go < v0.9.1 v0.9.1", "npm cue-wasm < 1.2.0 1.2.0"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("vulnerabilities (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]advisoryCreditInfo{{Login: "octocat", Type: "reporter"}}, in.Credits); diff != "" {
+		t.Errorf("credits (-want +got):\n%s", diff)
+	}
+	for _, s := range []string{
+		"\n### Fixes\n\n* [CL 1234]",
+		"- [ ] Request a CVE for this advisory\n",
+		"- [ ] Submit the fixes and tag v0.9.1, 1.2.0\n",
+	} {
+		if !strings.Contains(in.Description, s) {
+			t.Errorf("description lacks %q:\n%s", s, in.Description)
+		}
+	}
+}
+
+func TestVulnRecordCheck(t *testing.T) {
+	rec := vulnRecord{
+		Summary:     "s",
+		Description: "d",
+		Severity:    "severe",
+		Affected:    []vulnAffected{{Package: "cuelang.org/go", Vulnerable: "< v0.9.1"}},
+	}
+	if err := rec.check(); err == nil || !strings.Contains(err.Error(), "severity") {
+		t.Errorf("got error %v; want an invalid severity", err)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+const flagApplyDryRun flagName = "dry-run"
+
+// newApplySuggestionsCmd creates a new apply-suggestions command
+func newApplySuggestionsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply-suggestions",
+		Short: "apply the suggested edits on a CL to the local checkout",
+		Long: `
+Usage of apply-suggestions:
+
+	apply-suggestions [--dry-run] CL
+
+apply-suggestions applies the suggested edits made on the current patchset of
+a CL to the local checkout, and amends HEAD with them, ready to be uploaded as
+a new patchset with git codereview mail. This allows a batch of trivial
+suggestions to be accepted at once.
+
+Suggested edits are the "suggestion" code blocks which reviewers add to
+inline comments in the Gerrit UI, replacing the commented lines, and the fix
+suggestions of robot comments. Only suggestions in unresolved threads are
+applied, and suggestions which overlap one applied before them are skipped.
+Suggestions on older patchsets are skipped too, as their line numbers may no
+longer match.
+
+HEAD must be the current patchset of the CL, and the working tree must be
+clean. With --dry-run, the suggestions are only listed.
+`,
+		RunE:              mkRunE(c, applySuggestionsDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	c.registerFlags(cmd).Bool(flagApplyDryRun, false, "only list the suggestions which would be applied")
+	return cmd
+}
+
+// position is a position within a file, with lines starting at 1 and
+// characters at 0, as in Gerrit's CommentRange entity.
+type position struct {
+	line, char int
+}
+
+// replacement replaces the text between start and end in a file.
+type replacement struct {
+	path       string
+	start, end position
+	text       string
+
+	// source describes where the replacement comes from, such as
+	// "suggestion by Jane Doe".
+	source string
+}
+
+// robotComment is the subset of Gerrit's RobotCommentInfo entity used by
+// apply-suggestions.
+type robotComment struct {
+	gerritComment
+	RobotID        string `json:"robot_id"`
+	FixSuggestions []struct {
+		Description  string `json:"description"`
+		Replacements []struct {
+			Path        string       `json:"path"`
+			Range       commentRange `json:"range"`
+			Replacement string       `json:"replacement"`
+		} `json:"replacements"`
+	} `json:"fix_suggestions"`
+}
+
+// parseSuggestion returns the contents of the suggestion code block in a
+// comment message, if any, with a trailing newline.
+func parseSuggestion(msg string) (string, bool) {
+	_, rest, ok := strings.Cut(msg, "```suggestion\n")
+	if !ok {
+		return "", false
+	}
+	var lines []string
+	for _, line := range strings.SplitAfter(rest, "\n") {
+		if strings.TrimSpace(line) == "```" {
+			return strings.Join(lines, ""), true
+		}
+		lines = append(lines, line)
+	}
+	return "", false
+}
+
+// suggestionReplacement returns the replacement of the lines commented on
+// by a human comment with a suggestion, if any. A range ending at the start
+// of a line does not include that line.
+func suggestionReplacement(c gerritComment) (replacement, bool) {
+	text, ok := parseSuggestion(c.Message)
+	if !ok || c.Line == 0 {
+		return replacement{}, false
+	}
+	start, end := c.Line, c.Line
+	if r := c.Range; r != nil {
+		start, end = r.StartLine, r.EndLine
+		if r.EndCharacter == 0 && end > start {
+			end--
+		}
+	}
+	return replacement{
+		path:   c.Path,
+		start:  position{start, 0},
+		end:    position{end + 1, 0},
+		text:   text,
+		source: "suggestion by " + c.Author.Name,
+	}, true
+}
+
+// unresolvedThreads returns the comments which start threads which are not
+// resolved, as given by the latest comment in each thread.
+func unresolvedThreads(comments map[string][]gerritComment) []gerritComment {
+	var res []gerritComment
+	for _, t := range commentThreads(comments) {
+		if !t.resolved() {
+			res = append(res, t.first())
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Updated.Time.Before(res[j].Updated.Time) })
+	return res
+}
+
+// offset returns the byte offset of p in content, counting characters as
+// runes. Positions beyond the end of a line or of the content are clamped.
+func offset(content string, p position) int {
+	off := 0
+	for line := 1; line < p.line; line++ {
+		i := strings.IndexByte(content[off:], '\n')
+		if i < 0 {
+			return len(content)
+		}
+		off += i + 1
+	}
+	for n := 0; n < p.char && off < len(content) && content[off] != '\n'; n++ {
+		_, size := utf8.DecodeRuneInString(content[off:])
+		off += size
+	}
+	return off
+}
+
+// applyReplacements applies the replacements for a file to its content, in
+// order of their position. Replacements overlapping one before them are not
+// applied, and are returned.
+func applyReplacements(content string, reps []replacement) (string, []replacement) {
+	type span struct {
+		start, end int
+		rep        replacement
+	}
+	var spans []span
+	for _, r := range reps {
+		spans = append(spans, span{offset(content, r.start), offset(content, r.end), r})
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var b strings.Builder
+	var skipped []replacement
+	last := 0
+	for _, s := range spans {
+		if s.start < last || s.end < s.start {
+			skipped = append(skipped, s.rep)
+			continue
+		}
+		b.WriteString(content[last:s.start])
+		b.WriteString(s.rep.text)
+		last = s.end
+	}
+	b.WriteString(content[last:])
+	return b.String(), skipped
+}
+
+func applySuggestionsDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single CL")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	var ch struct {
+		Number          int    `json:"_number"`
+		CurrentRevision string `json:"current_revision"`
+		Revisions       map[string]struct {
+			Number int    `json:"_number"`
+			Ref    string `json:"ref"`
+		} `json:"revisions"`
+	}
+	if err := cfg.gerritDo(http.MethodGet, "changes/"+id+"?o=CURRENT_REVISION", nil, &ch); err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	rev := ch.Revisions[ch.CurrentRevision]
+	head, err := run(ctx, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(head) != ch.CurrentRevision {
+		return fmt.Errorf("HEAD is not patchset %d of CL %d; check it out first with:\n\tgit fetch %s %s && git checkout FETCH_HEAD",
+			rev.Number, ch.Number, cfg.gerritURL+cfg.gerritProject(), rev.Ref)
+	}
+	if status, err := run(ctx, "git", "status", "--porcelain", "--untracked-files=no"); err != nil {
+		return err
+	} else if strings.TrimSpace(status) != "" {
+		return fmt.Errorf("the working tree has uncommitted changes")
+	}
+
+	comments, err := cfg.listComments(id)
+	if err != nil {
+		return err
+	}
+	var robots map[string][]robotComment
+	if err := cfg.gerritDo(http.MethodGet, "changes/"+id+"/robotcomments", nil, &robots); err != nil && gerritStatus(err) != http.StatusNotFound {
+		return fmt.Errorf("failed to list robot comments: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	byPath := make(map[string][]replacement)
+	outdated := 0
+	for _, c := range unresolvedThreads(comments) {
+		r, ok := suggestionReplacement(c)
+		if !ok {
+			continue
+		}
+		if c.PatchSet != rev.Number {
+			outdated++
+			continue
+		}
+		byPath[r.path] = append(byPath[r.path], r)
+	}
+	for path, rcs := range robots {
+		for _, rc := range rcs {
+			if len(rc.FixSuggestions) == 0 {
+				continue
+			}
+			if rc.PatchSet != rev.Number {
+				outdated++
+				continue
+			}
+			// Only the first fix applies; the others are alternatives.
+			fix := rc.FixSuggestions[0]
+			for _, fr := range fix.Replacements {
+				p := fr.Path
+				if p == "" {
+					p = path
+				}
+				byPath[p] = append(byPath[p], replacement{
+					path:   p,
+					start:  position{fr.Range.StartLine, fr.Range.StartCharacter},
+					end:    position{fr.Range.EndLine, fr.Range.EndCharacter},
+					text:   fr.Replacement,
+					source: fmt.Sprintf("fix by %s: %s", rc.RobotID, firstLine(fix.Description)),
+				})
+			}
+		}
+	}
+	if outdated > 0 {
+		fmt.Fprintf(w, "skipping %d suggestions on older patchsets\n", outdated)
+	}
+	if len(byPath) == 0 {
+		fmt.Fprintln(w, "no suggestions to apply")
+		return nil
+	}
+
+	gitRoot := cfg.gitRoot
+	applied := 0
+	var paths []string
+	for _, path := range sortedKeys(byPath) {
+		if strings.HasPrefix(path, "/") {
+			// Such as /COMMIT_MSG, which cannot be edited in the working
+			// tree.
+			fmt.Fprintf(w, "skipping suggestions on %s\n", path)
+			continue
+		}
+		file := filepath.Join(gitRoot, filepath.FromSlash(path))
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		content, skipped := applyReplacements(string(data), byPath[path])
+		for _, r := range byPath[path] {
+			if !slicesContains(skipped, r) {
+				fmt.Fprintf(w, "%s:%d: %s\n", path, r.start.line, r.source)
+				applied++
+			}
+		}
+		for _, r := range skipped {
+			fmt.Fprintf(w, "%s:%d: skipping overlapping %s\n", path, r.start.line, r.source)
+		}
+		if flagApplyDryRun.Bool(cmd) || content == string(data) {
+			continue
+		}
+		if err := os.WriteFile(file, []byte(content), 0o666); err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+	if flagApplyDryRun.Bool(cmd) || len(paths) == 0 {
+		return nil
+	}
+	if _, err := gitIn(ctx, gitRoot, append([]string{"add", "--"}, paths...)...); err != nil {
+		return err
+	}
+	if _, err := gitIn(ctx, gitRoot, "commit", "--quiet", "--amend", "--no-edit"); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "amended HEAD with %d suggestions; upload them as a new patchset with git codereview mail\n", applied)
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSuggestion(t *testing.T) {
+	cases := []struct {
+		msg  string
+		want string
+		ok   bool
+	}{
+		{msg: "Nit: typo.\n\n```suggestion\n\treturn nil\n```\n", want: "\treturn nil\n", ok: true},
+		{msg: "Remove this line.\n```suggestion\n```", want: "", ok: true},
+		{msg: "```go\nfoo()\n```", ok: false},
+		{msg: "```suggestion\nunterminated\n", ok: false},
+	}
+	for _, c := range cases {
+		got, ok := parseSuggestion(c.msg)
+		if ok != c.ok || got != c.want {
+			t.Errorf("parseSuggestion(%q) = %q, %v; want %q, %v", c.msg, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestApplyReplacements(t *testing.T) {
+	content := "package p\n\nfunc f() {\n\treturn naïve\n}\n"
+	lines := func(start, end int, text string) replacement {
+		return replacement{start: position{start, 0}, end: position{end + 1, 0}, text: text}
+	}
+	reps := []replacement{
+		// Replaces "naïve" with "nil", counting ï as one character.
+		{start: position{4, 8}, end: position{4, 13}, text: "nil"},
+		lines(1, 1, "package q\n"),
+		// Overlaps the first replacement, which comes before it.
+		{start: position{4, 10}, end: position{4, 11}, text: "I"},
+	}
+	got, skipped := applyReplacements(content, reps)
+	if want := "package q\n\nfunc f() {\n\treturn nil\n}\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if diff := cmp.Diff([]replacement{reps[2]}, skipped, cmp.AllowUnexported(replacement{}, position{})); diff != "" {
+		t.Errorf("skipped (-want +got):\n%s", diff)
+	}
+
+	// A suggestion on the last line without a trailing newline.
+	got, _ = applyReplacements("a\nb", []replacement{lines(2, 2, "c\n")})
+	if want := "a\nc\n"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestSuggestionsInUnresolvedThreads(t *testing.T) {
+	at := func(min int) gerrit.Timestamp {
+		return gerrit.Timestamp{Time: time.Date(2026, 10, 16, 9, min, 0, 0, time.UTC)}
+	}
+	comments := map[string][]gerritComment{
+		"a.go": {
+			{ID: "1", Path: "a.go", Line: 3, Message: "```suggestion\nx\n```", Unresolved: true, Updated: at(0)},
+			{ID: "2", Path: "a.go", Line: 3, InReplyTo: "1", Message: "Done", Unresolved: false, Updated: at(1)},
+			{
+				ID: "3", Path: "a.go", Line: 7, Message: "```suggestion\ny\n```", Unresolved: true, Updated: at(2),
+				Range: &commentRange{StartLine: 5, EndLine: 8, EndCharacter: 0},
+			},
+		},
+	}
+	threads := unresolvedThreads(comments)
+	if len(threads) != 1 || threads[0].ID != "3" {
+		t.Fatalf("got unresolved threads %v; want only 3", threads)
+	}
+	r, ok := suggestionReplacement(threads[0])
+	if !ok {
+		t.Fatal("no suggestion found")
+	}
+	if r.start != (position{5, 0}) || r.end != (position{8, 0}) || r.text != "y\n" {
+		t.Errorf("got replacement %+v; want lines 5 to 7 replaced by y", r)
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagArchiveFormat flagName = "format"
+	flagArchiveOutput flagName = "output"
+)
+
+// newArchiveCmd creates a new archive command
+func newArchiveCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "export the review history of a CL",
+		Long: `
+Usage of archive:
+
+	archive [--format markdown|html] [--output FILE] CL
+
+archive renders the full review history of a CL, including its patchsets,
+review messages, votes and inline comments, as a standalone Markdown or HTML
+document. This is useful for preserving the rationale behind large changes
+alongside design documents.
+
+The document is written to standard output unless --output is provided.
+`,
+		RunE:              mkRunE(c, archiveDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	c.registerFlags(cmd).String(flagArchiveFormat, "markdown", "output format: markdown or html").OneOf("markdown", "html")
+	c.registerFlags(cmd).StringP(flagArchiveOutput, "o", "", "write the document to this file")
+	return cmd
+}
+
+// archive is the data from which an archive document is rendered.
+type archive struct {
+	Number    int
+	Subject   string
+	Project   string
+	Branch    string
+	Status    string
+	Owner     string
+	URL       string
+	Patchsets []archivePatchset
+	Votes     []archiveVote
+	Messages  []archiveMessage
+	Files     []archiveFile
+}
+
+type archivePatchset struct {
+	Number   int
+	Created  string
+	Uploader string
+	Commit   string
+	Message  string
+}
+
+type archiveVote struct {
+	Label string
+	Name  string
+	Value string
+}
+
+type archiveMessage struct {
+	Date     string
+	Author   string
+	Patchset int
+	Message  string
+}
+
+type archiveFile struct {
+	Path     string
+	Comments []archiveComment
+}
+
+type archiveComment struct {
+	Patchset int
+	Line     int
+	Date     string
+	Author   string
+	Message  string
+}
+
+func archiveDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single CL number")
+	}
+	var render func(io.Writer, *archive) error
+	switch f := flagArchiveFormat.String(cmd); f {
+	case "markdown":
+		render = func(w io.Writer, a *archive) error { return archiveMarkdown.Execute(w, a) }
+	case "html":
+		render = func(w io.Writer, a *archive) error { return archiveHTML.Execute(w, a) }
+	default:
+		return fmt.Errorf("unknown format %q", f)
+	}
+
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	a, err := cfg.buildArchive(args[0])
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	if fn := flagArchiveOutput.String(cmd); fn != "" {
+		f, err := os.Create(fn)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return render(w, a)
+}
+
+func (c *config) buildArchive(changeID string) (*archive, error) {
+	in, _, err := c.gerritClient.Changes.GetChangeDetail(changeID, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"ALL_REVISIONS", "ALL_COMMITS", "MESSAGES", "DETAILED_LABELS", "DETAILED_ACCOUNTS"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get change %s: %w", changeID, err)
+	}
+	comments, err := c.listComments(strconv.Itoa(in.Number))
+	if err != nil {
+		return nil, err
+	}
+
+	a := &archive{
+		Number:  in.Number,
+		Subject: in.Subject,
+		Project: in.Project,
+		Branch:  in.Branch,
+		Status:  in.Status,
+		Owner:   accountName(in.Owner),
+		URL:     c.changeURL(in.Number),
+	}
+	for hash, rev := range in.Revisions {
+		a.Patchsets = append(a.Patchsets, archivePatchset{
+			Number:   rev.Number,
+			Created:  formatTime(rev.Created.Time),
+			Uploader: accountName(rev.Uploader),
+			Commit:   hash,
+			Message:  rev.Commit.Message,
+		})
+	}
+	sort.Slice(a.Patchsets, func(i, j int) bool {
+		return a.Patchsets[i].Number < a.Patchsets[j].Number
+	})
+	for label, info := range in.Labels {
+		for _, approval := range info.All {
+			if approval.Value == 0 {
+				continue
+			}
+			a.Votes = append(a.Votes, archiveVote{
+				Label: label,
+				Name:  accountName(approval.AccountInfo),
+				Value: fmt.Sprintf("%+d", approval.Value),
+			})
+		}
+	}
+	sort.Slice(a.Votes, func(i, j int) bool {
+		if a.Votes[i].Label != a.Votes[j].Label {
+			return a.Votes[i].Label < a.Votes[j].Label
+		}
+		return a.Votes[i].Name < a.Votes[j].Name
+	})
+	for _, m := range in.Messages {
+		a.Messages = append(a.Messages, archiveMessage{
+			Date:     formatTime(m.Date.Time),
+			Author:   accountName(m.Author),
+			Patchset: m.RevisionNumber,
+			Message:  m.Message,
+		})
+	}
+	for path, cs := range comments {
+		f := archiveFile{Path: path}
+		for _, cm := range cs {
+			f.Comments = append(f.Comments, archiveComment{
+				Patchset: cm.PatchSet,
+				Line:     cm.Line,
+				Date:     formatTime(cm.Updated.Time),
+				Author:   accountName(cm.Author),
+				Message:  cm.Message,
+			})
+		}
+		sort.SliceStable(f.Comments, func(i, j int) bool {
+			ci, cj := f.Comments[i], f.Comments[j]
+			if ci.Patchset != cj.Patchset {
+				return ci.Patchset < cj.Patchset
+			}
+			if ci.Line != cj.Line {
+				return ci.Line < cj.Line
+			}
+			return ci.Date < cj.Date
+		})
+		a.Files = append(a.Files, f)
+	}
+	sort.Slice(a.Files, func(i, j int) bool {
+		return a.Files[i].Path < a.Files[j].Path
+	})
+	return a, nil
+}
+
+// accountName returns a human-readable name for a Gerrit account.
+func accountName(a gerrit.AccountInfo) string {
+	switch {
+	case a.Name != "":
+		return a.Name
+	case a.Username != "":
+		return a.Username
+	case a.Email != "":
+		return a.Email
+	}
+	return fmt.Sprintf("account %d", a.AccountID)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
+
+var archiveMarkdown = template.Must(template.New("markdown").Parse(`# CL {{.Number}}: {{.Subject}}
+
+* URL: {{.URL}}
+* Project: {{.Project}} (branch {{.Branch}})
+* Owner: {{.Owner}}
+* Status: {{.Status}}
+{{if .Votes}}
+## Votes
+{{range .Votes}}
+* {{.Label}} {{.Value}} by {{.Name}}{{end}}
+{{end}}
+## Patchsets
+{{range .Patchsets}}
+### Patchset {{.Number}}
+
+Uploaded by {{.Uploader}} on {{.Created}} as {{.Commit}}.
+
+` + "```" + `
+{{.Message}}
+` + "```" + `
+{{end}}
+## Review messages
+{{range .Messages}}
+### {{.Author}} on {{.Date}} (patchset {{.Patchset}})
+
+{{.Message}}
+{{end}}{{if .Files}}
+## Inline comments
+{{range .Files}}
+### {{.Path}}
+{{range .Comments}}
+* Patchset {{.Patchset}}, line {{.Line}}, {{.Author}} on {{.Date}}:
+
+  {{.Message}}
+{{end}}{{end}}{{end}}`))
+
+var archiveHTML = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CL {{.Number}}: {{.Subject}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: auto; }
+pre { background: #f4f4f4; padding: 1em; white-space: pre-wrap; }
+.meta { color: #666; }
+</style>
+</head>
+<body>
+<h1>CL {{.Number}}: {{.Subject}}</h1>
+<ul>
+<li>URL: <a href="{{.URL}}">{{.URL}}</a></li>
+<li>Project: {{.Project}} (branch {{.Branch}})</li>
+<li>Owner: {{.Owner}}</li>
+<li>Status: {{.Status}}</li>
+</ul>
+{{if .Votes}}<h2>Votes</h2>
+<ul>
+{{range .Votes}}<li>{{.Label}} {{.Value}} by {{.Name}}</li>
+{{end}}</ul>
+{{end}}<h2>Patchsets</h2>
+{{range .Patchsets}}<h3>Patchset {{.Number}}</h3>
+<p class="meta">Uploaded by {{.Uploader}} on {{.Created}} as {{.Commit}}.</p>
+<pre>{{.Message}}</pre>
+{{end}}<h2>Review messages</h2>
+{{range .Messages}}<h3>{{.Author}} <span class="meta">on {{.Date}} (patchset {{.Patchset}})</span></h3>
+<pre>{{.Message}}</pre>
+{{end}}{{if .Files}}<h2>Inline comments</h2>
+{{range .Files}}<h3>{{.Path}}</h3>
+{{range .Comments}}<p class="meta">Patchset {{.Patchset}}, line {{.Line}}, {{.Author}} on {{.Date}}:</p>
+<pre>{{.Message}}</pre>
+{{end}}{{end}}{{end}}</body>
+</html>
+`))
@@ -0,0 +1,273 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagAuditFormat flagName = "format"
+	flagAuditSince  flagName = "since"
+	flagAuditNoRuns flagName = "no-runs"
+	flagAuditOutput flagName = "output"
+)
+
+// newAuditCmd creates a new audit command, which groups the subcommands for
+// working with the audit log of dispatches.
+func newAuditCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "work with the local audit log of dispatches",
+	}
+	subCommands := []*cobra.Command{
+		newAuditExportCmd(c),
+	}
+	for _, sub := range subCommands {
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
+
+// newAuditExportCmd creates a new audit export command
+func newAuditExportCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export the audit log and run metadata for analysis",
+		Long: `
+Usage of audit export:
+
+	audit export [--format csv|bq] [--since DURATION] [--no-runs] [--output FILE]
+
+export writes the dispatches recorded in the local audit log, as listed by
+history, oldest first, along with the metadata of the workflow run each
+resulted in: its status and conclusion, when it was created, started and last
+updated, how long it was queued for and how long it ran for. Durations are in
+seconds, and are empty when not known, such as for runs still in progress.
+This is meant for analyses such as of CI health and of the latency of
+contributions, which are otherwise done by hand.
+
+The export is written as CSV with a header row, or with --format bq as
+newline-delimited JSON, which can be loaded into BigQuery with:
+
+	bq load --source_format=NEWLINE_DELIMITED_JSON --autodetect TABLE FILE
+
+--since only exports dispatches made within the given window, such as 30d;
+by default all are. --no-runs skips looking up the workflow runs via the
+GitHub API, leaving their columns empty.
+`,
+		RunE: mkRunE(c, auditExportDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagAuditFormat, "csv", "output format: csv or bq").OneOf("csv", "bq")
+	flags.String(flagAuditSince, "", "only export dispatches within this window, such as 30d").Validate(func(v string) error {
+		if v == "" {
+			return nil // all dispatches
+		}
+		_, err := parseDuration(v)
+		return err
+	})
+	flags.Bool(flagAuditNoRuns, false, "do not look up the resulting workflow runs")
+	flags.StringP(flagAuditOutput, "o", "", "write the export to this file")
+	return cmd
+}
+
+// auditRecord is a dispatch in the audit log along with the metadata of the
+// workflow run it resulted in, as exported by audit export. The JSON field
+// names are valid BigQuery column names.
+type auditRecord struct {
+	Time     time.Time `json:"time"`
+	Repo     string    `json:"repo"`
+	Type     string    `json:"type"`
+	Title    string    `json:"title"`
+	CL       int       `json:"cl,omitempty"`
+	Patchset int       `json:"patchset,omitempty"`
+	Ref      string    `json:"ref,omitempty"`
+
+	RunID      int64      `json:"run_id,omitempty"`
+	RunURL     string     `json:"run_url,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	Conclusion string     `json:"conclusion,omitempty"`
+	Created    *time.Time `json:"run_created,omitempty"`
+	Started    *time.Time `json:"run_started,omitempty"`
+	Updated    *time.Time `json:"run_updated,omitempty"`
+
+	// QueuedSeconds is the time from the dispatch until the run started,
+	// and RunSeconds is the time the run took once started, which is only
+	// known once it completed.
+	QueuedSeconds *float64 `json:"queued_seconds,omitempty"`
+	RunSeconds    *float64 `json:"run_seconds,omitempty"`
+}
+
+// newAuditRecord returns the record of the dispatch e, which resulted in run,
+// if it is not nil.
+func newAuditRecord(e auditEntry, run *github.WorkflowRun) auditRecord {
+	r := auditRecord{
+		Time:     e.Time.UTC(),
+		Repo:     e.Repo,
+		Type:     e.Type,
+		Title:    e.Title,
+		CL:       e.CL,
+		Patchset: e.Patchset,
+		Ref:      e.Ref,
+	}
+	if r.Type == "" {
+		r.Type, _, _ = strings.Cut(e.Title, " ")
+	}
+	if run == nil {
+		return r
+	}
+	timestamp := func(ts github.Timestamp) *time.Time {
+		if ts.IsZero() {
+			return nil
+		}
+		t := ts.Time.UTC()
+		return &t
+	}
+	seconds := func(from, to *time.Time) *float64 {
+		if from == nil || to == nil {
+			return nil
+		}
+		s := to.Sub(*from).Seconds()
+		return &s
+	}
+	r.RunID = run.GetID()
+	r.RunURL = run.GetHTMLURL()
+	r.Status = run.GetStatus()
+	r.Conclusion = run.GetConclusion()
+	r.Created = timestamp(run.GetCreatedAt())
+	r.Started = timestamp(run.GetRunStartedAt())
+	r.Updated = timestamp(run.GetUpdatedAt())
+	r.QueuedSeconds = seconds(&r.Time, r.Started)
+	if r.Status == "completed" {
+		r.RunSeconds = seconds(r.Started, r.Updated)
+	}
+	return r
+}
+
+func auditExportDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("audit export does not take any arguments")
+	}
+	ctx := cmd.Context()
+	entries, err := readAuditLog()
+	if err != nil {
+		return err
+	}
+	if since := flagAuditSince.String(cmd); since != "" {
+		window, err := parseDuration(since)
+		if err != nil {
+			return err
+		}
+		after := time.Now().Add(-window)
+		var selected []auditEntry
+		for _, e := range entries {
+			if !e.Time.Before(after) {
+				selected = append(selected, e)
+			}
+		}
+		entries = selected
+	}
+
+	runs := make(map[string][]*github.WorkflowRun)
+	if !flagAuditNoRuns.Bool(cmd) && len(entries) > 0 {
+		cfg, err := loadConfig(ctx)
+		if err != nil {
+			return err
+		}
+		runs = cfg.dispatchedRunsFor(ctx, entries)
+	}
+	records := make([]auditRecord, len(entries))
+	for i, e := range entries {
+		records[i] = newAuditRecord(e, matchDispatchedRun(runs[e.Repo], e))
+	}
+
+	w := cmd.OutOrStdout()
+	if fn := flagAuditOutput.String(cmd); fn != "" {
+		f, err := os.Create(fn)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if flagAuditFormat.String(cmd) == "bq" {
+		return writeAuditJSON(w, records)
+	}
+	return writeAuditCSV(w, records)
+}
+
+// writeAuditJSON writes records as newline-delimited JSON.
+func writeAuditJSON(w io.Writer, records []auditRecord) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditCSVHeader is the header row of the CSV export, matching the JSON
+// field names of auditRecord.
+var auditCSVHeader = []string{
+	"time", "repo", "type", "title", "cl", "patchset", "ref",
+	"run_id", "run_url", "status", "conclusion", "run_created", "run_started", "run_updated",
+	"queued_seconds", "run_seconds",
+}
+
+// writeAuditCSV writes records as CSV with a header row. Times are in RFC
+// 3339 format, and unknown values are left empty.
+func writeAuditCSV(w io.Writer, records []auditRecord) error {
+	num := func(n int64) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.FormatInt(n, 10)
+	}
+	tm := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	secs := func(s *float64) string {
+		if s == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*s, 'f', 0, 64)
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(auditCSVHeader)
+	for _, r := range records {
+		cw.Write([]string{
+			tm(&r.Time), r.Repo, r.Type, r.Title, num(int64(r.CL)), num(int64(r.Patchset)), r.Ref,
+			num(r.RunID), r.RunURL, r.Status, r.Conclusion, tm(r.Created), tm(r.Started), tm(r.Updated),
+			secs(r.QueuedSeconds), secs(r.RunSeconds),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+func TestAuditExport(t *testing.T) {
+	at := func(min, sec int) time.Time { return time.Date(2026, 10, 1, 12, min, sec, 0, time.UTC) }
+	ts := func(t time.Time) *github.Timestamp { return &github.Timestamp{Time: t} }
+	str := func(s string) *string { return &s }
+	id := int64(42)
+	records := []auditRecord{
+		newAuditRecord(auditEntry{
+			Time:     at(0, 0),
+			Repo:     "cue-lang/cue",
+			Title:    "trybot run for refs/changes/52/551352/3",
+			Type:     "trybot",
+			CL:       551352,
+			Patchset: 3,
+			Ref:      "refs/changes/52/551352/3",
+		}, &github.WorkflowRun{
+			ID:           &id,
+			HTMLURL:      str("https://github.com/cue-lang/cue/actions/runs/42"),
+			Status:       str("completed"),
+			Conclusion:   str("success"),
+			CreatedAt:    ts(at(0, 5)),
+			RunStartedAt: ts(at(0, 30)),
+			UpdatedAt:    ts(at(10, 30)),
+		}),
+		// Without a run, and with the type only known from the title.
+		newAuditRecord(auditEntry{Time: at(1, 0), Repo: "cue-lang/cue", Title: "unity run for refs/changes/52/551352/3"}, nil),
+	}
+
+	var buf bytes.Buffer
+	if err := writeAuditCSV(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	want := "time,repo,type,title,cl,patchset,ref,run_id,run_url,status,conclusion,run_created,run_started,run_updated,queued_seconds,run_seconds\n" +
+		"2026-10-01T12:00:00Z,cue-lang/cue,trybot,trybot run for refs/changes/52/551352/3,551352,3,refs/changes/52/551352/3," +
+		"42,https://github.com/cue-lang/cue/actions/runs/42,completed,success,2026-10-01T12:00:05Z,2026-10-01T12:00:30Z,2026-10-01T12:10:30Z,30,600\n" +
+		"2026-10-01T12:01:00Z,cue-lang/cue,unity,unity run for refs/changes/52/551352/3,,,,,,,,,,,,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got CSV:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := writeAuditJSON(&buf, records[1:]); err != nil {
+		t.Fatal(err)
+	}
+	want = `{"time":"2026-10-01T12:01:00Z","repo":"cue-lang/cue","type":"unity","title":"unity run for refs/changes/52/551352/3"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got JSON:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// The audit log is a local record of the repository dispatches made by
+// cueckoo, one JSON-encoded auditEntry per line, such that users can tell what
+// they have already triggered.
+
+// auditEntry records a single repository dispatch.
+type auditEntry struct {
+	Time time.Time `json:"time"`
+
+	// Repo is the repository the dispatch was sent to, as OWNER/REPO.
+	Repo string `json:"repo"`
+
+	// Title is the event type of the dispatch, which becomes the display
+	// title of the resulting workflow run, e.g. "trybot run for REF".
+	Title string `json:"title"`
+
+	// Type is the type field of the payload, such as "trybot".
+	Type string `json:"type,omitempty"`
+
+	CL       int    `json:"CL,omitempty"`
+	Patchset int    `json:"patchset,omitempty"`
+	Ref      string `json:"ref,omitempty"`
+}
+
+// auditLogPath returns the path of the audit log.
+func auditLogPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+// stateDir returns the directory where cueckoo keeps local state, such as the
+// audit log. It is $XDG_STATE_HOME/cueckoo if set, and otherwise the
+// directory of the user config.
+func stateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "cueckoo"), nil
+	}
+	path, err := userConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
+// recordDispatch appends an entry for the dispatch of payload to owner/repo
+// to the audit log. Failing to do so does not fail the dispatch.
+func recordDispatch(owner, repo string, payload github.DispatchRequestOptions) {
+	e := auditEntry{
+		Time:  time.Now().UTC(),
+		Repo:  owner + "/" + repo,
+		Title: payload.EventType,
+	}
+	if payload.ClientPayload != nil {
+		var p repositoryDispatchPayload
+		if err := json.Unmarshal(*payload.ClientPayload, &p); err == nil {
+			e.Type, e.CL, e.Patchset, e.Ref = p.Type, p.CL, p.Patchset, p.Ref
+		}
+	}
+	if err := appendAuditEntry(e); err != nil {
+		debugf("failed to record dispatch in audit log: %v\n", err)
+	}
+}
+
+func appendAuditEntry(e auditEntry) error {
+	path, err := auditLogPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o666)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// readAuditLog returns the entries of the audit log, oldest first. It is
+// empty if nothing has been recorded yet.
+func readAuditLog() ([]auditEntry, error) {
+	path, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var res []auditEntry
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		var e auditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		res = append(res, e)
+	}
+	return res, sc.Err()
+}
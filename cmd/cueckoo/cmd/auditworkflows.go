@@ -0,0 +1,231 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newAuditWorkflowsCmd creates a new audit-workflows command
+func newAuditWorkflowsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit-workflows",
+		Short: "check which repository dispatch events the target workflows handle",
+		Long: `
+Usage of audit-workflows:
+
+	audit-workflows
+
+audit-workflows lists the GitHub workflows in the repositories that cueckoo
+dispatches events to, the GitHub mirror and the unity repository if
+configured, which are triggered by repository_dispatch events, along with the
+event types they handle.
+
+A workflow handles an event type if it filters on it via the types of its
+repository_dispatch trigger, or via a condition such as
+
+	github.event.client_payload.type == 'trybot'
+
+which is how the workflows generated by the CUE CI packages tell events
+apart, as the event types sent by cueckoo double as run titles.
+
+The workflows are read from the default branch of each repository without
+a full YAML parser, so unusual layouts may not be recognised.
+
+audit-workflows then reports the event types which cueckoo can send to a
+repository but which none of its workflows handle, and the event types
+handled by workflows which cueckoo never sends. It exits with status 1 if
+there are any.
+`,
+		RunE: mkRunE(c, auditWorkflowsDef),
+	}
+	return cmd
+}
+
+// dispatchTargets returns the event types which cueckoo can send to each
+// repository, keyed by OWNER/REPO.
+func (c *config) dispatchTargets() map[string][]string {
+	res := map[string][]string{
+		c.githubOwner + "/" + c.githubRepo: {
+			string(eventTypeTrybot),
+			string(eventTypeCompat),
+			string(eventTypePreview),
+			string(eventTypePRTrybot),
+		},
+	}
+	if c.unityRepo != "" {
+		res[c.unityOwner+"/"+c.unityRepo] = append(res[c.unityOwner+"/"+c.unityRepo], string(eventTypeUnity))
+	}
+	return res
+}
+
+func auditWorkflowsDef(cmd *Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected no arguments")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "REPO\tWORKFLOW\tTYPES")
+	var problems []string
+	targets := cfg.dispatchTargets()
+	for _, repo := range sortedKeys(targets) {
+		owner, name, _ := strings.Cut(repo, "/")
+		workflows, err := cfg.dispatchWorkflows(ctx, owner, name)
+		if err != nil {
+			return err
+		}
+		handled := make(map[string]bool)
+		for _, wf := range sortedKeys(workflows) {
+			types := workflows[wf]
+			for _, t := range types {
+				handled[t] = true
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", repo, wf, orNone(strings.Join(types, ", ")))
+		}
+		sent := make(map[string]bool)
+		for _, t := range targets[repo] {
+			sent[t] = true
+			if !handled[t] {
+				problems = append(problems, fmt.Sprintf("%s: no workflow handles %q events sent by cueckoo", repo, t))
+			}
+		}
+		for _, t := range sortedKeys(handled) {
+			if !sent[t] {
+				problems = append(problems, fmt.Sprintf("%s: workflows handle %q events, which cueckoo never sends", repo, t))
+			}
+		}
+	}
+	tw.Flush()
+	if len(problems) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+	}
+	return fmt.Errorf("found %d mismatches between the events sent by cueckoo and the workflows handling them", len(problems))
+}
+
+// dispatchWorkflows returns the event types handled by each workflow in
+// owner/repo which is triggered by repository_dispatch events, keyed by the
+// file name of the workflow.
+func (c *config) dispatchWorkflows(ctx context.Context, owner, repo string) (map[string][]string, error) {
+	const dir = ".github/workflows"
+	_, entries, resp, err := c.githubClient.Repositories.GetContents(ctx, owner, repo, dir, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list workflows of %s/%s: %w", owner, repo, err)
+	}
+	res := make(map[string][]string)
+	for _, e := range entries {
+		name := e.GetName()
+		if e.GetType() != "file" || (path.Ext(name) != ".yml" && path.Ext(name) != ".yaml") {
+			continue
+		}
+		file, _, _, err := c.githubClient.Repositories.GetContents(ctx, owner, repo, e.GetPath(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s in %s/%s: %w", e.GetPath(), owner, repo, err)
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s in %s/%s: %w", e.GetPath(), owner, repo, err)
+		}
+		if ok, types := dispatchTypes(content); ok {
+			res[name] = types
+		}
+	}
+	return res, nil
+}
+
+var (
+	repositoryDispatchRegex = regexp.MustCompile(`^(\s*)repository_dispatch\s*:`)
+	dispatchTypesRegex      = regexp.MustCompile(`^\s*types\s*:\s*(.*)$`)
+	payloadTypeRegex        = regexp.MustCompile(`client_payload\.type\s*==\s*'([^']+)'`)
+)
+
+// dispatchTypes reports whether the workflow is triggered by
+// repository_dispatch events, and if so, which event types it handles, either
+// via the types of the trigger or via conditions on the type field of the
+// client payload.
+func dispatchTypes(workflow string) (ok bool, types []string) {
+	seen := make(map[string]bool)
+	add := func(t string) {
+		t = strings.Trim(strings.TrimSpace(t), `'"`)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	lines := strings.Split(workflow, "\n")
+	for i := 0; i < len(lines); i++ {
+		m := repositoryDispatchRegex.FindStringSubmatch(lines[i])
+		if m == nil {
+			if strings.Contains(lines[i], "repository_dispatch") && strings.Contains(lines[i], "on:") {
+				// The inline form, such as "on: [push, repository_dispatch]".
+				ok = true
+			}
+			continue
+		}
+		ok = true
+		indent := len(m[1])
+		// Look for the types within the block of the trigger.
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if len(line)-len(strings.TrimLeft(line, " ")) <= indent {
+				i--
+				break
+			}
+			tm := dispatchTypesRegex.FindStringSubmatch(line)
+			if tm == nil {
+				continue
+			}
+			if list := strings.TrimSpace(tm[1]); list != "" {
+				for _, t := range strings.Split(strings.Trim(list, "[]"), ",") {
+					add(t)
+				}
+				continue
+			}
+			for i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "- ") {
+				i++
+				add(strings.TrimPrefix(strings.TrimSpace(lines[i]), "- "))
+			}
+		}
+	}
+	if !ok {
+		return false, nil
+	}
+	for _, m := range payloadTypeRegex.FindAllStringSubmatch(workflow, -1) {
+		add(m[1])
+	}
+	return true, types
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDispatchTypes(t *testing.T) {
+	cases := []struct {
+		name     string
+		workflow string
+		ok       bool
+		types    []string
+	}{{
+		name: "NoDispatch",
+		workflow: `
+on:
+  push:
+    branches: [master]
+  pull_request:
+    types: [opened]
+`,
+	}, {
+		name: "PayloadType",
+		workflow: `
+on:
+  push:
+    branches: [ci/test]
+  repository_dispatch: {}
+jobs:
+  test:
+    if: ${{ github.event.client_payload.type == 'trybot' }}
+    steps:
+      - if: github.event.client_payload.type == 'trybot'
+`,
+		ok:    true,
+		types: []string{"trybot"},
+	}, {
+		name: "InlineTypes",
+		workflow: `
+on:
+  pull_request:
+    types: [opened]
+  repository_dispatch:
+    types: [unity, "compat"]
+`,
+		ok:    true,
+		types: []string{"unity", "compat"},
+	}, {
+		name: "ListTypes",
+		workflow: `
+on:
+  repository_dispatch:
+    # Only previews.
+    types:
+      - preview
+      - 'docs'
+  workflow_dispatch:
+`,
+		ok:    true,
+		types: []string{"preview", "docs"},
+	}, {
+		name: "InlineTrigger",
+		workflow: `
+on: [push, repository_dispatch]
+`,
+		ok: true,
+	}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ok, types := dispatchTypes(c.workflow)
+			if ok != c.ok {
+				t.Errorf("got ok %v; want %v", ok, c.ok)
+			}
+			if diff := cmp.Diff(c.types, types); diff != "" {
+				t.Errorf("types (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
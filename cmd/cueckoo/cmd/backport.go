@@ -0,0 +1,431 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/cue-lang/contrib-tools/internal/gitcmd"
+	"github.com/cue-lang/contrib-tools/internal/trailers"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagBackportBranch      flagName = "branch"
+	flagBackportFormat      flagName = "format"
+	flagBackportNewChangeID flagName = "new-change-id"
+
+	// releaseBranchPrefix prefixes the names of release branches, such as
+	// release-branch.v0.8.
+	releaseBranchPrefix = "release-branch."
+
+	// backportHashtagPrefix prefixes the hashtags requesting a backport to
+	// a release branch, such as backport-v0.8 for release-branch.v0.8.
+	backportHashtagPrefix = "backport-"
+)
+
+// newBackportCmd creates a new backport command, which cherry-picks a CL
+// onto a release branch, and groups the subcommands for managing backports.
+func newBackportCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backport",
+		Short: "manage backports to release branches",
+		Long: `
+Usage of backport:
+
+	backport [--new-change-id] CL BRANCH
+	backport status [--branch BRANCH,...] [--format table|markdown|json]
+
+backport cherry-picks the current patchset of CL onto the release branch
+BRANCH, such as ` + releaseBranchPrefix + `v0.8 or just v0.8, ready to be mailed
+for review with git-codereview mail. The cherry-pick is made on a new branch
+backport-CL-VERSION tracking origin/BRANCH, which is fetched first.
+
+The commit message of the CL is kept, with a "(cherry-pick of CL N)" line
+added before its trailers, and without the trailers which Gerrit added when
+the CL was reviewed and submitted, such as Reviewed-on. The Change-Id is kept
+too, which Gerrit allows for changes on different branches, such that
+backport status finds the cherry-pick; --new-change-id gives it a new one
+instead. If the cherry-pick has conflicts, resolve them and run git
+cherry-pick --continue, which uses the prepared commit message.
+
+See backport status --help for reporting on the backports requested for
+each release branch.
+`,
+		RunE: mkRunE(c, backportDef),
+	}
+	c.registerFlags(cmd).Bool(flagBackportNewChangeID, false, "give the cherry-pick a new Change-Id")
+	cmd.AddCommand(newBackportStatusCmd(c))
+	return cmd
+}
+
+// releaseBranch returns the name of the release branch given as name, which
+// is either a full branch name or only its version, such as v0.8.
+func releaseBranch(name string) (string, error) {
+	if strings.HasPrefix(name, releaseBranchPrefix) {
+		return name, nil
+	}
+	if strings.HasPrefix(name, "v") && !strings.Contains(name, "/") {
+		return releaseBranchPrefix + name, nil
+	}
+	return "", fmt.Errorf("%q is not a release branch; release branches are named like %sv0.8", name, releaseBranchPrefix)
+}
+
+// submitTrailers are the trailers which Gerrit adds to a commit as it is
+// reviewed and submitted, which do not apply to a cherry-pick of it.
+var submitTrailers = []string{"Reviewed-on", "Reviewed-by", "Tested-by", "TryBot-Result", "Unity-Result"}
+
+// backportMessage returns the commit message for the cherry-pick of CL cl,
+// whose commit message is msg. If changeID is not empty, it replaces the
+// Change-Id of the CL.
+func backportMessage(msg string, cl int, changeID string) string {
+	body, ts := trailers.Split(msg)
+	var kept []trailers.Trailer
+	for _, t := range ts {
+		switch {
+		case slicesContains(submitTrailers, t.Key):
+		case t.Key == "Change-Id" && changeID != "":
+			kept = append(kept, trailers.Trailer{Key: t.Key, Value: changeID})
+		default:
+			kept = append(kept, t)
+		}
+	}
+	body += fmt.Sprintf("\n\n(cherry-pick of CL %d)", cl)
+	return trailers.Format(body, kept)
+}
+
+func backportDef(cmd *Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected a CL and a release branch")
+	}
+	branch, err := releaseBranch(args[1])
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	ch, _, err := cfg.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION", "CURRENT_COMMIT"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	if ch.Branch == branch {
+		return fmt.Errorf("CL %d is already for %s", ch.Number, branch)
+	}
+	rev, ok := ch.Revisions[ch.CurrentRevision]
+	if !ok || rev.Commit.Message == "" {
+		return fmt.Errorf("failed to get the current revision of CL %d", ch.Number)
+	}
+
+	newID := ""
+	if flagBackportNewChangeID.Bool(cmd) {
+		if newID, err = newChangeID(); err != nil {
+			return err
+		}
+	}
+	msg := backportMessage(rev.Commit.Message, ch.Number, newID)
+
+	local := fmt.Sprintf("backport-%d-%s", ch.Number, strings.TrimPrefix(branch, releaseBranchPrefix))
+	if _, err := run(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+local); err == nil {
+		return fmt.Errorf("branch %q already exists; delete it to start over", local)
+	}
+	if err := gitcmd.New("").Fetch(ctx, "origin", 0, branch); err != nil {
+		return err
+	}
+	if _, err := run(ctx, "git", "fetch", "--quiet", cfg.gerritURL+cfg.gerritProject(), rev.Ref); err != nil {
+		return err
+	}
+	if _, err := run(ctx, "git", "switch", "--quiet", "--create", local, "--track", "origin/"+branch); err != nil {
+		return err
+	}
+	if _, err := run(ctx, "git", "cherry-pick", ch.CurrentRevision); err != nil {
+		// Have git cherry-pick --continue use the prepared message once
+		// the conflicts are resolved.
+		if path, perr := run(ctx, "git", "rev-parse", "--git-path", "MERGE_MSG"); perr == nil {
+			if werr := os.WriteFile(strings.TrimSpace(path), []byte(msg), 0o666); werr != nil {
+				debugf("failed to prepare the commit message: %v\n", werr)
+			}
+		}
+		return fmt.Errorf("failed to cherry-pick CL %d onto %s; resolve the conflicts on branch %q and run git cherry-pick --continue: %v", ch.Number, branch, local, err)
+	}
+	amend := exec.CommandContext(ctx, "git", "commit", "--quiet", "--amend", "--no-verify", "-F", "-")
+	amend.Stdin = strings.NewReader(msg)
+	if out, err := amend.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to amend the commit message: %v:\n%s", err, out)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "cherry-picked CL %d onto %s as branch %s; review it and mail it with git-codereview mail\n", ch.Number, branch, local)
+	return nil
+}
+
+// newBackportStatusCmd creates a new backport status command
+func newBackportStatusCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "report on the CLs to backport to each release branch",
+		Long: `
+Usage of backport status:
+
+	backport status [--branch BRANCH,...] [--format table|markdown|json]
+
+status lists the CLs to be backported to each release branch, and the state
+of their cherry-picks. A CL is requested for backport to a release branch
+such as ` + releaseBranchPrefix + `v0.8 by adding the hashtag ` + backportHashtagPrefix + `v0.8 to it. Its
+cherry-pick is found on the release branch by its Change-Id, and is one of:
+
+	pending      the CL itself is not merged yet
+	missing      no cherry-pick has been created
+	open         the cherry-pick is awaiting review
+	conflicting  the cherry-pick has merge conflicts to resolve
+	merged       the cherry-pick is merged
+	abandoned    the cherry-pick was abandoned
+
+For each branch, status then says how many backports remain before a patch
+release can be cut. Release branches are those whose names start with
+"` + releaseBranchPrefix + `"; only those with backport requests are listed,
+unless selected by --branch. The report is printed as a table, a Markdown
+table suitable for pasting into a release issue, or as JSON.
+`,
+		RunE: mkRunE(c, backportStatusDef),
+	}
+	c.registerFlags(cmd).String(flagBackportBranch, "", "comma-separated release branches to report on")
+	c.registerFlags(cmd).String(flagBackportFormat, "table", "output format: table, markdown or json").OneOf("table", "markdown", "json")
+	return cmd
+}
+
+// backportChange is the subset of Gerrit's ChangeInfo entity used by
+// backport status.
+type backportChange struct {
+	Number               int    `json:"_number"`
+	ChangeID             string `json:"change_id"`
+	Subject              string `json:"subject"`
+	Status               string `json:"status"`
+	Mergeable            *bool  `json:"mergeable"`
+	ContainsGitConflicts bool   `json:"contains_git_conflicts"`
+}
+
+// backport is the state of the backport of a CL to a release branch.
+type backport struct {
+	CL      int    `json:"cl"`
+	Subject string `json:"subject"`
+	State   string `json:"state"`
+
+	// CherryPick is the CL number of the cherry-pick, or zero if there is
+	// none.
+	CherryPick int `json:"cherryPick,omitempty"`
+}
+
+// branchBackports are the backports to a release branch.
+type branchBackports struct {
+	Branch    string     `json:"branch"`
+	Backports []backport `json:"backports"`
+}
+
+// remaining returns the number of backports which are not merged yet.
+// Abandoned cherry-picks are counted as done, as they were given up on.
+func (b branchBackports) remaining() int {
+	n := 0
+	for _, bp := range b.Backports {
+		if bp.State != "merged" && bp.State != "abandoned" {
+			n++
+		}
+	}
+	return n
+}
+
+// summary describes what remains before a patch release can be cut from
+// the branch.
+func (b branchBackports) summary() string {
+	switch n := b.remaining(); n {
+	case 0:
+		return "ready for a patch release"
+	case 1:
+		return "1 backport remains"
+	default:
+		return fmt.Sprintf("%d backports remain", n)
+	}
+}
+
+// backportState returns the state of the backport of orig given its
+// cherry-picks to the release branch, and the cherry-pick the state is
+// for, if any. The most advanced cherry-pick wins, such that an abandoned
+// attempt followed by a merged one counts as merged.
+func backportState(orig backportChange, picks []backportChange) (string, int) {
+	if orig.Status != "MERGED" {
+		return "pending", 0
+	}
+	rank := map[string]int{"abandoned": 1, "conflicting": 2, "open": 3, "merged": 4}
+	state, cl := "missing", 0
+	for _, p := range picks {
+		var s string
+		switch {
+		case p.Status == "MERGED":
+			s = "merged"
+		case p.Status == "ABANDONED":
+			s = "abandoned"
+		case p.ContainsGitConflicts || (p.Mergeable != nil && !*p.Mergeable):
+			s = "conflicting"
+		default:
+			s = "open"
+		}
+		if rank[s] > rank[state] {
+			state, cl = s, p.Number
+		}
+	}
+	return state, cl
+}
+
+func backportStatusDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("backport status does not take any arguments")
+	}
+	format := flagBackportFormat.String(cmd)
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	project := cfg.gerritProject()
+
+	var branches []string
+	explicit := flagBackportBranch.String(cmd) != ""
+	if explicit {
+		branches = strings.Split(flagBackportBranch.String(cmd), ",")
+	} else {
+		var infos []struct {
+			Ref string `json:"ref"`
+		}
+		path := "projects/" + url.PathEscape(project) + "/branches/?m=" + url.QueryEscape(releaseBranchPrefix)
+		if err := cfg.gerritDo(http.MethodGet, path, nil, &infos); err != nil {
+			return fmt.Errorf("failed to list release branches: %w", err)
+		}
+		for _, info := range infos {
+			if b := strings.TrimPrefix(info.Ref, "refs/heads/"); strings.HasPrefix(b, releaseBranchPrefix) {
+				branches = append(branches, b)
+			}
+		}
+	}
+	sort.Strings(branches)
+
+	var report []branchBackports
+	for _, branch := range branches {
+		hashtag := backportHashtagPrefix + strings.TrimPrefix(branch, releaseBranchPrefix)
+		var origs []backportChange
+		query := fmt.Sprintf("project:%s hashtag:%s -branch:%s", project, hashtag, branch)
+		if err := cfg.gerritDo(http.MethodGet, "changes/?q="+url.QueryEscape(query), nil, &origs); err != nil {
+			return fmt.Errorf("failed to query backports to %s: %w", branch, err)
+		}
+		if len(origs) == 0 && !explicit {
+			continue
+		}
+		sort.Slice(origs, func(i, j int) bool { return origs[i].Number < origs[j].Number })
+		bb := branchBackports{Branch: branch, Backports: []backport{}}
+		for _, orig := range origs {
+			var picks []backportChange
+			query := fmt.Sprintf("project:%s branch:%s change:%s", project, branch, orig.ChangeID)
+			if err := cfg.gerritDo(http.MethodGet, "changes/?q="+url.QueryEscape(query), nil, &picks); err != nil {
+				return fmt.Errorf("failed to query cherry-picks of CL %d: %w", orig.Number, err)
+			}
+			state, pick := backportState(orig, picks)
+			bb.Backports = append(bb.Backports, backport{
+				CL:         orig.Number,
+				Subject:    orig.Subject,
+				State:      state,
+				CherryPick: pick,
+			})
+		}
+		report = append(report, bb)
+	}
+
+	w := cmd.OutOrStdout()
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(report)
+	case "markdown":
+		printBackportStatusMarkdown(w, cfg, report)
+	default:
+		printBackportStatus(w, report)
+	}
+	return nil
+}
+
+func printBackportStatus(w io.Writer, report []branchBackports) {
+	if len(report) == 0 {
+		fmt.Fprintln(w, "no backports requested")
+		return
+	}
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "BRANCH\tCL\tSTATE\tCHERRY-PICK\tSUBJECT")
+	for _, bb := range report {
+		for _, bp := range bb.Backports {
+			pick := "none"
+			if bp.CherryPick != 0 {
+				pick = fmt.Sprint(bp.CherryPick)
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", bb.Branch, bp.CL, bp.State, pick, bp.Subject)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	for _, bb := range report {
+		fmt.Fprintf(w, "%s: %s\n", bb.Branch, bb.summary())
+	}
+}
+
+func printBackportStatusMarkdown(w io.Writer, cfg *config, report []branchBackports) {
+	for i, bb := range report {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "### %s\n\n%s.\n", bb.Branch, upperFirst(bb.summary()))
+		if len(bb.Backports) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, "\n| CL | State | Cherry-pick | Subject |")
+		fmt.Fprintln(w, "|---|---|---|---|")
+		for _, bp := range bb.Backports {
+			pick := "none"
+			if bp.CherryPick != 0 {
+				pick = fmt.Sprintf("[%d](%s)", bp.CherryPick, cfg.changeURL(bp.CherryPick))
+			}
+			fmt.Fprintf(w, "| [%d](%s) | %s | %s | %s |\n", bp.CL, cfg.changeURL(bp.CL), bp.State, pick, bp.Subject)
+		}
+	}
+}
+
+// upperFirst returns s with its first letter in upper case.
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBackportState(t *testing.T) {
+	no := false
+	merged := backportChange{Number: 1, Status: "MERGED"}
+	cases := []struct {
+		name  string
+		orig  backportChange
+		picks []backportChange
+		state string
+		pick  int
+	}{
+		{name: "pending", orig: backportChange{Number: 1, Status: "NEW"}, state: "pending"},
+		{name: "missing", orig: merged, state: "missing"},
+		{name: "open", orig: merged, picks: []backportChange{{Number: 2, Status: "NEW"}}, state: "open", pick: 2},
+		{name: "conflicts", orig: merged, picks: []backportChange{{Number: 2, Status: "NEW", ContainsGitConflicts: true}}, state: "conflicting", pick: 2},
+		{name: "unmergeable", orig: merged, picks: []backportChange{{Number: 2, Status: "NEW", Mergeable: &no}}, state: "conflicting", pick: 2},
+		{
+			name:  "retried after abandoning",
+			orig:  merged,
+			picks: []backportChange{{Number: 3, Status: "MERGED"}, {Number: 2, Status: "ABANDONED"}},
+			state: "merged",
+			pick:  3,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			state, pick := backportState(c.orig, c.picks)
+			if state != c.state || pick != c.pick {
+				t.Errorf("got %s, %d; want %s, %d", state, pick, c.state, c.pick)
+			}
+		})
+	}
+}
+
+func TestBranchBackportsSummary(t *testing.T) {
+	bb := branchBackports{Branch: "release-branch.v0.8", Backports: []backport{
+		{CL: 1, State: "merged"},
+		{CL: 2, State: "abandoned"},
+	}}
+	if got, want := bb.summary(), "ready for a patch release"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	bb.Backports = append(bb.Backports, backport{CL: 3, State: "open"}, backport{CL: 4, State: "missing"})
+	if got, want := bb.summary(), "2 backports remain"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestBackportMessage(t *testing.T) {
+	msg := `cue/load: fix a crash with empty packages
+
+Fixes #3012.
+
+Change-Id: I0123456789abcdef0123456789abcdef01234567
+Signed-off-by: Alice <alice@example.com>
+Reviewed-on: https://review.gerrithub.io/c/cue-lang/cue/+/551352
+Reviewed-by: Bob <bob@example.com>
+TryBot-Result: CUEcueckoo <cueckoo@cuelang.org>
+`
+	want := `cue/load: fix a crash with empty packages
+
+Fixes #3012.
+
+(cherry-pick of CL 551352)
+
+Change-Id: I0123456789abcdef0123456789abcdef01234567
+Signed-off-by: Alice <alice@example.com>
+`
+	if diff := cmp.Diff(want, backportMessage(msg, 551352, "")); diff != "" {
+		t.Errorf("message (-want +got):\n%s", diff)
+	}
+	want = `cue/load: fix a crash with empty packages
+
+Fixes #3012.
+
+(cherry-pick of CL 551352)
+
+Change-Id: Ifedcba9876543210fedcba9876543210fedcba98
+Signed-off-by: Alice <alice@example.com>
+`
+	if diff := cmp.Diff(want, backportMessage(msg, 551352, "Ifedcba9876543210fedcba9876543210fedcba98")); diff != "" {
+		t.Errorf("message with new Change-Id (-want +got):\n%s", diff)
+	}
+}
+
+func TestReleaseBranch(t *testing.T) {
+	for name, want := range map[string]string{
+		"v0.8":                "release-branch.v0.8",
+		"release-branch.v0.8": "release-branch.v0.8",
+		"master":              "",
+		"origin/v0.8":         "",
+	} {
+		got, err := releaseBranch(name)
+		if want == "" {
+			if err == nil {
+				t.Errorf("releaseBranch(%q) = %q; want an error", name, got)
+			}
+		} else if got != want || err != nil {
+			t.Errorf("releaseBranch(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+}
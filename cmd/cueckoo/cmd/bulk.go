@@ -0,0 +1,85 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cue-lang/contrib-tools/internal/workqueue"
+)
+
+const (
+	flagNoResume flagName = "no-resume"
+
+	// bulkConcurrency is the number of requests which bulk operations, such
+	// as triggering builds for many CLs, make at once.
+	bulkConcurrency = 8
+
+	// bulkInterval is the minimum time between the start of requests to the
+	// same host during bulk operations, to stay well within the quotas of
+	// hosts such as googlesource.com.
+	bulkInterval = 100 * time.Millisecond
+
+	// resumeExpiry is how long after its last update a work queue state
+	// file is still used to resume; see resumeStatePath.
+	resumeExpiry = 24 * time.Hour
+)
+
+// newBulkQueue returns a work queue for bulk operations.
+func newBulkQueue() *workqueue.Queue {
+	return &workqueue.Queue{
+		Concurrency: bulkConcurrency,
+		Interval:    bulkInterval,
+	}
+}
+
+// gerritHost returns the host of the Gerrit instance, for the purpose of
+// rate limiting.
+func (c *config) gerritHost() string {
+	u, err := url.Parse(c.gerritURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// resumeStatePath returns the path of the work queue state file for running
+// cmd with the given set of tasks, such that rerunning the same command for
+// the same tasks resumes where the last run left off. A state file which was
+// last updated more than resumeExpiry ago is removed, such that a much later
+// run starts afresh.
+func resumeStatePath(cmd string, names []string) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	path := filepath.Join(dir, "resume", fmt.Sprintf("%s-%x", cmd, sum[:8]))
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > resumeExpiry {
+		if err := os.Remove(path); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
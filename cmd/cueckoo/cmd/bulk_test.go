@@ -0,0 +1,68 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResumeStatePath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	names := []string{"I1234@abc", "I5678@def"}
+	path, err := resumeStatePath("runtrybot", names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reversed, err := resumeStatePath("runtrybot", []string{names[1], names[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reversed != path {
+		t.Errorf("state path depends on the order of tasks: %q != %q", reversed, path)
+	}
+	other, err := resumeStatePath("runtrybot", []string{"I1234@abc", "I5678@fed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == path {
+		t.Errorf("state path does not depend on the revisions of tasks")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(names[0]+"\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resumeStatePath("runtrybot", names); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("recent state file was removed: %v", err)
+	}
+	old := time.Now().Add(-resumeExpiry - time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resumeStatePath("runtrybot", names); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expired state file was not removed: %v", err)
+	}
+}
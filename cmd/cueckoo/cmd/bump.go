@@ -0,0 +1,303 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/cue-lang/contrib-tools/internal/codereviewcfg"
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const flagBumpIn flagName = "in"
+
+// newBumpCmd creates a new bump command
+func newBumpCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bump",
+		Short: "update a dependency in the repositories which depend on this one",
+		Long: `
+Usage of bump:
+
+	bump [--in NAME,...] MODULE@VERSION
+
+bump updates the dependency on MODULE to VERSION in each of the repositories
+which depend on this one, for example after a release:
+
+	cueckoo bump --in unity,cuelang.org cuelang.org/go@v0.8.2
+
+The dependent repositories are configured in codereview.cfg by entries of the
+form "dependent-NAME: URL", where URL is the URL to clone the repository from.
+--in selects some of them by NAME; all of them are updated by default.
+
+Each repository is cloned into a temporary directory, and go get and go mod
+tidy are run in every Go module within it which requires MODULE. The result is
+then sent for review. If the repository has a codereview.cfg for the same
+Gerrit server as this one, a CL is created and its trybots are triggered.
+Otherwise the repository is assumed to be on GitHub and a PR is opened, which
+triggers its CI as usual.
+
+A summary of the created changes is printed at the end. If only some of the
+repositories could be updated, bump exits with status 2.
+`,
+		RunE: mkRunE(c, bumpDef),
+	}
+	c.registerFlags(cmd).String(flagBumpIn, "", "comma-separated names of the dependent repositories to update")
+	return cmd
+}
+
+type bumpResult struct {
+	name   string
+	change string
+	err    error
+}
+
+func bumpDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single MODULE@VERSION")
+	}
+	module, version, ok := strings.Cut(args[0], "@")
+	if !ok || module == "" || version == "" {
+		return fmt.Errorf("expected MODULE@VERSION, got %q", args[0])
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if len(cfg.dependents) == 0 {
+		return fmt.Errorf("no dependent repositories configured; add %sNAME entries to codereview.cfg", dependentKeyPrefix)
+	}
+	names := sortedKeys(cfg.dependents)
+	if in := flagBumpIn.String(cmd); in != "" {
+		names = strings.Split(in, ",")
+		for _, name := range names {
+			if cfg.dependents[name] == "" {
+				return fmt.Errorf("unknown dependent repository %q", name)
+			}
+		}
+	}
+
+	var results []bumpResult
+	for _, name := range names {
+		change, err := cfg.bumpDependent(ctx, cfg.dependents[name], module, version)
+		if change == "" && err == nil {
+			change = "already up to date"
+		}
+		results = append(results, bumpResult{name: name, change: change, err: err})
+	}
+
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "REPOSITORY\tRESULT\tCHANGE")
+	failed := 0
+	for _, r := range results {
+		result, change := "ok", r.change
+		if r.err != nil {
+			failed++
+			result, change = "failed", firstLine(r.err.Error())
+			debugf("%s: %v\n", r.name, r.err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.name, result, change)
+	}
+	tw.Flush()
+	switch {
+	case failed == 0:
+		return nil
+	case failed < len(results):
+		return &partialFailureError{failed: failed, total: len(results)}
+	default:
+		return fmt.Errorf("failed to update all %d repositories", failed)
+	}
+}
+
+// bumpDependent updates module to version in the repository cloned from
+// cloneURL and sends the result for review, returning the URL of the change.
+// It returns an empty URL if the repository was already up to date.
+func (c *config) bumpDependent(ctx context.Context, cloneURL, module, version string) (string, error) {
+	dir, err := os.MkdirTemp("", "cueckoo-bump-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	if _, err := run(ctx, "git", "clone", "--quiet", "--depth=1", cloneURL, dir); err != nil {
+		return "", err
+	}
+	branch, err := gitIn(ctx, dir, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	branch = strings.TrimSpace(branch)
+
+	// Update every module in the repository which requires module.
+	goMods, err := gitIn(ctx, dir, "ls-files", "go.mod", "*/go.mod")
+	if err != nil {
+		return "", err
+	}
+	updated := false
+	for _, goMod := range strings.Fields(goMods) {
+		data, err := os.ReadFile(filepath.Join(dir, goMod))
+		if err != nil {
+			return "", err
+		}
+		if !requiresModule(string(data), module) {
+			continue
+		}
+		modDir := filepath.Join(dir, filepath.Dir(goMod))
+		for _, args := range [][]string{{"get", module + "@" + version}, {"mod", "tidy"}} {
+			if err := goIn(ctx, modDir, args...); err != nil {
+				return "", err
+			}
+		}
+		updated = true
+	}
+	if !updated {
+		return "", fmt.Errorf("no module requires %s", module)
+	}
+	status, err := gitIn(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(status) == "" {
+		return "", nil
+	}
+
+	subject := fmt.Sprintf("all: update %s to %s", module, version)
+	msg := subject + "\n\nThis change was generated by cueckoo bump.\n"
+	crCfg, _ := codereviewcfg.Config(dir)
+	gerritURL := crCfg["gerrit"]
+	if gerritURL == "" {
+		return c.bumpViaPR(ctx, dir, cloneURL, branch, subject, msg, module, version)
+	}
+
+	server, err := codereviewcfg.ParseGerritURL(gerritURL)
+	if err != nil {
+		return "", err
+	}
+	if server.URL != c.gerritURL {
+		return "", fmt.Errorf("Gerrit server %s differs from %s", server.URL, c.gerritURL)
+	}
+	changeID, err := newChangeID()
+	if err != nil {
+		return "", err
+	}
+	msg += "\nChange-Id: " + changeID + "\n"
+	if _, err := gitIn(ctx, dir, "commit", "--quiet", "-a", "-m", msg); err != nil {
+		return "", err
+	}
+	if _, err := gitIn(ctx, dir, "push", "--quiet", "origin", "HEAD:refs/for/"+branch); err != nil {
+		return "", err
+	}
+
+	// Trigger the trybots of the dependent via its own GitHub repository.
+	project := crCfg["gerrit-project"]
+	if project == "" {
+		project = server.Project
+	}
+	ch, _, err := c.gerritClient.Changes.GetChange(project+"~"+branch+"~"+changeID, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get created change: %w", err)
+	}
+	changeURL := fmt.Sprintf("%sc/%s/+/%d", c.gerritURL, project, ch.Number)
+	owner, repo, err := codereviewcfg.GithubURLToParts(crCfg["github"])
+	if err != nil {
+		return changeURL, fmt.Errorf("created %s but cannot trigger trybots: %v", changeURL, err)
+	}
+	rev := ch.Revisions[ch.CurrentRevision]
+	p, err := buildTryBotPayload(repositoryDispatchPayload{
+		Type:         string(eventTypeTrybot),
+		CL:           ch.Number,
+		Patchset:     rev.Number,
+		TargetBranch: ch.Branch,
+		Ref:          rev.Ref,
+	})
+	if err != nil {
+		return changeURL, err
+	}
+	if err := c.triggerRepositoryDispatch(ctx, owner, repo, p); err != nil {
+		return changeURL, fmt.Errorf("created %s but failed to trigger trybots: %w", changeURL, err)
+	}
+	return changeURL, nil
+}
+
+// bumpViaPR commits the changes in the clone dir of the GitHub repository at
+// cloneURL to a new branch and opens a PR against base, returning its URL.
+func (c *config) bumpViaPR(ctx context.Context, dir, cloneURL, base, subject, msg, module, version string) (string, error) {
+	owner, repo, err := codereviewcfg.GithubURLToParts(cloneURL)
+	if err != nil {
+		return "", err
+	}
+	// Pushing changes to workflows additionally requires the workflow
+	// scope.
+	scopes := []string{"repo"}
+	if changed, err := gitIn(ctx, dir, "diff", "--name-only"); err == nil && strings.Contains(changed, ".github/workflows/") {
+		scopes = append(scopes, "workflow")
+	}
+	if err := c.checkGitHubToken(ctx, owner, repo, scopes...); err != nil {
+		return "", err
+	}
+	head := "cueckoo-bump-" + strings.NewReplacer("/", "-", ".", "-").Replace(module) + "-" + version
+	if _, err := gitIn(ctx, dir, "commit", "--quiet", "-a", "-m", msg); err != nil {
+		return "", err
+	}
+	if _, err := gitIn(ctx, dir, "push", "--quiet", "origin", "HEAD:refs/heads/"+head); err != nil {
+		return "", err
+	}
+	body := strings.TrimPrefix(msg, subject+"\n\n")
+	pr, _, err := c.githubClient.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: &subject,
+		Head:  &head,
+		Base:  &base,
+		Body:  &body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open PR in %s/%s: %w", owner, repo, err)
+	}
+	return pr.GetHTMLURL(), nil
+}
+
+// requiresModule reports whether the go.mod file with the given content
+// requires module, either in a single-line require directive or in a block.
+func requiresModule(goMod, module string) bool {
+	inBlock := false
+	for _, l := range strings.Split(goMod, "\n") {
+		f := strings.Fields(l)
+		switch {
+		case len(f) == 0:
+		case inBlock && f[0] == ")":
+			inBlock = false
+		case inBlock && f[0] == module:
+			return true
+		case f[0] == "require" && len(f) >= 2 && f[1] == "(":
+			inBlock = true
+		case f[0] == "require" && len(f) >= 3 && f[1] == module:
+			return true
+		}
+	}
+	return false
+}
+
+// goIn runs the go command with args in dir.
+func goIn(ctx context.Context, dir string, args ...string) error {
+	_, err := run(ctx, "go", append([]string{"-C", dir}, args...)...)
+	return err
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const flagChangeIDAmend flagName = "amend"
+
+// newChangeIDCmd creates a new changeid command
+func newChangeIDCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "changeid",
+		Short: "generate a Gerrit Change-Id, optionally adding it to HEAD",
+		Long: `
+Usage of changeid:
+
+	changeid [--amend]
+
+changeid prints a new Gerrit-style Change-Id.
+
+With --amend, the Change-Id is instead added as a trailer to the commit
+message of HEAD, for commits made without the commit-msg hook installed. If
+HEAD already has a Change-Id, it is left unchanged.
+`,
+		RunE: mkRunE(c, changeIDDef),
+	}
+	c.registerFlags(cmd).Bool(flagChangeIDAmend, false, "add the Change-Id to the commit message of HEAD")
+	return cmd
+}
+
+func changeIDDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("changeid does not take any arguments")
+	}
+	id, err := newChangeID()
+	if err != nil {
+		return err
+	}
+	if !flagChangeIDAmend.Bool(cmd) {
+		fmt.Fprintln(cmd.OutOrStdout(), id)
+		return nil
+	}
+	ctx := cmd.Context()
+	msg, err := run(ctx, "git", "log", "-1", "--format=%B", "HEAD")
+	if err != nil {
+		return err
+	}
+	if existing, err := getChangeIDFromCommitMsg(msg); err == nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "HEAD already has Change-Id %s\n", existing)
+		return nil
+	}
+	if err := amendChangeID(ctx, msg, id); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "added Change-Id %s to HEAD\n", id)
+	return nil
+}
+
+// newChangeID returns a new random Gerrit Change-Id, in the same way as
+// git-codereview.
+func newChangeID() (string, error) {
+	var b [20]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate Change-Id: %v", err)
+	}
+	return fmt.Sprintf("I%x", b), nil
+}
+
+// amendChangeID amends HEAD, whose commit message is msg, to add a Change-Id
+// trailer with the given id. git interpret-trailers places it alongside any
+// existing trailers.
+func amendChangeID(ctx context.Context, msg, id string) error {
+	cmd := exec.CommandContext(ctx, "git", "interpret-trailers", "--trailer", "Change-Id: "+id)
+	cmd.Stdin = strings.NewReader(msg)
+	newMsg, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to add Change-Id trailer: %v", err)
+	}
+	cmd = exec.CommandContext(ctx, "git", "commit", "--amend", "--no-verify", "--allow-empty", "-F", "-")
+	cmd.Stdin = strings.NewReader(string(newMsg))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to amend HEAD: %v:\n%s", err, out)
+	}
+	return nil
+}
+
+// confirm asks the user the yes/no question prompt, returning false without
+// asking if standard input is not a terminal.
+func confirm(prompt string) bool {
+	if !isTerminal(os.Stdin) {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
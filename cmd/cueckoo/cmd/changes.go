@@ -0,0 +1,142 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+)
+
+// changeBatchSize is the number of changes which getChanges looks up with a
+// single Gerrit query, keeping the query URLs well within server limits.
+const changeBatchSize = 25
+
+// getChanges looks up the changes identified by ids, as accepted by
+// resolveChangeID, asking for the given additional fields. Change-Ids and CL
+// numbers are looked up in batches of changeBatchSize with a single query,
+// rather than with a request per change, which matters for stacks of dozens
+// of CLs. Other identifiers are resolved one at a time.
+//
+// The results are in the same order as ids; errs holds the error for each
+// change which could not be looked up, and is nil if all were.
+func (c *config) getChanges(ids []string, fields ...string) (changes []*gerrit.ChangeInfo, errs []error) {
+	changes = make([]*gerrit.ChangeInfo, len(ids))
+	var errList []error
+	fail := func(i int, err error) {
+		if errList == nil {
+			errList = make([]error, len(ids))
+		}
+		errList[i] = err
+	}
+
+	var batch []int
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		batchIDs := make([]string, len(batch))
+		for j, i := range batch {
+			batchIDs[j] = ids[i]
+		}
+		found, _, err := c.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+			QueryOptions: gerrit.QueryOptions{
+				Query: []string{changeQuery(c.gerritProject(), batchIDs)},
+			},
+			ChangeOptions: gerrit.ChangeOptions{AdditionalFields: fields},
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to query changes: %w", err)
+			for _, i := range batch {
+				fail(i, err)
+			}
+		} else {
+			for j, i := range batch {
+				changes[i], err = matchChange(c.gerritProject(), batchIDs[j], *found)
+				if err != nil {
+					fail(i, err)
+				}
+			}
+		}
+		batch = batch[:0]
+	}
+	for i, id := range ids {
+		if !batchableChangeID(id) {
+			cl, err := c.resolveChangeID(id)
+			if err == nil {
+				changes[i], _, err = c.gerritClient.Changes.GetChange(cl, &gerrit.ChangeOptions{AdditionalFields: fields})
+				if err != nil {
+					err = fmt.Errorf("failed to get change %s: %w", id, err)
+				}
+			}
+			if err != nil {
+				fail(i, err)
+			}
+			continue
+		}
+		batch = append(batch, i)
+		if len(batch) == changeBatchSize {
+			flush()
+		}
+	}
+	flush()
+	return changes, errList
+}
+
+// batchableChangeID reports whether id is a CL number or a bare Change-Id,
+// which getChanges can look up with the change: search operator.
+func batchableChangeID(id string) bool {
+	if _, err := strconv.Atoi(id); err == nil {
+		return true
+	}
+	return strings.HasPrefix(id, "I") && !strings.Contains(id, "~")
+}
+
+// changeQuery returns a Gerrit query for the changes in project with the
+// given CL numbers or Change-Ids.
+func changeQuery(project string, ids []string) string {
+	terms := make([]string, len(ids))
+	for i, id := range ids {
+		terms[i] = "change:" + id
+	}
+	return fmt.Sprintf("project:%s (%s)", project, strings.Join(terms, " OR "))
+}
+
+// matchChange returns the change identified by id, a CL number or Change-Id,
+// amongst the results of a query by changeQuery. As with resolveChangeID, a
+// Change-Id shared by changes on different branches is ambiguous.
+func matchChange(project, id string, found []gerrit.ChangeInfo) (*gerrit.ChangeInfo, error) {
+	var matches []*gerrit.ChangeInfo
+	for i := range found {
+		ch := &found[i]
+		if strconv.Itoa(ch.Number) == id || ch.ChangeID == id {
+			matches = append(matches, ch)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no change %s found in project %s", id, project)
+	case 1:
+		return matches[0], nil
+	default:
+		var cls []string
+		for _, ch := range matches {
+			cls = append(cls, fmt.Sprintf("%d (%s)", ch.Number, ch.Branch))
+		}
+		return nil, fmt.Errorf("change %s is ambiguous in project %s; use one of the CL numbers %s", id, project, strings.Join(cls, ", "))
+	}
+}
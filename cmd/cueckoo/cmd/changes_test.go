@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/andygrunwald/go-gerrit"
+)
+
+func TestChangeQuery(t *testing.T) {
+	got := changeQuery("cue", []string{"551352", "I0123456789abcdef"})
+	want := "project:cue (change:551352 OR change:I0123456789abcdef)"
+	if got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	for id, want := range map[string]bool{
+		"551352":              true,
+		"I0123456789abcdef":   true,
+		"cue~master~I0123456": false,
+		"a01b2c3d":            false,
+	} {
+		if got := batchableChangeID(id); got != want {
+			t.Errorf("batchableChangeID(%q) = %v; want %v", id, got, want)
+		}
+	}
+}
+
+func TestMatchChange(t *testing.T) {
+	found := []gerrit.ChangeInfo{
+		{Number: 1, ChangeID: "Iaaa", Branch: "master"},
+		{Number: 2, ChangeID: "Ibbb", Branch: "master"},
+		{Number: 3, ChangeID: "Ibbb", Branch: "release-branch.v0.8"},
+	}
+	for _, test := range []struct {
+		id      string
+		want    int
+		wantErr string
+	}{
+		{id: "1", want: 1},
+		{id: "Iaaa", want: 1},
+		{id: "3", want: 3},
+		{id: "Ibbb", wantErr: "change Ibbb is ambiguous in project cue; use one of the CL numbers 2 (master), 3 (release-branch.v0.8)"},
+		{id: "4", wantErr: "no change 4 found in project cue"},
+	} {
+		ch, err := matchChange("cue", test.id, found)
+		if test.wantErr != "" {
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("matchChange(%q): got error %v; want %q", test.id, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("matchChange(%q): %v", test.id, err)
+		} else if ch.Number != test.want {
+			t.Errorf("matchChange(%q) = CL %d; want %d", test.id, ch.Number, test.want)
+		}
+	}
+}
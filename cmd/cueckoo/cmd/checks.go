@@ -0,0 +1,247 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagChecksKind     flagName = "kind"
+	flagChecksState    flagName = "state"
+	flagChecksURL      flagName = "url"
+	flagChecksMessage  flagName = "message"
+	flagChecksStarted  flagName = "started"
+	flagChecksFinished flagName = "finished"
+
+	// checkerScheme is the scheme of the UUIDs of the checkers of cueckoo,
+	// which are "cueckoo:trybot" and "cueckoo:unity".
+	checkerScheme = "cueckoo"
+)
+
+// checkKinds are the kinds of runs published as checks, each with its own
+// checker.
+var checkKinds = []string{"trybot", "unity"}
+
+// checkStates are the states of a check in Gerrit's checks plugin.
+var checkStates = []string{"NOT_STARTED", "SCHEDULED", "RUNNING", "SUCCESSFUL", "FAILED", "NOT_RELEVANT"}
+
+// newChecksCmd creates a new checks command, which groups the subcommands
+// for publishing runs to Gerrit's checks plugin.
+func newChecksCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "checks",
+		Short: "publish trybot and unity runs as checks in Gerrit",
+		Long: `
+Usage of checks:
+
+	checks setup
+	checks post --kind KIND --state STATE [--url URL] [--message TEXT]
+	            [--started TIME] [--finished TIME] CL PATCHSET
+
+When the Gerrit server has the checks plugin installed, trybot and unity runs
+can be shown as structured checks in the Gerrit UI, with their state, a link
+to the run, and their duration, rather than as comments. Each kind of run has
+a checker, with the UUID ` + checkerScheme + `:trybot or ` + checkerScheme + `:unity.
+
+setup creates the checkers for the repository's Gerrit project, which needs
+the permission to administrate checkers. It only needs to be run once.
+
+post publishes the state of a run of the given KIND for a patchset, which is
+one of NOT_STARTED, SCHEDULED, RUNNING, SUCCESSFUL, FAILED and NOT_RELEVANT.
+It is meant for workflows publishing checks as they go. TIME is in RFC 3339
+format, such as 2026-10-16T09:30:00Z.
+
+serve --checks publishes the runs it sees as checks instead of comments.
+`,
+	}
+	setup := &cobra.Command{
+		Use:   "setup",
+		Short: "create the checkers for the repository",
+		RunE:  mkRunE(c, checksSetupDef),
+	}
+	post := &cobra.Command{
+		Use:   "post",
+		Short: "publish the state of a run as a check",
+		RunE:  mkRunE(c, checksPostDef),
+	}
+	postFlags := c.registerFlags(post)
+	postFlags.String(flagChecksKind, "trybot", "the kind of run: trybot or unity")
+	postFlags.String(flagChecksState, "", "the state of the check")
+	postFlags.String(flagChecksURL, "", "the URL of the run")
+	postFlags.String(flagChecksMessage, "", "a short message on the result")
+	postFlags.String(flagChecksStarted, "", "when the run started")
+	postFlags.String(flagChecksFinished, "", "when the run finished")
+	cmd.AddCommand(setup, post)
+	return cmd
+}
+
+// checkInput is Gerrit's CheckInput entity of the checks plugin.
+type checkInput struct {
+	CheckerUUID string `json:"checker_uuid"`
+	State       string `json:"state"`
+	Message     string `json:"message,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Started     string `json:"started,omitempty"`
+	Finished    string `json:"finished,omitempty"`
+}
+
+// checkerUUID returns the UUID of the checker for runs of the given kind.
+func checkerUUID(kind string) string {
+	return checkerScheme + ":" + kind
+}
+
+// checkTime formats t as a Gerrit timestamp, or returns the empty string
+// for the zero time.
+func checkTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04:05.000000000")
+}
+
+// runCheckState returns the check state for a workflow run with the given
+// status and conclusion.
+func runCheckState(status, conclusion string) string {
+	switch status {
+	case "queued", "requested", "waiting", "pending":
+		return "SCHEDULED"
+	case "in_progress":
+		return "RUNNING"
+	}
+	switch conclusion {
+	case "success":
+		return "SUCCESSFUL"
+	case "failure", "timed_out", "startup_failure":
+		return "FAILED"
+	case "cancelled", "skipped", "neutral", "stale":
+		return "NOT_RELEVANT"
+	}
+	return "NOT_STARTED"
+}
+
+// runCheck returns the check for a workflow run of the given kind.
+func runCheck(kind string, run *github.WorkflowRun) checkInput {
+	in := checkInput{
+		CheckerUUID: checkerUUID(kind),
+		State:       runCheckState(run.GetStatus(), run.GetConclusion()),
+		URL:         run.GetHTMLURL(),
+		Started:     checkTime(run.GetRunStartedAt().Time),
+	}
+	if run.GetStatus() == "completed" {
+		in.Message = fmt.Sprintf("%s run %s", kind, run.GetConclusion())
+		in.Finished = checkTime(run.GetUpdatedAt().Time)
+	}
+	return in
+}
+
+// postCheck publishes in as the check of its checker on patchset of CL cl.
+// It returns an error satisfying gerritStatus(err) == 404 if the checks
+// plugin is not installed or the checker does not exist.
+func (c *config) postCheck(cl, patchset int, in checkInput) error {
+	path := "changes/" + strconv.Itoa(cl) + "/revisions/" + strconv.Itoa(patchset) + "/checks~checks"
+	if err := c.gerritDo(http.MethodPost, path, in, nil); err != nil {
+		return fmt.Errorf("failed to post %s check on CL %d patchset %d: %w", in.CheckerUUID, cl, patchset, err)
+	}
+	return nil
+}
+
+func checksSetupDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("checks setup does not take any arguments")
+	}
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	for _, kind := range checkKinds {
+		uuid := checkerUUID(kind)
+		err := cfg.gerritDo(http.MethodGet, "plugins/checks/checkers/"+url.PathEscape(uuid), nil, nil)
+		if err == nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "checker %s already exists\n", uuid)
+			continue
+		}
+		if gerritStatus(err) != http.StatusNotFound {
+			return fmt.Errorf("failed to get checker %s: %w", uuid, err)
+		}
+		in := map[string]string{
+			"uuid":        uuid,
+			"name":        kind,
+			"description": fmt.Sprintf("%s runs dispatched by cueckoo", kind),
+			"repository":  cfg.gerritProject(),
+		}
+		if err := cfg.gerritDo(http.MethodPost, "plugins/checks/checkers/", in, nil); err != nil {
+			if gerritStatus(err) == http.StatusNotFound {
+				return fmt.Errorf("the checks plugin is not installed on %s", cfg.gerritURL)
+			}
+			return fmt.Errorf("failed to create checker %s: %w", uuid, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "created checker %s\n", uuid)
+	}
+	return nil
+}
+
+func checksPostDef(cmd *Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected a CL and a patchset")
+	}
+	cl, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid CL %q", args[0])
+	}
+	patchset, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid patchset %q", args[1])
+	}
+	kind := flagChecksKind.String(cmd)
+	if !slicesContains(checkKinds, kind) {
+		return fmt.Errorf("unknown kind %q; want one of %s", kind, strings.Join(checkKinds, ", "))
+	}
+	state := flagChecksState.String(cmd)
+	if !slicesContains(checkStates, state) {
+		return fmt.Errorf("invalid --%s %q; want one of %s", flagChecksState, state, strings.Join(checkStates, ", "))
+	}
+	in := checkInput{
+		CheckerUUID: checkerUUID(kind),
+		State:       state,
+		URL:         flagChecksURL.String(cmd),
+		Message:     flagChecksMessage.String(cmd),
+	}
+	for _, f := range []struct {
+		flag flagName
+		dst  *string
+	}{{flagChecksStarted, &in.Started}, {flagChecksFinished, &in.Finished}} {
+		if s := f.flag.String(cmd); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return fmt.Errorf("invalid --%s: %v", f.flag, err)
+			}
+			*f.dst = checkTime(t)
+		}
+	}
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return cfg.postCheck(cl, patchset, in)
+}
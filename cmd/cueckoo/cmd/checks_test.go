@@ -0,0 +1,67 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v53/github"
+)
+
+func TestRunCheck(t *testing.T) {
+	started := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	run := &github.WorkflowRun{
+		Status:       github.String("in_progress"),
+		HTMLURL:      github.String("https://github.com/cue-lang/cue/actions/runs/1"),
+		RunStartedAt: &github.Timestamp{Time: started},
+		UpdatedAt:    &github.Timestamp{Time: started.Add(5 * time.Minute)},
+	}
+	want := checkInput{
+		CheckerUUID: "cueckoo:trybot",
+		State:       "RUNNING",
+		URL:         "https://github.com/cue-lang/cue/actions/runs/1",
+		Started:     "2026-10-16 09:30:00.000000000",
+	}
+	if diff := cmp.Diff(want, runCheck("trybot", run)); diff != "" {
+		t.Errorf("running (-want +got):\n%s", diff)
+	}
+
+	run.Status = github.String("completed")
+	run.Conclusion = github.String("failure")
+	want.State = "FAILED"
+	want.Message = "trybot run failure"
+	want.Finished = "2026-10-16 09:35:00.000000000"
+	if diff := cmp.Diff(want, runCheck("trybot", run)); diff != "" {
+		t.Errorf("completed (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunCheckState(t *testing.T) {
+	cases := []struct{ status, conclusion, want string }{
+		{"queued", "", "SCHEDULED"},
+		{"in_progress", "", "RUNNING"},
+		{"completed", "success", "SUCCESSFUL"},
+		{"completed", "timed_out", "FAILED"},
+		{"completed", "cancelled", "NOT_RELEVANT"},
+		{"completed", "", "NOT_STARTED"},
+	}
+	for _, c := range cases {
+		if got := runCheckState(c.status, c.conclusion); got != c.want {
+			t.Errorf("runCheckState(%q, %q) = %s; want %s", c.status, c.conclusion, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newCICmd creates a new ci command, which groups the subcommands for
+// inspecting and maintaining the GitHub Actions CI of the project.
+func newCICmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "inspect and maintain the project's GitHub Actions CI",
+	}
+	subCommands := []*cobra.Command{
+		newCICostCmd(c),
+		newCIGCArtifactsCmd(c),
+		newCIRunnersCmd(c),
+	}
+	for _, sub := range subCommands {
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
+
+// ciRepos returns the repositories, as OWNER/REPO, which the ci subcommands
+// operate on: the repository and, if configured, the unity repository from
+// codereview.cfg, followed by any extra repositories.
+func ciRepos(cfg *config, extra []string) ([]string, error) {
+	repos := []string{cfg.githubOwner + "/" + cfg.githubRepo}
+	if cfg.unityRepo != "" {
+		repos = append(repos, cfg.unityOwner+"/"+cfg.unityRepo)
+	}
+	for _, r := range extra {
+		if !strings.Contains(r, "/") {
+			return nil, fmt.Errorf("invalid repository %q; expected OWNER/REPO", r)
+		}
+		if !slicesContains(repos, r) {
+			repos = append(repos, r)
+		}
+	}
+	return repos, nil
+}
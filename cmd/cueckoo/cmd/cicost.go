@@ -0,0 +1,289 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	flagCICostSince flagName = "since"
+	flagCICostRepo  flagName = "repo"
+)
+
+// newCICostCmd creates a new ci cost command
+func newCICostCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "report billable CI minutes per workflow and runner type",
+		Long: `
+Usage of ci cost:
+
+	ci cost [--since DURATION] [--repo OWNER/REPO ...] [--template FILE]
+
+ci cost aggregates the billable minutes of the GitHub Actions workflow runs
+created within the --since window, per workflow and runner type, and
+attributes them to trybot, unity, release or other usage.
+
+By default the repository and, if configured, the unity repository from
+codereview.cfg are included. Further repositories, such as one where trybot
+runs happen, can be added with --repo.
+
+Billable minutes are as reported by GitHub: each job is rounded up to the
+whole minute, and only GitHub-hosted runners are included.
+
+The report can instead be formatted with the Go template given by --template.
+Its data has the fields Rows, each with the fields Repo, Workflow, Runner,
+Category, Runs and Minutes; Categories, each with the fields Category, Minutes
+and Share (a percentage); and Total, the total minutes.
+`,
+		RunE: mkRunE(c, ciCostDef),
+	}
+	flags := c.registerFlags(cmd)
+	addTemplateFlag(flags)
+	flags.String(flagCICostSince, "30d", "how far back to look for workflow runs")
+	flags.StringArray(flagCICostRepo, nil, "additional OWNER/REPO to include; may be repeated")
+	return cmd
+}
+
+// costKey identifies a row in the cost report.
+type costKey struct {
+	repo     string
+	workflow string
+	runner   string // e.g. UBUNTU or MACOS
+	category string
+}
+
+type costTotal struct {
+	runs    int
+	minutes int64
+}
+
+func ciCostDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("ci cost does not take any arguments")
+	}
+	ctx := cmd.Context()
+	since, err := parseDuration(flagCICostSince.String(cmd))
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	repos, err := ciRepos(cfg, flagCICostRepo.StringArray(cmd))
+	if err != nil {
+		return err
+	}
+
+	created := ">=" + time.Now().Add(-since).UTC().Format("2006-01-02")
+	var (
+		mu     sync.Mutex
+		totals = make(map[costKey]*costTotal)
+	)
+	for _, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		runs, err := cfg.listWorkflowRuns(ctx, owner, name, created)
+		if err != nil {
+			return err
+		}
+		var prog *progress
+		if len(runs) > 1 {
+			prog = newProgress(os.Stderr, "fetched usage for", len(runs))
+		}
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(8)
+		for _, run := range runs {
+			run := run
+			g.Go(func() error {
+				label := fmt.Sprint(run.GetID())
+				prog.begin(label)
+				usage, _, err := cfg.githubClient.Actions.GetWorkflowRunUsageByID(gctx, owner, name, run.GetID())
+				prog.end(label, err)
+				if err != nil {
+					return fmt.Errorf("failed to get usage of run %d in %s: %w", run.GetID(), repo, err)
+				}
+				if usage.Billable == nil {
+					return nil
+				}
+				category := costCategory(cfg, repo, run)
+				mu.Lock()
+				defer mu.Unlock()
+				for runner, bill := range *usage.Billable {
+					k := costKey{repo: repo, workflow: run.GetName(), runner: runner, category: category}
+					t := totals[k]
+					if t == nil {
+						t = new(costTotal)
+						totals[k] = t
+					}
+					t.runs++
+					t.minutes += billableMinutes(bill)
+				}
+				return nil
+			})
+		}
+		err = g.Wait()
+		prog.finish()
+		if err != nil {
+			return err
+		}
+	}
+	report := buildCostReport(totals)
+	if ok, err := execTemplateFlag(cmd, report); ok {
+		return err
+	}
+	printCostReport(cmd, report)
+	return nil
+}
+
+// listWorkflowRuns returns the completed workflow runs in owner/repo created
+// within the range given by created, using the syntax of GitHub searches.
+func (c *config) listWorkflowRuns(ctx context.Context, owner, repo, created string) ([]*github.WorkflowRun, error) {
+	var res []*github.WorkflowRun
+	opts := &github.ListWorkflowRunsOptions{
+		Status:      "completed",
+		Created:     created,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		runs, resp, err := c.githubClient.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workflow runs in %s/%s: %w", owner, repo, err)
+		}
+		res = append(res, runs.WorkflowRuns...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return res, nil
+}
+
+// costCategory attributes run in repo to trybot, unity, release or other
+// usage.
+func costCategory(cfg *config, repo string, run *github.WorkflowRun) string {
+	name := strings.ToLower(run.GetName())
+	switch {
+	case repo == cfg.unityOwner+"/"+cfg.unityRepo:
+		return "unity"
+	case strings.Contains(name, "release") || run.GetEvent() == "release":
+		return "release"
+	case strings.HasSuffix(repo, "-trybot") || strings.Contains(name, "trybot") ||
+		strings.HasPrefix(run.GetDisplayTitle(), "trybot run for"):
+		return "trybot"
+	}
+	return "other"
+}
+
+// billableMinutes returns the billable minutes for bill, rounding each job up
+// to the whole minute as GitHub does.
+func billableMinutes(bill *github.WorkflowRunBill) int64 {
+	if len(bill.JobRuns) == 0 {
+		return (bill.GetTotalMS() + 59999) / 60000
+	}
+	var mins int64
+	for _, j := range bill.JobRuns {
+		mins += (j.GetDurationMS() + 59999) / 60000
+	}
+	return mins
+}
+
+// costReport is the data for the cost report, also passed to a ci cost
+// --template.
+type costReport struct {
+	Rows       []costRow
+	Categories []costCategoryTotal
+	Total      int64
+}
+
+type costRow struct {
+	Repo     string
+	Workflow string
+	Runner   string
+	Category string
+	Runs     int
+	Minutes  int64
+}
+
+type costCategoryTotal struct {
+	Category string
+	Minutes  int64
+	Share    float64
+}
+
+func buildCostReport(totals map[costKey]*costTotal) *costReport {
+	var report costReport
+	byCategory := make(map[string]int64)
+	for k, t := range totals {
+		report.Rows = append(report.Rows, costRow{
+			Repo:     k.repo,
+			Workflow: k.workflow,
+			Runner:   k.runner,
+			Category: k.category,
+			Runs:     t.runs,
+			Minutes:  t.minutes,
+		})
+		byCategory[k.category] += t.minutes
+		report.Total += t.minutes
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		ri, rj := report.Rows[i], report.Rows[j]
+		if ri.Minutes != rj.Minutes {
+			return ri.Minutes > rj.Minutes
+		}
+		if ri.Repo != rj.Repo {
+			return ri.Repo < rj.Repo
+		}
+		if ri.Workflow != rj.Workflow {
+			return ri.Workflow < rj.Workflow
+		}
+		return ri.Runner < rj.Runner
+	})
+	for _, cat := range []string{"trybot", "unity", "release", "other"} {
+		mins := byCategory[cat]
+		share := 0.0
+		if report.Total > 0 {
+			share = 100 * float64(mins) / float64(report.Total)
+		}
+		report.Categories = append(report.Categories, costCategoryTotal{Category: cat, Minutes: mins, Share: share})
+	}
+	return &report
+}
+
+func printCostReport(cmd *Command, report *costReport) {
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "REPO\tWORKFLOW\tRUNNER\tCATEGORY\tRUNS\tMINUTES")
+	for _, r := range report.Rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n", r.Repo, r.Workflow, r.Runner, r.Category, r.Runs, r.Minutes)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "CATEGORY\tMINUTES\tSHARE")
+	for _, c := range report.Categories {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\n", c.Category, c.Minutes, c.Share)
+	}
+	fmt.Fprintf(tw, "total\t%d\t\n", report.Total)
+	tw.Flush()
+}
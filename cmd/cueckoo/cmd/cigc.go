@@ -0,0 +1,224 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagCIGCOlderThan flagName = "older-than"
+	flagCIGCRepo      flagName = "repo"
+	flagCIGCDelete    flagName = "delete"
+)
+
+// newCIGCArtifactsCmd creates a new ci gc-artifacts command
+func newCIGCArtifactsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc-artifacts",
+		Short: "delete old workflow artifacts and caches",
+		Long: `
+Usage of ci gc-artifacts:
+
+	ci gc-artifacts [--older-than DURATION] [--repo OWNER/REPO ...] [--delete]
+
+ci gc-artifacts lists the GitHub Actions artifacts created, and the caches last
+used, longer ago than --older-than, along with the storage they take up.
+Artifacts and caches count towards the storage quota of the organisation
+until they expire.
+
+Nothing is deleted unless --delete is given, in which case the listed
+artifacts and caches are deleted and the reclaimed storage is reported.
+
+By default the repository and, if configured, the unity repository from
+codereview.cfg are included. Further repositories can be added with --repo.
+`,
+		RunE: mkRunE(c, ciGCArtifactsDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagCIGCOlderThan, "30d", "minimum age of the artifacts and caches to delete")
+	flags.StringArray(flagCIGCRepo, nil, "additional OWNER/REPO to include; may be repeated")
+	flags.Bool(flagCIGCDelete, false, "delete the artifacts and caches rather than just listing them")
+	return cmd
+}
+
+// gcItem is an artifact or cache which is old enough to be deleted.
+type gcItem struct {
+	repo string
+	kind string // "artifact" or "cache"
+	id   int64
+	name string
+	age  time.Duration
+	size int64
+}
+
+func ciGCArtifactsDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("ci gc-artifacts does not take any arguments")
+	}
+	ctx := cmd.Context()
+	olderThan, err := parseDuration(flagCIGCOlderThan.String(cmd))
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	repos, err := ciRepos(cfg, flagCIGCRepo.StringArray(cmd))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var items []gcItem
+	for _, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		found, err := cfg.oldArtifacts(ctx, owner, name, now, olderThan)
+		if err != nil {
+			return err
+		}
+		items = append(items, found...)
+		found, err = cfg.oldCaches(ctx, owner, name, now, olderThan)
+		if err != nil {
+			return err
+		}
+		items = append(items, found...)
+	}
+
+	w := cmd.OutOrStdout()
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "REPO\tKIND\tNAME\tAGE\tSIZE")
+	var total int64
+	for _, it := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%dd\t%s\n", it.repo, it.kind, it.name, int(it.age.Hours()/24), formatBytes(it.size))
+		total += it.size
+	}
+	tw.Flush()
+
+	if !flagCIGCDelete.Bool(cmd) {
+		fmt.Fprintf(w, "\n%d items using %s; run with --%s to delete them\n", len(items), formatBytes(total), flagCIGCDelete)
+		return nil
+	}
+	var reclaimed int64
+	failed := 0
+	for _, it := range items {
+		owner, name, _ := strings.Cut(it.repo, "/")
+		var err error
+		if it.kind == "artifact" {
+			_, err = cfg.githubClient.Actions.DeleteArtifact(ctx, owner, name, it.id)
+		} else {
+			_, err = cfg.githubClient.Actions.DeleteCachesByID(ctx, owner, name, it.id)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to delete %s %s in %s: %v\n", it.kind, it.name, it.repo, err)
+			failed++
+			continue
+		}
+		reclaimed += it.size
+	}
+	fmt.Fprintf(w, "\ndeleted %d items, reclaiming %s\n", len(items)-failed, formatBytes(reclaimed))
+	switch {
+	case failed == 0:
+		return nil
+	case failed < len(items):
+		return &partialFailureError{failed: failed, total: len(items)}
+	default:
+		return fmt.Errorf("failed to delete all %d items", failed)
+	}
+}
+
+// oldArtifacts returns the unexpired artifacts in owner/repo created longer
+// than olderThan before now.
+func (c *config) oldArtifacts(ctx context.Context, owner, repo string, now time.Time, olderThan time.Duration) ([]gcItem, error) {
+	var res []gcItem
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		list, resp, err := c.githubClient.Actions.ListArtifacts(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list artifacts in %s/%s: %w", owner, repo, err)
+		}
+		for _, a := range list.Artifacts {
+			age := now.Sub(a.GetCreatedAt().Time)
+			if a.GetExpired() || age < olderThan {
+				continue
+			}
+			res = append(res, gcItem{
+				repo: owner + "/" + repo,
+				kind: "artifact",
+				id:   a.GetID(),
+				name: a.GetName(),
+				age:  age,
+				size: a.GetSizeInBytes(),
+			})
+		}
+		if resp.NextPage == 0 {
+			return res, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// oldCaches returns the caches in owner/repo last used longer than olderThan
+// before now.
+func (c *config) oldCaches(ctx context.Context, owner, repo string, now time.Time, olderThan time.Duration) ([]gcItem, error) {
+	var res []gcItem
+	opts := &github.ActionsCacheListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		list, resp, err := c.githubClient.Actions.ListCaches(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list caches in %s/%s: %w", owner, repo, err)
+		}
+		for _, cache := range list.ActionsCaches {
+			age := now.Sub(cache.GetLastAccessedAt().Time)
+			if age < olderThan {
+				continue
+			}
+			res = append(res, gcItem{
+				repo: owner + "/" + repo,
+				kind: "cache",
+				id:   cache.GetID(),
+				name: cache.GetKey(),
+				age:  age,
+				size: cache.GetSizeInBytes(),
+			})
+		}
+		if resp.NextPage == 0 {
+			return res, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// formatBytes formats n bytes in a human-readable way, e.g. 1.5 GiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagCIRunnersRepo     flagName = "repo"
+	flagCIRunnersOrg      flagName = "org"
+	flagCIRunnersWatch    flagName = "watch"
+	flagCIRunnersInterval flagName = "interval"
+	flagCIRunnersNotify   flagName = "notify"
+)
+
+// newCIRunnersCmd creates a new ci runners command
+func newCIRunnersCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "runners",
+		Short: "list the status of self-hosted runners",
+		Long: `
+Usage of ci runners:
+
+	ci runners [--repo OWNER/REPO ...] [--org ORG ...] [--watch] [--interval DURATION] [--notify SPEC]
+
+ci runners lists the self-hosted GitHub Actions runners of the configured
+repositories and organisations, along with whether they are online, whether
+they are busy, and their labels.
+
+By default the repository and, if configured, the unity repository from
+codereview.cfg are included. Further repositories can be added with --repo,
+and the runners of whole organisations with --org. Listing runners needs a
+token with admin access to the repositories or organisations.
+
+With --watch, the runners are checked every --interval and an alert is sent
+whenever a runner goes offline, disappears or comes back online, such that
+outages are noticed before someone's run hangs waiting for a runner. Runners
+which are offline when watching starts are alerted on too. Alerts are sent
+via --notify, which is a comma-separated list of notifiers: "desktop",
+"bell", or a webhook URL. It defaults to the "` + notifierKey + `" entry in the
+user config, or "bell".
+`,
+		RunE: mkRunE(c, ciRunnersDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.StringArray(flagCIRunnersRepo, nil, "additional OWNER/REPO to include; may be repeated")
+	flags.StringArray(flagCIRunnersOrg, nil, "organisation whose runners to include; may be repeated")
+	flags.Bool(flagCIRunnersWatch, false, "keep watching the runners and alert on changes")
+	flags.String(flagCIRunnersInterval, "1m", "how often to check the runners with --watch")
+	flags.String(flagCIRunnersNotify, "", "notifiers for alerts with --watch")
+	return cmd
+}
+
+// runnerStatus is the status of a self-hosted runner.
+type runnerStatus struct {
+	scope  string // OWNER/REPO or ORG
+	name   string
+	os     string
+	online bool
+	busy   bool
+	labels []string
+}
+
+func (r runnerStatus) key() string {
+	return r.scope + "/" + r.name
+}
+
+func ciRunnersDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("ci runners does not take any arguments")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	repos, err := ciRepos(cfg, flagCIRunnersRepo.StringArray(cmd))
+	if err != nil {
+		return err
+	}
+	orgs := flagCIRunnersOrg.StringArray(cmd)
+
+	runners, err := cfg.listRunners(ctx, repos, orgs)
+	if err != nil {
+		return err
+	}
+	printRunners(cmd, runners)
+	if !flagCIRunnersWatch.Bool(cmd) {
+		return nil
+	}
+
+	interval, err := parseDuration(flagCIRunnersInterval.String(cmd))
+	if err != nil {
+		return err
+	}
+	n, err := newNotifier(flagCIRunnersNotify.String(cmd))
+	if err != nil {
+		return err
+	}
+	alert := func(title, body string) {
+		if err := n.notify(ctx, title, body); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to send alert: %v\n", err)
+		}
+	}
+	prev := make(map[string]runnerStatus)
+	for _, r := range runners {
+		if !r.online {
+			alert("runner offline", fmt.Sprintf("%s is offline", r.key()))
+		}
+		prev[r.key()] = r
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		runners, err := cfg.listRunners(ctx, repos, orgs)
+		if err != nil {
+			// Carry on watching through transient API failures.
+			fmt.Fprintf(os.Stderr, "%s: %v\n", time.Now().Format(time.TimeOnly), err)
+			continue
+		}
+		cur := make(map[string]runnerStatus)
+		for _, r := range runners {
+			cur[r.key()] = r
+			p, ok := prev[r.key()]
+			switch {
+			case !ok && !r.online:
+				alert("runner offline", fmt.Sprintf("new runner %s is offline", r.key()))
+			case ok && p.online && !r.online:
+				alert("runner offline", fmt.Sprintf("%s went offline", r.key()))
+			case ok && !p.online && r.online:
+				alert("runner online", fmt.Sprintf("%s is back online", r.key()))
+			}
+		}
+		for k := range prev {
+			if _, ok := cur[k]; !ok {
+				alert("runner removed", fmt.Sprintf("%s is no longer registered", k))
+			}
+		}
+		prev = cur
+	}
+}
+
+// listRunners returns the self-hosted runners of repos and orgs, sorted by
+// scope and name.
+func (c *config) listRunners(ctx context.Context, repos, orgs []string) ([]runnerStatus, error) {
+	var res []runnerStatus
+	list := func(scope string, f func(*github.ListOptions) (*github.Runners, *github.Response, error)) error {
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			runners, resp, err := f(opts)
+			if err != nil {
+				return fmt.Errorf("failed to list runners of %s: %w", scope, err)
+			}
+			for _, r := range runners.Runners {
+				var labels []string
+				for _, l := range r.Labels {
+					labels = append(labels, l.GetName())
+				}
+				res = append(res, runnerStatus{
+					scope:  scope,
+					name:   r.GetName(),
+					os:     r.GetOS(),
+					online: r.GetStatus() == "online",
+					busy:   r.GetBusy(),
+					labels: labels,
+				})
+			}
+			if resp.NextPage == 0 {
+				return nil
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	for _, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		if err := list(repo, func(opts *github.ListOptions) (*github.Runners, *github.Response, error) {
+			return c.githubClient.Actions.ListRunners(ctx, owner, name, opts)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, org := range orgs {
+		if err := list(org, func(opts *github.ListOptions) (*github.Runners, *github.Response, error) {
+			return c.githubClient.Actions.ListOrganizationRunners(ctx, org, opts)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].key() < res[j].key()
+	})
+	return res, nil
+}
+
+func printRunners(cmd *Command, runners []runnerStatus) {
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "SCOPE\tRUNNER\tOS\tSTATUS\tBUSY\tLABELS")
+	for _, r := range runners {
+		status := "offline"
+		if r.online {
+			status = "online"
+		}
+		busy := ""
+		if r.busy {
+			busy = "busy"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.scope, r.name, r.os, status, busy, strings.Join(r.labels, ","))
+	}
+	tw.Flush()
+}
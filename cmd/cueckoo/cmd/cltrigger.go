@@ -16,14 +16,17 @@ package cmd
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/andygrunwald/go-gerrit"
+	"github.com/cue-lang/contrib-tools/internal/gitcmd"
+	"github.com/cue-lang/contrib-tools/internal/workqueue"
 )
 
 var (
@@ -58,7 +61,9 @@ func (c *cltrigger) run() (err error) {
 	args := c.cmd.Flags().Args()
 	derive := true
 	for _, arg := range args {
-		if rxChangeID.MatchString(arg) {
+		// Commit hashes which are not known locally, such as those copied
+		// from CI logs, are looked up in Gerrit.
+		if rxChangeID.MatchString(arg) || looksLikeCommit(arg) && !isLocalCommit(arg) {
 			derive = false
 		} else if !derive {
 			return fmt.Errorf("cannot mix change IDs and git refs")
@@ -74,9 +79,14 @@ func (c *cltrigger) run() (err error) {
 			return fmt.Errorf("must provide at least one change number of ID")
 		}
 		for _, a := range args {
-			changeIDs = append(changeIDs, revision{
-				changeID: a,
-			})
+			rev := revision{changeID: a}
+			if looksLikeCommit(a) {
+				rev.changeID, rev.revision, err = c.cfg.changeForCommit(a)
+				if err != nil {
+					return err
+				}
+			}
+			changeIDs = append(changeIDs, rev)
 		}
 	}
 	return c.triggerBuilds(changeIDs)
@@ -99,14 +109,36 @@ func slicesContains[S ~[]E, E comparable](s S, v E) bool {
 // https://pkg.go.dev/golang.org/x/review/git-codereview
 func (c *cltrigger) deriveChangeIDs(args []string) (res []revision, err error) {
 	ctx := context.TODO()
-	// Work out the branchpoint
-	bp, err := run(ctx, "git", "codereview", "branchpoint")
-	if err != nil {
-		return nil, err
+	ref := flagRunTrybotRef.String(c.cmd)
+	var bp, upstream string
+	if ref == "" {
+		// Catch states in which git-codereview fails in confusing ways.
+		st, err := currentRepoState(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := st.err(); err != nil {
+			return nil, err
+		}
+		ref = "HEAD"
+
+		// Work out the branchpoint
+		bp, err = run(ctx, "git", "codereview", "branchpoint")
+		if err != nil {
+			return nil, err
+		}
+		upstream, _ = run(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD@{u}")
+	} else {
+		upstream, bp, err = refBranchpoint(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	c.checkFreshness(ctx, strings.TrimSpace(bp), upstream)
+
 	// Calculate the list of commits that are pending
-	pendingCommits, err := resolveCommits(ctx, fmt.Sprintf("%s..HEAD", strings.TrimSpace(bp)))
+	pendingCommits, err := resolveCommits(ctx, fmt.Sprintf("%s..%s", strings.TrimSpace(bp), ref))
 	if err != nil {
 		return nil, err
 	}
@@ -124,18 +156,17 @@ func (c *cltrigger) deriveChangeIDs(args []string) (res []revision, err error) {
 	addRevision := func(pc commit) error {
 		changeID, err := getChangeIDFromCommitMsg(pc.body)
 		if err != nil {
-			return fmt.Errorf("failed to derive change ID: %v", err)
+			return c.missingChangeID(ctx, pc, err)
 		}
-		// If HEAD is tracking an origin remote branch,
+		// If the branch is tracking an origin remote branch,
 		// make the changeID include the project name and target branch,
 		// which will make the changeID string be an unique identifier.
 		// See [revision.changeID].
-		targetBranch, _ := run(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD@{u}")
-		targetBranch = strings.TrimSpace(targetBranch)             // no trailing newline
+		targetBranch := strings.TrimSpace(upstream)                // no trailing newline
 		targetBranch = strings.TrimPrefix(targetBranch, "origin/") // no remote name prefix
 		if targetBranch != "" {
 			changeID = url.PathEscape(
-				c.cfg.githubOwner + "/" + c.cfg.githubRepo +
+				c.cfg.gerritProject() +
 					"~" +
 					targetBranch +
 					"~" +
@@ -181,12 +212,69 @@ func (c *cltrigger) deriveChangeIDs(args []string) (res []revision, err error) {
 					continue EachArg
 				}
 			}
+			if looksLikeCommit(h) {
+				// Not one of ours, but Gerrit might know it.
+				cl, rev, err := c.cfg.changeForCommit(h)
+				if err != nil {
+					return nil, fmt.Errorf("commit %v is not a pending commit, nor a patchset of a change: %v", h, err)
+				}
+				res = append(res, revision{changeID: cl, revision: rev})
+				continue
+			}
 			return nil, fmt.Errorf("commit %v is not a pending commit", h)
 		}
 	}
 	return
 }
 
+// isLocalCommit reports whether rev names a commit in the local repository.
+func isLocalCommit(rev string) bool {
+	_, err := gitcmd.New("").RevParse(context.TODO(), rev)
+	return err == nil
+}
+
+// refBranchpoint returns the upstream branch of ref, or of the default
+// branch of origin if ref has none, along with the commit at which ref
+// branched from it. It is used in place of git-codereview, which only works
+// with the current branch.
+func refBranchpoint(ctx context.Context, ref string) (upstream, bp string, err error) {
+	upstream, err = run(ctx, "git", "rev-parse", "--abbrev-ref", ref+"@{u}")
+	if err != nil {
+		upstream, err = run(ctx, "git", "rev-parse", "--abbrev-ref", "origin/HEAD")
+		if err != nil {
+			return "", "", fmt.Errorf("%s has no upstream branch, and the default branch of origin is unknown", ref)
+		}
+	}
+	upstream = strings.TrimSpace(upstream)
+	bp, err = run(ctx, "git", "merge-base", ref, upstream)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to determine where %s branched from %s: %w", ref, upstream, err)
+	}
+	return upstream, strings.TrimSpace(bp), nil
+}
+
+// missingChangeID handles pc lacking a Change-Id, as happens when the
+// commit-msg hook is not installed. If pc is HEAD, the user is offered to add
+// one. Either way, an error explaining how to proceed is returned, as the
+// commit will not have been mailed.
+func (c *cltrigger) missingChangeID(ctx context.Context, pc commit, err error) error {
+	head, headErr := run(ctx, "git", "rev-parse", "HEAD")
+	if headErr != nil || strings.TrimSpace(head) != pc.hash {
+		return fmt.Errorf("failed to derive change ID for %s: %v; add a Change-Id with git rebase and cueckoo changeid", pc.hash, err)
+	}
+	if !confirm("HEAD has no Change-Id; add one now?") {
+		return fmt.Errorf("failed to derive change ID: %v; run cueckoo changeid --amend to add one", err)
+	}
+	id, err := newChangeID()
+	if err != nil {
+		return err
+	}
+	if err := amendChangeID(ctx, pc.body, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("added Change-Id %s to HEAD; run git codereview mail before triggering builds", id)
+}
+
 type commit struct {
 	hash string
 	body string
@@ -235,6 +323,8 @@ type revision struct {
 	// When deriving change IDs, we will always use the third form,
 	// as it is the only one which cannot result in ambiguous identifiers.
 	// However, the command-line UI accepts the three forms as direct arguments.
+	// The second form is resolved within the repository's Gerrit project
+	// before use; see [config.resolveChangeID].
 	changeID string
 
 	// revision is a commit hash; when empty, we use changeID's latest patchset,
@@ -242,36 +332,160 @@ type revision struct {
 	revision string
 }
 
+func (r revision) String() string {
+	if r.revision == "" {
+		return r.changeID
+	}
+	return r.changeID + "@" + r.revision
+}
+
+// short returns an abbreviated form of r suitable for progress output.
+func (r revision) short() string {
+	id := r.changeID
+	if i := strings.LastIndex(id, "~"); i >= 0 {
+		id = id[i+1:]
+	}
+	if strings.HasPrefix(id, "I") && len(id) > 9 {
+		id = id[:9]
+	}
+	return id
+}
+
+// buildResult records the outcome of triggerBuild for a single revision.
+type buildResult struct {
+	rev revision
+
+	// cl is the CL number of the change, or zero if the change could not be
+	// resolved.
+	cl int
+
+	// action is the last step attempted for the revision: "lookup", "skip" or
+	// "dispatch", or "resumed" if it was dispatched by a previous run.
+	action string
+
+	err error
+}
+
 func (c *cltrigger) triggerBuilds(revs []revision) error {
 	errs := new(errorList)
-	var wg sync.WaitGroup
+	results := make([]buildResult, len(revs))
+
+	// Dispatching many CLs can take a while; report progress so that we
+	// don't appear to hang.
+	var prog *progress
+	if len(revs) > 1 {
+		prog = newProgress(os.Stderr, "dispatched", len(revs))
+	}
+
+	// Look up all the changes up front, as a few batched queries are much
+	// cheaper than a request per change for large stacks.
+	ids := make([]string, len(revs))
+	for i, rev := range revs {
+		ids[i] = rev.changeID
+	}
+	changes, lookupErrs := c.cfg.getChanges(ids, "ALL_REVISIONS", "LABELS")
+
+	// Name each task after the revision it dispatches for, resolving the
+	// current revision where none was given, such that a previous run is
+	// only resumed for changes which have no new patchsets since.
+	names := make([]string, len(revs))
+	for i, rev := range revs {
+		names[i] = rev.String()
+		if rev.revision == "" && changes[i] != nil {
+			names[i] = revision{changeID: rev.changeID, revision: changes[i].CurrentRevision}.String()
+		}
+	}
+	q := newBulkQueue()
+	if len(revs) > 1 && !flagNoResume.Bool(c.cmd) && !dryRun {
+		// If some of a set of revisions failed to dispatch, rerunning the
+		// same command only retries those which failed. Dry runs dispatch
+		// nothing, so do not count.
+		var err error
+		if q.StateFile, err = resumeStatePath(c.cmd.Name(), names); err != nil {
+			return err
+		}
+	}
 
+	tasks := make([]workqueue.Task, len(revs))
 	for i := range revs {
-		rev := revs[i]
-		wg.Add(1)
-		go func() {
-			var err error
-			defer wg.Done()
-			defer errs.Add(&err)
-			defer recoverError(&err)
-			err = c.triggerBuild(rev)
-		}()
-	}
-
-	wg.Wait()
-	return errors.Join(errs.errs...)
+		i, rev := i, revs[i]
+		results[i].rev = rev
+		tasks[i] = workqueue.Task{
+			Name: names[i],
+			Host: c.cfg.gerritHost(),
+			Run: func(ctx context.Context) (err error) {
+				prog.begin(rev.short())
+				defer func() { prog.end(rev.short(), err) }()
+				defer recoverError(&err)
+				if lookupErrs != nil && lookupErrs[i] != nil {
+					results[i].action = "lookup"
+					return lookupErrs[i]
+				}
+				results[i].cl, results[i].action, err = c.triggerBuild(rev, changes[i])
+				return err
+			},
+		}
+	}
+	taskErrs, err := q.Run(c.cmd.Context(), tasks)
+	prog.finish()
+	if err != nil {
+		return fmt.Errorf("failed to record progress: %v", err)
+	}
+	resumed := 0
+	for i, err := range taskErrs {
+		if err == workqueue.ErrCompleted {
+			results[i].action = "resumed"
+			resumed++
+			continue
+		}
+		results[i].err = err
+		errs.Add(revs[i], err)
+	}
+	if resumed > 0 {
+		fmt.Fprintf(os.Stderr, "skipped %d changes dispatched by a previous run; use --%s to dispatch them again\n", resumed, flagNoResume)
+	}
+
+	if len(revs) == 1 {
+		return results[0].err
+	}
+	c.printSummary(results)
+	switch n := len(errs.errs); {
+	case n == 0:
+		return nil
+	case n < len(revs):
+		return &partialFailureError{failed: n, total: len(revs)}
+	default:
+		return fmt.Errorf("failed to trigger builds for all %d changes", n)
+	}
 }
 
-func (c *cltrigger) triggerBuild(rev revision) error {
-	in, _, err := c.cfg.gerritClient.Changes.GetChange(rev.changeID, &gerrit.ChangeOptions{
-		AdditionalFields: []string{"ALL_REVISIONS", "LABELS"},
-	})
-	if err != nil {
-		// Note that this may be a "change not found" error when the changeID is
-		// an ambiguous identifier. See [revision.changeID].
-		return fmt.Errorf("failed to get current revision information: %v", err)
+// printSummary writes a table of per-revision outcomes to the command's
+// output, so that failures for some CLs are not lost amongst the successes
+// of others.
+func (c *cltrigger) printSummary(results []buildResult) {
+	tw := newTableWriter(c.cmd.OutOrStdout())
+	fmt.Fprintln(tw, "CL\tACTION\tRESULT\tERROR")
+	for _, r := range results {
+		cl := r.rev.changeID
+		if r.cl != 0 {
+			cl = strconv.Itoa(r.cl)
+		}
+		result, msg := "ok", ""
+		if r.err != nil {
+			result = "failed"
+			// Only show the first line of an error, to keep the table
+			// readable.
+			msg = firstLine(r.err.Error())
+			debugf("%v: %v\n", r.rev, r.err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", cl, r.action, result, msg)
 	}
+	tw.Flush()
+}
 
+// triggerBuild triggers builds for rev, given its change in, returning the CL
+// number of the change and the last action attempted.
+func (c *cltrigger) triggerBuild(rev revision, in *gerrit.ChangeInfo) (cl int, action string, _ error) {
 	commit := rev.revision
 	if commit == "" {
 		// fall back to the current/latest revision, also a commit hash
@@ -279,7 +493,7 @@ func (c *cltrigger) triggerBuild(rev revision) error {
 	}
 	revision, ok := in.Revisions[commit]
 	if !ok {
-		return fmt.Errorf("change %q does not know about revision %q; did you forget to run git codereview mail?", rev.changeID, commit)
+		return in.Number, "lookup", fmt.Errorf("change %q does not know about revision %q; did you forget to run git codereview mail?", rev.changeID, commit)
 	}
 
 	// If we do not have the --force flag, only trigger trybots when we do not
@@ -302,13 +516,13 @@ func (c *cltrigger) triggerBuild(rev revision) error {
 				// who actually voted because it can only have been someone
 				// with permission to do so.
 				if approval.Value == 1 {
-					return nil
+					return in.Number, "skip", nil
 				}
 			}
 		}
 	}
 
-	return c.builder(repositoryDispatchPayload{
+	return in.Number, "dispatch", c.builder(repositoryDispatchPayload{
 		CL:           in.Number,
 		Patchset:     revision.Number,
 		TargetBranch: in.Branch,
@@ -317,6 +531,10 @@ func (c *cltrigger) triggerBuild(rev revision) error {
 }
 
 type repositoryDispatchPayload struct {
+	// PayloadVersion is the version of the schema of the payload; see
+	// payloadVersion.
+	PayloadVersion int `json:"payloadVersion"`
+
 	Type         string `json:"type,omitempty"`
 	CL           int    `json:"CL,omitempty"`
 	Patchset     int    `json:"patchset,omitempty"`
@@ -332,17 +550,31 @@ func getChangeIDFromCommitMsg(msg string) (string, error) {
 	return matches[0][1], nil
 }
 
+// revisionError is an error encountered while triggering builds for rev.
+type revisionError struct {
+	rev revision
+	err error
+}
+
+func (e *revisionError) Error() string {
+	return fmt.Sprintf("%v: %v", e.rev, e.err)
+}
+
+func (e *revisionError) Unwrap() error {
+	return e.err
+}
+
 type errorList struct {
 	mu   sync.Mutex
-	errs []error
+	errs []*revisionError
 }
 
-func (e *errorList) Add(err *error) {
-	if *err == nil {
+func (e *errorList) Add(rev revision, err error) {
+	if err == nil {
 		return
 	}
 	e.mu.Lock()
-	e.errs = append(e.errs, *err)
+	e.errs = append(e.errs, &revisionError{rev: rev, err: err})
 	e.mu.Unlock()
 }
 
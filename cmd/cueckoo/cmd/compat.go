@@ -0,0 +1,177 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagCompatBase    flagName = "base"
+	flagCompatNoWait  flagName = "no-wait"
+	flagCompatTimeout flagName = "timeout"
+
+	// compatReportArtifact is the name of the artifact in which the compat
+	// workflow uploads its report.
+	compatReportArtifact = "compat-report"
+)
+
+// newCompatCmd creates a new compat command
+func newCompatCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compat",
+		Short: "check a CL for incompatible API changes",
+		Long: `
+Usage of compat:
+
+	compat [--base VERSION] [--no-wait] [--timeout DURATION] CL
+
+compat dispatches an API compatibility check, such as apidiff or gorelease,
+for the current patchset of the given CL, comparing it against the latest
+release of the repository or the version given by --base. It then waits for
+the check to complete and prints its report, to help reviewers spot
+accidental API breaks.
+
+The check is run by a workflow in the GitHub repository which handles
+repository dispatch events of type "compat". Its client payload holds the CL,
+patchset, ref and target branch as for trybot runs, along with the base
+version as "base". The workflow is expected to upload its report as an
+artifact named "` + compatReportArtifact + `", and to fail if it found incompatible
+changes.
+
+With --no-wait, compat only dispatches the check.
+`,
+		RunE:              mkRunE(c, compatDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagCompatBase, "", "version to compare against (default the latest release)")
+	flags.Bool(flagCompatNoWait, false, "do not wait for the check to complete")
+	flags.String(flagCompatTimeout, "30m", "how long to wait for the check to complete")
+	flags.Bool(flagOverrideFreeze, false, "proceed even during a freeze window of the repository")
+	return cmd
+}
+
+type compatPayload struct {
+	repositoryDispatchPayload
+
+	// Base is the version against which to check compatibility, e.g.
+	// v0.8.2.
+	Base string `json:"base"`
+}
+
+// compatRunTitle returns the display title of the workflow run resulting from
+// a compat dispatch for the Gerrit ref.
+func compatRunTitle(ref string) string {
+	return fmt.Sprintf("compat run for %v", ref)
+}
+
+func buildCompatPayload(payload compatPayload) (github.DispatchRequestOptions, error) {
+	payload.PayloadVersion = payloadVersion
+	return buildDispatchPayload(compatRunTitle(payload.Ref), payload)
+}
+
+func compatDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single CL")
+	}
+	timeout, err := parseDuration(flagCompatTimeout.String(cmd))
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkFreeze(cmd); err != nil {
+		return err
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	ch, _, err := cfg.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	base := flagCompatBase.String(cmd)
+	if base == "" {
+		rel, _, err := cfg.githubClient.Repositories.GetLatestRelease(ctx, cfg.githubOwner, cfg.githubRepo)
+		if err != nil {
+			return fmt.Errorf("failed to get latest release: %w", err)
+		}
+		base = rel.GetTagName()
+	}
+
+	rev := ch.Revisions[ch.CurrentRevision]
+	p, err := buildCompatPayload(compatPayload{
+		repositoryDispatchPayload: repositoryDispatchPayload{
+			Type:         string(eventTypeCompat),
+			CL:           ch.Number,
+			Patchset:     rev.Number,
+			TargetBranch: ch.Branch,
+			Ref:          rev.Ref,
+		},
+		Base: base,
+	})
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := cfg.triggerRepositoryDispatch(ctx, cfg.githubOwner, cfg.githubRepo, p); err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "dispatched compat check of CL %d patchset %d against %s\n", ch.Number, rev.Number, base)
+	if flagCompatNoWait.Bool(cmd) {
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	run, err := cfg.waitForDispatchedRun(waitCtx, cfg.githubOwner, cfg.githubRepo, compatRunTitle(rev.Ref), start)
+	if err != nil {
+		return err
+	}
+	files, err := cfg.runArtifact(ctx, cfg.githubOwner, cfg.githubRepo, run, compatReportArtifact)
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(files) {
+		fmt.Fprintf(w, "\n%s", files[name])
+		if !strings.HasSuffix(string(files[name]), "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+	switch {
+	case run.GetConclusion() == "success":
+		fmt.Fprintf(w, "\nno incompatible changes found by %s\n", run.GetHTMLURL())
+		return nil
+	case files == nil:
+		return fmt.Errorf("compat check %s concluded %s without a report", run.GetHTMLURL(), run.GetConclusion())
+	default:
+		return fmt.Errorf("compat check %s found incompatible changes", run.GetHTMLURL())
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+// Dynamic shell completion of CL numbers and branches is backed by a small
+// per-repository cache, such that completion is instant and still works when
+// offline. The cache is refreshed on demand when it is older than
+// completionCacheTTL; if that fails, the stale data is used.
+
+const (
+	completionCacheTTL = 10 * time.Minute
+
+	// completionRefreshTimeout bounds how long a completion may block on
+	// refreshing the cache.
+	completionRefreshTimeout = 3 * time.Second
+)
+
+// completionCache is the data cached for completions in one repository.
+type completionCache struct {
+	Updated  time.Time          `json:"updated"`
+	Changes  []completionChange `json:"changes"`
+	Branches []string           `json:"branches"`
+}
+
+type completionChange struct {
+	Number  int    `json:"number"`
+	Subject string `json:"subject"`
+}
+
+// completionCachePath returns the path of the completion cache for the
+// repository at gitRoot, under $XDG_CACHE_HOME or its platform equivalent.
+func completionCachePath(gitRoot string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(gitRoot))
+	return filepath.Join(dir, "cueckoo", "completion-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadCompletionCache returns the completion data for the current
+// repository, refreshing it first if it is stale. It returns nil if there is
+// no data at all.
+func loadCompletionCache(ctx context.Context) *completionCache {
+	gitRoot, err := run(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	path, err := completionCachePath(strings.TrimSpace(gitRoot))
+	if err != nil {
+		return nil
+	}
+	var cache *completionCache
+	if data, err := os.ReadFile(path); err == nil {
+		cache = new(completionCache)
+		if err := json.Unmarshal(data, cache); err != nil {
+			cache = nil
+		}
+	}
+	if cache != nil && time.Since(cache.Updated) < completionCacheTTL {
+		return cache
+	}
+	ctx, cancel := context.WithTimeout(ctx, completionRefreshTimeout)
+	defer cancel()
+	fresh, err := refreshCompletionCache(ctx)
+	if err != nil {
+		debugf("failed to refresh completion cache: %v\n", err)
+		return cache
+	}
+	if data, err := json.Marshal(fresh); err == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o777); err == nil {
+			os.WriteFile(path, data, 0o666)
+		}
+	}
+	return fresh
+}
+
+// refreshCompletionCache fetches the user's open CLs and the branches of the
+// Gerrit project.
+func refreshCompletionCache(ctx context.Context) (*completionCache, error) {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The go-gerrit client does not take a context, so enforce the
+	// deadline here.
+	type result struct {
+		cache *completionCache
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		cache, err := cfg.completionData()
+		done <- result{cache, err}
+	}()
+	select {
+	case r := <-done:
+		return r.cache, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *config) completionData() (*completionCache, error) {
+	changes, _, err := c.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s owner:self status:open", c.gerritProject())},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open CLs: %w", err)
+	}
+	var branches []struct {
+		Ref string `json:"ref"`
+	}
+	if err := c.gerritDo(http.MethodGet, "projects/"+url.PathEscape(c.gerritProject())+"/branches/", nil, &branches); err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	cache := &completionCache{Updated: time.Now()}
+	for _, ch := range *changes {
+		cache.Changes = append(cache.Changes, completionChange{Number: ch.Number, Subject: ch.Subject})
+	}
+	for _, b := range branches {
+		if name, ok := strings.CutPrefix(b.Ref, "refs/heads/"); ok {
+			cache.Branches = append(cache.Branches, name)
+		}
+	}
+	return cache, nil
+}
+
+// completeChanges returns a cobra completion function which completes the
+// numbers of the user's open CLs for the first n arguments, or for all
+// arguments if n is zero.
+func completeChanges(n int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if n > 0 && len(args) >= n {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		cache := loadCompletionCache(completionContext(cmd))
+		if cache == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var res []string
+		for _, ch := range cache.Changes {
+			num := strconv.Itoa(ch.Number)
+			if strings.HasPrefix(num, toComplete) && !slicesContains(args, num) {
+				res = append(res, num+"\t"+ch.Subject)
+			}
+		}
+		return res, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeBranches completes the local branches and the branches of the
+// Gerrit project, the latter as remote-tracking branches of origin.
+func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx := completionContext(cmd)
+	var res []string
+	if out, err := run(ctx, "git", "for-each-ref", "--format=%(refname:short)", "refs/heads"); err == nil {
+		res = append(res, strings.Fields(out)...)
+	}
+	if cache := loadCompletionCache(ctx); cache != nil {
+		for _, b := range cache.Branches {
+			res = append(res, "origin/"+b)
+		}
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,59 @@
+// This file is the schema of the "key: value" config files read by cueckoo:
+// codereview.cfg at the root of a repository, and the user config. It is
+// embedded in cueckoo, which checks both files against it on load, failing
+// on invalid values and warning about unknown keys, which are most likely
+// typos.
+//
+// Each field maps a key, or a pattern of keys, to a constraint on its value.
+// Only the subset of CUE understood by cueckoo's own checker is used: string,
+// regular expressions, disjunctions of strings, and references to the
+// definitions at the bottom of this file.
+
+package config
+
+// #codereview is the schema of codereview.cfg, which is shared with
+// git-codereview.
+#codereview: {
+	gerrit: #URL
+	github: #GitHubURL
+
+	"gerrit-project"?:  string
+	"cue-unity"?:       #GitHubURL
+	"cue-unity-new"?:   #GitHubURL
+	"import-trailers"?: string
+	freeze?:            string
+	"activity-feed"?:   =~"^(#[0-9]+|gist:.+)$"
+
+	[=~"^dependent-"]: #URL
+
+	// Keys used by git-codereview.
+	branch?:          string
+	"parent-branch"?: string
+	issuerepo?:       string
+}
+
+// #user is the schema of the user config.
+#user: {
+	telemetry?:            "on" | "off"
+	"telemetry-endpoint"?: #URL
+	notifier?:             string
+	"freshness-commits"?:  #Count
+	"freshness-age"?:      #Duration
+	"account-map"?:        string
+	"digest-summarizer"?:  string
+
+	[=~"^workspace\\."]: string
+	[=~"^defaults\\."]:  string
+}
+
+// #URL is an http or https URL.
+#URL: =~"^https?://[^/]+"
+
+// #GitHubURL is a GitHub repository URL.
+#GitHubURL: =~"^https://github\\.com/[^/]+/[^/]+$"
+
+// #Count is a whole number.
+#Count: =~"^[0-9]+$"
+
+// #Duration is a duration such as 36h or 7d.
+#Duration: =~"^([0-9]+d|([0-9.]+(ns|us|µs|ms|s|m|h))+)$"
@@ -0,0 +1,254 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// configSchemas holds the schema of the config files, as CUE definitions.
+//
+//go:embed configschema.cue
+var configSchemas string
+
+// configField is a field of a config schema, as parsed by parseConfigSchema.
+type configField struct {
+	// key is the literal key of the field, or empty for a pattern.
+	key string
+
+	// pattern matches the keys of a pattern field.
+	pattern *regexp.Regexp
+
+	optional bool
+
+	// constraint is the CUE constraint on the value, such as
+	// "string" or "#URL".
+	constraint string
+}
+
+var (
+	configFieldRegex   = regexp.MustCompile(`^\t("[^"]+"|\w+)(\?)?:\s*(.+)$`)
+	configPatternRegex = regexp.MustCompile(`^\t\[=~("(?:[^"\\]|\\.)*")\]:\s*(.+)$`)
+)
+
+// parseConfigSchema returns the fields of the definition #name in
+// configSchemas. Like schemaFields in the tests of the payloads, it is no
+// CUE evaluator; it relies on the schema being cue fmt'ed and simple.
+func parseConfigSchema(name string) ([]configField, error) {
+	def := cueDefinition(configSchemas, name)
+	if def == "" {
+		return nil, fmt.Errorf("no definition #%s in config schema", name)
+	}
+	var fields []configField
+	for _, line := range strings.Split(def, "\n") {
+		if m := configPatternRegex.FindStringSubmatch(line); m != nil {
+			re, err := strconv.Unquote(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s in config schema: %v", m[1], err)
+			}
+			rx, err := regexp.Compile(re)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s in config schema: %v", m[1], err)
+			}
+			fields = append(fields, configField{pattern: rx, optional: true, constraint: m[2]})
+		} else if m := configFieldRegex.FindStringSubmatch(line); m != nil {
+			key := m[1]
+			if k, err := strconv.Unquote(key); err == nil {
+				key = k
+			}
+			fields = append(fields, configField{key: key, optional: m[2] != "", constraint: m[3]})
+		}
+	}
+	return fields, nil
+}
+
+// checkConfigValue checks value against the CUE constraint c, returning a
+// description of what was expected if it does not match.
+func checkConfigValue(c, value string) (ok bool, expected string, _ error) {
+	switch {
+	case c == "string":
+		return true, "", nil
+	case strings.HasPrefix(c, "#"):
+		body, desc, ok := configDefinition(c)
+		if !ok {
+			return false, "", fmt.Errorf("no definition %s in config schema", c)
+		}
+		ok, _, err := checkConfigValue(body, value)
+		return ok, desc, err
+	case strings.HasPrefix(c, "=~"):
+		re, err := strconv.Unquote(strings.TrimSpace(c[2:]))
+		if err != nil {
+			return false, "", fmt.Errorf("invalid constraint %s in config schema: %v", c, err)
+		}
+		rx, err := regexp.Compile(re)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid constraint %s in config schema: %v", c, err)
+		}
+		return rx.MatchString(value), "a value matching " + re, nil
+	case strings.HasPrefix(c, `"`):
+		var alts []string
+		for _, alt := range strings.Split(c, "|") {
+			s, err := strconv.Unquote(strings.TrimSpace(alt))
+			if err != nil {
+				return false, "", fmt.Errorf("invalid constraint %s in config schema: %v", c, err)
+			}
+			if s == value {
+				return true, "", nil
+			}
+			alts = append(alts, strconv.Quote(s))
+		}
+		return false, "one of " + strings.Join(alts, ", "), nil
+	}
+	return false, "", fmt.Errorf("unsupported constraint %s in config schema", c)
+}
+
+// configDefinition returns the constraint of the single-line definition
+// named by ref, such as "#URL", along with its description, taken from a doc
+// comment of the form "#URL is an http or https URL."
+func configDefinition(ref string) (constraint, desc string, ok bool) {
+	lines := strings.Split(configSchemas, "\n")
+	for i, line := range lines {
+		constraint, ok := strings.CutPrefix(line, ref+":")
+		if !ok {
+			continue
+		}
+		desc = ref[1:]
+		if i > 0 {
+			if d, ok := strings.CutPrefix(lines[i-1], "// "+ref+" is "); ok {
+				desc = strings.TrimSuffix(d, ".")
+			}
+		}
+		return strings.TrimSpace(constraint), desc, true
+	}
+	return "", "", false
+}
+
+// validateConfig checks cfg, as loaded from the file named by source,
+// against the definition #name of the config schema. Invalid values and
+// missing required keys are returned as an error, while unknown keys are
+// returned as warnings, suggesting a known key for likely typos.
+func validateConfig(name, source string, cfg map[string]string) (warnings []string, _ error) {
+	fields, err := parseConfigSchema(name)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, f := range fields {
+		if _, ok := cfg[f.key]; f.key != "" && !ok && !f.optional {
+			problems = append(problems, fmt.Sprintf("%s: missing required key", f.key))
+		}
+	}
+	for _, key := range sortedKeys(cfg) {
+		var field *configField
+		for i, f := range fields {
+			if f.key == key || f.pattern != nil && f.pattern.MatchString(key) {
+				field = &fields[i]
+				break
+			}
+		}
+		if field == nil {
+			msg := fmt.Sprintf("%s: unknown key %q", source, key)
+			if s := closestConfigKey(key, fields); s != "" {
+				msg += fmt.Sprintf("; did you mean %q?", s)
+			}
+			warnings = append(warnings, msg)
+			continue
+		}
+		ok, expected, err := checkConfigValue(field.constraint, cfg[key])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: expected %s, got %q", key, expected, cfg[key]))
+		}
+	}
+	if len(problems) > 0 {
+		return warnings, fmt.Errorf("invalid %s:\n\t%s", source, strings.Join(problems, "\n\t"))
+	}
+	return warnings, nil
+}
+
+// closestConfigKey returns the literal key of fields closest to key, if it
+// is close enough to be a likely typo.
+func closestConfigKey(key string, fields []configField) string {
+	best, bestDist := "", 3
+	for _, f := range fields {
+		if f.key == "" {
+			continue
+		}
+		if d := editDistance(key, f.key); d < bestDist {
+			best, bestDist = f.key, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Damerau-Levenshtein distance between a and b, in
+// its optimal string alignment form, such that transposed letters count as a
+// single edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// TODO: replace once we can use the min builtin
+func minInt(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+	return first
+}
+
+// configWarned records the config warnings already printed, as the user
+// config is loaded by several parts of a single invocation.
+var configWarned sync.Map
+
+// printConfigWarnings prints warnings to stderr, once per process.
+func printConfigWarnings(warnings []string) {
+	sort.Strings(warnings)
+	for _, w := range warnings {
+		if _, loaded := configWarned.LoadOrStore(w, true); !loaded {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+	}
+}
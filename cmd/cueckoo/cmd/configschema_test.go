@@ -0,0 +1,130 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		def      string
+		cfg      map[string]string
+		warnings []string
+		err      string
+	}{{
+		name: "Valid",
+		def:  "codereview",
+		cfg: map[string]string{
+			"gerrit":          "https://review.gerrithub.io/a/cue-lang/cue",
+			"github":          "https://github.com/cue-lang/cue",
+			"cue-unity-new":   "https://github.com/cue-unity/unity-private",
+			"dependent-unity": "https://github.com/cue-unity/unity",
+			"branch":          "master",
+		},
+	}, {
+		name: "Typo",
+		def:  "codereview",
+		cfg: map[string]string{
+			"gerrit":     "https://review.gerrithub.io/a/cue-lang/cue",
+			"github":     "https://github.com/cue-lang/cue",
+			"cue-untiy":  "https://github.com/cue-unity/unity",
+			"frobnicate": "yes",
+		},
+		warnings: []string{
+			`codereview.cfg: unknown key "cue-untiy"; did you mean "cue-unity"?`,
+			`codereview.cfg: unknown key "frobnicate"`,
+		},
+	}, {
+		name: "InvalidValues",
+		def:  "codereview",
+		cfg: map[string]string{
+			"github":    "https://github.com/cue-lang/cue",
+			"cue-unity": "cue-unity/unity",
+		},
+		err: `invalid codereview.cfg:
+	gerrit: missing required key
+	cue-unity: expected a GitHub repository URL, got "cue-unity/unity"`,
+	}, {
+		name: "User",
+		def:  "user",
+		cfg: map[string]string{
+			"telemetry":         "yes",
+			"freshness-age":     "3d",
+			"freshness-commits": "many",
+			"workspace.unity":   "~/src/unity",
+		},
+		err: `invalid config:
+	freshness-commits: expected a whole number, got "many"
+	telemetry: expected one of "on", "off", got "yes"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := "codereview.cfg"
+			if tc.def == "user" {
+				source = "config"
+			}
+			warnings, err := validateConfig(tc.def, source, tc.cfg)
+			if diff := cmp.Diff(tc.warnings, warnings); diff != "" {
+				t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+			}
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.err {
+				t.Errorf("got error:\n%s\nwant:\n%s", got, tc.err)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"cue-unity", "cue-unity", 0},
+		{"cue-untiy", "cue-unity", 1},
+		{"gerit", "gerrit", 1},
+		{"telemetry", "notifier", 8},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d; want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestConfigSchemaDefinitions(t *testing.T) {
+	// Every definition referenced by a field must exist.
+	for _, def := range []string{"codereview", "user"} {
+		fields, err := parseConfigSchema(def)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range fields {
+			if !strings.HasPrefix(f.constraint, "#") {
+				continue
+			}
+			if _, _, ok := configDefinition(f.constraint); !ok {
+				t.Errorf("#%s: field references missing definition %s", def, f.constraint)
+			}
+		}
+	}
+}
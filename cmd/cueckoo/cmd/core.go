@@ -52,6 +52,14 @@ type Command struct {
 	*cobra.Command
 	root   *cobra.Command
 	hasErr bool
+
+	// defaultArgsNote says which default arguments from the user config
+	// were applied, if any; see applyDefaultArgs.
+	defaultArgsNote string
+
+	// flagRegistries holds the flag registries of the commands in the tree;
+	// see registerFlags.
+	flagRegistries map[flagRegistryKey]*flagRegistry
 }
 
 func (c *Command) Run(ctx context.Context) (err error) {
@@ -95,6 +103,11 @@ func (f flagName) String(cmd *Command) string {
 	return v
 }
 
+func (f flagName) Int(cmd *Command) int {
+	v, _ := cmd.Flags().GetInt(string(f))
+	return v
+}
+
 func (f flagName) StringArray(cmd *Command) []string {
 	v, _ := cmd.Flags().GetStringArray(string(f))
 	return v
@@ -102,9 +115,17 @@ func (f flagName) StringArray(cmd *Command) []string {
 
 var errPrintedError = errors.New("terminating because of errors")
 
+// errPrintedPartialFailure is like errPrintedError, but signals that the
+// command only partially failed. See partialFailureError.
+var errPrintedPartialFailure = errors.New("terminating because of partial failure")
+
 func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		c.Command = cmd
+		if err := c.checkFlags(cmd, args); err != nil {
+			exitOnErr(c, err, true)
+			return err
+		}
 		err := f(c, args)
 		if err != nil {
 			exitOnErr(c, err, true)
@@ -127,7 +148,12 @@ func exitOnErr(cmd *Command, err error, fatal bool) {
 	}
 
 	fmt.Fprintln(os.Stderr, err)
+	printErrorHint(os.Stderr, err)
 	if fatal {
+		var pf *partialFailureError
+		if errors.As(err, &pf) {
+			panic(panicError{errPrintedPartialFailure})
+		}
 		exit()
 	}
 }
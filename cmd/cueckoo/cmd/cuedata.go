@@ -0,0 +1,73 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// loadCUEFile decodes the data in the CUE file at path into v.
+//
+// JSON is valid CUE, so files which are plain JSON are decoded directly.
+// Anything else is exported to JSON using the cue command, which must be
+// available on PATH; cueckoo does not depend on the CUE Go API.
+func loadCUEFile(ctx context.Context, path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err == nil {
+		return nil
+	} else if filepath.Ext(path) != ".cue" {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	out, err := run(ctx, "cue", "export", "--out", "json", path)
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", path, err)
+	}
+	if err := json.Unmarshal([]byte(out), v); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return nil
+}
+
+// decodeCUE is like loadCUEFile, but decodes CUE data which is held in
+// memory, such as a file fetched from a remote repository. name is used in
+// error messages.
+func decodeCUE(ctx context.Context, name string, data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err == nil {
+		return nil
+	}
+	f, err := os.CreateTemp("", "cueckoo-*.cue")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	if err := loadCUEFile(ctx, f.Name(), v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return nil
+}
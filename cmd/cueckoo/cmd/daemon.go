@@ -0,0 +1,138 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const flagDaemon flagName = "daemon"
+
+// In daemon mode, a long-running cueckoo process listens on a unix socket.
+// Other cueckoo invocations find the socket, pass their arguments, working
+// directory, environment and standard I/O file descriptors to the daemon,
+// and exit with the exit code of the command as run by the daemon. This means
+// that loops in scripts which run cueckoo many times only load the config
+// and authenticate once, and reuse warm HTTP connections.
+//
+// The daemon runs one command at a time, as commands rely on process-wide
+// state such as the working directory.
+//
+// The socket lives in a directory only accessible by the user, and both ends
+// check that the peer runs as the same user. Credentials are never sent over
+// the socket: commands run by the daemon use the credentials from the
+// daemon's own environment.
+
+// daemonRequest is sent by a client to the daemon after its standard I/O
+// file descriptors.
+type daemonRequest struct {
+	Args []string `json:"args"`
+	Dir  string   `json:"dir"`
+	Env  []string `json:"env"`
+}
+
+// daemonResponse is sent by the daemon once the command has finished.
+type daemonResponse struct {
+	Code int `json:"code"`
+}
+
+// credentialEnv lists the environment variables holding credentials. They
+// are not forwarded to the daemon.
+var credentialEnv = []string{"GITHUB_USER", "GITHUB_PAT", "GITHUB_TOKEN", "GERRIT_USER", "GERRIT_PASSWORD"}
+
+// daemonSocket returns the path of the daemon socket, or the empty string if
+// the use of a daemon has been disabled by setting CUECKOO_DAEMON=off. The
+// directory containing the socket must only be accessible by the user.
+func daemonSocket() string {
+	switch s := os.Getenv("CUECKOO_DAEMON"); s {
+	case "off":
+		return ""
+	case "":
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+			return filepath.Join(dir, "cueckoo.sock")
+		}
+		dir := filepath.Join(os.TempDir(), fmt.Sprintf("cueckoo-%d", os.Getuid()))
+		return filepath.Join(dir, "daemon.sock")
+	default:
+		return s
+	}
+}
+
+// isCredentialEnv reports whether the environment entry kv sets one of
+// credentialEnv.
+func isCredentialEnv(kv string) bool {
+	k, _, _ := strings.Cut(kv, "=")
+	return slicesContains(credentialEnv, k)
+}
+
+// daemonEnv returns env without the credentials listed in credentialEnv.
+func daemonEnv(env []string) []string {
+	var res []string
+	for _, kv := range env {
+		if !isCredentialEnv(kv) {
+			res = append(res, kv)
+		}
+	}
+	return res
+}
+
+// configCache holds the configs loaded by loadConfig when running as a
+// daemon, such that their clients are reused across commands.
+var configCache struct {
+	sync.Mutex
+	enabled bool
+	configs map[string]*config
+}
+
+// configCacheKey returns the key under which a config is cached. It includes
+// everything that loadConfig reads, such that a change in the codereview
+// config or the credentials results in a new config.
+func configCacheKey(gitRoot string, cfg map[string]string) string {
+	key := struct {
+		GitRoot string
+		Config  map[string]string
+		Env     []string
+	}{GitRoot: gitRoot, Config: cfg}
+	for _, name := range credentialEnv {
+		key.Env = append(key.Env, os.Getenv(name))
+	}
+	b, _ := json.Marshal(key)
+	return string(b)
+}
+
+// cachedConfig returns the config cached under key, if any.
+func cachedConfig(key string) *config {
+	configCache.Lock()
+	defer configCache.Unlock()
+	return configCache.configs[key]
+}
+
+// storeConfig caches cfg under key when running as a daemon.
+func storeConfig(key string, cfg *config) {
+	configCache.Lock()
+	defer configCache.Unlock()
+	if !configCache.enabled {
+		return
+	}
+	if configCache.configs == nil {
+		configCache.configs = make(map[string]*config)
+	}
+	configCache.configs[key] = cfg
+}
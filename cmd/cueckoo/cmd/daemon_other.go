@@ -0,0 +1,31 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !unix
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+func runViaDaemon(args []string) (code int, ok bool) {
+	return 0, false
+}
+
+func serveDaemon(ctx context.Context, w io.Writer) error {
+	return fmt.Errorf("--%s is only supported on unix systems", flagDaemon)
+}
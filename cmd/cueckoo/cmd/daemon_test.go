@@ -0,0 +1,35 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDaemonEnv(t *testing.T) {
+	env := []string{
+		"HOME=/home/gopher",
+		"GITHUB_TOKEN=secret",
+		"GERRIT_PASSWORD=secret",
+		"GITHUB_TOKEN_FILE=/tmp/token",
+		"CUECKOO_DEBUG=1",
+	}
+	want := []string{"HOME=/home/gopher", "GITHUB_TOKEN_FILE=/tmp/token", "CUECKOO_DEBUG=1"}
+	if diff := cmp.Diff(want, daemonEnv(env)); diff != "" {
+		t.Errorf("daemonEnv mismatch (-want +got):\n%s", diff)
+	}
+}
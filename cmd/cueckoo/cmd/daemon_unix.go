@@ -0,0 +1,277 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// runViaDaemon runs the command given by args via the daemon, if one is
+// listening. It reports whether it did so, along with the exit code.
+func runViaDaemon(args []string) (code int, ok bool) {
+	if slicesContains(args, "--"+string(flagDaemon)) {
+		return 0, false
+	}
+	path := daemonSocket()
+	if path == "" {
+		return 0, false
+	}
+	if err := checkPrivateDir(filepath.Dir(path)); err != nil {
+		debugf("not using daemon: %v\n", err)
+		return 0, false
+	}
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return 0, false
+	}
+	defer conn.Close()
+	if err := checkPeer(conn); err != nil {
+		debugf("not using daemon: %v\n", err)
+		return 0, false
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return 0, false
+	}
+
+	// Until the request has been sent in full, the daemon cannot have run
+	// anything, so fall back to running the command ourselves.
+	rights := syscall.UnixRights(int(os.Stdin.Fd()), int(os.Stdout.Fd()), int(os.Stderr.Fd()))
+	if _, _, err := conn.WriteMsgUnix([]byte{0}, rights, nil); err != nil {
+		debugf("failed to send file descriptors to daemon: %v\n", err)
+		return 0, false
+	}
+	req := daemonRequest{Args: args, Dir: dir, Env: daemonEnv(os.Environ())}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		debugf("failed to send request to daemon: %v\n", err)
+		return 0, false
+	}
+	var resp daemonResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		fmt.Fprintf(os.Stderr, "lost connection to cueckoo daemon: %v\n", err)
+		return 1, true
+	}
+	return resp.Code, true
+}
+
+// serveDaemon listens on the daemon socket and runs the commands requested by
+// clients, one at a time, until ctx is done or the process is interrupted.
+func serveDaemon(ctx context.Context, w io.Writer) error {
+	path := daemonSocket()
+	if path == "" {
+		return fmt.Errorf("the daemon is disabled by CUECKOO_DAEMON=off")
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	dir := filepath.Dir(path)
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	if err := checkPrivateDir(dir); err != nil {
+		return err
+	}
+	// Any existing socket is stale.
+	os.Remove(path)
+	// Create the socket without access for anyone but the user, rather than
+	// restricting it once it is already listening.
+	umask := syscall.Umask(0o077)
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	syscall.Umask(umask)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	configCache.Lock()
+	configCache.enabled = true
+	configCache.Unlock()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	fmt.Fprintf(w, "listening on %s\n", path)
+	for {
+		conn, err := l.AcceptUnix()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		serveDaemonConn(ctx, conn)
+	}
+}
+
+func serveDaemonConn(ctx context.Context, conn *net.UnixConn) {
+	defer conn.Close()
+	if err := checkPeer(conn); err != nil {
+		fmt.Fprintf(os.Stderr, "daemon: rejecting connection: %v\n", err)
+		return
+	}
+	files, err := receiveFiles(conn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "daemon: %v\n", err)
+		return
+	}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	var req daemonRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		fmt.Fprintf(os.Stderr, "daemon: failed to decode request: %v\n", err)
+		return
+	}
+	code := runDaemonRequest(ctx, req, files[0], files[1], files[2])
+	if err := json.NewEncoder(conn).Encode(daemonResponse{Code: code}); err != nil {
+		fmt.Fprintf(os.Stderr, "daemon: failed to send response: %v\n", err)
+	}
+}
+
+// checkPrivateDir checks that dir is a directory owned by the user which no
+// one else can access, such that no one else can place a socket in it.
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	switch {
+	case !info.IsDir():
+		return fmt.Errorf("%s is not a directory", dir)
+	case !ok || int(st.Uid) != os.Getuid():
+		return fmt.Errorf("%s is not owned by the current user", dir)
+	case info.Mode().Perm()&0o077 != 0:
+		return fmt.Errorf("%s is accessible by other users (mode %v)", dir, info.Mode().Perm())
+	}
+	return nil
+}
+
+// checkPeer checks that the process at the other end of conn runs as the
+// current user.
+func checkPeer(conn *net.UnixConn) error {
+	uid, err := peerUID(conn)
+	if err != nil {
+		return fmt.Errorf("failed to get peer credentials: %v", err)
+	}
+	if uid != os.Getuid() {
+		return fmt.Errorf("peer runs as uid %d, not %d", uid, os.Getuid())
+	}
+	return nil
+}
+
+// receiveFiles receives the standard I/O file descriptors sent by a client.
+func receiveFiles(conn *net.UnixConn) ([]*os.File, error) {
+	buf := make([]byte, 1)
+	oob := make([]byte, syscall.CmsgSpace(3*4))
+	_, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file descriptors: %v", err)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file descriptors: %v", err)
+	}
+	var fds []int
+	for i := range msgs {
+		rights, err := syscall.ParseUnixRights(&msgs[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file descriptors: %v", err)
+		}
+		fds = append(fds, rights...)
+	}
+	if len(fds) != 3 {
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+		return nil, fmt.Errorf("expected 3 file descriptors, got %d", len(fds))
+	}
+	return []*os.File{
+		os.NewFile(uintptr(fds[0]), "stdin"),
+		os.NewFile(uintptr(fds[1]), "stdout"),
+		os.NewFile(uintptr(fds[2]), "stderr"),
+	}, nil
+}
+
+// runDaemonRequest runs the command for req with the client's standard I/O,
+// working directory and environment in place of the daemon's own, restoring
+// them afterwards.
+func runDaemonRequest(ctx context.Context, req daemonRequest, stdin, stdout, stderr *os.File) (code int) {
+	origStdin, origStdout, origStderr := os.Stdin, os.Stdout, os.Stderr
+	origEnv := os.Environ()
+	origDebug := debug
+	origDir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(stderr, "daemon: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			fmt.Fprintf(stderr, "daemon: panic: %v\n", e)
+			code = 1
+		}
+		os.Stdin, os.Stdout, os.Stderr = origStdin, origStdout, origStderr
+		log.SetOutput(origStderr)
+		setEnv(origEnv)
+		debug = origDebug
+		os.Chdir(origDir)
+	}()
+
+	os.Stdin, os.Stdout, os.Stderr = stdin, stdout, stderr
+	log.SetOutput(stderr)
+	// Commands use the daemon's own credentials, which clients do not send.
+	env := daemonEnv(req.Env)
+	for _, kv := range origEnv {
+		if isCredentialEnv(kv) {
+			env = append(env, kv)
+		}
+	}
+	setEnv(env)
+	debug = os.Getenv("CUECKOO_DEBUG") != ""
+	if err := os.Chdir(req.Dir); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return mainCode(ctx, req.Args)
+}
+
+// setEnv replaces the environment with env.
+func setEnv(env []string) {
+	os.Clearenv()
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		os.Setenv(k, v)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagVerbose flagName = "verbose"
+
+	// defaultsKeyPrefix prefixes the user config keys giving the default
+	// arguments of a command, e.g. "defaults.runtrybot: --nounity" or
+	// "defaults.rota.report: --since 14d".
+	defaultsKeyPrefix = "defaults."
+)
+
+// applyDefaultArgs returns args with the default arguments configured in
+// the user config cfg for the command which args run inserted right after
+// the name of the command, such that arguments given explicitly take
+// precedence. It also returns a note on where the defaults came from, which
+// is empty if none were applied.
+func applyDefaultArgs(root *cobra.Command, args []string, cfg map[string]string) ([]string, string, error) {
+	cmd, _, err := root.Find(args)
+	if err != nil || cmd == root {
+		// Let cobra report any error as usual.
+		return args, "", nil
+	}
+	var path []*cobra.Command
+	for c := cmd; c != root; c = c.Parent() {
+		path = append([]*cobra.Command{c}, path...)
+	}
+	var names []string
+	for _, c := range path {
+		names = append(names, c.Name())
+	}
+	key := defaultsKeyPrefix + strings.Join(names, ".")
+	value, ok := cfg[key]
+	if !ok {
+		return args, "", nil
+	}
+	defaults, err := splitArgs(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s entry in user config: %v", key, err)
+	}
+
+	// Find the position of the name of the command, which may be preceded
+	// by global flags.
+	pos := -1
+	for _, c := range path {
+		for i := pos + 1; i < len(args); i++ {
+			if args[i] == c.Name() || c.HasAlias(args[i]) {
+				pos = i
+				break
+			}
+		}
+	}
+	if pos < 0 {
+		return args, "", nil
+	}
+	res := make([]string, 0, len(args)+len(defaults))
+	res = append(res, args[:pos+1]...)
+	res = append(res, defaults...)
+	res = append(res, args[pos+1:]...)
+	note := fmt.Sprintf("applying default arguments from the %s entry of the user config: %s", key, strings.Join(defaults, " "))
+	return res, note, nil
+}
+
+// splitArgs splits s into arguments at spaces, like a shell would, except
+// within single or double quotes. A backslash escapes the next character
+// outside of single quotes.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var b strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			b.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+)
+
+func TestSplitArgs(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+		err  bool
+	}{
+		{in: "", want: nil},
+		{in: "--nounity", want: []string{"--nounity"}},
+		{in: "  --since   14d ", want: []string{"--since", "14d"}},
+		{in: `--versions "v0.8.0 v0.9.0"`, want: []string{"--versions", "v0.8.0 v0.9.0"}},
+		{in: `--reason 'it\'s'`, err: true},
+		{in: `--reason it\'s ''`, want: []string{"--reason", "it's", ""}},
+		{in: `"unterminated`, err: true},
+	}
+	for _, c := range cases {
+		got, err := splitArgs(c.in)
+		if (err != nil) != c.err {
+			t.Errorf("splitArgs(%q): got error %v; want error %v", c.in, err, c.err)
+			continue
+		}
+		if diff := cmp.Diff(c.want, got); diff != "" {
+			t.Errorf("splitArgs(%q) (-want +got):\n%s", c.in, diff)
+		}
+	}
+}
+
+func TestApplyDefaultArgs(t *testing.T) {
+	root := &cobra.Command{Use: "cueckoo"}
+	root.PersistentFlags().String("repo-dir", "", "")
+	rota := &cobra.Command{Use: "rota"}
+	rota.AddCommand(&cobra.Command{Use: "report", Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(
+		&cobra.Command{Use: "runtrybot", Run: func(*cobra.Command, []string) {}},
+		&cobra.Command{Use: "unity", Run: func(*cobra.Command, []string) {}},
+		rota,
+	)
+	cfg := map[string]string{
+		"defaults.runtrybot":   "--nounity",
+		"defaults.rota.report": "--since 14d",
+	}
+	cases := []struct {
+		args []string
+		want []string
+		note bool
+	}{
+		{args: []string{"runtrybot", "1234"}, want: []string{"runtrybot", "--nounity", "1234"}, note: true},
+		{args: []string{"--repo-dir=x", "runtrybot"}, want: []string{"--repo-dir=x", "runtrybot", "--nounity"}, note: true},
+		{args: []string{"rota", "report", "--since", "1d"}, want: []string{"rota", "report", "--since", "14d", "--since", "1d"}, note: true},
+		{args: []string{"unity", "runtrybot"}, want: []string{"unity", "runtrybot"}},
+		{args: []string{"rota"}, want: []string{"rota"}},
+		{args: []string{"nosuchcommand"}, want: []string{"nosuchcommand"}},
+	}
+	for _, c := range cases {
+		got, note, err := applyDefaultArgs(root, c.args, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(c.want, got); diff != "" {
+			t.Errorf("%q (-want +got):\n%s", c.args, diff)
+		}
+		if (note != "") != c.note {
+			t.Errorf("%q: got note %q; want a note: %v", c.args, note, c.note)
+		}
+	}
+}
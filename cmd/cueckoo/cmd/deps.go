@@ -0,0 +1,308 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newDepsCmd creates a new deps command, which groups the subcommands for
+// keeping the CUE module dependencies of the repository up to date.
+func newDepsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deps",
+		Short: "keep the CUE module dependencies of the repository up to date",
+	}
+	subCommands := []*cobra.Command{
+		newDepsCheckCmd(c),
+		newDepsBumpCmd(c),
+	}
+	for _, sub := range subCommands {
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
+
+// newDepsCheckCmd creates a new deps check command
+func newDepsCheckCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "list outdated CUE module dependencies",
+		Long: `
+Usage of deps check:
+
+	deps check
+
+check lists the dependencies in cue.mod/module.cue for which the registry has
+a newer release with the same major version. Pre-release versions are not
+considered.
+
+The registry is taken from $CUE_REGISTRY if it names a single registry, such
+as "registry.example.com/prefix", and is otherwise ` + defaultCUERegistry + `.
+Modules are fetched anonymously.
+`,
+		RunE: mkRunE(c, depsCheckDef),
+	}
+	return cmd
+}
+
+func depsCheckDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("check does not take any arguments")
+	}
+	ctx := cmd.Context()
+	gitRoot, err := run(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	updates, err := outdatedDeps(ctx, strings.TrimSpace(gitRoot))
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	if len(updates) == 0 {
+		fmt.Fprintln(w, "all dependencies are up to date")
+		return nil
+	}
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "MODULE\tCURRENT\tLATEST\tCHANGES")
+	for _, u := range updates {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", u.module, u.current, u.latest, orNone(u.changesURL()))
+	}
+	tw.Flush()
+	return nil
+}
+
+// defaultCUERegistry is the registry used by the cue command by default.
+const defaultCUERegistry = "registry.cue.works"
+
+// depUpdate is an available update of a module dependency.
+type depUpdate struct {
+	module  string // module path with major version suffix, e.g. foo.com/bar@v0
+	current string
+	latest  string
+}
+
+// path returns the module path without the major version suffix.
+func (u depUpdate) path() string {
+	path, _, _ := strings.Cut(u.module, "@")
+	return path
+}
+
+// changesURL returns a URL comparing the current and latest versions, for
+// modules hosted on GitHub, or the empty string.
+func (u depUpdate) changesURL() string {
+	parts := strings.Split(u.path(), "/")
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return ""
+	}
+	// Modules in subdirectories are tagged with the subdirectory as prefix.
+	prefix := strings.Join(parts[3:], "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return fmt.Sprintf("https://github.com/%s/%s/compare/%s%s...%s%s", parts[1], parts[2], prefix, u.current, prefix, u.latest)
+}
+
+// outdatedDeps returns the dependencies of the CUE module at gitRoot with a
+// newer release in the registry, sorted by module.
+func outdatedDeps(ctx context.Context, gitRoot string) ([]depUpdate, error) {
+	var mod struct {
+		Deps map[string]struct {
+			V string `json:"v"`
+		} `json:"deps"`
+	}
+	if err := loadCUEFile(ctx, filepath.Join(gitRoot, "cue.mod", "module.cue"), &mod); err != nil {
+		return nil, err
+	}
+	reg := cueRegistry()
+	var updates []depUpdate
+	for _, module := range sortedKeys(mod.Deps) {
+		current := mod.Deps[module].V
+		path, major, ok := strings.Cut(module, "@")
+		if !ok {
+			return nil, fmt.Errorf("dependency %q has no major version suffix", module)
+		}
+		versions, err := reg.versions(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if latest := latestVersion(versions, major); latest != "" && releaseVersionLess(current, latest) {
+			updates = append(updates, depUpdate{module: module, current: current, latest: latest})
+		}
+	}
+	return updates, nil
+}
+
+// latestVersion returns the highest release version in versions with the
+// given major version, such as "v0", or the empty string if there is none.
+func latestVersion(versions []string, major string) string {
+	latest := ""
+	for _, v := range versions {
+		if !releaseVersionRegex.MatchString(v) || !strings.HasPrefix(v, major+".") {
+			continue
+		}
+		if latest == "" || releaseVersionLess(latest, v) {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// releaseVersionLess reports whether the release version a is lower than b.
+// Versions which are not release versions sort first.
+func releaseVersionLess(a, b string) bool {
+	parse := func(v string) (n [3]int, ok bool) {
+		m := releaseVersionRegex.FindStringSubmatch(v)
+		if m == nil {
+			return n, false
+		}
+		for i := range n {
+			n[i], _ = strconv.Atoi(m[i+1])
+		}
+		return n, true
+	}
+	na, oka := parse(a)
+	nb, okb := parse(b)
+	if oka != okb {
+		return !oka
+	}
+	for i := range na {
+		if na[i] != nb[i] {
+			return na[i] < nb[i]
+		}
+	}
+	return false
+}
+
+// cueModRegistry is an OCI registry holding CUE modules, with an optional
+// repository prefix.
+type cueModRegistry struct {
+	host   string
+	prefix string
+}
+
+// simpleRegistryRegex matches CUE_REGISTRY values naming a single registry,
+// as opposed to per-module configuration.
+var simpleRegistryRegex = regexp.MustCompile(`^[^,=+]+$`)
+
+func cueRegistry() cueModRegistry {
+	r := os.Getenv("CUE_REGISTRY")
+	if !simpleRegistryRegex.MatchString(r) {
+		r = defaultCUERegistry
+	}
+	host, prefix, _ := strings.Cut(r, "/")
+	return cueModRegistry{host: host, prefix: prefix}
+}
+
+// versions returns the tags of the module with the given path, without its
+// major version suffix.
+func (r cueModRegistry) versions(ctx context.Context, path string) ([]string, error) {
+	repo := path
+	if r.prefix != "" {
+		repo = r.prefix + "/" + path
+	}
+	u := "https://" + r.host + "/v2/" + repo + "/tags/list"
+	var res struct {
+		Tags []string `json:"tags"`
+	}
+	resp, err := ociGet(ctx, u, "")
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// Anonymous pulls still need a token from the registry's token
+		// service; see the distribution spec's token authentication.
+		resp.Body.Close()
+		var token string
+		token, err = ociToken(ctx, resp.Header.Get("Www-Authenticate"), repo)
+		if err == nil {
+			resp, err = ociGet(ctx, u, token)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list versions of %s: %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("failed to decode versions of %s: %v", path, err)
+	}
+	return res.Tags, nil
+}
+
+func ociGet(ctx context.Context, u, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+var challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ociToken obtains an anonymous pull token for repo from the token service
+// described by a Bearer WWW-Authenticate challenge.
+func ociToken(ctx context.Context, challenge, repo string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	p := make(map[string]string)
+	for _, m := range challengeParamRegex.FindAllStringSubmatch(params, -1) {
+		p[m[1]] = m[2]
+	}
+	if p["realm"] == "" {
+		return "", fmt.Errorf("authentication challenge %q has no realm", challenge)
+	}
+	q := url.Values{"scope": {"repository:" + repo + ":pull"}}
+	if p["service"] != "" {
+		q.Set("service", p["service"])
+	}
+	resp, err := ociGet(ctx, p["realm"]+"?"+q.Encode(), "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("token request failed: %s: %s", resp.Status, body)
+	}
+	var res struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	if res.Token != "" {
+		return res.Token, nil
+	}
+	return res.AccessToken, nil
+}
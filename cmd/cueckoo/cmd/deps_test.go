@@ -0,0 +1,52 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "testing"
+
+func TestLatestVersion(t *testing.T) {
+	versions := []string{"v0.1.0", "v0.10.0", "v0.9.3", "v0.11.0-alpha.1", "v1.2.0", "latest"}
+	for major, want := range map[string]string{
+		"v0": "v0.10.0",
+		"v1": "v1.2.0",
+		"v2": "",
+	} {
+		if got := latestVersion(versions, major); got != want {
+			t.Errorf("latestVersion(%s) = %q, want %q", major, got, want)
+		}
+	}
+}
+
+func TestDepUpdateChangesURL(t *testing.T) {
+	testCases := []struct {
+		module string
+		want   string
+	}{{
+		module: "github.com/cue-lang/example@v0",
+		want:   "https://github.com/cue-lang/example/compare/v0.1.0...v0.2.0",
+	}, {
+		module: "github.com/cue-lang/example/sub/mod@v0",
+		want:   "https://github.com/cue-lang/example/compare/sub/mod/v0.1.0...sub/mod/v0.2.0",
+	}, {
+		module: "cue.dev/x/example@v0",
+		want:   "",
+	}}
+	for _, tc := range testCases {
+		u := depUpdate{module: tc.module, current: "v0.1.0", latest: "v0.2.0"}
+		if got := u.changesURL(); got != tc.want {
+			t.Errorf("changesURL of %s = %q, want %q", tc.module, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/gitcmd"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagDepsBumpEach flagName = "each"
+)
+
+// newDepsBumpCmd creates a new deps bump command
+func newDepsBumpCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bump",
+		Short: "create CLs updating outdated CUE module dependencies",
+		Long: `
+Usage of deps bump:
+
+	deps bump [--each] [MODULE...]
+
+bump creates a CL updating the outdated dependencies listed by "deps check"
+to their latest versions, or only the given modules if any. The updates are
+made with "cue mod get" on top of the default branch of origin, leaving the
+working tree untouched, so the cue command must be v0.9.0 or later.
+
+By default all the updates are grouped into a single CL. With --each, a CL is
+created per module instead, such that they can be reviewed and submitted
+independently. The commit messages link to the changes between the versions
+for modules hosted on GitHub.
+
+If only some of the CLs could be created, bump exits with status 2.
+`,
+		RunE: mkRunE(c, depsBumpDef),
+	}
+	c.registerFlags(cmd).Bool(flagDepsBumpEach, false, "create a CL per module")
+	return cmd
+}
+
+func depsBumpDef(cmd *Command, args []string) error {
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	updates, err := outdatedDeps(ctx, cfg.gitRoot)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		var selected []depUpdate
+		for _, u := range updates {
+			if slicesContains(args, u.module) || slicesContains(args, u.path()) {
+				selected = append(selected, u)
+			}
+		}
+		updates = selected
+	}
+	w := cmd.OutOrStdout()
+	if len(updates) == 0 {
+		fmt.Fprintln(w, "nothing to update")
+		return nil
+	}
+
+	base, err := run(ctx, "git", "rev-parse", "--abbrev-ref", "origin/HEAD")
+	if err != nil {
+		return fmt.Errorf("cannot determine the default branch of origin: %w", err)
+	}
+	base = strings.TrimSpace(base)
+	branch := strings.TrimPrefix(base, "origin/")
+	if err := gitcmd.New("").Fetch(ctx, "origin", 0, branch); err != nil {
+		return err
+	}
+
+	groups := [][]depUpdate{updates}
+	if flagDepsBumpEach.Bool(cmd) {
+		groups = nil
+		for _, u := range updates {
+			groups = append(groups, []depUpdate{u})
+		}
+	}
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "MODULES\tRESULT\tCHANGE")
+	failed := 0
+	for _, group := range groups {
+		var modules []string
+		for _, u := range group {
+			modules = append(modules, u.module)
+		}
+		result := "ok"
+		change, err := cfg.depsBumpChange(ctx, base, branch, group)
+		switch {
+		case err != nil:
+			failed++
+			result, change = "failed", firstLine(err.Error())
+			debugf("%s: %v\n", strings.Join(modules, ", "), err)
+		case change == "":
+			change = "already up to date"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.Join(modules, ", "), result, change)
+	}
+	tw.Flush()
+	switch {
+	case failed == 0:
+		return nil
+	case failed < len(groups):
+		return &partialFailureError{failed: failed, total: len(groups)}
+	default:
+		return fmt.Errorf("failed to create any of the %d CLs", failed)
+	}
+}
+
+// depsBumpChange applies updates on top of base and sends the result for
+// review against branch, returning the URL of the change. It returns an empty
+// URL if there was nothing to change.
+func (c *config) depsBumpChange(ctx context.Context, base, branch string, updates []depUpdate) (string, error) {
+	dir, err := os.MkdirTemp("", "cueckoo-deps-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	if _, err := run(ctx, "git", "worktree", "add", "--detach", dir, base); err != nil {
+		return "", err
+	}
+	defer run(context.Background(), "git", "worktree", "remove", "--force", dir)
+
+	getArgs := []string{"mod", "get"}
+	for _, u := range updates {
+		getArgs = append(getArgs, u.path()+"@"+u.latest)
+	}
+	cue := exec.CommandContext(ctx, "cue", getArgs...)
+	cue.Dir = dir
+	if out, err := cue.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to run %q: %v:\n%s", cue.Args, err, out)
+	}
+	status, err := gitIn(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(status) == "" {
+		return "", nil
+	}
+
+	changeID, err := newChangeID()
+	if err != nil {
+		return "", err
+	}
+	msg := depsBumpMessage(updates) + "\nChange-Id: " + changeID + "\n"
+	if _, err := gitIn(ctx, dir, "commit", "--quiet", "-a", "-m", msg); err != nil {
+		return "", err
+	}
+	if _, err := gitIn(ctx, dir, "push", "--quiet", "origin", "HEAD:refs/for/"+branch); err != nil {
+		return "", err
+	}
+	ch, _, err := c.gerritClient.Changes.GetChange(c.gerritProject()+"~"+branch+"~"+changeID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get created change: %w", err)
+	}
+	return c.changeURL(ch.Number), nil
+}
+
+// depsBumpMessage returns the commit message, without Change-Id, for a change
+// applying updates.
+func depsBumpMessage(updates []depUpdate) string {
+	var b strings.Builder
+	if len(updates) == 1 {
+		fmt.Fprintf(&b, "cue.mod: update %s to %s\n\n", updates[0].path(), updates[0].latest)
+	} else {
+		fmt.Fprintf(&b, "cue.mod: update %d dependencies\n\n", len(updates))
+	}
+	for _, u := range updates {
+		fmt.Fprintf(&b, "* %s %s => %s\n", u.path(), u.current, u.latest)
+		if link := u.changesURL(); link != "" {
+			fmt.Fprintf(&b, "  %s\n", link)
+		}
+	}
+	b.WriteString("\nThis change was generated by cueckoo deps bump.\n")
+	return b.String()
+}
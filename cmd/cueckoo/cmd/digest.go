@@ -0,0 +1,284 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagDigestSummarizer flagName = "summarizer"
+
+	// digestSummarizerKey is the user config key giving the default
+	// summarizer command of digest.
+	digestSummarizerKey = "digest-summarizer"
+)
+
+// newDigestCmd creates a new digest command
+func newDigestCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "summarize the review comments on a CL",
+		Long: `
+Usage of digest:
+
+	digest [--summarizer COMMAND] CL
+
+digest prints a condensed digest of the comments made by people on all the
+patchsets of a CL, to help new reviewers catch up on a long-running review.
+Comments posted by bots, which are tagged as autogenerated, are left out.
+
+The comment threads are grouped by file, with the unresolved threads of each
+file first. Each thread is shown with its location, the patchset it was
+started on, and the first lines of its first and latest comments.
+
+With --summarizer, or the "` + digestSummarizerKey + `" entry of the user
+config, the full text of every thread is instead written to the standard
+input of COMMAND, run by the shell, and its output is printed. This allows a
+local tool to summarize the discussion.
+`,
+		RunE:              mkRunE(c, digestDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	c.registerFlags(cmd).String(flagDigestSummarizer, "", "command to summarize the full text of the comments")
+	return cmd
+}
+
+func digestDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single CL")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	summarizer := flagDigestSummarizer.String(cmd)
+	if summarizer == "" {
+		ucfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		summarizer = ucfg[digestSummarizerKey]
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	ch, _, err := cfg.gerritClient.Changes.GetChange(id, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %v", args[0], err)
+	}
+	comments, err := cfg.listComments(id)
+	if err != nil {
+		return err
+	}
+	threads := commentThreads(humanComments(comments))
+
+	if summarizer == "" {
+		return writeDigest(cmd.OutOrStdout(), ch, threads, false)
+	}
+	var in bytes.Buffer
+	if err := writeDigest(&in, ch, threads, true); err != nil {
+		return err
+	}
+	sh := exec.CommandContext(ctx, "sh", "-c", summarizer)
+	sh.Stdin = &in
+	sh.Stdout = cmd.OutOrStdout()
+	sh.Stderr = os.Stderr
+	if err := sh.Run(); err != nil {
+		return fmt.Errorf("summarizer %q failed: %v", summarizer, err)
+	}
+	return nil
+}
+
+// humanComments returns comments without those tagged as autogenerated,
+// which are posted by bots.
+func humanComments(comments map[string][]gerritComment) map[string][]gerritComment {
+	res := make(map[string][]gerritComment)
+	for path, cs := range comments {
+		for _, c := range cs {
+			if !strings.HasPrefix(c.Tag, "autogenerated:") {
+				res[path] = append(res[path], c)
+			}
+		}
+	}
+	return res
+}
+
+// commentThread is a thread of comments, started by the first comment.
+type commentThread struct {
+	// comments are in the order they were written.
+	comments []gerritComment
+}
+
+func (t commentThread) first() gerritComment  { return t.comments[0] }
+func (t commentThread) latest() gerritComment { return t.comments[len(t.comments)-1] }
+
+// resolved reports whether t is resolved, as given by its latest comment.
+func (t commentThread) resolved() bool { return !t.latest().Unresolved }
+
+// commentThreads groups comments into threads, following the comments each
+// replies to. The threads are sorted by path, with the threads on the
+// patchset and the commit message first, then with unresolved threads
+// first, and then by line.
+func commentThreads(comments map[string][]gerritComment) []commentThread {
+	byID := make(map[string]gerritComment)
+	for _, cs := range comments {
+		for _, c := range cs {
+			byID[c.ID] = c
+		}
+	}
+	root := func(c gerritComment) gerritComment {
+		for c.InReplyTo != "" {
+			parent, ok := byID[c.InReplyTo]
+			if !ok {
+				break
+			}
+			c = parent
+		}
+		return c
+	}
+	threads := make(map[string]*commentThread)
+	for _, c := range byID {
+		r := root(c)
+		t := threads[r.ID]
+		if t == nil {
+			t = new(commentThread)
+			threads[r.ID] = t
+		}
+		t.comments = append(t.comments, c)
+	}
+	res := make([]commentThread, 0, len(threads))
+	for id, t := range threads {
+		sort.Slice(t.comments, func(i, j int) bool {
+			// The root comes first, even if another comment has the same
+			// timestamp.
+			ci, cj := t.comments[i], t.comments[j]
+			if (ci.ID == id) != (cj.ID == id) {
+				return ci.ID == id
+			}
+			return ci.Updated.Time.Before(cj.Updated.Time)
+		})
+		res = append(res, *t)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		ti, tj := res[i], res[j]
+		if pi, pj := pathOrder(ti.first().Path), pathOrder(tj.first().Path); pi != pj {
+			return pi < pj
+		}
+		if ti.first().Path != tj.first().Path {
+			return ti.first().Path < tj.first().Path
+		}
+		if ti.resolved() != tj.resolved() {
+			return !ti.resolved()
+		}
+		if ti.first().Line != tj.first().Line {
+			return ti.first().Line < tj.first().Line
+		}
+		return ti.first().Updated.Time.Before(tj.first().Updated.Time)
+	})
+	return res
+}
+
+// pathOrder orders the special paths of Gerrit comments before files.
+func pathOrder(path string) int {
+	switch path {
+	case "/PATCHSET_LEVEL":
+		return 0
+	case "/COMMIT_MSG":
+		return 1
+	case "/MERGE_LIST":
+		return 2
+	}
+	return 3
+}
+
+// pathLabel describes the path of a Gerrit comment.
+func pathLabel(path string) string {
+	switch path {
+	case "/PATCHSET_LEVEL":
+		return "patchset comments"
+	case "/COMMIT_MSG":
+		return "commit message"
+	case "/MERGE_LIST":
+		return "merge list"
+	}
+	return path
+}
+
+// writeDigest writes the digest of the comment threads of ch to w. If full,
+// the full text of every comment is written, rather than the first lines of
+// the first and latest comment of each thread.
+func writeDigest(w io.Writer, ch *gerrit.ChangeInfo, threads []commentThread, full bool) error {
+	var b strings.Builder
+	unresolved, comments := 0, 0
+	authors := make(map[string]bool)
+	for _, t := range threads {
+		if !t.resolved() {
+			unresolved++
+		}
+		comments += len(t.comments)
+		for _, c := range t.comments {
+			authors[c.Author.Name] = true
+		}
+	}
+	fmt.Fprintf(&b, "CL %d: %s\n", ch.Number, ch.Subject)
+	fmt.Fprintf(&b, "%d threads, %d unresolved, with %d comments by %d people\n", len(threads), unresolved, comments, len(authors))
+
+	path := ""
+	for i, t := range threads {
+		if i == 0 || t.first().Path != path {
+			path = t.first().Path
+			fmt.Fprintf(&b, "\n%s\n", pathLabel(path))
+		}
+		state := "resolved"
+		if !t.resolved() {
+			state = "unresolved"
+		}
+		where := fmt.Sprintf("PS%d", t.first().PatchSet)
+		if line := t.first().Line; line > 0 {
+			where = fmt.Sprintf("line %d, %s", line, where)
+		}
+		fmt.Fprintf(&b, "  %s: %s, %d comments\n", state, where, len(t.comments))
+		if full {
+			for _, c := range t.comments {
+				fmt.Fprintf(&b, "    %s:\n", c.Author.Name)
+				for _, line := range strings.Split(strings.TrimSpace(c.Message), "\n") {
+					fmt.Fprintf(&b, "      %s\n", line)
+				}
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "    %s: %s\n", t.first().Author.Name, firstLine(strings.TrimSpace(t.first().Message)))
+		if n := len(t.comments); n > 1 {
+			if n > 2 {
+				fmt.Fprintf(&b, "    ... %d more\n", n-2)
+			}
+			fmt.Fprintf(&b, "    %s: %s\n", t.latest().Author.Name, firstLine(strings.TrimSpace(t.latest().Message)))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDigest(t *testing.T) {
+	at := func(min int) gerrit.Timestamp {
+		return gerrit.Timestamp{Time: time.Date(2026, 10, 1, 12, min, 0, 0, time.UTC)}
+	}
+	alice := gerrit.AccountInfo{Name: "Alice"}
+	bob := gerrit.AccountInfo{Name: "Bob"}
+	comments := map[string][]gerritComment{
+		"/PATCHSET_LEVEL": {
+			{ID: "p1", Path: "/PATCHSET_LEVEL", PatchSet: 2, Message: "Looks close, a few nits.", Author: alice, Updated: at(1)},
+		},
+		"a.go": {
+			{ID: "a1", Path: "a.go", PatchSet: 1, Line: 10, Message: "Why is this needed?\n\nMore detail.", Unresolved: true, Author: alice, Updated: at(2)},
+			{ID: "a2", Path: "a.go", PatchSet: 1, Line: 10, InReplyTo: "a1", Message: "It handles the empty case.", Unresolved: true, Author: bob, Updated: at(3)},
+			{ID: "a3", Path: "a.go", PatchSet: 1, Line: 10, InReplyTo: "a2", Message: "Please add a comment.", Unresolved: true, Author: alice, Updated: at(4)},
+			{ID: "a4", Path: "a.go", PatchSet: 1, Line: 10, InReplyTo: "a3", Message: "Done", Author: bob, Updated: at(5)},
+			{ID: "a5", Path: "a.go", PatchSet: 2, Line: 20, Message: "Typo.", Unresolved: true, Author: alice, Updated: at(6)},
+			{ID: "a6", Path: "a.go", PatchSet: 2, Line: 20, InReplyTo: "a5", Message: "trybot results", Tag: "autogenerated:cueckoo", Unresolved: true, Updated: at(7)},
+		},
+	}
+	threads := commentThreads(humanComments(comments))
+	var b strings.Builder
+	if err := writeDigest(&b, &gerrit.ChangeInfo{Number: 1234, Subject: "cue: fix it"}, threads, false); err != nil {
+		t.Fatal(err)
+	}
+	want := `CL 1234: cue: fix it
+3 threads, 1 unresolved, with 6 comments by 2 people
+
+patchset comments
+  resolved: PS2, 1 comments
+    Alice: Looks close, a few nits.
+
+a.go
+  unresolved: line 20, PS2, 1 comments
+    Alice: Typo.
+  resolved: line 10, PS1, 4 comments
+    Alice: Why is this needed?
+    ... 2 more
+    Bob: Done
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("digest (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newDoctorCmd creates a new doctor command
+func newDoctorCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "check that cueckoo is set up correctly for the repository",
+		Long: `
+Usage of doctor:
+
+	doctor
+
+doctor checks the setup which cueckoo needs to work with the current
+repository, and reports the outcome of each check along with the reason for
+any failure:
+
+	gerrit        your Gerrit credentials are accepted
+	gerrit-version
+	              the Gerrit server supports the parts of its API which
+	              cueckoo uses, such as robot comments and change edits
+	github        your GitHub credentials are accepted, and your token has
+	              the scopes and single sign-on authorization needed to
+	              dispatch to the repository
+	unity         the unity repository is accessible, if one is configured
+	payload       the workflows of the repositories that cueckoo dispatches
+	              to understand the payloads that it sends
+
+Unlike ping, which diagnoses connectivity, doctor is about configuration and
+permissions. For example, when the unity repository is private and your
+token lacks access to it, runtrybot only triggers trybot runs.
+`,
+		RunE: mkRunE(c, doctorDef),
+	}
+	return cmd
+}
+
+// doctorCheck is a single check made by doctor. run returns a short detail
+// to report on success, if any.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+func doctorDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("doctor does not take any arguments")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	checks := doctorChecks(cfg)
+	failed := runChecks(ctx, cmd.OutOrStdout(), checks)
+	switch {
+	case failed == 0:
+		return nil
+	case failed < len(checks):
+		return &partialFailureError{failed: failed, total: len(checks)}
+	default:
+		return fmt.Errorf("all checks failed")
+	}
+}
+
+// doctorChecks returns the checks made by doctor of the setup in cfg.
+func doctorChecks(cfg *config) []doctorCheck {
+	return []doctorCheck{{
+		name: "gerrit",
+		run: func(context.Context) (string, error) {
+			var acct struct {
+				Username string `json:"username"`
+			}
+			if err := cfg.gerritDo(http.MethodGet, "accounts/self", nil, &acct); err != nil {
+				return "", err
+			}
+			return "authenticated as " + acct.Username, nil
+		},
+	}, {
+		name: "gerrit-version",
+		run: func(context.Context) (string, error) {
+			v, ok := cfg.gerritServerVersion()
+			if !ok {
+				return "unknown; assuming all features are supported", nil
+			}
+			var missing []string
+			for _, f := range gerritFeatures {
+				if !cfg.supports(f) {
+					missing = append(missing, f.name)
+				}
+			}
+			if len(missing) > 0 {
+				return "", fmt.Errorf("version %s does not support %s", v, strings.Join(missing, ", "))
+			}
+			return "version " + v.String() + " supports all features", nil
+		},
+	}, {
+		name: "github",
+		run: func(ctx context.Context) (string, error) {
+			login, err := cfg.githubLogin(ctx)
+			if err != nil {
+				return "", err
+			}
+			if err := cfg.checkGitHubToken(ctx, cfg.githubOwner, cfg.githubRepo, "repo"); err != nil {
+				return "", err
+			}
+			return "authenticated as " + login, nil
+		},
+	}, {
+		name: "unity",
+		run: func(ctx context.Context) (string, error) {
+			if cfg.unityRepo == "" {
+				return "not configured", nil
+			}
+			if err := cfg.checkUnityAccess(ctx); err != nil {
+				return "", err
+			}
+			return cfg.unityOwner + "/" + cfg.unityRepo + " is accessible", nil
+		},
+	}, {
+		name: "payload",
+		run: func(ctx context.Context) (string, error) {
+			if err := cfg.checkPayloadVersion(ctx, cfg.githubOwner, cfg.githubRepo); err != nil {
+				return "", err
+			}
+			if cfg.unityRepo != "" {
+				if err := cfg.checkPayloadVersion(ctx, cfg.unityOwner, cfg.unityRepo); err != nil {
+					return "", err
+				}
+			}
+			return fmt.Sprintf("version %d is supported", payloadVersion), nil
+		},
+	}}
+}
+
+// skipError is returned by a check which did not apply, or which the user
+// chose to skip, with the reason why.
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string { return e.reason }
+
+// runChecks runs checks in order, writing a table of their outcomes to w, and
+// returns the number which failed. Skipped checks do not count as failures.
+func runChecks(ctx context.Context, w io.Writer, checks []doctorCheck) int {
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "CHECK\tRESULT\tDETAIL")
+	failed := 0
+	for _, c := range checks {
+		result, detail := "ok", ""
+		d, err := c.run(ctx)
+		var skip *skipError
+		switch {
+		case errors.As(err, &skip):
+			result, detail = "skipped", skip.reason
+		case err != nil:
+			failed++
+			result, detail = "failed", firstLine(err.Error())
+			debugf("%s: %v\n", c.name, err)
+		default:
+			detail = d
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.name, result, detail)
+	}
+	tw.Flush()
+	return failed
+}
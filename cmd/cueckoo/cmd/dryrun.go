@@ -0,0 +1,50 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const flagDryRun flagName = "dry-run"
+
+// dryRun is whether repository dispatches are printed rather than sent, as
+// set by the global --dry-run flag. Commands with a --dry-run flag of their
+// own, such as apply-suggestions, use it to skip their other changes too.
+var dryRun bool
+
+// readDryRun sets dryRun from the --dry-run flag.
+func readDryRun(cmd *cobra.Command) {
+	dryRun, _ = cmd.Flags().GetBool(string(flagDryRun))
+}
+
+// printDispatch writes the repository dispatch of payload to owner/repo to
+// w, as it would be sent, with the client payload indented.
+func printDispatch(w io.Writer, owner, repo string, payload github.DispatchRequestOptions) error {
+	var client bytes.Buffer
+	if payload.ClientPayload != nil {
+		if err := json.Indent(&client, *payload.ClientPayload, "", "\t"); err != nil {
+			return fmt.Errorf("invalid client payload: %v", err)
+		}
+	}
+	_, err := fmt.Fprintf(w, "repository_dispatch to %s/%s\nevent type: %s\nclient payload:\n%s\n", owner, repo, payload.EventType, client.Bytes())
+	return err
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrintDispatch(t *testing.T) {
+	p, err := buildDispatchPayload("trybot run for refs/changes/34/1234/5", repositoryDispatchPayload{
+		Type:     "trybot",
+		CL:       1234,
+		Patchset: 5,
+		Ref:      "refs/changes/34/1234/5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := printDispatch(&b, "cue-lang", "cue", p); err != nil {
+		t.Fatal(err)
+	}
+	want := `repository_dispatch to cue-lang/cue
+event type: trybot run for refs/changes/34/1234/5
+client payload:
+{
+	"payloadVersion": 0,
+	"type": "trybot",
+	"CL": 1234,
+	"patchset": 5,
+	"ref": "refs/changes/34/1234/5"
+}
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("output (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+// newEditCmd creates a new edit command
+func newEditCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "edit a file in a CL and upload the result as a new patchset",
+		Long: `
+Usage of edit:
+
+	edit CL FILE
+
+edit fetches FILE from the latest patchset of the given CL and opens it in
+your editor, as determined by git. When the editor exits, the modified file is
+uploaded as a new patchset using a Gerrit change edit, and the owner of the CL
+is notified. CL is either a CL number or a Change-Id.
+
+This is intended for trivial fixes, such as typos, which would otherwise have
+to be made via the Gerrit web UI. If FILE does not exist in the CL, it is
+created.
+`,
+		RunE:              mkRunE(c, editDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	return cmd
+}
+
+func editDef(cmd *Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected a CL and a file path")
+	}
+	ctx := cmd.Context()
+	path := strings.TrimPrefix(args[1], "/")
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	ch, _, err := cfg.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	if ch.Status != "NEW" {
+		return fmt.Errorf("CL %d has status %s; only open CLs can be edited", ch.Number, ch.Status)
+	}
+	id = fmt.Sprint(ch.Number)
+
+	// Publishing a change edit publishes all of its modifications, so don't
+	// risk publishing someone else's work in progress.
+	if ok, err := cfg.hasChangeEdit(id); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("CL %d already has an unpublished change edit; publish or delete it first", ch.Number)
+	}
+
+	content, err := cfg.fileContent(id, ch.CurrentRevision, path)
+	if err != nil && gerritStatus(err) != http.StatusNotFound {
+		return err
+	}
+
+	edited, err := editContent(ctx, path, content)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, content) {
+		fmt.Fprintln(cmd.OutOrStdout(), "no changes made")
+		return nil
+	}
+	if err := cfg.putEditFile(id, path, edited); err != nil {
+		return err
+	}
+	if err := cfg.publishEdit(id, "OWNER"); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "uploaded new patchset of %s\n", cfg.changeURL(ch.Number))
+	return nil
+}
+
+// editContent opens content in the user's editor, in a temporary file named
+// after path so that editors can detect the file type, and returns the result.
+func editContent(ctx context.Context, path string, content []byte) ([]byte, error) {
+	editor, err := run(ctx, "git", "var", "GIT_EDITOR")
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "cueckoo-edit-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, filepath.Base(path))
+	if err := os.WriteFile(fn, content, 0o666); err != nil {
+		return nil, err
+	}
+
+	// Like git, run the editor via the shell so that it may include
+	// arguments.
+	cmd := exec.CommandContext(ctx, "sh", "-c", strings.TrimSpace(editor)+` "$@"`, "editor", fn)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor failed: %v", err)
+	}
+	return os.ReadFile(fn)
+}
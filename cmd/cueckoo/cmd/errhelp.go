@@ -0,0 +1,120 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// errorHint explains a common failure and how to remedy it.
+type errorHint struct {
+	text string
+	link string // documentation, if any
+}
+
+const (
+	docGitHubTokens   = "https://docs.github.com/en/authentication/keeping-your-account-and-data-secure/managing-your-personal-access-tokens"
+	docGitHubSAML     = "https://docs.github.com/en/authentication/authenticating-with-saml-single-sign-on/authorizing-a-personal-access-token-for-use-with-saml-single-sign-on"
+	docGitHubDispatch = "https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#repository_dispatch"
+	docGitHubLimits   = "https://docs.github.com/en/rest/overview/resources-in-the-rest-api#rate-limiting"
+)
+
+// explainError returns a hint for err if it matches the signature of a
+// frequent failure of the Gerrit or GitHub APIs, or nil otherwise.
+func explainError(err error) *errorHint {
+	var (
+		ge    *gerritError
+		rate  *github.RateLimitError
+		abuse *github.AbuseRateLimitError
+		gh    *github.ErrorResponse
+	)
+	switch {
+	case errors.As(err, &ge):
+		if ge.statusCode != http.StatusUnauthorized {
+			return nil
+		}
+		h := &errorHint{text: "Gerrit rejected your credentials; the HTTP password or cookie used by git has likely expired or been regenerated. Generate a new one and update your git credential helper or .gitcookies."}
+		if ge.url != nil {
+			h.link = fmt.Sprintf("%s://%s/settings/#HTTPCredentials", ge.url.Scheme, ge.url.Host)
+		}
+		return h
+	case errors.As(err, &rate):
+		return &errorHint{
+			text: fmt.Sprintf("GitHub API rate limit exceeded; it resets at %s. Authenticated requests have a much higher limit, so check that a token is configured.", rate.Rate.Reset.Local().Format(time.TimeOnly)),
+			link: docGitHubLimits,
+		}
+	case errors.As(err, &abuse):
+		text := "GitHub secondary rate limit triggered by too many requests in a short time; wait before retrying"
+		if abuse.RetryAfter != nil {
+			text += fmt.Sprintf(" (at least %v)", abuse.RetryAfter.Round(time.Second))
+		}
+		return &errorHint{text: text + ".", link: docGitHubLimits}
+	case errors.As(err, &gh) && gh.Response != nil:
+		return explainGitHubError(gh)
+	}
+	return nil
+}
+
+func explainGitHubError(gh *github.ErrorResponse) *errorHint {
+	path := ""
+	if gh.Response.Request != nil && gh.Response.Request.URL != nil {
+		path = gh.Response.Request.URL.Path
+	}
+	switch code := gh.Response.StatusCode; {
+	case code == http.StatusForbidden && (strings.Contains(gh.Message, "SAML") || ssoAuthorizeURL(gh.Response.Header) != ""):
+		// GitHub links directly to the page authorizing the token where
+		// it can.
+		link := ssoAuthorizeURL(gh.Response.Header)
+		if link == "" {
+			link = docGitHubSAML
+		}
+		return &errorHint{
+			text: "the organization enforces SAML single sign-on; authorize your GitHub token for the organization.",
+			link: link,
+		}
+	case code == http.StatusNotFound && strings.HasSuffix(path, "/dispatches"):
+		// GitHub reports a repository the token cannot write to as not
+		// found, rather than forbidden.
+		return &errorHint{
+			text: "GitHub could not find the repository to dispatch to; check that it exists, that your token has write access to it, and that it has a workflow triggered by repository_dispatch.",
+			link: docGitHubDispatch,
+		}
+	case code == http.StatusUnauthorized:
+		return &errorHint{
+			text: "GitHub rejected your credentials; the token may have expired or been revoked.",
+			link: docGitHubTokens,
+		}
+	}
+	return nil
+}
+
+// printErrorHint writes the hint for err to w, if there is one.
+func printErrorHint(w io.Writer, err error) {
+	h := explainError(err)
+	if h == nil {
+		return
+	}
+	fmt.Fprintf(w, "hint: %s\n", h.text)
+	if h.link != "" {
+		fmt.Fprintf(w, "      see %s\n", h.link)
+	}
+}
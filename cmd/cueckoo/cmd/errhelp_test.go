@@ -0,0 +1,96 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+)
+
+func TestExplainError(t *testing.T) {
+	ghErr := func(code int, path, msg string) error {
+		return &github.ErrorResponse{
+			Response: &http.Response{
+				StatusCode: code,
+				Request:    &http.Request{URL: &url.URL{Scheme: "https", Host: "api.github.com", Path: path}},
+			},
+			Message: msg,
+		}
+	}
+	testCases := []struct {
+		name string
+		err  error
+		want string // substring of the hint's link, or empty for no hint
+	}{{
+		name: "GerritUnauthorized",
+		err: fmt.Errorf("failed to get change: %w", &gerritError{
+			statusCode: http.StatusUnauthorized,
+			url:        &url.URL{Scheme: "https", Host: "review.gerrithub.io", Path: "/a/changes/123"},
+			err:        fmt.Errorf("401 Unauthorized"),
+		}),
+		want: "https://review.gerrithub.io/settings/#HTTPCredentials",
+	}, {
+		name: "GerritNotFound",
+		err:  &gerritError{statusCode: http.StatusNotFound, err: fmt.Errorf("404 Not Found")},
+	}, {
+		name: "DispatchNotFound",
+		err:  fmt.Errorf("failed to send dispatch event: %w", ghErr(http.StatusNotFound, "/repos/cue-lang/cue/dispatches", "Not Found")),
+		want: "repository_dispatch",
+	}, {
+		name: "OtherNotFound",
+		err:  ghErr(http.StatusNotFound, "/repos/cue-lang/cue/pulls/1", "Not Found"),
+	}, {
+		name: "SAML",
+		err:  ghErr(http.StatusForbidden, "/repos/cue-lang/cue", "Resource protected by organization SAML enforcement."),
+		want: "saml-single-sign-on",
+	}, {
+		name: "SAMLAuthorizeURL",
+		err: func() error {
+			err := ghErr(http.StatusForbidden, "/repos/cue-lang/cue/dispatches", "Resource protected by organization SAML enforcement.")
+			err.(*github.ErrorResponse).Response.Header = http.Header{
+				"X-Github-Sso": {"required; url=https://github.com/orgs/cue-lang/sso?authorization_request=AbC"},
+			}
+			return err
+		}(),
+		want: "https://github.com/orgs/cue-lang/sso?authorization_request=AbC",
+	}, {
+		name: "RateLimit",
+		err: &github.RateLimitError{
+			Response: &http.Response{StatusCode: http.StatusForbidden},
+		},
+		want: "rate-limiting",
+	}, {
+		name: "Plain",
+		err:  fmt.Errorf("something else"),
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := explainError(tc.err)
+			switch {
+			case tc.want == "" && h != nil:
+				t.Errorf("unexpected hint: %q", h.text)
+			case tc.want != "" && h == nil:
+				t.Errorf("no hint, want link containing %q", tc.want)
+			case tc.want != "" && !strings.Contains(h.link, tc.want):
+				t.Errorf("got link %q, want it to contain %q", h.link, tc.want)
+			}
+		})
+	}
+}
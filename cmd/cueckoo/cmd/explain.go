@@ -0,0 +1,195 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const flagExplainFormat flagName = "format"
+
+// payloadSchemas documents the client payloads of the events sent by
+// cueckoo, as CUE definitions.
+//
+//go:embed payloads.cue
+var payloadSchemas string
+
+// explainEvents maps the event types which cueckoo dispatches to an example
+// of each, built the same way as the real thing.
+var explainEvents = map[string]func() (github.DispatchRequestOptions, error){
+	string(eventTypeTrybot): func() (github.DispatchRequestOptions, error) {
+		return buildTryBotPayload(repositoryDispatchPayload{
+			Type:         string(eventTypeTrybot),
+			CL:           551352,
+			Patchset:     140,
+			TargetBranch: "master",
+			Ref:          "refs/changes/52/551352/140",
+		})
+	},
+	string(eventTypePRTrybot): func() (github.DispatchRequestOptions, error) {
+		return buildPRTrybotPayload(prTrybotPayload{
+			repositoryDispatchPayload: repositoryDispatchPayload{
+				Type:         string(eventTypePRTrybot),
+				TargetBranch: "master",
+				Ref:          prHeadRef(2891),
+			},
+			PR:  2891,
+			SHA: "4c5f2a1b0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b",
+		})
+	},
+	string(eventTypeUnity): func() (github.DispatchRequestOptions, error) {
+		return buildUnityPayloadFromCLTrigger(repositoryDispatchPayload{
+			Type:         string(eventTypeUnity),
+			CL:           551352,
+			Patchset:     140,
+			TargetBranch: "master",
+			Ref:          "refs/changes/52/551352/140",
+		})
+	},
+	string(eventTypeCompat): func() (github.DispatchRequestOptions, error) {
+		return buildCompatPayload(compatPayload{
+			repositoryDispatchPayload: repositoryDispatchPayload{
+				Type:         string(eventTypeCompat),
+				CL:           551352,
+				Patchset:     140,
+				TargetBranch: "master",
+				Ref:          "refs/changes/52/551352/140",
+			},
+			Base: "v0.8.2",
+		})
+	},
+	string(eventTypePreview): func() (github.DispatchRequestOptions, error) {
+		return buildPreviewPayload(repositoryDispatchPayload{
+			Type:         string(eventTypePreview),
+			CL:           551352,
+			Patchset:     140,
+			TargetBranch: "master",
+			Ref:          "refs/changes/52/551352/140",
+		})
+	},
+}
+
+// newExplainCmd creates a new explain command
+func newExplainCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain",
+		Short: "describe the payloads of the events dispatched by cueckoo",
+		Long: `
+Usage of explain:
+
+	explain [--format cue|jsonschema|openapi] [EVENT]
+
+explain prints the schema of the client payload of the given repository
+dispatch event type, as a CUE definition, followed by an example of the
+dispatch as sent to the GitHub API. This is meant for the authors of GitHub
+workflows which consume the dispatches.
+
+With no arguments, explain lists the event types.
+
+With --format jsonschema or openapi, the schema is instead exported as JSON
+Schema, or as an OpenAPI 3.1 document with the schema under
+components.schemas, such that consumers not written in Go or CUE can generate
+types for the payloads. Without an event type, the schemas of all the event
+types are exported. Note that importpr does not
+dispatch any events, and mirroring to GitHub is done by Gerrit rather than
+cueckoo.
+`,
+		RunE: mkRunE(c, explainDef),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return sortedKeys(explainEvents), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	c.registerFlags(cmd).String(flagExplainFormat, "cue", "output format: cue, jsonschema or openapi").OneOf("cue", "jsonschema", "openapi")
+	return cmd
+}
+
+func explainDef(cmd *Command, args []string) error {
+	w := cmd.OutOrStdout()
+	if format := flagExplainFormat.String(cmd); format != "cue" {
+		events := args
+		if len(events) == 0 {
+			events = sortedKeys(explainEvents)
+		}
+		for _, ev := range events {
+			if _, ok := explainEvents[ev]; !ok {
+				return fmt.Errorf("unknown event type %q; known types are: %s", ev, strings.Join(sortedKeys(explainEvents), ", "))
+			}
+		}
+		doc, err := payloadSchemaDocument(format, events)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(doc)
+	}
+	switch len(args) {
+	case 0:
+		for _, ev := range sortedKeys(explainEvents) {
+			fmt.Fprintln(w, ev)
+		}
+		return nil
+	case 1:
+	default:
+		return fmt.Errorf("expected at most one event type")
+	}
+	ev := args[0]
+	example, ok := explainEvents[ev]
+	if !ok {
+		return fmt.Errorf("unknown event type %q; known types are: %s", ev, strings.Join(sortedKeys(explainEvents), ", "))
+	}
+	dro, err := example()
+	if err != nil {
+		return err
+	}
+	byts, err := json.MarshalIndent(dro, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s\n%s\n", cueDefinition(payloadSchemas, "change"), cueDefinition(payloadSchemas, ev))
+	fmt.Fprintf(w, "// Example:\n%s\n", byts)
+	return nil
+}
+
+// cueDefinition returns the top-level definition #name in the CUE source src,
+// including its doc comment, or the empty string if there is no such
+// definition. It relies on src being formatted with cue fmt.
+func cueDefinition(src, name string) string {
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "#"+name+":") {
+			continue
+		}
+		start := i
+		for start > 0 && strings.HasPrefix(lines[start-1], "//") {
+			start--
+		}
+		end := i
+		for end < len(lines)-1 && lines[end] != "}" {
+			end++
+		}
+		return strings.Join(lines[start:end+1], "\n") + "\n"
+	}
+	return ""
+}
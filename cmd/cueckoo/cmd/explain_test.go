@@ -0,0 +1,126 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestExplainSchemas checks that every field of the example payloads is
+// documented in payloads.cue.
+func TestExplainSchemas(t *testing.T) {
+	for ev, example := range explainEvents {
+		t.Run(ev, func(t *testing.T) {
+			schema := cueDefinition(payloadSchemas, ev)
+			if schema == "" {
+				t.Fatalf("no definition #%s in payloads.cue", ev)
+			}
+			schema += cueDefinition(payloadSchemas, "change")
+			dro, err := example()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(*dro.ClientPayload, &fields); err != nil {
+				t.Fatal(err)
+			}
+			for f := range fields {
+				if !strings.Contains(schema, "\t"+f+":") && !strings.Contains(schema, "\t"+f+"?:") {
+					t.Errorf("field %q of the example payload is not documented", f)
+				}
+			}
+		})
+	}
+}
+
+// TestPayloadJSONSchema checks that the example payloads are valid against
+// the JSON Schemas derived from payloads.cue, for the subset of JSON Schema
+// which they use.
+func TestPayloadJSONSchema(t *testing.T) {
+	for ev, example := range explainEvents {
+		t.Run(ev, func(t *testing.T) {
+			schema, err := payloadJSONSchema(ev)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dro, err := example()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(*dro.ClientPayload, &fields); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range schema["required"].([]string) {
+				if _, ok := fields[name]; !ok {
+					t.Errorf("required field %q is missing", name)
+				}
+			}
+			props := schema["properties"].(map[string]any)
+			for name, v := range fields {
+				p, ok := props[name].(map[string]any)
+				if !ok {
+					t.Errorf("field %q is not in the schema", name)
+					continue
+				}
+				switch p["type"] {
+				case "integer":
+					n, ok := v.(float64)
+					if !ok {
+						t.Errorf("field %q: got %v; want an integer", name, v)
+					} else if min, ok := p["minimum"].(int); ok && n < float64(min) {
+						t.Errorf("field %q: got %v; want at least %d", name, v, min)
+					}
+				case "string":
+					if _, ok := v.(string); !ok {
+						t.Errorf("field %q: got %v; want a string", name, v)
+					}
+				}
+				if c, ok := p["const"]; ok && c != v {
+					t.Errorf("field %q: got %v; want %v", name, v, c)
+				}
+			}
+		})
+	}
+}
+
+func TestPayloadSchemaDocument(t *testing.T) {
+	doc, err := payloadSchemaDocument("jsonschema", []string{"trybot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"CL", "patchset", "payloadVersion", "ref", "type"}
+	if got := doc["required"].([]string); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got required fields %v; want %v", got, want)
+	}
+	props := doc["properties"].(map[string]any)
+	if got := props["type"].(map[string]any)["const"]; got != "trybot" {
+		t.Errorf("got type const %v; want trybot", got)
+	}
+	if got := props["ref"].(map[string]any)["description"]; got == nil {
+		t.Errorf("ref lacks the description inherited from #change")
+	}
+
+	doc, err = payloadSchemaDocument("openapi", sortedKeys(explainEvents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	if len(schemas) != len(explainEvents) {
+		t.Errorf("got %d schemas; want %d", len(schemas), len(explainEvents))
+	}
+}
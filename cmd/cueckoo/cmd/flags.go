@@ -0,0 +1,234 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// flagRegistry declares the flags of a command along with the constraints on
+// them: required flags, flags which cannot be used together or with
+// positional arguments, and the valid values of flags. The constraints are
+// checked by mkRunE before the command runs, such that nonsensical
+// combinations of flags are rejected with consistent usage errors, rather
+// than some flags being silently ignored.
+//
+// Every flag is declared via a registry, obtained with
+// Command.registerFlags or Command.registerPersistentFlags.
+type flagRegistry struct {
+	cmd *cobra.Command
+
+	// persistent is whether the flags are declared as persistent flags,
+	// which the subcommands of cmd inherit along with the constraints.
+	persistent bool
+
+	rules []func(cmd *cobra.Command, args []string) error
+}
+
+// flagRegistryKey identifies a flagRegistry in Command.flagRegistries.
+type flagRegistryKey struct {
+	cmd        *cobra.Command
+	persistent bool
+}
+
+// registerFlags returns the registry of the local flags of cmd, creating it
+// if needed.
+func (c *Command) registerFlags(cmd *cobra.Command) *flagRegistry {
+	return c.flagRegistry(cmd, false)
+}
+
+// registerPersistentFlags returns the registry of the persistent flags of
+// cmd, creating it if needed. Their constraints are also checked when
+// running the subcommands of cmd.
+func (c *Command) registerPersistentFlags(cmd *cobra.Command) *flagRegistry {
+	return c.flagRegistry(cmd, true)
+}
+
+func (c *Command) flagRegistry(cmd *cobra.Command, persistent bool) *flagRegistry {
+	key := flagRegistryKey{cmd: cmd, persistent: persistent}
+	r := c.flagRegistries[key]
+	if r == nil {
+		if c.flagRegistries == nil {
+			c.flagRegistries = make(map[flagRegistryKey]*flagRegistry)
+		}
+		r = &flagRegistry{cmd: cmd, persistent: persistent}
+		c.flagRegistries[key] = r
+	}
+	return r
+}
+
+// checkFlags checks the flags and positional arguments args of cmd against
+// the constraints registered for its local flags, and for the persistent
+// flags of it and its parents.
+func (c *Command) checkFlags(cmd *cobra.Command, args []string) error {
+	var rules []func(cmd *cobra.Command, args []string) error
+	if r := c.flagRegistries[flagRegistryKey{cmd: cmd}]; r != nil {
+		rules = append(rules, r.rules...)
+	}
+	for p := cmd; p != nil; p = p.Parent() {
+		if r := c.flagRegistries[flagRegistryKey{cmd: p, persistent: true}]; r != nil {
+			rules = append(rules, r.rules...)
+		}
+	}
+	for _, rule := range rules {
+		if err := rule(cmd, args); err != nil {
+			return &usageError{cmd: cmd.CommandPath(), msg: err.Error()}
+		}
+	}
+	return nil
+}
+
+// usageError reports flags or arguments which a command cannot run with.
+type usageError struct {
+	cmd string
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return fmt.Sprintf("%s\nsee \"%s --help\" for usage", e.msg, e.cmd)
+}
+
+// flagDecl is a flag declared with a flagRegistry, whose constraints can be
+// added with its methods.
+type flagDecl struct {
+	r    *flagRegistry
+	name flagName
+}
+
+// flags returns the flag set which r declares flags in.
+func (r *flagRegistry) flags() *pflag.FlagSet {
+	if r.persistent {
+		return r.cmd.PersistentFlags()
+	}
+	return r.cmd.Flags()
+}
+
+// String declares a string flag.
+func (r *flagRegistry) String(name flagName, value, usage string) *flagDecl {
+	return r.StringP(name, "", value, usage)
+}
+
+// StringP is like String, but also declares a one-letter shorthand.
+func (r *flagRegistry) StringP(name flagName, shorthand, value, usage string) *flagDecl {
+	r.flags().StringP(string(name), shorthand, value, usage)
+	return &flagDecl{r: r, name: name}
+}
+
+// StringArray declares a string flag which can be given more than once.
+func (r *flagRegistry) StringArray(name flagName, value []string, usage string) *flagDecl {
+	r.flags().StringArray(string(name), value, usage)
+	return &flagDecl{r: r, name: name}
+}
+
+// Bool declares a bool flag.
+func (r *flagRegistry) Bool(name flagName, value bool, usage string) *flagDecl {
+	return r.BoolP(name, "", value, usage)
+}
+
+// BoolP is like Bool, but also declares a one-letter shorthand.
+func (r *flagRegistry) BoolP(name flagName, shorthand string, value bool, usage string) *flagDecl {
+	r.flags().BoolP(string(name), shorthand, value, usage)
+	return &flagDecl{r: r, name: name}
+}
+
+// Int declares an int flag.
+func (r *flagRegistry) Int(name flagName, value int, usage string) *flagDecl {
+	r.flags().Int(string(name), value, usage)
+	return &flagDecl{r: r, name: name}
+}
+
+// Required requires the flag to be set.
+func (d *flagDecl) Required() *flagDecl {
+	d.r.rules = append(d.r.rules, func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed(string(d.name)) {
+			return fmt.Errorf("--%s is required", d.name)
+		}
+		return nil
+	})
+	return d
+}
+
+// OneOf requires the value of the flag to be one of values.
+func (d *flagDecl) OneOf(values ...string) *flagDecl {
+	return d.Validate(func(v string) error {
+		if !slicesContains(values, v) {
+			return fmt.Errorf("want one of %s", strings.Join(values, ", "))
+		}
+		return nil
+	})
+}
+
+// Duration requires the value of the flag to be a duration, as accepted by
+// parseDuration.
+func (d *flagDecl) Duration() *flagDecl {
+	return d.Validate(func(v string) error {
+		_, err := parseDuration(v)
+		return err
+	})
+}
+
+// Validate requires the value of the flag to be accepted by valid, which is
+// called with the value as a string, whether it was set or is the default.
+func (d *flagDecl) Validate(valid func(string) error) *flagDecl {
+	d.r.rules = append(d.r.rules, func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags().Lookup(string(d.name))
+		if err := valid(f.Value.String()); err != nil {
+			return fmt.Errorf("invalid --%s %q: %v", d.name, f.Value.String(), err)
+		}
+		return nil
+	})
+	return d
+}
+
+// Exclusive forbids more than one of the flags names from being set.
+func (r *flagRegistry) Exclusive(names ...flagName) {
+	r.rules = append(r.rules, func(cmd *cobra.Command, args []string) error {
+		var set []string
+		for _, name := range names {
+			if cmd.Flags().Changed(string(name)) {
+				set = append(set, "--"+string(name))
+			}
+		}
+		if len(set) > 1 {
+			return fmt.Errorf("%s cannot be used together", strings.Join(set, " and "))
+		}
+		return nil
+	})
+}
+
+// NoArgsWith forbids positional arguments when the flag name is set.
+func (r *flagRegistry) NoArgsWith(name flagName) {
+	r.rules = append(r.rules, func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed(string(name)) && len(args) > 0 {
+			return fmt.Errorf("--%s does not take any arguments", name)
+		}
+		return nil
+	})
+}
+
+// ArgsWith requires at least one positional argument, described by what,
+// when the flag name is set.
+func (r *flagRegistry) ArgsWith(name flagName, what string) {
+	r.rules = append(r.rules, func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed(string(name)) && len(args) == 0 {
+			return fmt.Errorf("--%s needs at least one %s", name, what)
+		}
+		return nil
+	})
+}
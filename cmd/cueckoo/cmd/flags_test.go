@@ -0,0 +1,99 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCheckFlags(t *testing.T) {
+	c := &Command{}
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		flags := c.registerFlags(cmd)
+		flags.String("format", "table", "").OneOf("table", "json")
+		flags.String("since", "24h", "").Duration()
+		flags.String("ref", "", "")
+		flags.Bool("list", false, "")
+		flags.Bool("update", false, "")
+		flags.Exclusive("list", "update")
+		flags.NoArgsWith("list")
+		flags.Bool("versions", false, "")
+		flags.ArgsWith("versions", "version")
+		return cmd
+	}
+	cases := []struct {
+		args []string
+		want string // a substring of the error, if any
+	}{
+		{args: []string{"1234"}},
+		{args: []string{"--format", "xml"}, want: `invalid --format "xml": want one of table, json`},
+		{args: []string{"--since", "soon"}, want: `invalid --since "soon"`},
+		{args: []string{"--list", "--update"}, want: "--list and --update cannot be used together"},
+		{args: []string{"--list", "1234"}, want: "--list does not take any arguments"},
+		{args: []string{"--versions"}, want: "--versions needs at least one version"},
+		{args: []string{"--versions", "v0.9.0"}},
+	}
+	for _, tc := range cases {
+		cmd := newCmd()
+		if err := cmd.ParseFlags(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		err := c.checkFlags(cmd, cmd.Flags().Args())
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%q: unexpected error: %v", tc.args, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("%q: got error %v; want %q", tc.args, err, tc.want)
+		}
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	c.registerFlags(cmd).String("ref", "", "").Required()
+	err := c.checkFlags(cmd, nil)
+	if want := "--ref is required\nsee \"test --help\" for usage"; err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+}
+
+func TestCheckPersistentFlags(t *testing.T) {
+	c := &Command{}
+	root := &cobra.Command{Use: "root"}
+	c.registerPersistentFlags(root).String("format", "table", "").OneOf("table", "json")
+	sub := &cobra.Command{Use: "sub", Run: func(*cobra.Command, []string) {}}
+	c.registerFlags(sub).Bool("list", false, "")
+	root.AddCommand(sub)
+
+	cmd, args, err := root.Find([]string{"sub", "--format", "xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	err = c.checkFlags(cmd, cmd.Flags().Args())
+	if want := `invalid --format "xml"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %v; want %q", err, want)
+	}
+
+	// The registries belong to c, so another command tree has its own.
+	other := &Command{}
+	if err := other.checkFlags(cmd, cmd.Flags().Args()); err != nil {
+		t.Errorf("got error %v from the registries of another command tree", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// defaultFlakesFile is the path, relative to the root of the repository, of
+// the known-flake database.
+const defaultFlakesFile = ".github/cueckoo-flakes.cue"
+
+// flakeSignature describes a known flaky failure. It is decoded from the
+// known-flake database, which is a CUE file of the form:
+//
+//	flakes: [{
+//		name:    "go test timeout on macOS"
+//		job:     "macos"
+//		pattern: "panic: test timed out after"
+//	}]
+type flakeSignature struct {
+	// Name describes the flake, and is used when annotating the CL.
+	Name string `json:"name"`
+
+	// Job is a regular expression which the name of the failed job must
+	// match. An empty Job matches any job.
+	Job string `json:"job"`
+
+	// Pattern is a regular expression which must match the log of the
+	// failed job.
+	Pattern string `json:"pattern"`
+
+	job, pattern *regexp.Regexp
+}
+
+type flakeDB struct {
+	Flakes []*flakeSignature `json:"flakes"`
+}
+
+// loadFlakeDB loads the known-flake database from path.
+func loadFlakeDB(ctx context.Context, path string) (*flakeDB, error) {
+	var db flakeDB
+	if err := loadCUEFile(ctx, path, &db); err != nil {
+		return nil, fmt.Errorf("failed to load flake database: %w", err)
+	}
+	for _, f := range db.Flakes {
+		var err error
+		if f.job, err = regexp.Compile(f.Job); err != nil {
+			return nil, fmt.Errorf("invalid job regexp for flake %q: %v", f.Name, err)
+		}
+		if f.Pattern == "" {
+			return nil, fmt.Errorf("flake %q has no pattern", f.Name)
+		}
+		if f.pattern, err = regexp.Compile(f.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern for flake %q: %v", f.Name, err)
+		}
+	}
+	return &db, nil
+}
+
+// match returns the first flake signature matching the failed job with the
+// given name and log, or nil if there is none.
+func (db *flakeDB) match(job string, log []byte) *flakeSignature {
+	for _, f := range db.Flakes {
+		if f.job.MatchString(job) && f.pattern.Match(log) {
+			return f
+		}
+	}
+	return nil
+}
+
+// flakyJob is a failed job along with the known flake it matched.
+type flakyJob struct {
+	job   *github.WorkflowJob
+	flake *flakeSignature
+}
+
+// matchFlakes checks the failed jobs of the latest attempt of run in
+// owner/repo against db. It returns the failed jobs along with the flakes
+// they matched, and whether all of them matched one.
+func (c *config) matchFlakes(ctx context.Context, db *flakeDB, owner, repo string, run *github.WorkflowRun) (_ []flakyJob, allFlaky bool, _ error) {
+	jobs, _, err := c.githubClient.Actions.ListWorkflowJobs(ctx, owner, repo, run.GetID(), &github.ListWorkflowJobsOptions{
+		Filter:      "latest",
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list jobs of run %d: %w", run.GetID(), err)
+	}
+	var res []flakyJob
+	allFlaky = true
+	for _, job := range jobs.Jobs {
+		if job.GetConclusion() != "failure" {
+			continue
+		}
+		log, err := c.jobLog(ctx, owner, repo, job.GetID())
+		if err != nil {
+			return nil, false, err
+		}
+		f := db.match(job.GetName(), log)
+		if f == nil {
+			allFlaky = false
+		}
+		res = append(res, flakyJob{job: job, flake: f})
+	}
+	return res, allFlaky && len(res) > 0, nil
+}
+
+// jobLog returns the log of the workflow job with the given ID.
+func (c *config) jobLog(ctx context.Context, owner, repo string, jobID int64) ([]byte, error) {
+	u, _, err := c.githubClient.Actions.GetWorkflowJobLogs(ctx, owner, repo, jobID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log URL of job %d: %w", jobID, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download log of job %d: %w", jobID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download log of job %d: %s", jobID, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	flagOverrideFreeze flagName = "override-freeze"
+
+	// freezeKey is the codereview config key giving where the freeze
+	// windows are published: either the path of a file on the default
+	// branch of the GitHub repository, or "#N" for the body of issue N,
+	// such as one pinned for the duration of a release.
+	freezeKey = "freeze"
+)
+
+// A freezeWindow is a period during which mutating commands such as submit
+// and runtrybot are refused without --override-freeze.
+//
+// Windows are written one per line, as either a one-off period in UTC, or
+// daily quiet hours in UTC which may wrap around midnight:
+//
+//	2026-11-02 2026-11-06 v0.15 release
+//	2026-11-09T08:00 2026-11-09T12:00 infrastructure migration
+//	daily 22:00 06:00 quiet hours
+//
+// Empty lines and lines starting with # are ignored. An end date without a
+// time of day includes that whole day.
+type freezeWindow struct {
+	start, end time.Time
+
+	// daily is set for quiet hours, in which case only the time of day of
+	// start and end is used.
+	daily bool
+
+	reason string
+}
+
+// active reports whether t falls within the window, and if so, until when.
+func (w freezeWindow) active(t time.Time) (until time.Time, ok bool) {
+	t = t.UTC()
+	if !w.daily {
+		return w.end, !t.Before(w.start) && t.Before(w.end)
+	}
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	start := day.Add(sinceMidnight(w.start))
+	end := day.Add(sinceMidnight(w.end))
+	if !end.After(start) {
+		// The window wraps around midnight.
+		if t.Before(end) {
+			return end, true
+		}
+		end = end.Add(24 * time.Hour)
+	}
+	return end, !t.Before(start) && t.Before(end)
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+// parseFreezeWindows parses freeze windows as documented on freezeWindow.
+func parseFreezeWindows(src string) ([]freezeWindow, error) {
+	var windows []freezeWindow
+	sc := bufio.NewScanner(strings.NewReader(src))
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) < 3 {
+			return nil, fmt.Errorf("line %d: want START END [REASON], got %q", lineNum, line)
+		}
+		var w freezeWindow
+		var err error
+		if f[0] == "daily" {
+			if len(f) < 4 {
+				return nil, fmt.Errorf("line %d: want daily START END [REASON], got %q", lineNum, line)
+			}
+			w.daily = true
+			f = f[1:]
+			if w.start, err = time.Parse("15:04", f[0]); err == nil {
+				w.end, err = time.Parse("15:04", f[1])
+			}
+		} else if w.start, err = parseFreezeTime(f[0]); err == nil {
+			if w.end, err = parseFreezeTime(f[1]); err == nil && !strings.Contains(f[1], "T") {
+				w.end = w.end.Add(24 * time.Hour)
+			}
+			if err == nil && !w.end.After(w.start) {
+				err = fmt.Errorf("window ends before it starts")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		w.reason = strings.Join(f[2:], " ")
+		windows = append(windows, w)
+	}
+	return windows, sc.Err()
+}
+
+func parseFreezeTime(s string) (time.Time, error) {
+	if strings.Contains(s, "T") {
+		return time.Parse("2006-01-02T15:04", s)
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// loadFreezeWindows fetches the freeze windows published as configured by
+// the freeze entry in the codereview config, if any. In an issue, only the
+// first fenced code block of its body is parsed, to allow for a description
+// of the freeze around it.
+func (c *config) loadFreezeWindows(ctx context.Context) ([]freezeWindow, error) {
+	var src string
+	switch {
+	case c.freeze == "":
+		return nil, nil
+	case strings.HasPrefix(c.freeze, "#"):
+		n, err := strconv.Atoi(c.freeze[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q in codereview config", freezeKey, c.freeze)
+		}
+		issue, _, err := c.githubClient.Issues.Get(ctx, c.githubOwner, c.githubRepo, n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get freeze issue %d: %w", n, err)
+		}
+		_, block, ok := strings.Cut(issue.GetBody(), "```")
+		if !ok {
+			return nil, nil
+		}
+		// Skip the info string of the code block.
+		_, block, _ = strings.Cut(block, "\n")
+		src, _, _ = strings.Cut(block, "```")
+	default:
+		var err error
+		_, src, err = c.defaultBranchFile(ctx, c.githubOwner, c.githubRepo, c.freeze)
+		if err != nil {
+			return nil, err
+		}
+	}
+	windows, err := parseFreezeWindows(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid freeze windows in %s: %v", c.freeze, err)
+	}
+	return windows, nil
+}
+
+// activeFreeze returns the first of the repository's freeze windows which is
+// active now, if any.
+func (c *config) activeFreeze(ctx context.Context) (w freezeWindow, until time.Time, ok bool, _ error) {
+	windows, err := c.loadFreezeWindows(ctx)
+	if err != nil {
+		return freezeWindow{}, time.Time{}, false, err
+	}
+	now := time.Now()
+	for _, w := range windows {
+		if until, ok := w.active(now); ok {
+			return w, until, true, nil
+		}
+	}
+	return freezeWindow{}, time.Time{}, false, nil
+}
+
+// checkFreeze returns an error if a freeze window of the repository is
+// active, unless the --override-freeze flag of cmd is set, in which case it
+// only warns. Mutating commands such as submit and runtrybot call it before
+// doing anything, so that nothing is submitted or dispatched by accident
+// during a release freeze.
+func (c *config) checkFreeze(cmd *Command) error {
+	w, until, ok, err := c.activeFreeze(cmd.Context())
+	if err != nil {
+		if flagOverrideFreeze.Bool(cmd) {
+			return nil
+		}
+		return fmt.Errorf("failed to check for a freeze: %w\nuse --%s to proceed regardless", err, flagOverrideFreeze)
+	}
+	if !ok {
+		return nil
+	}
+	msg := fmt.Sprintf("%s is frozen until %s", c.githubRepo, until.UTC().Format("2006-01-02 15:04 UTC"))
+	if w.reason != "" {
+		msg += ": " + w.reason
+	}
+	if flagOverrideFreeze.Bool(cmd) {
+		fmt.Fprintf(os.Stderr, "warning: %s; proceeding as --%s was given\n", msg, flagOverrideFreeze)
+		return nil
+	}
+	return fmt.Errorf("%s\nuse --%s if this really cannot wait", msg, flagOverrideFreeze)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreezeWindows(t *testing.T) {
+	windows, err := parseFreezeWindows(`
+# Release freezes.
+2026-11-02 2026-11-06 v0.15 release
+2026-11-09T08:00 2026-11-09T12:00 infrastructure migration
+
+daily 22:00 06:00 quiet hours
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 3 {
+		t.Fatalf("got %d windows; want 3", len(windows))
+	}
+	testCases := []struct {
+		at     string
+		reason string
+		until  string
+	}{
+		{"2026-11-01 12:00", "", ""},
+		{"2026-11-02 00:00", "v0.15 release", "2026-11-07 00:00"},
+		{"2026-11-06 21:59", "v0.15 release", "2026-11-07 00:00"},
+		{"2026-11-09 08:00", "infrastructure migration", "2026-11-09 12:00"},
+		{"2026-11-09 12:00", "", ""},
+		{"2026-11-10 23:30", "quiet hours", "2026-11-11 06:00"},
+		{"2026-11-11 05:59", "quiet hours", "2026-11-11 06:00"},
+		{"2026-11-11 06:00", "", ""},
+	}
+	for _, tc := range testCases {
+		at, err := time.Parse("2006-01-02 15:04", tc.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reason, until string
+		for _, w := range windows {
+			if u, ok := w.active(at); ok {
+				reason, until = w.reason, u.Format("2006-01-02 15:04")
+				break
+			}
+		}
+		if reason != tc.reason || until != tc.until {
+			t.Errorf("at %s: got %q until %q; want %q until %q", tc.at, reason, until, tc.reason, tc.until)
+		}
+	}
+}
+
+func TestFreezeWindowsInvalid(t *testing.T) {
+	for _, src := range []string{
+		"2026-11-02",
+		"2026-11-06 2026-11-02 backwards",
+		"daily 22:00",
+		"daily 25:00 06:00 quiet",
+		"tomorrow 2026-11-02 x",
+	} {
+		if _, err := parseFreezeWindows(src); err == nil {
+			t.Errorf("parseFreezeWindows(%q) succeeded; want an error", src)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	flagNoFreshnessCheck flagName = "no-freshness-check"
+
+	// freshnessCommitsKey and freshnessAgeKey are the user config keys
+	// which override the thresholds of the freshness check.
+	freshnessCommitsKey = "freshness-commits"
+	freshnessAgeKey     = "freshness-age"
+
+	defaultFreshnessCommits = 100
+	defaultFreshnessAge     = 7 * 24 * time.Hour
+)
+
+// checkFreshness warns if the branchpoint bp of the pending commits is far
+// behind the default branch of origin, as CI results for an outdated base
+// are of little use. upstream is used when origin has no default branch.
+// The local remote-tracking branches are used as-is, so the check is only as
+// up to date as the last fetch. Failures to run the check are not fatal.
+func (c *cltrigger) checkFreshness(ctx context.Context, bp, upstream string) {
+	if flagNoFreshnessCheck.Bool(c.cmd) {
+		return
+	}
+	maxCommits, maxAge, err := freshnessThresholds()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: skipping freshness check: %v\n", err)
+		return
+	}
+	base, err := run(ctx, "git", "rev-parse", "--abbrev-ref", "origin/HEAD")
+	if err != nil {
+		base = upstream
+	}
+	base = strings.TrimSpace(base)
+	if base == "" {
+		return
+	}
+	behind, age, err := baseFreshness(ctx, bp, base)
+	if err != nil {
+		debugf("freshness check against %s failed: %v\n", base, err)
+		return
+	}
+	if behind == 0 || (behind < maxCommits && age < maxAge) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: pending commits are based on a commit %d commits and %s behind %s;\n"+
+		"consider rebasing with \"git codereview sync\" first, or use --%s to skip this check\n",
+		behind, formatAge(age), base, flagNoFreshnessCheck)
+}
+
+// freshnessThresholds returns the number of commits and the age by which the
+// branchpoint may lag before checkFreshness warns.
+func freshnessThresholds() (commits int, age time.Duration, _ error) {
+	ucfg, err := loadUserConfig()
+	if err != nil {
+		return 0, 0, err
+	}
+	commits, age = defaultFreshnessCommits, defaultFreshnessAge
+	if s := ucfg[freshnessCommitsKey]; s != "" {
+		if commits, err = strconv.Atoi(s); err != nil {
+			return 0, 0, fmt.Errorf("invalid %s %q in user config", freshnessCommitsKey, s)
+		}
+	}
+	if s := ucfg[freshnessAgeKey]; s != "" {
+		if age, err = parseDuration(s); err != nil {
+			return 0, 0, fmt.Errorf("invalid %s %q in user config", freshnessAgeKey, s)
+		}
+	}
+	return commits, age, nil
+}
+
+// baseFreshness returns the number of commits in base which are not in bp,
+// and how much older the commit bp is than the tip of base.
+func baseFreshness(ctx context.Context, bp, base string) (behind int, age time.Duration, _ error) {
+	out, err := run(ctx, "git", "rev-list", "--count", bp+".."+base)
+	if err != nil {
+		return 0, 0, err
+	}
+	if behind, err = strconv.Atoi(strings.TrimSpace(out)); err != nil {
+		return 0, 0, err
+	}
+	if behind == 0 {
+		return 0, 0, nil
+	}
+	out, err = run(ctx, "git", "show", "--no-patch", "--format=%ct", bp, base)
+	if err != nil {
+		return 0, 0, err
+	}
+	times := strings.Fields(out)
+	if len(times) != 2 {
+		return 0, 0, fmt.Errorf("unexpected commit times %q", out)
+	}
+	bpTime, err1 := strconv.ParseInt(times[0], 10, 64)
+	baseTime, err2 := strconv.ParseInt(times[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("unexpected commit times %q", out)
+	}
+	return behind, time.Duration(baseTime-bpTime) * time.Second, nil
+}
+
+// formatAge formats d in days if it is at least two days, and in hours
+// otherwise.
+func formatAge(d time.Duration) string {
+	if d >= 48*time.Hour {
+		return fmt.Sprintf("%d days", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%d hours", d/time.Hour)
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+)
+
+// gerritError is returned for Gerrit API calls that failed with a non-2xx
+// status code, allowing callers to act on the code.
+type gerritError struct {
+	statusCode int
+	url        *url.URL // URL of the failed request
+	message    string   // start of the response body, which explains the failure
+	err        error
+}
+
+func (e *gerritError) Error() string {
+	return e.err.Error()
+}
+
+func (e *gerritError) Unwrap() error {
+	return e.err
+}
+
+// checkGerrit adapts the results of a go-gerrit call so that the HTTP status
+// code of a failed request is available via gerritStatus.
+func checkGerrit(resp *gerrit.Response, err error) error {
+	if err != nil && resp != nil && resp.Response != nil {
+		ge := &gerritError{statusCode: resp.StatusCode, err: err}
+		if resp.Request != nil {
+			ge.url = resp.Request.URL
+		}
+		// go-gerrit leaves the body of a failed response unread.
+		if resp.Body != nil {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+			ge.message = strings.TrimSpace(string(body))
+		}
+		return ge
+	}
+	return err
+}
+
+// gerritStatus returns the HTTP status code of a failed Gerrit API call, or
+// zero if err did not result from a Gerrit response.
+func gerritStatus(err error) int {
+	var ge *gerritError
+	if errors.As(err, &ge) {
+		return ge.statusCode
+	}
+	return 0
+}
+
+// gerritMessage returns the message which Gerrit gave with a failed API call,
+// or the empty string if err did not result from a Gerrit response.
+func gerritMessage(err error) string {
+	var ge *gerritError
+	if errors.As(err, &ge) {
+		return ge.message
+	}
+	return ""
+}
+
+// gerritDo performs a request against the Gerrit REST API for the endpoints
+// that go-gerrit does not provide methods for. path is relative to the
+// server, e.g. "config/server/info". body, if non-nil, is encoded as JSON.
+// If v is non-nil the JSON response is decoded into it.
+func (c *config) gerritDo(method, path string, body, v any) error {
+	req, err := c.gerritClient.NewRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.gerritClient.Do(req, v)
+	return checkGerrit(resp, err)
+}
+
+// gerritComment is the subset of Gerrit's CommentInfo entity that we use.
+type gerritComment struct {
+	ID         string             `json:"id"`
+	PatchSet   int                `json:"patch_set"`
+	Path       string             `json:"path"`
+	Line       int                `json:"line"`
+	Range      *commentRange      `json:"range"`
+	InReplyTo  string             `json:"in_reply_to"`
+	Message    string             `json:"message"`
+	Updated    gerrit.Timestamp   `json:"updated"`
+	Unresolved bool               `json:"unresolved"`
+	Tag        string             `json:"tag"`
+	Author     gerrit.AccountInfo `json:"author"`
+}
+
+// commentRange is Gerrit's CommentRange entity. Lines start at 1, and
+// characters at 0.
+type commentRange struct {
+	StartLine      int `json:"start_line"`
+	StartCharacter int `json:"start_character"`
+	EndLine        int `json:"end_line"`
+	EndCharacter   int `json:"end_character"`
+}
+
+// listComments returns the published comments on all revisions of changeID,
+// keyed by file path. The Path field of each comment is filled in.
+func (c *config) listComments(changeID string) (map[string][]gerritComment, error) {
+	var res map[string][]gerritComment
+	if err := c.gerritDo(http.MethodGet, "changes/"+changeID+"/comments", nil, &res); err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	for path, comments := range res {
+		for i := range comments {
+			comments[i].Path = path
+		}
+	}
+	return res, nil
+}
+
+// gerritServerInfo is the subset of Gerrit's ServerInfo entity that we use.
+type gerritServerInfo struct {
+	Change struct {
+		SubmitWholeTopic bool `json:"submit_whole_topic"`
+	} `json:"change"`
+}
+
+func (c *config) serverInfo() (*gerritServerInfo, error) {
+	var info gerritServerInfo
+	if err := c.gerritDo(http.MethodGet, "config/server/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// createFileChange creates a new change on branch in project which sets the
+// contents of the file at path. msg is used as the commit message; Gerrit
+// adds the Change-Id.
+func (c *config) createFileChange(project, branch, msg, path string, content []byte) (*gerrit.ChangeInfo, error) {
+	in := map[string]string{
+		"project": project,
+		"branch":  branch,
+		"subject": msg,
+	}
+	var ch gerrit.ChangeInfo
+	if err := c.gerritDo(http.MethodPost, "changes/", in, &ch); err != nil {
+		return nil, fmt.Errorf("failed to create change: %w", err)
+	}
+	id := strconv.Itoa(ch.Number)
+	if err := c.putEditFile(id, path, content); err != nil {
+		return nil, err
+	}
+	if err := c.publishEdit(id, "NONE"); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+// putEditFile sets the contents of the file at path in the change edit of
+// changeID, creating the change edit if needed.
+func (c *config) putEditFile(changeID, path string, content []byte) error {
+	if err := c.requireGerrit(featureChangeEdits); err != nil {
+		return err
+	}
+	in := struct {
+		BinaryContent string `json:"binary_content"`
+	}{
+		BinaryContent: "data:text/plain;base64," + base64.StdEncoding.EncodeToString(content),
+	}
+	if err := c.gerritDo(http.MethodPut, "changes/"+changeID+"/edit/"+url.PathEscape(path), in, nil); err != nil {
+		return fmt.Errorf("failed to modify %s in change edit: %w", path, err)
+	}
+	return nil
+}
+
+// publishEdit publishes the change edit of changeID as a new patchset,
+// notifying the accounts given by notify, such as NONE or OWNER.
+func (c *config) publishEdit(changeID, notify string) error {
+	in := map[string]string{"notify": notify}
+	if err := c.gerritDo(http.MethodPost, "changes/"+changeID+"/edit:publish", in, nil); err != nil {
+		return fmt.Errorf("failed to publish change edit: %w", err)
+	}
+	return nil
+}
+
+// hasChangeEdit reports whether changeID has an unpublished change edit.
+func (c *config) hasChangeEdit(changeID string) (bool, error) {
+	req, err := c.gerritClient.NewRequest(http.MethodGet, "changes/"+changeID+"/edit", nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := c.gerritClient.Do(req, nil)
+	if err := checkGerrit(resp, err); err != nil {
+		return false, fmt.Errorf("failed to get change edit: %w", err)
+	}
+	// Gerrit responds with 204 No Content when there is no change edit.
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// fileContent returns the content of the file at path in the given revision
+// of changeID. It returns an error satisfying gerritStatus(err) == 404 if the
+// file does not exist.
+func (c *config) fileContent(changeID, revision, path string) ([]byte, error) {
+	var buf bytes.Buffer
+	u := "changes/" + changeID + "/revisions/" + revision + "/files/" + url.PathEscape(path) + "/content"
+	if err := c.gerritDo(http.MethodGet, u, nil, &buf); err != nil {
+		return nil, fmt.Errorf("failed to get content of %s: %w", path, err)
+	}
+	// The content is returned base64 encoded.
+	content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(buf.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content of %s: %v", path, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagAuditAccessPolicy flagName = "policy"
+
+	// defaultAccessPolicyFile is the path, relative to the root of the
+	// repository, of the default access policy for audit-access.
+	defaultAccessPolicyFile = ".github/cueckoo-access.cue"
+)
+
+// auditedPermissions are the permissions which audit-access reports on in
+// addition to any named by the policy. The trybot gating logic relies on only
+// the right people being able to vote on TryBot-Result.
+var auditedPermissions = []string{
+	"label-TryBot-Result",
+	"label-Code-Review",
+	"submit",
+}
+
+// newGerritAuditAccessCmd creates a new gerrit audit-access command
+func newGerritAuditAccessCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit-access",
+		Short: "compare who can vote and submit against an expected policy",
+		Long: `
+Usage of gerrit audit-access:
+
+	gerrit audit-access [--policy FILE]
+
+audit-access lists the Gerrit groups which are granted the permissions to vote
+on TryBot-Result and Code-Review and to submit in the project, including those
+inherited from parent projects, along with the members of those groups. It
+then compares them against the expected policy, listing any unexpected groups
+and members, and fails if there are any.
+
+The policy is read from --policy, which defaults to ` + defaultAccessPolicyFile + `
+in the repository. If there is no policy, the access is only listed. The
+policy is a CUE file giving the groups expected to be granted each permission,
+and optionally the expected members of groups by username or email address:
+
+	permissions: {
+		"label-TryBot-Result": ["cue-trybots"]
+		"label-Code-Review": ["cue-maintainers", "Project Owners"]
+		"submit": ["cue-maintainers"]
+	}
+	members: {
+		"cue-maintainers": ["alice", "bob@example.com"]
+	}
+
+The members of groups which are managed outside of Gerrit, such as
+Registered Users, cannot be listed and are not audited.
+`,
+		RunE: mkRunE(c, gerritAuditAccessDef),
+	}
+	c.registerFlags(cmd).String(flagAuditAccessPolicy, "", "path of the expected access policy (default "+defaultAccessPolicyFile+")")
+	return cmd
+}
+
+// accessPolicy is the expected access to a project; see audit-access.
+type accessPolicy struct {
+	// Permissions maps permission names to the names of the groups expected
+	// to be granted them.
+	Permissions map[string][]string `json:"permissions"`
+
+	// Members maps group names to their expected members.
+	Members map[string][]string `json:"members"`
+}
+
+// gerritProjectAccess is the subset of Gerrit's ProjectAccessInfo entity that
+// we use.
+type gerritProjectAccess struct {
+	InheritsFrom *struct {
+		Name string `json:"name"`
+	} `json:"inherits_from"`
+	Local map[string]struct {
+		Permissions map[string]struct {
+			Rules map[string]struct {
+				Action string `json:"action"`
+				Min    int    `json:"min"`
+				Max    int    `json:"max"`
+			} `json:"rules"`
+		} `json:"permissions"`
+	} `json:"local"`
+	Groups map[string]struct {
+		Name string `json:"name"`
+	} `json:"groups"`
+}
+
+// accessGrant is a permission granted to a group on a ref in a project.
+type accessGrant struct {
+	permission string
+	project    string
+	ref        string
+	groupID    string
+	group      string
+	min, max   int
+}
+
+func gerritAuditAccessDef(cmd *Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	var policy *accessPolicy
+	path := flagAuditAccessPolicy.String(cmd)
+	if path == "" {
+		path = filepath.Join(cfg.gitRoot, defaultAccessPolicyFile)
+		if !fileExists(path) {
+			path = ""
+		}
+	}
+	if path != "" {
+		policy = new(accessPolicy)
+		if err := loadCUEFile(ctx, path, policy); err != nil {
+			return err
+		}
+	}
+
+	permissions := append([]string(nil), auditedPermissions...)
+	if policy != nil {
+		for _, p := range sortedKeys(policy.Permissions) {
+			if !slicesContains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	grants, err := cfg.accessGrants(cfg.gerritProject(), permissions)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	problems := 0
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "PERMISSION\tPROJECT\tREF\tGROUP\tRANGE\tSTATUS")
+	groups := make(map[string]string) // group name to UUID
+	for _, g := range grants {
+		groups[g.group] = g.groupID
+		status := ""
+		if policy != nil {
+			status = "ok"
+			if !slicesContains(policy.Permissions[g.permission], g.group) {
+				status = "unexpected"
+				problems++
+			}
+		}
+		rng := ""
+		if strings.HasPrefix(g.permission, "label-") {
+			rng = fmt.Sprintf("%+d..%+d", g.min, g.max)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", g.permission, g.project, g.ref, g.group, rng, status)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	tw = newTableWriter(w)
+	fmt.Fprintln(tw, "GROUP\tMEMBER\tSTATUS")
+	for _, name := range sortedKeys(groups) {
+		members, ok, err := cfg.groupMembers(groups[name])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintf(tw, "%s\t\tnot listable\n", name)
+			continue
+		}
+		var expected []string
+		if policy != nil {
+			expected = policy.Members[name]
+		}
+		seen := make(map[string]bool)
+		for _, m := range members {
+			status := ""
+			if expected != nil {
+				status = "unexpected"
+				for _, id := range []string{m.Username, m.Email} {
+					if id != "" && slicesContains(expected, id) {
+						status = "ok"
+						seen[id] = true
+					}
+				}
+				if status == "unexpected" {
+					problems++
+				}
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, accountName(m), status)
+		}
+		for _, id := range expected {
+			if !seen[id] {
+				fmt.Fprintf(tw, "%s\t%s\tmissing\n", name, id)
+				problems++
+			}
+		}
+	}
+	tw.Flush()
+
+	if problems > 0 {
+		return fmt.Errorf("found %d differences from the policy in %s", problems, path)
+	}
+	return nil
+}
+
+// accessGrants returns the grants of the given permissions in project and
+// the projects it inherits from, ordered by permission.
+func (c *config) accessGrants(project string, permissions []string) ([]accessGrant, error) {
+	var grants []accessGrant
+	seen := make(map[string]bool)
+	for project != "" && !seen[project] {
+		seen[project] = true
+		var access gerritProjectAccess
+		if err := c.gerritDo(http.MethodGet, "projects/"+url.PathEscape(project)+"/access", nil, &access); err != nil {
+			return nil, fmt.Errorf("failed to get access of project %s: %w", project, err)
+		}
+		for ref, section := range access.Local {
+			for _, p := range permissions {
+				for groupID, rule := range section.Permissions[p].Rules {
+					if rule.Action != "" && rule.Action != "ALLOW" {
+						continue
+					}
+					name := access.Groups[groupID].Name
+					if name == "" {
+						name = groupID
+					}
+					grants = append(grants, accessGrant{
+						permission: p,
+						project:    project,
+						ref:        ref,
+						groupID:    groupID,
+						group:      name,
+						min:        rule.Min,
+						max:        rule.Max,
+					})
+				}
+			}
+		}
+		project = ""
+		if access.InheritsFrom != nil {
+			project = access.InheritsFrom.Name
+		}
+	}
+	order := make(map[string]int)
+	for i, p := range permissions {
+		order[p] = i
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		gi, gj := grants[i], grants[j]
+		if gi.permission != gj.permission {
+			return order[gi.permission] < order[gj.permission]
+		}
+		if gi.project != gj.project {
+			return gi.project < gj.project
+		}
+		if gi.ref != gj.ref {
+			return gi.ref < gj.ref
+		}
+		return gi.group < gj.group
+	})
+	return grants, nil
+}
+
+// groupMembers returns the members of the group, including those of any
+// included groups. It returns false if the group's members cannot be listed,
+// as is the case for groups managed outside of Gerrit.
+func (c *config) groupMembers(groupID string) ([]gerrit.AccountInfo, bool, error) {
+	var members []gerrit.AccountInfo
+	err := c.gerritDo(http.MethodGet, "groups/"+url.PathEscape(groupID)+"/members/?recursive", nil, &members)
+	switch status := gerritStatus(err); {
+	case status == http.StatusNotFound || status == http.StatusMethodNotAllowed:
+		return nil, false, nil
+	case err != nil:
+		return nil, false, fmt.Errorf("failed to list members of group %s: %w", groupID, err)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return accountName(members[i]) < accountName(members[j])
+	})
+	return members, true, nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newGerritCmd creates a new gerrit command, which groups the subcommands
+// for inspecting and maintaining the project's Gerrit configuration.
+func newGerritCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gerrit",
+		Short: "inspect and maintain the project's Gerrit configuration",
+	}
+	subCommands := []*cobra.Command{
+		newGerritAuditAccessCmd(c),
+	}
+	for _, sub := range subCommands {
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gerritVersion is the version of a Gerrit server, such as 3.9.1.
+type gerritVersion struct {
+	major, minor, patch int
+}
+
+func (v gerritVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// less reports whether v is older than w.
+func (v gerritVersion) less(w gerritVersion) bool {
+	if v.major != w.major {
+		return v.major < w.major
+	}
+	if v.minor != w.minor {
+		return v.minor < w.minor
+	}
+	return v.patch < w.patch
+}
+
+// parseGerritVersion parses a version as reported by Gerrit, which may carry
+// a suffix for pre-releases and development builds, such as 3.10.0-rc2 or
+// 3.9.1-12-gdeadbeef. The suffix is ignored.
+func parseGerritVersion(s string) (gerritVersion, bool) {
+	s, _, _ = strings.Cut(strings.TrimPrefix(s, "v"), "-")
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return gerritVersion{}, false
+	}
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return gerritVersion{}, false
+		}
+		nums[i] = n
+	}
+	return gerritVersion{nums[0], nums[1], nums[2]}, true
+}
+
+// A gerritFeature is a part of the Gerrit REST API which cueckoo uses and
+// which is not supported by all the Gerrit versions still in use.
+type gerritFeature struct {
+	name  string
+	since gerritVersion
+}
+
+var (
+	featureChangeEdits   = gerritFeature{"change edits", gerritVersion{2, 11, 0}}
+	featureRevert        = gerritFeature{"reverting changes", gerritVersion{2, 8, 0}}
+	featureRobotComments = gerritFeature{"robot comments", gerritVersion{2, 15, 0}}
+	featureDeleteMessage = gerritFeature{"deleting change messages", gerritVersion{2, 15, 0}}
+
+	// gerritFeatures lists the features for doctor.
+	gerritFeatures = []gerritFeature{featureChangeEdits, featureRevert, featureRobotComments, featureDeleteMessage}
+)
+
+// gerritServerVersion returns the version of the Gerrit server, which is only
+// queried once per config. ok is false if the version could not be
+// determined, such as when the server hides it, in which case all features
+// are assumed to be supported.
+func (c *config) gerritServerVersion() (v gerritVersion, ok bool) {
+	c.gerritVersionOnce.Do(func() {
+		var s string
+		if err := c.gerritDo(http.MethodGet, "config/server/version", nil, &s); err != nil {
+			debugf("failed to determine the Gerrit server version: %v\n", err)
+			return
+		}
+		if c.gerritVersion, c.gerritVersionOK = parseGerritVersion(s); !c.gerritVersionOK {
+			debugf("unknown Gerrit server version %q\n", s)
+		}
+	})
+	return c.gerritVersion, c.gerritVersionOK
+}
+
+// supports reports whether the Gerrit server supports f.
+func (c *config) supports(f gerritFeature) bool {
+	v, ok := c.gerritServerVersion()
+	return !ok || !v.less(f.since)
+}
+
+// requireGerrit returns an error explaining that the Gerrit server is too
+// old if it does not support f.
+func (c *config) requireGerrit(f gerritFeature) error {
+	if c.supports(f) {
+		return nil
+	}
+	v, _ := c.gerritServerVersion()
+	return fmt.Errorf("the Gerrit server at %s runs version %s, which does not support %s; version %d.%d or later is required",
+		c.gerritURL, v, f.name, f.since.major, f.since.minor)
+}
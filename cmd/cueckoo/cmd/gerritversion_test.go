@@ -0,0 +1,46 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "testing"
+
+func TestParseGerritVersion(t *testing.T) {
+	cases := []struct {
+		in   string
+		want gerritVersion
+		ok   bool
+	}{
+		{in: "3.9.1", want: gerritVersion{3, 9, 1}, ok: true},
+		{in: "2.16", want: gerritVersion{2, 16, 0}, ok: true},
+		{in: "3.10.0-rc2", want: gerritVersion{3, 10, 0}, ok: true},
+		{in: "3.9.1-12-gdeadbeef", want: gerritVersion{3, 9, 1}, ok: true},
+		{in: "v3.8.0", want: gerritVersion{3, 8, 0}, ok: true},
+		{in: "", ok: false},
+		{in: "3", ok: false},
+		{in: "three.nine", ok: false},
+	}
+	for _, c := range cases {
+		got, ok := parseGerritVersion(c.in)
+		if ok != c.ok || got != c.want {
+			t.Errorf("parseGerritVersion(%q) = %v, %v; want %v, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+	if !(gerritVersion{2, 14, 9}).less(featureRobotComments.since) {
+		t.Errorf("2.14.9 should be older than %v", featureRobotComments.since)
+	}
+	if (gerritVersion{3, 0, 0}).less(featureRobotComments.since) {
+		t.Errorf("3.0.0 should not be older than %v", featureRobotComments.since)
+	}
+}
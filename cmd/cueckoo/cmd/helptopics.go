@@ -0,0 +1,117 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "github.com/spf13/cobra"
+
+// newHelpTopics returns the additional help topics, listed by the help of
+// the root command and shown by "cueckoo help TOPIC". They document the
+// global flags and settings which apply to every command, keeping the help
+// of the root command short.
+func newHelpTopics() []*cobra.Command {
+	return []*cobra.Command{{
+		Use:   "daemon",
+		Short: "reusing one cueckoo process via --daemon",
+		Long: `When run with --daemon, cueckoo listens on a unix socket and runs the commands
+of other cueckoo invocations on their behalf, reusing the loaded config and
+warm HTTP connections. This speeds up scripts which run cueckoo repeatedly:
+
+	cueckoo --daemon &
+	for cl in 1234 5678; do cueckoo runtrybot $cl; done
+	kill %1
+
+The socket is placed in $XDG_RUNTIME_DIR, or a directory of the system
+temporary directory only accessible by the user, unless CUECKOO_DAEMON is set
+to another path in such a directory. Set CUECKOO_DAEMON=off to never use a
+daemon. The daemon only serves the user running it, and runs commands with the
+credentials from its own environment rather than those of the client.
+`,
+	}, {
+		Use:   "maintenance",
+		Short: "waiting for Gerrit while it is down for maintenance",
+		Long: `While Gerrit is unavailable for maintenance, requests to it are paused and
+retried rather than failing, with the pauses reported on stderr, such that
+long-running and bulk commands carry on once Gerrit is back. The global
+--max-pause flag bounds how long cueckoo waits, 30m by default; 0 disables
+this.
+`,
+	}, {
+		Use:   "cache",
+		Short: "the local cache of workflow runs",
+		Long: `The workflow runs found for dispatches are cached in the cueckoo directory of
+$XDG_CACHE_HOME, or its platform equivalent, to avoid listing hundreds of runs
+on every lookup. The global --no-cache flag bypasses the cache.
+`,
+	}, {
+		Use:   "output",
+		Short: "progress lines, tables and colors",
+		Long: `When writing to a terminal, progress is shown as a status line which is
+redrawn in place. The global --no-color flag, or setting NO_COLOR or
+TERM=dumb, writes a plain line per completed item instead, as when not
+writing to a terminal. The global --plain flag additionally writes tables as
+tab-separated values rather than aligned columns, for output which is stable
+for scripts and CI logs and easy to follow with a screen reader.
+`,
+	}, {
+		Use:   "freeze",
+		Short: "freeze windows and quiet hours for a repository",
+		Long: `Maintainers can publish freeze windows, such as during a release, and daily
+quiet hours for a repository, as configured by the freeze entry of
+codereview.cfg: either the path of a file on the default branch of the GitHub
+repository, or "#N" for the first code block of the body of issue N. Each
+line gives a window in UTC:
+
+	2026-11-02 2026-11-06 v0.15 release
+	2026-11-09T08:00 2026-11-09T12:00 infrastructure migration
+	daily 22:00 06:00 quiet hours
+
+During a window, commands which submit CLs or dispatch workflows refuse to
+run unless given --override-freeze, and the submit queue of serve pauses.
+`,
+	}, {
+		Use:   "defaults",
+		Short: "default arguments for commands in the user config",
+		Long: `Default arguments for a command can be set in the user config, by an entry
+named "defaults." followed by the path of the command with dots, such as:
+
+	defaults.runtrybot: --nounity
+	defaults.rota.report: --since 14d
+
+The default arguments are inserted right after the name of the command, such
+that the arguments given on the command line take precedence. The global
+--verbose flag, or setting CUECKOO_DEBUG, prints any default arguments applied
+along with other debug output.
+`,
+	}, {
+		Use:   "read-only",
+		Short: "refusing every change to Gerrit and GitHub",
+		Long: `In read-only mode, set by the global --read-only flag or CUECKOO_READONLY=1,
+cueckoo refuses every request to Gerrit and GitHub which could modify them,
+such as workflow dispatches, votes and comments, whichever command makes it.
+Likewise, it refuses to run git push and git-codereview mail. Dashboards and
+reports can then safely run cueckoo in shared automation with powerful
+credentials.
+`,
+	}, {
+		Use:   "dry-run",
+		Short: "printing repository dispatches instead of sending them",
+		Long: `The global --dry-run flag makes the commands which dispatch workflows, such
+as runtrybot and unity, print each repository dispatch instead of sending it:
+the target repository, the event type, and the client payload as indented
+JSON. This helps to debug changes to payloads, and to check what a workflow
+would receive.
+`,
+	}}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagHistoryLimit  flagName = "limit"
+	flagHistoryCL     flagName = "cl"
+	flagHistoryNoRuns flagName = "no-runs"
+)
+
+// newHistoryCmd creates a new history command
+func newHistoryCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "list recent dispatches made by cueckoo",
+		Long: `
+Usage of history:
+
+	history [--limit N] [--cl CL] [--no-runs]
+
+history lists the most recent repository dispatches made by cueckoo on this
+machine, such as trybot and unity runs, newest first. This answers questions
+like "did I already trigger this?" without checking GitHub.
+
+Dispatches are recorded in a local audit log, kept in $XDG_STATE_HOME/cueckoo
+if set and otherwise in the cueckoo user config directory. For each dispatch
+the workflow run it resulted in, if any, is looked up via the GitHub API
+along with its status; --no-runs skips this.
+
+--cl only lists dispatches for the given CL number.
+`,
+		RunE: mkRunE(c, historyDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.Int(flagHistoryLimit, 20, "maximum number of dispatches to list")
+	flags.Int(flagHistoryCL, 0, "only list dispatches for this CL number")
+	flags.Bool(flagHistoryNoRuns, false, "do not look up the resulting workflow runs")
+	return cmd
+}
+
+func historyDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("history does not take any arguments")
+	}
+	ctx := cmd.Context()
+	entries, err := readAuditLog()
+	if err != nil {
+		return err
+	}
+	var selected []auditEntry
+	cl := flagHistoryCL.Int(cmd)
+	limit := flagHistoryLimit.Int(cmd)
+	for i := len(entries) - 1; i >= 0 && len(selected) < limit; i-- {
+		if cl == 0 || entries[i].CL == cl {
+			selected = append(selected, entries[i])
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no dispatches recorded")
+		return nil
+	}
+
+	runs := make(map[string][]*github.WorkflowRun)
+	if !flagHistoryNoRuns.Bool(cmd) {
+		cfg, err := loadConfig(ctx)
+		if err != nil {
+			return err
+		}
+		runs = cfg.dispatchedRunsFor(ctx, selected)
+	}
+
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "TIME\tTYPE\tREPO\tCL\tPATCHSET\tSTATUS\tRUN")
+	for _, e := range selected {
+		typ := e.Type
+		if typ == "" {
+			typ, _, _ = strings.Cut(e.Title, " ")
+		}
+		clStr, ps := "", ""
+		if e.CL != 0 {
+			clStr, ps = strconv.Itoa(e.CL), strconv.Itoa(e.Patchset)
+		}
+		status, link := "", ""
+		if run := matchDispatchedRun(runs[e.Repo], e); run != nil {
+			status = run.GetStatus()
+			if status == "completed" {
+				status = run.GetConclusion()
+			}
+			link = run.GetHTMLURL()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04"), typ, e.Repo, clStr, ps, status, link)
+	}
+	return tw.Flush()
+}
+
+// dispatchedRunsFor returns the workflow runs triggered by a repository
+// dispatch which may have resulted from entries, keyed by repository as
+// OWNER/REPO. Failing to list the runs of a repository is reported on
+// standard error, such that what is known locally can still be listed.
+func (c *config) dispatchedRunsFor(ctx context.Context, entries []auditEntry) map[string][]*github.WorkflowRun {
+	since := make(map[string]time.Time)
+	for _, e := range entries {
+		if t, ok := since[e.Repo]; !ok || e.Time.Before(t) {
+			since[e.Repo] = e.Time
+		}
+	}
+	runs := make(map[string][]*github.WorkflowRun)
+	for _, r := range sortedKeys(since) {
+		owner, repo, _ := strings.Cut(r, "/")
+		rs, err := c.listDispatchedRuns(ctx, owner, repo, since[r])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		runs[r] = rs
+	}
+	return runs
+}
+
+// listDispatchedRuns returns the workflow runs in owner/repo triggered by a
+// repository dispatch since the given time, most recent first.
+func (c *config) listDispatchedRuns(ctx context.Context, owner, repo string, since time.Time) ([]*github.WorkflowRun, error) {
+	var res []*github.WorkflowRun
+	opts := &github.ListWorkflowRunsOptions{
+		Event:       "repository_dispatch",
+		Created:     ">=" + since.Add(-time.Minute).UTC().Format(time.RFC3339),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		runs, resp, err := c.githubClient.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
+		if err != nil {
+			return res, fmt.Errorf("failed to list workflow runs in %s/%s: %w", owner, repo, err)
+		}
+		res = append(res, runs.WorkflowRuns...)
+		if resp.NextPage == 0 {
+			return res, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// matchDispatchedRun returns the earliest of runs, which are most recent
+// first, with the display title of e created shortly after e was recorded.
+func matchDispatchedRun(runs []*github.WorkflowRun, e auditEntry) *github.WorkflowRun {
+	// Allow for clock skew between us and GitHub.
+	after := e.Time.Add(-time.Minute)
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if run.GetDisplayTitle() == e.Title && !run.GetCreatedAt().Time.Before(after) {
+			return run
+		}
+	}
+	return nil
+}
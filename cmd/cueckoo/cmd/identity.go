@@ -0,0 +1,156 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/identity"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagIdentityName   flagName = "name"
+	flagIdentityGerrit flagName = "gerrit"
+)
+
+// identitiesFile is the path, relative to the repository root, of the CUE
+// file holding the identity registry of the repository; see
+// internal/identity.
+const identitiesFile = ".github/cueckoo-identities.cue"
+
+const identitiesHeader = `The identities of contributors, linking their GitHub logins to their
+Gerrit accounts. Maintained with "cueckoo identity add".`
+
+// newIdentityCmd creates a new identity command
+func newIdentityCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "identity",
+		Short: "maintain the links between GitHub logins and Gerrit accounts",
+		Long: `
+Usage of identity:
+
+	identity add [--name NAME] [--gerrit ID] LOGIN EMAIL
+	identity list
+
+The identity registry links the GitHub logins of contributors to the email
+addresses and IDs of their Gerrit accounts. It is kept in the repository in
+` + identitiesFile + `, such that it is shared by its maintainers, and used by:
+
+	importpr     to add the PR's reviewers to the CL, and to attribute the
+	             import to the PR author when their commits use a GitHub
+	             noreply address
+	releaselog   to credit the GitHub login of authors whose commit email
+	             is not linked to their GitHub account
+	welcome      to mention the GitHub login of contributors in comments,
+	             for CLs which were not imported from a PR
+
+add links LOGIN to EMAIL, which replaces any previous email address of LOGIN
+but is kept as another of its addresses. Commit the updated file as usual.
+The account map in the user config, described in "cueckoo help importpr",
+takes precedence over the registry.
+`,
+	}
+	add := &cobra.Command{
+		Use:   "add",
+		Short: "link a GitHub login to a Gerrit account",
+		RunE:  mkRunE(c, identityAddDef),
+	}
+	addFlags := c.registerFlags(add)
+	addFlags.String(flagIdentityName, "", "the full name of the contributor")
+	addFlags.Int(flagIdentityGerrit, 0, "the ID of the contributor's Gerrit account")
+	cmd.AddCommand(add, &cobra.Command{
+		Use:   "list",
+		Short: "list the identities in the registry",
+		RunE:  mkRunE(c, identityListDef),
+	})
+	return cmd
+}
+
+func identityAddDef(cmd *Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected a GitHub login and an email address")
+	}
+	ctx := cmd.Context()
+	gitRoot, err := run(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("failed to determine git root: %w", err)
+	}
+	gitRoot = strings.TrimSpace(gitRoot)
+	reg, err := loadIdentities(ctx, gitRoot)
+	if err != nil {
+		return err
+	}
+	if err := reg.Add(args[0], identity.Identity{
+		Name:   flagIdentityName.String(cmd),
+		Email:  args[1],
+		Gerrit: flagIdentityGerrit.Int(cmd),
+	}); err != nil {
+		return err
+	}
+	path := filepath.Join(gitRoot, identitiesFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, reg.Format(identitiesHeader), 0o666); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "updated %s\n", identitiesFile)
+	return nil
+}
+
+func identityListDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("identity list does not take any arguments")
+	}
+	ctx := cmd.Context()
+	gitRoot, err := run(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("failed to determine git root: %w", err)
+	}
+	gitRoot = strings.TrimSpace(gitRoot)
+	reg, err := loadIdentities(ctx, gitRoot)
+	if err != nil {
+		return err
+	}
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "LOGIN\tNAME\tEMAIL\tGERRIT")
+	for _, login := range sortedKeys(reg.Identities) {
+		id := reg.Identities[login]
+		gerrit := "-"
+		if id.Gerrit != 0 {
+			gerrit = fmt.Sprint(id.Gerrit)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", login, orNone(id.Name), id.Email, gerrit)
+	}
+	return tw.Flush()
+}
+
+// loadIdentities returns the identity registry of the repository at gitRoot,
+// which is empty if it has none.
+func loadIdentities(ctx context.Context, gitRoot string) (*identity.Registry, error) {
+	reg := &identity.Registry{}
+	path := filepath.Join(gitRoot, identitiesFile)
+	if fileExists(path) {
+		if err := loadCUEFile(ctx, path, reg); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
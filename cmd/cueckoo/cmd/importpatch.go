@@ -0,0 +1,183 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/trailers"
+	"github.com/spf13/cobra"
+)
+
+// newImportPatchCmd creates a new importpatch command
+func newImportPatchCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "importpatch",
+		Short: "import a series of patches as pending CLs",
+		Long: `
+Usage of importpatch:
+
+	importpatch FILE.mbox|DIR
+
+importpatch applies a series of patches, as produced by git format-patch, to a
+new branch so that they can be mailed as CLs. The patches are read from an
+mbox file, or from the .patch files in a directory in name order.
+
+The new branch is named after the mbox file or directory, and tracks the
+default branch of the origin remote. Each patch is applied with git am, and
+its commit message is then fixed up: trailing whitespace and leftover
+"[PATCH]" subject prefixes are removed, duplicate trailers are dropped, and
+any existing Change-Id is replaced by a new one, such that each patch becomes
+a separate CL. The author of each patch is kept.
+
+If a patch does not apply, importpatch stops, leaving the patches applied so
+far on the branch. Once happy with the commits, mail them with:
+
+	git codereview mail
+`,
+		RunE: mkRunE(c, importPatchDef),
+	}
+	return cmd
+}
+
+func importPatchDef(cmd *Command, args []string) error {
+	log.SetPrefix("[importpatch] ")
+	log.SetFlags(0)
+
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single mbox file or directory of patches")
+	}
+	ctx := cmd.Context()
+	src := args[0]
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	patches, cleanup, err := listPatches(ctx, src, info.IsDir())
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(patches) == 0 {
+		return fmt.Errorf("no patches found in %s", src)
+	}
+
+	if out, err := run(ctx, "git", "status", "--porcelain", "--untracked-files=no"); err != nil {
+		return err
+	} else if strings.TrimSpace(out) != "" {
+		return fmt.Errorf("working tree has uncommitted changes")
+	}
+	upstream, err := run(ctx, "git", "rev-parse", "--abbrev-ref", "origin/HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to determine the default branch of origin: %w", err)
+	}
+	upstream = strings.TrimSpace(upstream)
+	base := strings.TrimSuffix(filepath.Base(filepath.Clean(src)), filepath.Ext(src))
+	branch := "importpatch-" + branchUnsafeRegex.ReplaceAllString(base, "-")
+	if _, err := run(ctx, "git", "switch", "--quiet", "--create", branch, "--track", upstream); err != nil {
+		return err
+	}
+	log.Printf("created branch %q tracking %s", branch, upstream)
+
+	for i, p := range patches {
+		if _, err := run(ctx, "git", "am", "--quiet", "--3way", p); err != nil {
+			run(ctx, "git", "am", "--abort")
+			return fmt.Errorf("patch %d of %d (%s) does not apply: %w", i+1, len(patches), filepath.Base(p), err)
+		}
+		msg, err := run(ctx, "git", "log", "-1", "--format=%B")
+		if err != nil {
+			return err
+		}
+		id, err := newChangeID()
+		if err != nil {
+			return err
+		}
+		amend := exec.CommandContext(ctx, "git", "commit", "--quiet", "--amend", "--no-verify", "-F", "-")
+		amend.Stdin = strings.NewReader(fixPatchMessage(msg, id))
+		if out, err := amend.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to fix up commit message: %v:\n%s", err, out)
+		}
+		log.Printf("applied %s", firstLine(msg))
+	}
+	log.Printf("imported %d patches; when you're happy with the commits, run: git-codereview mail", len(patches))
+	return nil
+}
+
+// listPatches returns the patch files in src, which is an mbox file or, if
+// isDir, a directory of .patch files. An mbox is split into a temporary
+// directory which is removed by cleanup.
+func listPatches(ctx context.Context, src string, isDir bool) (patches []string, cleanup func(), _ error) {
+	cleanup = func() {}
+	if isDir {
+		patches, err := filepath.Glob(filepath.Join(src, "*.patch"))
+		sort.Strings(patches)
+		return patches, cleanup, err
+	}
+	dir, err := os.MkdirTemp("", "cueckoo-importpatch-")
+	if err != nil {
+		return nil, cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	if _, err := run(ctx, "git", "mailsplit", "-o"+dir, src); err != nil {
+		return nil, cleanup, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	for _, e := range entries {
+		patches = append(patches, filepath.Join(dir, e.Name()))
+	}
+	return patches, cleanup, nil
+}
+
+var (
+	patchPrefixRegex  = regexp.MustCompile(`^(\[[^\]]*PATCH[^\]]*\]\s*)+`)
+	branchUnsafeRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// fixPatchMessage returns msg, the commit message of an applied patch, with
+// common issues fixed and its Change-Id set to changeID.
+func fixPatchMessage(msg, changeID string) string {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n") {
+		l = strings.TrimRight(l, " \t")
+		// Collapse runs of blank lines.
+		if l == "" && len(lines) > 0 && lines[len(lines)-1] == "" {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	if len(lines) > 0 {
+		lines[0] = patchPrefixRegex.ReplaceAllString(lines[0], "")
+	}
+	body, ts := trailers.Split(strings.Join(lines, "\n"))
+	var kept []trailers.Trailer
+	for _, t := range trailers.Dedupe(ts) {
+		if !strings.EqualFold(t.Key, "Change-Id") {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, trailers.Trailer{Key: "Change-Id", Value: changeID})
+	return trailers.Format(body, kept)
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "testing"
+
+func TestFixPatchMessage(t *testing.T) {
+	const id = "I0123456789abcdef0123456789abcdef01234567"
+	testCases := []struct {
+		name string
+		msg  string
+		want string
+	}{{
+		name: "SubjectOnly",
+		msg:  "cue: fix crash\n",
+		want: "cue: fix crash\n\nChange-Id: " + id + "\n",
+	}, {
+		name: "PatchPrefix",
+		msg:  "[PATCH v2 3/4] cue: fix crash  \n\n\n\nDetails here.\t\n",
+		want: "cue: fix crash\n\nDetails here.\n\nChange-Id: " + id + "\n",
+	}, {
+		name: "Trailers",
+		msg: `cue: fix crash
+
+Details.
+
+Signed-off-by: A <a@example.com>
+Change-Id: Iffffffffffffffffffffffffffffffffffffffff
+Signed-off-by: A <a@example.com>
+`,
+		want: `cue: fix crash
+
+Details.
+
+Signed-off-by: A <a@example.com>
+Change-Id: ` + id + "\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fixPatchMessage(tc.msg, id); got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
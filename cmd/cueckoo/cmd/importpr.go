@@ -16,20 +16,39 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
+	"github.com/cue-lang/contrib-tools/internal/gitcmd"
+	"github.com/cue-lang/contrib-tools/internal/identity"
+	"github.com/cue-lang/contrib-tools/internal/trailers"
+	"github.com/google/go-github/v53/github"
 	"github.com/spf13/cobra"
 )
 
 const (
-	flagUpdate flagName = "update"
+	flagUpdate         flagName = "update"
+	flagImportList     flagName = "list"
+	flagImportResume   flagName = "resume"
+	flagImportMail     flagName = "mail"
+	flagImportAnnounce flagName = "announce"
+	flagImportNoSquash flagName = "no-squash"
+
+	// importedLabel is the label added to the PRs which were imported to
+	// Gerrit.
+	importedLabel = "imported-to-gerrit"
 )
 
 // newImportPRCmd creates a new importpr command
@@ -37,9 +56,81 @@ func newImportPRCmd(c *Command) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "importpr",
 		Short: "Import GitHub PRs to Gerrit",
-		RunE:  mkRunE(c, importPRDef),
+		Long: `
+Usage of importpr:
+
+	importpr [--update] [--resume] [--force] [--mail] [--no-squash] PR...
+	importpr --announce PR...
+	importpr --list
+
+importpr fetches the given GitHub PR into a new branch, squashes its commits
+onto the target branch, and opens an editor to fix up the commit message,
+ready to be mailed to Gerrit with git-codereview mail.
+
+With --no-squash, the commits of the PR are instead rebased onto the target
+branch one by one, each with a Change-Id added, such that they are mailed as
+a relation chain of CLs, for PRs whose commits are deliberately separate. The
+trailers below are then added to the last commit of the chain, and an editor
+is opened for each commit message in turn.
+
+Multiple PRs can be imported at once, given as numbers or ranges such as
+100-105. Each is imported into its own importpr-N branch, without opening an
+editor, and the original branch is checked out again after each. A summary
+at the end says which imports succeeded and which need attention; the commit
+messages of the imported branches should be fixed up before mailing.
+
+The fetches of the PR and of the target branch are retried a few times when
+they fail. importpr records its progress in a state file in the .git
+directory, such that if a step still fails, such as a fetch when offline, or
+the editor, importpr --resume continues from that step, instead of the
+importpr branch having to be deleted to start over. If the rebase stopped due
+to a conflict, abort it with git rebase --abort before resuming.
+
+The squashed commit records the head commit of the PR in a ` + gitOriginRevIDKey + `
+trailer. Before importing a PR, Gerrit is searched for open or merged CLs
+with that trailer, or which close the PR, and importpr refuses to create a
+duplicate CL if there are any, unless --force is given.
+
+The PR's requested reviewers are added as reviewers of the CL, and its
+assignees are CCed, via the git-codereview mail command suggested at the end.
+GitHub logins are mapped to Gerrit accounts via the account map, a file of
+"LOGIN: EMAIL" lines. It lives next to the user config as "accounts", or
+wherever the "` + accountMapKey + `" entry of the user config says.
+
+With --mail, importpr runs that command itself once the commit message has
+been fixed up, and then announces the import on the PR: it comments on the PR
+with the URL of the CL, so that the contributor knows where the review
+continues, and labels the PR as ` + importedLabel + `. After mailing a CL
+by hand, importpr --announce does the same for the given PRs, finding their
+CLs as above. PRs which already have the comment are not commented on again,
+so announcing an import again after a failure is safe.
+
+With --list, importpr instead lists the open PRs along with signals of
+whether they are ready to be imported: their age, their size in lines
+changed, the state of any CLA or DCO status check, whether they merge
+cleanly, and the CL they were already imported as, if any, as found by
+searching Gerrit for the PR in commit messages. PRs which are ready come
+first, oldest first, to help pick the next ones to import.
+`,
+		RunE: mkRunE(c, importPRDef),
 	}
-	cmd.Flags().Bool(string(flagUpdate), false, "rebase against the tip of the target branch")
+	flags := c.registerFlags(cmd)
+	flags.Bool(flagUpdate, false, "rebase against the tip of the target branch")
+	flags.Bool(flagImportList, false, "list the open PRs with their import readiness")
+	flags.Bool(flagImportResume, false, "resume a failed import from the step which failed")
+	flags.Bool(flagForce, false, "import a PR even if it was already imported as a CL")
+	flags.Bool(flagImportMail, false, "mail the CL and announce the import on the PR")
+	flags.Bool(flagImportAnnounce, false, "announce on the PRs that they were imported as mailed CLs")
+	flags.Bool(flagImportNoSquash, false, "keep the commits of the PR as a chain of CLs rather than squashing them")
+	flags.Exclusive(flagImportList, flagUpdate)
+	flags.Exclusive(flagImportList, flagImportResume)
+	flags.Exclusive(flagImportList, flagForce)
+	flags.Exclusive(flagImportList, flagImportMail)
+	flags.Exclusive(flagImportList, flagImportAnnounce)
+	flags.Exclusive(flagImportAnnounce, flagImportMail)
+	flags.Exclusive(flagImportAnnounce, flagImportResume)
+	flags.Exclusive(flagImportList, flagImportNoSquash)
+	flags.NoArgsWith(flagImportList)
 	return cmd
 }
 
@@ -52,24 +143,62 @@ func importPRDef(c *Command, args []string) error {
 		return err
 	}
 
-	if len(args) != 1 {
-		return fmt.Errorf("expected a single PR number")
+	if flagImportList.Bool(c) {
+		return importPRList(c.Context(), c, cfg)
 	}
 
-	prNumber, err := strconv.Atoi(args[0])
+	prs, err := parsePRArgs(args)
+	if err != nil {
+		return err
+	}
 
-	if err != nil || prNumber <= 0 {
-		return fmt.Errorf("%q is not a valid number", prNumber)
+	if flagImportAnnounce.Bool(c) {
+		for _, n := range prs {
+			pr, _, err := cfg.githubClient.PullRequests.Get(c.Context(), cfg.githubOwner, cfg.githubRepo, n)
+			if err != nil {
+				return fmt.Errorf("could not get github PR: %v", err)
+			}
+			if err := cfg.announceImport(c.Context(), pr); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
 	log.Printf("using github remote URL %q", cfg.githubURL)
 
+	if len(prs) == 1 {
+		return importPR(c, cfg, prs[0], true)
+	}
+	if flagImportMail.Bool(c) {
+		// The commit messages of a batch are not reviewed before mailing.
+		return fmt.Errorf("--%s can only be used when importing a single PR", flagImportMail)
+	}
+	return importPRs(c, cfg, prs)
+}
+
+// importPR imports the PR prNumber into the branch importpr-N. If edit, an
+// editor is then opened to fix up the commit message.
+func importPR(c *Command, cfg *config, prNumber int, edit bool) error {
 	branchName := fmt.Sprintf("importpr-%d", prNumber)
 
-	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
-	defer cancel()
+	// There is no overall timeout, as the network steps are retried with
+	// their own timeouts.
+	ctx := c.Context()
 
-	pr, _, err := cfg.githubClient.PullRequests.Get(context.Background(), cfg.githubOwner, cfg.githubRepo, prNumber)
+	statePath, err := importStatePath(ctx, prNumber)
+	if err != nil {
+		return err
+	}
+	state := &importState{PR: prNumber, Update: flagUpdate.Bool(c), NoSquash: flagImportNoSquash.Bool(c)}
+	if flagImportResume.Bool(c) {
+		if state, err = resumeImportState(statePath, prNumber); err != nil {
+			return err
+		}
+		log.Printf("resuming the import of PR %d after step %q", prNumber, state.Step)
+	}
+
+	pr, _, err := cfg.githubClient.PullRequests.Get(ctx, cfg.githubOwner, cfg.githubRepo, prNumber)
 	if err != nil {
 		return fmt.Errorf("could not get github PR: %v", err)
 	}
@@ -78,15 +207,37 @@ func importPRDef(c *Command, args []string) error {
 		return fmt.Errorf("PR seems to have an empty base branch?")
 	}
 
-	// If the branch already exists, refuse to continue.
-	if out, err := run(ctx,
-		"git", "show-ref", "--verify", "--quiet", fmt.Sprintf("refs/heads/%s", branchName),
-	); err == nil {
-		return fmt.Errorf("branch %q already exists; delete it to start over", branchName)
-	} else if len(out) == 0 {
-		// An error without output means the branch does not exist.
-	} else {
-		return err // something else went wrong
+	// Refuse to import a PR a second time, which would create a duplicate
+	// CL. A resumed import has already been checked.
+	if state.Step == importStepStart && !flagForce.Bool(c) {
+		cls, err := cfg.findImports(pr)
+		if err != nil {
+			return err
+		}
+		if len(cls) > 0 {
+			urls := make([]string, len(cls))
+			for i, cl := range cls {
+				urls[i] = cfg.changeURL(cl)
+			}
+			return fmt.Errorf("PR %d was already imported as %s; use --%s to import it again", prNumber, strings.Join(urls, ", "), flagForce)
+		}
+	}
+
+	// If the branch already exists, refuse to continue, unless we are
+	// resuming an import which already created it.
+	if state.Step < importStepFetchedPR {
+		if out, err := run(ctx,
+			"git", "show-ref", "--verify", "--quiet", fmt.Sprintf("refs/heads/%s", branchName),
+		); err == nil {
+			if fileExists(statePath) {
+				return fmt.Errorf("branch %q already exists; use --%s to resume the import, or delete the branch to start over", branchName, flagImportResume)
+			}
+			return fmt.Errorf("branch %q already exists; delete it to start over", branchName)
+		} else if len(out) == 0 {
+			// An error without output means the branch does not exist.
+		} else {
+			return err // something else went wrong
+		}
 	}
 
 	// TODO: note that we assume that the upstream github remote is "origin".
@@ -95,30 +246,53 @@ func importPRDef(c *Command, args []string) error {
 	// we can figure out a way to remove this assumption.
 	originBaseRef := "origin/" + baseRef
 
-	// Fetch the PR HEAD and place it in a new branch, then switch to it.
-	if _, err := run(ctx,
-		"git", "fetch", "--quiet", cfg.githubURL,
-		fmt.Sprintf("pull/%d/head:%s", prNumber, branchName),
-	); err != nil {
-		return err
-	}
-	if _, err := run(ctx, "git", "switch", "--quiet", branchName); err != nil {
-		return err
+	// Each step below records its completion in the state file, such that
+	// a failed import can be resumed from the step which failed.
+	done := func(step importStep) error {
+		state.Step = step
+		return state.save(statePath)
 	}
-	log.Printf("fetched PR into branch %q", branchName)
 
-	// Extract the commit hash
-	commitHash, err := run(ctx, "git", "rev-parse", "--short", "HEAD")
-	if err != nil {
-		return fmt.Errorf("failed to establish commit hash: %w", err)
+	// Fetch the PR HEAD and place it in a new branch, then switch to it.
+	if state.Step < importStepFetchedPR {
+		if err := retryNetwork(ctx, "fetch of the PR", func(ctx context.Context) error {
+			_, err := run(ctx,
+				"git", "fetch", "--quiet", cfg.githubURL,
+				fmt.Sprintf("pull/%d/head:%s", prNumber, branchName),
+			)
+			return err
+		}); err != nil {
+			return err
+		}
+		if err := done(importStepFetchedPR); err != nil {
+			return err
+		}
+		log.Printf("fetched PR into branch %q", branchName)
 	}
-	// Remove the trailing \n
-	commitHash = strings.TrimSpace(commitHash)
 
-	// Set the branch upstream as the first step. If subsequent commands fail
-	// (they shouldn't but it can happen) we still need the upstream to have
-	// been set.
-	if _, err := run(ctx, "git", "branch", "--set-upstream-to", originBaseRef); err != nil {
+	if state.Step < importStepSetUpstream {
+		if _, err := run(ctx, "git", "switch", "--quiet", branchName); err != nil {
+			return err
+		}
+
+		// Extract the commit hash
+		commitHash, err := run(ctx, "git", "rev-parse", "--short", "HEAD")
+		if err != nil {
+			return fmt.Errorf("failed to establish commit hash: %w", err)
+		}
+		// Remove the trailing \n
+		state.CommitHash = strings.TrimSpace(commitHash)
+
+		// Set the branch upstream as the first step. If subsequent commands fail
+		// (they shouldn't but it can happen) we still need the upstream to have
+		// been set.
+		if _, err := run(ctx, "git", "branch", "--set-upstream-to", originBaseRef); err != nil {
+			return err
+		}
+		if err := done(importStepSetUpstream); err != nil {
+			return err
+		}
+	} else if _, err := run(ctx, "git", "switch", "--quiet", branchName); err != nil {
 		return err
 	}
 
@@ -131,29 +305,57 @@ func importPRDef(c *Command, args []string) error {
 	//
 	// When the --update flag is passed, we perform the same rebase (to squash
 	// commits) but against the tip of the target branch instead of the merge
-	// base.
-	if _, err := run(ctx, "git", "fetch", "--quiet", cfg.githubURL, baseRef); err != nil {
-		return err
-	}
-	rebaseMsg := "tip of target branch"
-	rebasePoint := "FETCH_HEAD"
-	if !flagUpdate.Bool(c) {
-		// We need to work out the mergebase
-		out, err := run(ctx, "git", "merge-base", originBaseRef, branchName)
+	// base. As FETCH_HEAD does not survive a failed run, the base is fetched
+	// again when resuming.
+	if state.Step < importStepRebased {
+		if err := retryNetwork(ctx, "fetch of the base branch", func(ctx context.Context) error {
+			return gitcmd.New("").Fetch(ctx, cfg.githubURL, 0, baseRef)
+		}); err != nil {
+			return err
+		}
+		if err := done(importStepFetchedBase); err != nil {
+			return err
+		}
+		rebaseMsg := "tip of target branch"
+		rebasePoint := "FETCH_HEAD"
+		if !state.Update {
+			// We need to work out the mergebase
+			out, err := run(ctx, "git", "merge-base", originBaseRef, branchName)
+			if err != nil {
+				return fmt.Errorf("failed to determine merge base %w", err)
+			}
+			rebaseMsg = "existing merge-base"
+			rebasePoint = strings.TrimSpace(out)
+		}
+		base, err := run(ctx, "git", "rev-parse", rebasePoint)
 		if err != nil {
-			return fmt.Errorf("failed to determine merge base %w", err)
+			return err
+		}
+		state.Base = strings.TrimSpace(base)
+		if state.NoSquash {
+			self, err := os.Executable()
+			if err != nil {
+				return err
+			}
+			if err := rebaseChain(ctx, self, state.Base); err != nil {
+				return err
+			}
+		} else if _, err := run(ctx, "git",
+			"-c", "core.editor=cat",
+			"-c", `sequence.editor=sed -i -e '2,$s/^pick/squash/'`,
+			"rebase", "--interactive", state.Base,
+		); err != nil {
+			return err
+		}
+		if err := done(importStepRebased); err != nil {
+			return err
+		}
+		if state.NoSquash {
+			log.Printf("rebased on %s, keeping the commits as a chain", rebaseMsg)
+		} else {
+			log.Printf("rebased and squashed on %s", rebaseMsg)
 		}
-		rebaseMsg = "existing merge-base"
-		rebasePoint = strings.TrimSpace(out)
-	}
-	if _, err := run(ctx, "git",
-		"-c", "core.editor=cat",
-		"-c", `sequence.editor=sed -i -e '2,$s/^pick/squash/'`,
-		"rebase", "--interactive", rebasePoint,
-	); err != nil {
-		return err
 	}
-	log.Printf("rebased and squashed on %s", rebaseMsg)
 
 	// TODO: fix up common commit message issues, especially when squashing, in Go code.
 
@@ -161,26 +363,29 @@ func importPRDef(c *Command, args []string) error {
 	// up adding a Change-Id trailer if the user has git commit hooks set for
 	// post-commit. This means that the Changed-ID will be visible in the commit
 	// message when it comes to final human-edit of the commit message below.
-	msg, err := run(ctx, "git", "log", "--pretty=%B", "-1")
+	idents, err := loadIdentities(ctx, cfg.gitRoot)
 	if err != nil {
 		return err
 	}
-	msg, err = addClosesMsg(msg, prNumber, commitHash)
-	if err != nil {
-		return err
-	}
-	addClosesCmd := exec.CommandContext(context.Background(), "git", "commit", "--quiet", "--amend", "-F", "-")
-	addClosesCmd.Stdin = strings.NewReader(msg)
-	addClosesCmd.Stdout = os.Stdout
-	addClosesCmd.Stderr = os.Stderr
-	if err := addClosesCmd.Run(); err != nil {
-		return err
+	if state.Step < importStepAmended {
+		if err := amendHeadMessage(ctx, func(msg string) (string, error) {
+			msg, err := addClosesMsg(msg, prNumber, state.CommitHash)
+			if err != nil {
+				return "", err
+			}
+			msg, err = cfg.addProvenance(ctx, msg, pr, idents)
+			if err != nil {
+				return "", err
+			}
+			return addOriginRevID(msg, pr.GetHead().GetSHA()), nil
+		}); err != nil {
+			return err
+		}
+		if err := done(importStepAmended); err != nil {
+			return err
+		}
 	}
 
-	// TODO: add a header (Change-Id or GitOrigin-RevId? see
-	// https://cue-review.googlesource.com/c/cue/+/9781) to ensure that we don't
-	// import the same PR multiple times into different CLs.
-
 	// Amend the squashed commit message manually.
 	// More often than not, we'll want to tweak commit messages to follow
 	// https://github.com/cue-lang/cue/blob/HEAD/doc/contribute.md#good-commit-messages.
@@ -188,22 +393,344 @@ func importPRDef(c *Command, args []string) error {
 	// Note that we forward stdin/out/err for terminal editors like vim.
 	// TODO: also add the PR title and description above the commit messages if
 	// we squashed, because some people put the info there.
-	log.Printf("opening editor to fix up commit message...")
-	editCmd := exec.CommandContext(context.Background(), "git", "commit", "--quiet", "--amend")
-	editCmd.Stdin = os.Stdin
-	editCmd.Stdout = os.Stdout
-	editCmd.Stderr = os.Stderr
-	if err := editCmd.Run(); err != nil {
+	switch {
+	case edit && state.NoSquash:
+		log.Printf("opening editor to fix up each commit message...")
+		if err := runInteractive(ctx, "git",
+			"-c", `sequence.editor=sed -i -e 's/^pick/reword/'`,
+			"rebase", "--interactive", state.Base,
+		); err != nil {
+			return err
+		}
+	case state.NoSquash:
+		log.Printf("fix up the commit messages of branch %q with git rebase --interactive %s", branchName, state.Base)
+	case edit:
+		log.Printf("opening editor to fix up commit message...")
+		editCmd := exec.CommandContext(context.Background(), "git", "commit", "--quiet", "--amend")
+		editCmd.Stdin = os.Stdin
+		editCmd.Stdout = os.Stdout
+		editCmd.Stderr = os.Stderr
+		if err := editCmd.Run(); err != nil {
+			return err
+		}
+	default:
+		log.Printf("fix up the commit message of branch %q with git commit --amend", branchName)
+	}
+	if err := os.Remove(statePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return err
 	}
 
-	log.Printf("When you're happy with the commit, run: git-codereview mail")
-	log.Printf("Remember to ensure that the commit contains:")
-	log.Printf("\tFixes #N. (if it fixes an open issue)")
+	// Carry over the review routing intended by the contributor. The CL
+	// does not exist until it is mailed, so we can only suggest how to mail
+	// it.
+	accounts, err := loadAccountMap(idents)
+	if err != nil {
+		return fmt.Errorf("failed to load account map: %v", err)
+	}
+	reviewers, cc, unmapped := prReviewRouting(pr, accounts)
+	if len(unmapped) > 0 {
+		log.Printf("no Gerrit account known for PR reviewers or assignees %s; see %q in cueckoo help importpr", strings.Join(unmapped, ", "), accountMapKey)
+	}
+	if !edit || !flagImportMail.Bool(c) {
+		log.Printf("When you're happy with the commit, run: %s", mailCommand(reviewers, cc))
+		log.Printf("Then announce the import on the PR with: cueckoo importpr --%s %d", flagImportAnnounce, prNumber)
+		log.Printf("Remember to ensure that the commit contains:")
+		log.Printf("\tFixes #N. (if it fixes an open issue)")
+		return nil
+	}
+	args := mailArgs(reviewers, cc)
+	log.Printf("mailing the CL with: %s", strings.Join(args, " "))
+	if err := runInteractive(ctx, args[0], args[1:]...); err != nil {
+		return fmt.Errorf("failed to mail the CL: %v", err)
+	}
+	return cfg.announceImport(ctx, pr)
+}
+
+// announceImport labels pr with importedLabel and comments on it with the URL
+// of the CL it was imported as, such that the contributor knows where its
+// review continues. The label is added first, as adding it again is harmless,
+// and the comment is skipped if the PR already has it, so that announcing an
+// import again after a failure neither comments twice nor misses the label.
+func (c *config) announceImport(ctx context.Context, pr *github.PullRequest) error {
+	n := pr.GetNumber()
+	cls, err := c.findImports(pr)
+	if err != nil {
+		return err
+	}
+	if len(cls) == 0 {
+		return fmt.Errorf("no CL found for PR %d; mail it with git-codereview mail first", n)
+	}
+	// Changes are listed most recently updated first.
+	clURL := c.changeURL(cls[0])
+	body := importComment(clURL)
+	if _, _, err := c.githubClient.Issues.AddLabelsToIssue(ctx, c.githubOwner, c.githubRepo, n, []string{importedLabel}); err != nil {
+		return fmt.Errorf("failed to label PR %d: %w", n, err)
+	}
+	commented, err := c.hasComment(ctx, n, body)
+	if err != nil {
+		return err
+	}
+	if commented {
+		log.Printf("PR %d already has a comment announcing %s; not commenting again", n, clURL)
+		return nil
+	}
+	if _, _, err := c.githubClient.Issues.CreateComment(ctx, c.githubOwner, c.githubRepo, n, &github.IssueComment{Body: &body}); err != nil {
+		return fmt.Errorf("failed to comment on PR %d: %w", n, err)
+	}
+	log.Printf("announced the import of PR %d as %s", n, clURL)
 	return nil
 }
 
+// hasComment reports whether the issue or PR number n has a comment with
+// exactly the given body.
+func (c *config) hasComment(ctx context.Context, n int, body string) (bool, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := c.githubClient.Issues.ListComments(ctx, c.githubOwner, c.githubRepo, n, opts)
+		if err != nil {
+			return false, fmt.Errorf("failed to list comments on PR %d: %w", n, err)
+		}
+		for _, cm := range comments {
+			if cm.GetBody() == body {
+				return true, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return false, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// importComment is the comment posted on a PR which was imported as the CL
+// at url.
+func importComment(url string) string {
+	return fmt.Sprintf("Thank you for your contribution! This PR has been imported to Gerrit as %s, "+
+		"where its review will continue. Please follow the review there.", url)
+}
+
+// maxPRRange is the largest number of PRs which a range such as 100-105 may
+// give, to catch typos such as 100-1050.
+const maxPRRange = 50
+
+// parsePRArgs parses the PR numbers and ranges of PR numbers, such as
+// 100-105, given as arguments to importpr. Duplicates are removed.
+func parsePRArgs(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expected at least one PR number")
+	}
+	var prs []int
+	add := func(n int) {
+		if !slicesContains(prs, n) {
+			prs = append(prs, n)
+		}
+	}
+	for _, arg := range args {
+		from, to, isRange := strings.Cut(arg, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil || first <= 0 {
+			return nil, fmt.Errorf("%q is not a valid PR number or range", arg)
+		}
+		last := first
+		if isRange {
+			last, err = strconv.Atoi(to)
+			if err != nil || last < first {
+				return nil, fmt.Errorf("%q is not a valid PR number or range", arg)
+			}
+			if last-first >= maxPRRange {
+				return nil, fmt.Errorf("range %q has more than %d PRs", arg, maxPRRange)
+			}
+		}
+		for n := first; n <= last; n++ {
+			add(n)
+		}
+	}
+	return prs, nil
+}
+
+// importPRs imports each of prs into its own branch, as importPR does but
+// without opening an editor, and then summarizes the outcome of each. After
+// each import, or failed import, the original branch is checked out again.
+func importPRs(c *Command, cfg *config, prs []int) error {
+	ctx := c.Context()
+	orig, err := run(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+	orig = strings.TrimSpace(orig)
+	checkout := []string{"git", "switch", "--quiet", orig}
+	if orig == "HEAD" {
+		// Detached, so return to the commit instead.
+		if orig, err = run(ctx, "git", "rev-parse", "HEAD"); err != nil {
+			return err
+		}
+		orig = strings.TrimSpace(orig)
+		checkout = []string{"git", "switch", "--quiet", "--detach", orig}
+	}
+
+	errs := make([]error, len(prs))
+	failed := 0
+	for i, pr := range prs {
+		log.Printf("importing PR %d (%d of %d)", pr, i+1, len(prs))
+		errs[i] = importPR(c, cfg, pr, false)
+		if errs[i] != nil {
+			failed++
+			log.Printf("failed to import PR %d: %v", pr, errs[i])
+			// Leave the repository as we found it for the next import,
+			// such as when a rebase stopped due to a conflict.
+			if path, err := run(ctx, "git", "rev-parse", "--git-path", "rebase-merge"); err == nil && fileExists(strings.TrimSpace(path)) {
+				if _, err := run(ctx, "git", "rebase", "--abort"); err != nil {
+					return fmt.Errorf("failed to abort the rebase of PR %d: %w", pr, err)
+				}
+			}
+		}
+		if _, err := run(ctx, checkout[0], checkout[1:]...); err != nil {
+			return fmt.Errorf("failed to check out %s again: %w", orig, err)
+		}
+	}
+
+	tw := newTableWriter(c.OutOrStdout())
+	fmt.Fprintln(tw, "PR\tBRANCH\tRESULT")
+	for i, pr := range prs {
+		result := "imported"
+		if errs[i] != nil {
+			result = fmt.Sprintf("needs attention: %s; retry with importpr --%s %d", firstLine(errs[i].Error()), flagImportResume, pr)
+		}
+		fmt.Fprintf(tw, "%d\timportpr-%d\t%s\n", pr, pr, result)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed < len(prs):
+		return &partialFailureError{failed: failed, total: len(prs)}
+	default:
+		return fmt.Errorf("failed to import all %d PRs", failed)
+	}
+}
+
+// importStep is a step of importpr which has been completed, in order.
+type importStep int
+
+const (
+	importStepStart importStep = iota
+	importStepFetchedPR
+	importStepSetUpstream
+	importStepFetchedBase
+	importStepRebased
+	importStepAmended
+)
+
+var importStepNames = [...]string{
+	importStepStart:       "start",
+	importStepFetchedPR:   "fetch PR",
+	importStepSetUpstream: "set upstream",
+	importStepFetchedBase: "fetch base",
+	importStepRebased:     "rebase",
+	importStepAmended:     "add trailers",
+}
+
+func (s importStep) String() string {
+	if s < 0 || int(s) >= len(importStepNames) {
+		return fmt.Sprintf("importStep(%d)", int(s))
+	}
+	return importStepNames[s]
+}
+
+// importState is the progress of importpr for a PR, saved after each step
+// such that a failed import can be resumed.
+type importState struct {
+	PR         int        `json:"pr"`
+	Step       importStep `json:"step"`
+	Update     bool       `json:"update"`
+	CommitHash string     `json:"commitHash,omitempty"`
+	NoSquash   bool       `json:"noSquash,omitempty"`
+
+	// Base is the commit which the PR was rebased onto.
+	Base string `json:"base,omitempty"`
+}
+
+// importStatePath returns the path of the state file for importing pr,
+// which lives in the .git directory of the current repository.
+func importStatePath(ctx context.Context, pr int) (string, error) {
+	out, err := run(ctx, "git", "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(strings.TrimSpace(out), fmt.Sprintf("cueckoo-importpr-%d.json", pr)), nil
+}
+
+// load loads the state saved at path, if any.
+func (s *importState) load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return nil
+}
+
+func (s *importState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o666)
+}
+
+// resumeImportState returns the state of the import of pr saved at path, to
+// resume it. A resumed import carries on as it started, such as keeping the
+// commits of the PR as a chain, whichever flags are given when resuming.
+func resumeImportState(path string, pr int) (*importState, error) {
+	state := &importState{PR: pr}
+	if err := state.load(path); err != nil {
+		return nil, err
+	}
+	if state.Step == importStepStart {
+		return nil, fmt.Errorf("there is no import of PR %d to resume", pr)
+	}
+	return state, nil
+}
+
+const (
+	// networkAttempts is how many times retryNetwork tries a step.
+	networkAttempts = 3
+
+	// networkTimeout bounds each attempt of retryNetwork.
+	networkTimeout = time.Minute
+)
+
+// retryNetwork runs f, which performs the network step described by what,
+// retrying it with a growing delay if it fails, as fetches can fail
+// transiently. Each attempt is given its own timeout.
+func retryNetwork(ctx context.Context, what string, f func(context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		actx, cancel := context.WithTimeout(ctx, networkTimeout)
+		err := f(actx)
+		cancel()
+		if err == nil || attempt >= networkAttempts || ctx.Err() != nil {
+			return err
+		}
+		log.Printf("%s failed; retrying (attempt %d of %d): %v", what, attempt+1, networkAttempts, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * 2 * time.Second):
+		}
+	}
+}
+
 func run(ctx context.Context, name string, args ...string) (string, error) {
+	if err := checkReadOnlyCommand(name, args...); err != nil {
+		return "", err
+	}
 	cmd := exec.CommandContext(ctx, name, args...)
 	out, err := cmd.Output()
 	if err != nil {
@@ -216,6 +743,136 @@ func run(ctx context.Context, name string, args ...string) (string, error) {
 	return string(out), err
 }
 
+// rebaseChain rebases the commits of the current branch onto base, keeping
+// them as a chain, and gives each commit its own Change-Id by running self,
+// the cueckoo executable, as "cueckoo changeid --amend". The daemon is
+// disabled for those runs: when importpr itself runs in the daemon, which
+// serves one command at a time, they would wait for it forever.
+func rebaseChain(ctx context.Context, self, base string) error {
+	g := gitcmd.Exec{Env: []string{"CUECKOO_DAEMON=off"}}
+	_, err := g.Run(ctx, "rebase", "--exec", shellQuote(self)+" changeid --amend", base)
+	return err
+}
+
+// amendHeadMessage rewrites the message of the commit at HEAD with edit. When
+// importing a PR as a chain of commits, only the last one is amended, such
+// that the trailers recording the import are added once.
+func amendHeadMessage(ctx context.Context, edit func(msg string) (string, error)) error {
+	msg, err := run(ctx, "git", "log", "--pretty=%B", "-1")
+	if err != nil {
+		return err
+	}
+	if msg, err = edit(msg); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "git", "commit", "--quiet", "--amend", "-F", "-")
+	cmd.Stdin = strings.NewReader(msg)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// shellQuote quotes s as a single word for a POSIX shell, such as for the
+// commands run by git rebase --exec.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// provenanceTrailers are the trailers which importpr can add to record the
+// origin of an imported commit, in the order they are added:
+//
+//	PR-URL: https://github.com/OWNER/REPO/pull/N
+//	Imported-By: Name <email>
+//	Original-Author: Name <email>
+//
+// Imported-By is the user running importpr, and Original-Author is the author
+// of the squashed commit, which is that of the first commit in the PR. When
+// that author used a GitHub noreply address, the PR author's identity from
+// the registry is used instead, if known.
+var provenanceTrailers = []string{"PR-URL", "Imported-By", "Original-Author"}
+
+// addProvenance adds the provenance trailers selected in the codereview
+// config to the message msg of the commit at HEAD, imported from pr, using
+// the identity registry idents.
+func (c *config) addProvenance(ctx context.Context, msg string, pr *github.PullRequest, idents *identity.Registry) (string, error) {
+	body, ts := trailers.Split(msg)
+	for _, key := range c.importTrailers {
+		var value string
+		switch key {
+		case "PR-URL":
+			value = pr.GetHTMLURL()
+		case "Imported-By":
+			name, err := run(ctx, "git", "config", "user.name")
+			if err != nil {
+				return "", err
+			}
+			email, err := run(ctx, "git", "config", "user.email")
+			if err != nil {
+				return "", err
+			}
+			value = fmt.Sprintf("%s <%s>", strings.TrimSpace(name), strings.TrimSpace(email))
+		case "Original-Author":
+			out, err := run(ctx, "git", "log", "-1", "--format=%an <%ae>", "HEAD")
+			if err != nil {
+				return "", err
+			}
+			value = strings.TrimSpace(out)
+			if strings.HasSuffix(value, "@users.noreply.github.com>") {
+				if id, ok := idents.ByGitHub(pr.GetUser().GetLogin()); ok {
+					value = id.NameEmail()
+				}
+			}
+		default:
+			return "", fmt.Errorf("unknown trailer %q in %s; must be one of %s", key, importTrailersKey, strings.Join(provenanceTrailers, ", "))
+		}
+		ts = append(ts, trailers.Trailer{Key: key, Value: value})
+	}
+	return trailers.Format(body, trailers.Dedupe(ts)), nil
+}
+
+// gitOriginRevIDKey is the trailer recording the head commit of the PR which
+// a commit was imported from, as done by tools such as Copybara.
+const gitOriginRevIDKey = "GitOrigin-RevId"
+
+// addOriginRevID adds the GitOrigin-RevId trailer for the PR head commit sha
+// to the commit message msg.
+func addOriginRevID(msg, sha string) string {
+	body, ts := trailers.Split(msg)
+	ts = append(ts, trailers.Trailer{Key: gitOriginRevIDKey, Value: sha})
+	return trailers.Format(body, trailers.Dedupe(ts))
+}
+
+// importQuery returns the Gerrit query for the open or merged CLs in project
+// which were imported from the PR number pr with the head commit sha: either
+// with the GitOrigin-RevId trailer, or for imports predating the trailer, by
+// the message closing the PR.
+//
+// Note that the trailer is keyed on the head commit of the PR, not on the PR
+// itself, so it only finds imports of that head commit. Imports of earlier
+// heads, from before further pushes to the PR, are found by the message
+// closing the PR, which importpr adds to every import.
+func importQuery(project string, pr int, sha string) string {
+	return fmt.Sprintf("project:%s (status:open OR status:merged) (message:%q OR message:%q)", project,
+		gitOriginRevIDKey+": "+sha, fmt.Sprintf("Closes #%d as merged", pr))
+}
+
+// findImports returns the numbers of the open or merged CLs which pr was
+// already imported as; see importQuery.
+func (c *config) findImports(pr *github.PullRequest) ([]int, error) {
+	var changes []struct {
+		Number int `json:"_number"`
+	}
+	query := importQuery(c.gerritProject(), pr.GetNumber(), pr.GetHead().GetSHA())
+	if err := c.gerritDo(http.MethodGet, "changes/?q="+url.QueryEscape(query), nil, &changes); err != nil {
+		return nil, fmt.Errorf("failed to search for imports of PR #%d: %w", pr.GetNumber(), err)
+	}
+	cls := make([]int, len(changes))
+	for i, ch := range changes {
+		cls[i] = ch.Number
+	}
+	return cls, nil
+}
+
 // addClosesMsg adds the message to "Closes #pr as merged." to the commit message
 // msg.  It respects trailers and leaves a newline at the end of the message.
 // Like git it respects the last block of trailers.
@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v53/github"
 )
 
 func TestAddCloses(t *testing.T) {
@@ -49,3 +57,220 @@ Signed-off-by: Paul
 		})
 	}
 }
+
+func TestPRReviewRouting(t *testing.T) {
+	user := func(login string) *github.User { return &github.User{Login: github.String(login)} }
+	pr := &github.PullRequest{
+		RequestedReviewers: []*github.User{user("alice"), user("bob"), user("carol")},
+		Assignees:          []*github.User{user("alice"), user("dave")},
+		RequestedTeams:     []*github.Team{{Slug: github.String("core")}},
+	}
+	accounts := map[string]string{
+		"alice": "alice@example.com",
+		"bob":   "bob@example.com",
+		"dave":  "dave@example.com",
+	}
+	reviewers, cc, unmapped := prReviewRouting(pr, accounts)
+	if diff := cmp.Diff([]string{"alice@example.com", "bob@example.com"}, reviewers); diff != "" {
+		t.Errorf("reviewers (-want +got):\n%s", diff)
+	}
+	// alice is already a reviewer, so is not CCed as well.
+	if diff := cmp.Diff([]string{"dave@example.com"}, cc); diff != "" {
+		t.Errorf("cc (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"carol", "team core"}, unmapped); diff != "" {
+		t.Errorf("unmapped (-want +got):\n%s", diff)
+	}
+	want := "git-codereview mail -r alice@example.com,bob@example.com -cc dave@example.com"
+	if got := mailCommand(reviewers, cc); got != want {
+		t.Errorf("got mail command %q; want %q", got, want)
+	}
+}
+
+func TestSortImportCandidates(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	candidates := []importCandidate{
+		{number: 1, created: day(1), imported: 5001},
+		{number: 2, created: day(2), cla: "failure"},
+		{number: 3, created: day(5), cla: "success", mergeable: "yes"},
+		{number: 4, created: day(3), mergeable: "unknown"},
+		{number: 5, created: day(4), mergeable: "no"},
+	}
+	sortImportCandidates(candidates)
+	var got []int
+	for _, ic := range candidates {
+		got = append(got, ic.number)
+	}
+	if diff := cmp.Diff([]int{4, 3, 1, 2, 5}, got); diff != "" {
+		t.Errorf("order (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	var s importState
+	if err := s.load(path); err != nil {
+		t.Fatalf("loading missing state: %v", err)
+	}
+	if s.Step != importStepStart {
+		t.Errorf("got step %q for missing state; want %q", s.Step, importStepStart)
+	}
+	want := importState{PR: 12, Step: importStepRebased, Update: true, CommitHash: "a01b2c3d", NoSquash: true, Base: "b1c2d3e4"}
+	if err := want.save(path); err != nil {
+		t.Fatal(err)
+	}
+	var got importState
+	if err := got.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("state (-want +got):\n%s", diff)
+	}
+	if got, want := importStepRebased.String(), "rebase"; got != want {
+		t.Errorf("got step name %q; want %q", got, want)
+	}
+}
+
+func TestResumeImportState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if _, err := resumeImportState(path, 12); err == nil {
+		t.Errorf("resuming without a saved state succeeded")
+	}
+	for _, noSquash := range []bool{true, false} {
+		saved := importState{PR: 12, Step: importStepRebased, NoSquash: noSquash, Base: "b1c2d3e4"}
+		if err := saved.save(path); err != nil {
+			t.Fatal(err)
+		}
+		got, err := resumeImportState(path, 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(saved, *got); diff != "" {
+			t.Errorf("resumed state (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestImportChain(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "gopher")
+	t.Setenv("GIT_AUTHOR_EMAIL", "gopher@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "gopher")
+	t.Setenv("GIT_COMMITTER_EMAIL", "gopher@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("CUECKOO_DAEMON", "")
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	git("commit", "--quiet", "--allow-empty", "-m", "base")
+	base := git("rev-parse", "HEAD")
+	for _, name := range []string{"one", "two", "three"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		git("commit", "--quiet", "-m", "add "+name)
+	}
+
+	// Stand in for cueckoo changeid --amend, recording how it was run.
+	self := filepath.Join(t.TempDir(), "cueckoo")
+	script := `#!/bin/sh
+git commit --quiet --amend -m "$(git log -1 --format=%B)" -m "Args: $*" -m "Daemon: $CUECKOO_DAEMON"
+`
+	if err := os.WriteFile(self, []byte(script), 0o777); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	ctx := context.Background()
+	if err := rebaseChain(ctx, self, base); err != nil {
+		t.Fatal(err)
+	}
+	if err := amendHeadMessage(ctx, func(msg string) (string, error) {
+		return addOriginRevID(msg, "a01b2c3d4e5f"), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := strings.Split(git("log", "--format=%B%x00", base+"..HEAD"), "\x00")
+	if len(msgs) != 4 || strings.TrimSpace(msgs[3]) != "" {
+		t.Fatalf("got %d commits; want 3:\n%q", len(msgs)-1, msgs)
+	}
+	for i, msg := range msgs[:3] {
+		// Each commit of the chain gets its own Change-Id, with the
+		// daemon disabled.
+		if !strings.Contains(msg, "Args: changeid --amend") || !strings.Contains(msg, "Daemon: off") {
+			t.Errorf("commit %d was not amended as by changeid with the daemon off:\n%s", i, msg)
+		}
+		// Only the last commit, listed first, records the import.
+		if got, want := strings.Contains(msg, "GitOrigin-RevId: a01b2c3d4e5f"), i == 0; got != want {
+			t.Errorf("commit %d has GitOrigin-RevId: %v; want %v:\n%s", i, got, want, msg)
+		}
+	}
+}
+
+func TestParsePRArgs(t *testing.T) {
+	got, err := parsePRArgs([]string{"7", "100-103", "102", "5-5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int{7, 100, 101, 102, 103, 5}, got); diff != "" {
+		t.Errorf("PRs (-want +got):\n%s", diff)
+	}
+	for _, args := range [][]string{nil, {"0"}, {"x"}, {"105-100"}, {"100-"}, {"1-1000"}} {
+		if _, err := parsePRArgs(args); err == nil {
+			t.Errorf("parsePRArgs(%q) succeeded; want an error", args)
+		}
+	}
+}
+
+func TestAddOriginRevID(t *testing.T) {
+	msg := "cue: fix a bug\n\nCloses #12 as merged as of commit a01b2c3.\n\nSigned-off-by: Alice <alice@example.com>\n"
+	want := "cue: fix a bug\n\nCloses #12 as merged as of commit a01b2c3.\n\nSigned-off-by: Alice <alice@example.com>\nGitOrigin-RevId: a01b2c3d4e5f\n"
+	if got := addOriginRevID(msg, "a01b2c3d4e5f"); got != want {
+		t.Errorf("got message:\n%s\nwant:\n%s", got, want)
+	}
+	// Resuming an import must not add the trailer twice.
+	if got := addOriginRevID(want, "a01b2c3d4e5f"); got != want {
+		t.Errorf("got message after adding twice:\n%s\nwant:\n%s", got, want)
+	}
+
+	got := importQuery("cue", 12, "a01b2c3d4e5f")
+	wantQuery := `project:cue (status:open OR status:merged) (message:"GitOrigin-RevId: a01b2c3d4e5f" OR message:"Closes #12 as merged")`
+	if got != wantQuery {
+		t.Errorf("got query %q; want %q", got, wantQuery)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for s, want := range map[string]string{
+		"/usr/bin/cueckoo":       `'/usr/bin/cueckoo'`,
+		"/home/o'brien/cueckoo":  `'/home/o'\''brien/cueckoo'`,
+		"/path with spaces/tool": `'/path with spaces/tool'`,
+	} {
+		if got := shellQuote(s); got != want {
+			t.Errorf("shellQuote(%q) = %s; want %s", s, got, want)
+		}
+	}
+}
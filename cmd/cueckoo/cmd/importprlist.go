@@ -0,0 +1,164 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/sync/errgroup"
+)
+
+// importCandidate is an open PR along with the signals of whether it is
+// ready to be imported.
+type importCandidate struct {
+	number  int
+	title   string
+	created time.Time
+
+	// size is the number of lines added and deleted.
+	size int
+
+	// cla is the state of the CLA or DCO status check of the head commit,
+	// such as "success" or "failure", or empty if there is none.
+	cla string
+
+	// mergeable is "yes", "no", or "unknown" while GitHub computes it.
+	mergeable string
+
+	// imported is the number of a CL which the PR was imported as, if any.
+	imported int
+}
+
+// ready reports whether nothing is known to stand in the way of importing
+// the PR.
+func (ic importCandidate) ready() bool {
+	return ic.imported == 0 && ic.cla != "failure" && ic.cla != "error" && ic.mergeable != "no"
+}
+
+// importPRList lists the open PRs of the repository with their import
+// readiness, in the order of sortImportCandidates.
+func importPRList(ctx context.Context, cmd *Command, cfg *config) error {
+	var prs []*github.PullRequest
+	opts := &github.PullRequestListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := cfg.githubClient.PullRequests.List(ctx, cfg.githubOwner, cfg.githubRepo, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list PRs: %w", err)
+		}
+		prs = append(prs, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	candidates := make([]importCandidate, len(prs))
+	prog := newProgress(os.Stderr, "inspected", len(prs))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(8)
+	for i, pr := range prs {
+		i, pr := i, pr
+		g.Go(func() error {
+			name := fmt.Sprintf("#%d", pr.GetNumber())
+			prog.begin(name)
+			var err error
+			candidates[i], err = cfg.inspectImportCandidate(gctx, pr.GetNumber())
+			prog.end(name, err)
+			return err
+		})
+	}
+	err := g.Wait()
+	prog.finish()
+	if err != nil {
+		return err
+	}
+	sortImportCandidates(candidates)
+	printImportCandidates(cmd.OutOrStdout(), candidates, time.Now())
+	return nil
+}
+
+// inspectImportCandidate gathers the import readiness signals of PR n. The
+// PR is fetched on its own as only then does GitHub report its size and
+// mergeability.
+func (c *config) inspectImportCandidate(ctx context.Context, n int) (importCandidate, error) {
+	pr, _, err := c.githubClient.PullRequests.Get(ctx, c.githubOwner, c.githubRepo, n)
+	if err != nil {
+		return importCandidate{}, fmt.Errorf("failed to get PR #%d: %w", n, err)
+	}
+	ic := importCandidate{
+		number:    n,
+		title:     pr.GetTitle(),
+		created:   pr.GetCreatedAt().Time,
+		size:      pr.GetAdditions() + pr.GetDeletions(),
+		mergeable: "unknown",
+	}
+	if pr.Mergeable != nil {
+		ic.mergeable = yesNo(pr.GetMergeable())
+	}
+
+	status, _, err := c.githubClient.Repositories.GetCombinedStatus(ctx, c.githubOwner, c.githubRepo, pr.GetHead().GetSHA(), nil)
+	if err != nil {
+		return importCandidate{}, fmt.Errorf("failed to get status of PR #%d: %w", n, err)
+	}
+	for _, s := range status.Statuses {
+		if name := strings.ToLower(s.GetContext()); strings.Contains(name, "cla") || strings.Contains(name, "dco") {
+			ic.cla = s.GetState()
+		}
+	}
+
+	cls, err := c.findImports(pr)
+	if err != nil {
+		return importCandidate{}, err
+	}
+	if len(cls) > 0 {
+		// Changes are listed most recently updated first.
+		ic.imported = cls[0]
+	}
+	return ic, nil
+}
+
+// sortImportCandidates orders the PRs which are ready to be imported first,
+// and the oldest first within that, such that contributors who have waited
+// longest are seen to first.
+func sortImportCandidates(candidates []importCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.ready() != cj.ready() {
+			return ci.ready()
+		}
+		return ci.created.Before(cj.created)
+	})
+}
+
+func printImportCandidates(w io.Writer, candidates []importCandidate, now time.Time) {
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "PR\tAGE\tSIZE\tCLA\tMERGEABLE\tIMPORTED\tTITLE")
+	for _, ic := range candidates {
+		imported := "-"
+		if ic.imported != 0 {
+			imported = fmt.Sprintf("CL %d", ic.imported)
+		}
+		fmt.Fprintf(tw, "#%d\t%s\t%d\t%s\t%s\t%s\t%s\n", ic.number, formatAge(now.Sub(ic.created)), ic.size,
+			orNone(ic.cla), ic.mergeable, imported, ic.title)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/codereviewcfg"
+	"github.com/spf13/cobra"
+)
+
+// newInitCmd creates a new init command
+func newInitCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "set up a clone for contributing, step by step",
+		Long: `
+Usage of init:
+
+	init
+
+init walks a new contributor through setting up their clone of the
+repository, as described by the setup section of contribute.md, asking
+before changing anything:
+
+	clone         the clone has a codereview.cfg naming its Gerrit server and
+	              GitHub repository
+	git-codereview
+	              git-codereview is installed, or is installed with go install
+	hooks         the git-codereview hooks are installed, which add a
+	              Change-Id to each commit
+	gerrit-credentials
+	github-credentials
+	              credentials are available from the environment or from a
+	              git credential helper; git prompts for them if needed, and
+	              they are then stored in the helper
+	config        cueckoo can load its configuration
+
+This is followed by the checks made by doctor, which test access to Gerrit
+and GitHub, and optionally by a dry run of a trybot dispatch, which prints
+what runtrybot would send to GitHub without sending it. init ends with a
+summary of which steps are done and which need attention. It is safe to run
+again; steps which are already done are left alone.
+`,
+		RunE: mkRunE(c, initDef),
+	}
+	return cmd
+}
+
+func initDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("init does not take any arguments")
+	}
+	var (
+		gerritURL, githubURL string
+		cfg                  *config
+	)
+	notCloned := &skipError{"the clone is not set up"}
+	notLoaded := &skipError{"the config could not be loaded"}
+
+	steps := []doctorCheck{{
+		name: "clone",
+		run: func(ctx context.Context) (string, error) {
+			root, err := run(ctx, "git", "rev-parse", "--show-toplevel")
+			if err != nil {
+				return "", fmt.Errorf("not in a git clone; clone the repository and run init inside it")
+			}
+			crc, err := codereviewcfg.Config(strings.TrimSpace(root))
+			if err != nil {
+				return "", fmt.Errorf("failed to load codereview config: %v", err)
+			}
+			gerritURL, githubURL = crc["gerrit"], crc["github"]
+			if gerritURL == "" || githubURL == "" {
+				return "", fmt.Errorf("codereview.cfg must name both the gerrit server and the github repository")
+			}
+			return fmt.Sprintf("reviewed on %s, mirrored to %s", gerritURL, githubURL), nil
+		},
+	}, {
+		name: "git-codereview",
+		run: func(ctx context.Context) (string, error) {
+			if _, err := exec.LookPath("git-codereview"); err == nil {
+				return "installed", nil
+			}
+			const install = "go install golang.org/x/review/git-codereview@latest"
+			if !confirm("git-codereview is not installed; install it with " + install + "?") {
+				return "", fmt.Errorf("not installed; run: %s", install)
+			}
+			if err := runInteractive(ctx, "go", "install", "golang.org/x/review/git-codereview@latest"); err != nil {
+				return "", err
+			}
+			if _, err := exec.LookPath("git-codereview"); err != nil {
+				return "", fmt.Errorf("installed, but not in your PATH; add $(go env GOPATH)/bin to it")
+			}
+			return "installed with go install", nil
+		},
+	}, {
+		name: "hooks",
+		run: func(ctx context.Context) (string, error) {
+			if gerritURL == "" {
+				return "", notCloned
+			}
+			hook, err := run(ctx, "git", "rev-parse", "--git-path", "hooks/commit-msg")
+			if err != nil {
+				return "", err
+			}
+			if fileExists(strings.TrimSpace(hook)) {
+				return "commit-msg hook installed", nil
+			}
+			if _, err := exec.LookPath("git-codereview"); err != nil {
+				return "", &skipError{"git-codereview is not installed"}
+			}
+			if !confirm("install the git-codereview hooks, which add a Change-Id to each commit?") {
+				return "", &skipError{"declined; run: git-codereview hooks"}
+			}
+			if err := runInteractive(ctx, "git-codereview", "hooks"); err != nil {
+				return "", err
+			}
+			return "installed with git-codereview hooks", nil
+		},
+	}, {
+		name: "gerrit-credentials",
+		run: func(ctx context.Context) (string, error) {
+			if gerritURL == "" {
+				return "", notCloned
+			}
+			if os.Getenv("GERRIT_USER") != "" && os.Getenv("GERRIT_PASSWORD") != "" {
+				return "from GERRIT_USER and GERRIT_PASSWORD", nil
+			}
+			detail, err := setupCredentials(ctx, gerritURL)
+			if err != nil {
+				return "", fmt.Errorf("%v; generate an HTTP password at %s/settings/#HTTPCredentials", err, strings.TrimSuffix(gerritURL, "/"))
+			}
+			return detail, nil
+		},
+	}, {
+		name: "github-credentials",
+		run: func(ctx context.Context) (string, error) {
+			if githubURL == "" {
+				return "", notCloned
+			}
+			switch {
+			case os.Getenv("GITHUB_PAT") != "":
+				return "from GITHUB_PAT", nil
+			case os.Getenv("GITHUB_TOKEN") != "":
+				return "from GITHUB_TOKEN", nil
+			}
+			detail, err := setupCredentials(ctx, githubURL)
+			if err != nil {
+				return "", fmt.Errorf("%v; create a personal access token with the repo scope at https://github.com/settings/tokens", err)
+			}
+			return detail, nil
+		},
+	}, {
+		name: "config",
+		run: func(ctx context.Context) (string, error) {
+			if gerritURL == "" {
+				return "", notCloned
+			}
+			var err error
+			if cfg, err = loadConfig(ctx); err != nil {
+				return "", err
+			}
+			return "loaded", nil
+		},
+	}}
+
+	// The checks made by doctor need the config, so are only looked up
+	// once it has been loaded.
+	for _, check := range doctorChecks(nil) {
+		name := check.name
+		steps = append(steps, doctorCheck{
+			name: name,
+			run: func(ctx context.Context) (string, error) {
+				if cfg == nil {
+					return "", notLoaded
+				}
+				for _, check := range doctorChecks(cfg) {
+					if check.name == name {
+						return check.run(ctx)
+					}
+				}
+				panic("unreachable")
+			},
+		})
+	}
+
+	steps = append(steps, doctorCheck{
+		name: "dispatch",
+		run: func(ctx context.Context) (string, error) {
+			if cfg == nil {
+				return "", notLoaded
+			}
+			if !confirm("do a dry run of a trybot dispatch, printing what would be sent without sending it?") {
+				return "", &skipError{"declined"}
+			}
+			payload, err := explainEvents[string(eventTypeTrybot)]()
+			if err != nil {
+				return "", err
+			}
+			saved := dryRun
+			dryRun = true
+			defer func() { dryRun = saved }()
+			if err := cfg.triggerRepositoryDispatch(ctx, cfg.githubOwner, cfg.githubRepo, payload); err != nil {
+				return "", err
+			}
+			return "printed an example trybot dispatch", nil
+		},
+	})
+
+	failed := runChecks(cmd.Context(), cmd.OutOrStdout(), steps)
+	if failed > 0 {
+		return &partialFailureError{failed: failed, total: len(steps)}
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "\nready to contribute; see cueckoo help for what cueckoo can do")
+	return nil
+}
+
+// setupCredentials makes sure that a git credential helper has credentials
+// for repoURL, letting git prompt for them if it has none, and storing them
+// in the helper. It returns who the credentials are for.
+func setupCredentials(ctx context.Context, repoURL string) (string, error) {
+	helper, _ := run(ctx, "git", "config", "--get", "credential.helper")
+	if strings.TrimSpace(helper) == "" {
+		return "", fmt.Errorf("no git credential helper is configured; see https://git-scm.com/doc/credential-helpers")
+	}
+	user, password, err := gitCredentials(ctx, repoURL)
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", fmt.Errorf("no credentials for %s", repoURL)
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", err
+	}
+	approve := exec.CommandContext(ctx, "git", "credential", "approve")
+	approve.Stdin = strings.NewReader(strings.Join([]string{
+		"protocol=" + u.Scheme,
+		"host=" + u.Host,
+		"path=" + u.Path,
+		"username=" + user,
+		"password=" + password,
+	}, "\n") + "\n")
+	if out, err := approve.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to store credentials: %v:\n%s", err, out)
+	}
+	return "stored for " + user, nil
+}
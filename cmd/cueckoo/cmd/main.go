@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -26,14 +27,35 @@ var (
 	debug = os.Getenv("CUECKOO_DEBUG") != ""
 )
 
+const (
+	// exitPartialFailure is the exit code used when a command operating on
+	// multiple changes failed for some, but not all, of them.
+	exitPartialFailure = 2
+)
+
 // Main runs the cueckoo tool and returns the code for passing to os.Exit.
 //
 // We follow the same approach here as the cue command (as well as using the
 // using the same version of Cobra) for consistency. Panic is used as a
 // strategy for early-return from any running command.
 func Main() int {
-	err := mainErr(context.Background(), os.Args[1:])
+	args := os.Args[1:]
+	if code, ok := runPlugin(context.Background(), args); ok {
+		return code
+	}
+	if code, ok := runViaDaemon(args); ok {
+		return code
+	}
+	return mainCode(context.Background(), args)
+}
+
+// mainCode runs the cueckoo tool with args and returns the exit code.
+func mainCode(ctx context.Context, args []string) int {
+	err := mainErr(ctx, args)
 	if err != nil {
+		if err == errPrintedPartialFailure {
+			return exitPartialFailure
+		}
 		if err != errPrintedError {
 			fmt.Fprintln(os.Stderr, err)
 		}
@@ -48,6 +70,8 @@ func mainErr(ctx context.Context, args []string) (err error) {
 	if err != nil {
 		return err
 	}
+	start := time.Now()
+	defer func() { reportTelemetry(ctx, cmd, start, err) }()
 	return cmd.Run(ctx)
 }
 
@@ -59,33 +83,159 @@ func New(args []string) (cmd *Command, err error) {
 	if len(args) == 0 {
 		return cmd, nil
 	}
+	userCfg, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	args, cmd.defaultArgsNote, err = applyDefaultArgs(rootCmd, args, userCfg)
+	if err != nil {
+		return nil, err
+	}
 	rootCmd.SetArgs(args)
 	return
 }
 
 func newRootCmd() *Command {
 	cmd := &cobra.Command{
-		Use:          "cueckoo",
-		Short:        "cueckoo is a development tool for working with the CUE project",
+		Use:   "cueckoo",
+		Short: "cueckoo is a development tool for working with the CUE project",
+		Long: `cueckoo is a development tool for working with the CUE project.
+
+cueckoo operates on the repository containing the current directory. The
+global --repo-dir and --workspace flags select another repository instead;
+see cueckoo workspace.
+
+Running "cueckoo NAME" for a NAME which is not a cueckoo command runs the
+plugin cueckoo-NAME found on $PATH; see "cueckoo help plugins".
+
+The help topics below describe the global flags and settings which apply to
+every command, such as "cueckoo help daemon".
+`,
 		SilenceUsage: true,
 	}
 
 	c := &Command{Command: cmd, root: cmd}
+	cmd.RunE = mkRunE(c, rootDef)
+	c.registerFlags(cmd).Bool(flagDaemon, false, "serve the commands of other cueckoo invocations over a unix socket")
+	persistentFlags := c.registerPersistentFlags(cmd)
+	addRepoDirFlags(persistentFlags)
+	persistentFlags.String(flagMaxPause, defaultMaxPause, "how long to wait for Gerrit while it is unavailable for maintenance")
+	persistentFlags.Bool(flagNoCache, false, "do not use the local cache of workflow runs")
+	persistentFlags.Bool(flagNoColor, false, "do not use terminal control sequences, such as to redraw progress")
+	persistentFlags.Bool(flagPlain, false, "write tables as tab-separated values and progress as plain lines")
+	persistentFlags.Bool(flagVerbose, false, "print debug output, as CUECKOO_DEBUG does")
+	persistentFlags.Bool(flagReadOnly, false, "refuse any request which could modify Gerrit or GitHub")
+	persistentFlags.Bool(flagDryRun, false, "print repository dispatches rather than sending them")
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if verbose, _ := cmd.Flags().GetBool(string(flagVerbose)); verbose {
+			debug = true
+		}
+		if c.defaultArgsNote != "" {
+			debugf("%s\n", c.defaultArgsNote)
+		}
+		if err := enterRepoDir(cmd); err != nil {
+			return err
+		}
+		readNoCache(cmd)
+		readOutputFlags(cmd)
+		readReadOnly(cmd)
+		readDryRun(cmd)
+		return readMaxPause(cmd)
+	}
 
 	subCommands := []*cobra.Command{
 		newRuntrybotCmd(c),
 		newImportPRCmd(c),
 		newUnityCmd(c),
 		newReleaselogCmd(c),
+		newSubmitCmd(c),
+		newWelcomeCmd(c),
+		newArchiveCmd(c),
+		newCICmd(c),
+		newRerunCmd(c),
+		newEditCmd(c),
+		newTelemetryCmd(c),
+		newPingCmd(c),
+		newChangeIDCmd(c),
+		newSquashCmd(c),
+		newSplitCmd(c),
+		newVetCmd(c),
+		newReplyCmd(c),
+		newWorkspaceCmd(c),
+		newRevertCmd(c),
+		newReleaseCmd(c),
+		newCompatCmd(c),
+		newGerritCmd(c),
+		newBumpCmd(c),
+		newImportPatchCmd(c),
+		newHistoryCmd(c),
+		newNotifyCmd(c),
+		newSelftestCmd(c),
+		newUnvoteCmd(c),
+		newServeCmd(c),
+		newOrgCmd(c),
+		newDepsCmd(c),
+		newExplainCmd(c),
+		newPreviewCmd(c),
+		newAuditWorkflowsCmd(c),
+		newDoctorCmd(c),
+		newRotaCmd(c),
+		newPluginsCmd(c),
+		newWaitRefCmd(c),
+		newIdentityCmd(c),
+		newFeedCmd(c),
+		newSizeCmd(c),
+		newBackportCmd(c),
+		newChecksCmd(c),
+		newMirrorCmd(c),
+		newApplySuggestionsCmd(c),
+		newNewCmd(c),
+		newStatusCmd(c),
+		newAdvisoryCmd(c),
+		newDigestCmd(c),
+		newAuditCmd(c),
+		newInitCmd(c),
 	}
 
 	for _, sub := range subCommands {
 		cmd.AddCommand(sub)
 	}
+	cmd.AddCommand(newHelpTopics()...)
+
+	// Add cobra's completion command now rather than when executing, to
+	// document how the completions behave.
+	cmd.InitDefaultCompletionCmd()
+	if comp, _, err := cmd.Find([]string{"completion"}); err == nil {
+		comp.Long += `
+The generated completions complete the numbers of your open CLs and the
+branches of the repository. These are cached in the cueckoo directory of
+$XDG_CACHE_HOME, or its platform equivalent, and refreshed when more than 10
+minutes old, falling back to the cached data when the refresh fails, such as
+when offline.
+`
+	}
 
 	return c
 }
 
+func rootDef(cmd *Command, args []string) error {
+	if !flagDaemon.Bool(cmd) {
+		return cmd.Help()
+	}
+	return serveDaemon(cmd.Context(), cmd.OutOrStdout())
+}
+
+// partialFailureError is returned by commands that operate on multiple
+// changes when some, but not all, of those operations failed. It results in
+// the distinct exit code exitPartialFailure.
+type partialFailureError struct {
+	failed, total int
+}
+
+func (e *partialFailureError) Error() string {
+	return fmt.Sprintf("%d of %d operations failed", e.failed, e.total)
+}
+
 func debugf(format string, args ...any) {
 	if debug {
 		fmt.Fprintf(os.Stderr, format, args...)
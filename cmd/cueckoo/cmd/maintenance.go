@@ -0,0 +1,144 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const flagMaxPause flagName = "max-pause"
+
+// defaultMaxPause is how long requests to Gerrit are paused for by default
+// while it is unavailable, which comfortably covers its usual maintenance
+// windows.
+const defaultMaxPause = "30m"
+
+// gerritMaxPause is the value of the global --max-pause flag for the command
+// being run.
+var gerritMaxPause time.Duration
+
+// readMaxPause sets gerritMaxPause from the --max-pause flag.
+func readMaxPause(cmd *cobra.Command) error {
+	s, _ := cmd.Flags().GetString(string(flagMaxPause))
+	d, err := parseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid --%s: %v", flagMaxPause, err)
+	}
+	gerritMaxPause = d
+	return nil
+}
+
+// maintenanceTransport retries requests to Gerrit which fail with 503 Service
+// Unavailable, as Gerrit responds during maintenance windows, instead of
+// failing the entire command. Requests are paused for up to gerritMaxPause,
+// reporting the pause on stderr, before the 503 response is returned as is.
+type maintenanceTransport struct {
+	base http.RoundTripper
+
+	// firstWait is the wait before the first retry, which then doubles with
+	// every retry up to maxWait, unless Gerrit asks for another delay via
+	// Retry-After.
+	firstWait, maxWait time.Duration
+
+	// quietUntil is when the next pause may be reported, such that
+	// concurrent requests do not all report the same outage.
+	mu         sync.Mutex
+	quietUntil time.Time
+}
+
+func newMaintenanceTransport(base http.RoundTripper) *maintenanceTransport {
+	return &maintenanceTransport{
+		base:      base,
+		firstWait: 15 * time.Second,
+		maxWait:   2 * time.Minute,
+	}
+}
+
+func (t *maintenanceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var paused time.Duration
+	wait := t.firstWait
+	r := req
+	for {
+		resp, err := t.base.RoundTrip(r)
+		if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+			if paused > 0 {
+				t.report("Gerrit is available again after %v\n", paused.Round(time.Second))
+			}
+			return resp, err
+		}
+		d := retryAfter(resp, wait)
+		if paused+d > gerritMaxPause || (req.Body != nil && req.GetBody == nil) {
+			if paused > 0 {
+				fmt.Fprintf(os.Stderr, "Gerrit is still unavailable after %v; giving up (see --%s)\n", paused.Round(time.Second), flagMaxPause)
+			}
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		t.report("Gerrit is unavailable (%s), likely for maintenance; retrying in %v\n", resp.Status, d)
+
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		paused += d
+		if wait *= 2; wait > t.maxWait {
+			wait = t.maxWait
+		}
+
+		// A RoundTripper must not modify the request, so retry with a copy
+		// that has a fresh body.
+		r = req.Clone(req.Context())
+		if req.Body != nil {
+			if r.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// report writes a status message to stderr, unless another request reported
+// the same outage very recently.
+func (t *maintenanceTransport) report(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if now.Before(t.quietUntil) {
+		return
+	}
+	t.quietUntil = now.Add(t.firstWait / 2)
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// retryAfter returns the delay requested by the Retry-After header of resp in
+// seconds, if any, and def otherwise. HTTP dates are not supported, as Gerrit
+// does not use them.
+func retryAfter(resp *http.Response, def time.Duration) time.Duration {
+	if n, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return def
+}
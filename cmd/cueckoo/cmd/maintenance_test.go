@@ -0,0 +1,76 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceTransport(t *testing.T) {
+	failures := 2
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tr := newMaintenanceTransport(http.DefaultTransport)
+	tr.firstWait, tr.maxWait = time.Millisecond, time.Millisecond
+	client := &http.Client{Transport: tr}
+	post := func() int {
+		resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	defer func(d time.Duration) { gerritMaxPause = d }(gerritMaxPause)
+	gerritMaxPause = time.Minute
+	if got := post(); got != http.StatusOK {
+		t.Errorf("got status %d; want 200 after retries", got)
+	}
+	if len(bodies) != 3 {
+		t.Errorf("got %d requests; want 3", len(bodies))
+	}
+	for i, b := range bodies {
+		if b != "body" {
+			t.Errorf("request %d had body %q; want %q", i, b, "body")
+		}
+	}
+
+	// With no pause allowed, the 503 is returned straight away.
+	gerritMaxPause = 0
+	failures, bodies = 1, nil
+	if got := post(); got != http.StatusServiceUnavailable {
+		t.Errorf("got status %d; want 503 with no pause allowed", got)
+	}
+	if len(bodies) != 1 {
+		t.Errorf("got %d requests; want 1", len(bodies))
+	}
+}
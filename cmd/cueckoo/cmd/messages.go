@@ -0,0 +1,112 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"text/template"
+)
+
+// messagesFile is the path, relative to the repository root, of the CUE file
+// which overrides the text that cueckoo posts on Gerrit on its own behalf.
+const messagesFile = ".github/cueckoo-messages.cue"
+
+// botMessages are the Go text/template strings for the messages cueckoo
+// posts on Gerrit as a bot, as opposed to on behalf of a user. Projects can
+// override any of them via the top-level fields of messagesFile named after
+// the JSON tags below, e.g.
+//
+//	runResult: "{{.Kind}} run {{.Conclusion}}, see {{.URL}}"
+type botMessages struct {
+	// RunResult reports the result of a trybot or unity run; see
+	// runResultData.
+	RunResult string `json:"runResult"`
+
+	// FlakeRetry announces that a trybot run was re-run as a suspected
+	// flake; see flakeRetryData.
+	FlakeRetry string `json:"flakeRetry"`
+
+	// Welcome is posted on a contributor's first merged CL; see
+	// welcomeData. It is the default for the welcome command's
+	// "comment" template.
+	Welcome string `json:"welcome"`
+}
+
+var defaultBotMessages = botMessages{
+	RunResult: `{{.Kind}} run {{.Conclusion}}: {{.URL}}`,
+	FlakeRetry: `The trybot run {{.URL}} failed with suspected flakes, so its failed jobs were automatically re-run once:
+{{range .Jobs}}
+* {{.Job}}: {{.Flake}}{{end}}
+`,
+	Welcome: defaultWelcomeTemplates.Comment,
+}
+
+// runResultData is the data made available to the runResult template.
+type runResultData struct {
+	Kind       string // trybot or unity
+	Conclusion string
+	URL        string
+	CL         int
+	Patchset   int
+}
+
+// flakeRetryData is the data made available to the flakeRetry template.
+type flakeRetryData struct {
+	URL  string
+	Jobs []flakeRetryJob
+}
+
+type flakeRetryJob struct {
+	Job   string
+	Flake string
+}
+
+// loadBotMessages returns the bot message templates of the repository at
+// gitRoot, which are the defaults unless overridden by messagesFile.
+func loadBotMessages(ctx context.Context, gitRoot string) (*botMessages, error) {
+	msgs := defaultBotMessages
+	path := filepath.Join(gitRoot, messagesFile)
+	if fileExists(path) {
+		if err := loadCUEFile(ctx, path, &msgs); err != nil {
+			return nil, err
+		}
+	}
+	// Check all the templates up front, rather than when first used.
+	for name, text := range msgs.templates() {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return nil, fmt.Errorf("invalid %s message in %s: %v", name, messagesFile, err)
+		}
+	}
+	return &msgs, nil
+}
+
+func (m *botMessages) templates() map[string]string {
+	return map[string]string{
+		"runResult":  m.RunResult,
+		"flakeRetry": m.FlakeRetry,
+		"welcome":    m.Welcome,
+	}
+}
+
+// exec executes the message template called name with data.
+func (m *botMessages) exec(name string, data any) (string, error) {
+	t, err := template.New(name).Parse(m.templates()[name])
+	if err != nil {
+		return "", fmt.Errorf("invalid %s message: %v", name, err)
+	}
+	return execTemplate(t, data)
+}
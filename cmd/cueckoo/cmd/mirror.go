@@ -0,0 +1,266 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/codereviewcfg"
+	"github.com/cue-lang/contrib-tools/internal/gitcmd"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagMirrorTags flagName = "tags"
+	flagMirrorKeys flagName = "keys"
+)
+
+// newMirrorCmd creates a new mirror command, which groups the subcommands
+// for checking the GitHub mirror of the Gerrit project.
+func newMirrorCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mirror",
+		Short: "check the GitHub mirror of the Gerrit project",
+	}
+	cmd.AddCommand(newMirrorVerifyTagsCmd(c))
+	return cmd
+}
+
+// newMirrorVerifyTagsCmd creates a new mirror verify-tags command
+func newMirrorVerifyTagsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-tags",
+		Short: "check that the release tags on the GitHub mirror match Gerrit",
+		Long: `
+Usage of mirror verify-tags:
+
+	mirror verify-tags [--tags PATTERN] [--keys FILE]
+
+verify-tags checks every release tag on the GitHub mirror of the repository,
+those matching --tags which defaults to v*, as a safeguard against the mirror
+being tampered with. For each tag, it checks that:
+
+	gerrit     the tag exists on Gerrit and points at the same object
+	reachable  the tagged commit is reachable from a branch on Gerrit
+	signature  the tag is signed by one of the project keys
+
+The project keys are the OpenPGP public keys in --keys, such as an armored
+keyring exported with "gpg --export --armor". Without --keys, signatures are
+not checked.
+
+The tags are listed in a table, and verify-tags fails if any tag does not
+pass every check, such that a scheduled workflow running it alerts on
+mismatches. No credentials are needed.
+`,
+		RunE: mkRunE(c, mirrorVerifyTagsDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagMirrorTags, "v*", "pattern of the release tags to verify")
+	flags.String(flagMirrorKeys, "", "file of the OpenPGP public keys which sign release tags")
+	return cmd
+}
+
+// tagCheck is the outcome of verifying a tag on the GitHub mirror.
+type tagCheck struct {
+	tag    string
+	commit string
+
+	// gerrit is whether the tag exists on Gerrit with the same target.
+	gerrit bool
+
+	// reachable is whether the commit is reachable from a Gerrit branch.
+	reachable bool
+
+	// signature is "good", "bad", "unsigned", or "unchecked" without keys.
+	signature string
+}
+
+// problems returns the reasons why the tag failed verification, if any.
+func (tc tagCheck) problems() []string {
+	var res []string
+	if !tc.gerrit {
+		res = append(res, "differs from Gerrit")
+	}
+	if !tc.reachable {
+		res = append(res, "commit not on a Gerrit branch")
+	}
+	switch tc.signature {
+	case "bad":
+		res = append(res, "bad signature")
+	case "unsigned":
+		res = append(res, "not signed")
+	}
+	return res
+}
+
+// parseLsRemote parses the output of git ls-remote into a map from ref name
+// to object name.
+func parseLsRemote(out string) map[string]string {
+	refs := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if hash, ref, ok := strings.Cut(line, "\t"); ok {
+			refs[ref] = hash
+		}
+	}
+	return refs
+}
+
+func mirrorVerifyTagsDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("mirror verify-tags does not take any arguments")
+	}
+	ctx := cmd.Context()
+	gerritURL, err := gerritRemote(ctx)
+	if err != nil {
+		return err
+	}
+	gitRoot, err := run(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("failed to determine git root: %w", err)
+	}
+	crCfg, err := codereviewcfg.Config(strings.TrimSpace(gitRoot))
+	if err != nil {
+		return fmt.Errorf("failed to load codereview config: %v", err)
+	}
+	githubURL := crCfg["github"]
+	if githubURL == "" {
+		return fmt.Errorf("missing GitHub repo in codereview config")
+	}
+
+	dir, err := os.MkdirTemp("", "cueckoo-verify-tags-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	g := gitcmd.Exec{Dir: filepath.Join(dir, "repo")}
+	if _, err := run(ctx, "git", "init", "--quiet", "--bare", g.Dir); err != nil {
+		return err
+	}
+	keys := flagMirrorKeys.String(cmd)
+	if keys != "" {
+		// Only trust the project keys, not those of the user.
+		home := filepath.Join(dir, "gnupg")
+		if err := os.Mkdir(home, 0o700); err != nil {
+			return err
+		}
+		if _, err := run(ctx, "gpg", "--batch", "--quiet", "--homedir", home, "--import", keys); err != nil {
+			return fmt.Errorf("failed to import keys: %w", err)
+		}
+		g.Env = []string{"GNUPGHOME=" + home}
+	}
+
+	pattern := "refs/tags/" + flagMirrorTags.String(cmd)
+	out, err := g.Run(ctx, "ls-remote", githubURL, pattern)
+	if err != nil {
+		return err
+	}
+	mirrorTags := parseLsRemote(out)
+	if out, err = g.Run(ctx, "ls-remote", gerritURL, pattern); err != nil {
+		return err
+	}
+	gerritTags := parseLsRemote(out)
+	if len(mirrorTags) == 0 {
+		return fmt.Errorf("no tags matching %s on %s", pattern, githubURL)
+	}
+
+	prog := newProgress(os.Stderr, "fetched", 2)
+	prog.begin("Gerrit branches")
+	err = g.Fetch(ctx, gerritURL, 0, "+refs/heads/*:refs/gerrit/*")
+	prog.end("Gerrit branches", err)
+	if err != nil {
+		prog.finish()
+		return err
+	}
+	prog.begin("mirror tags")
+	err = g.Fetch(ctx, githubURL, 0, "+"+pattern+":"+pattern)
+	prog.end("mirror tags", err)
+	prog.finish()
+	if err != nil {
+		return err
+	}
+
+	var checks []tagCheck
+	for _, ref := range sortedKeys(mirrorTags) {
+		if strings.HasSuffix(ref, "^{}") {
+			continue
+		}
+		tc, err := verifyTag(ctx, g, ref, mirrorTags, gerritTags, keys != "")
+		if err != nil {
+			return err
+		}
+		checks = append(checks, tc)
+	}
+
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "TAG\tCOMMIT\tGERRIT\tREACHABLE\tSIGNATURE\tRESULT")
+	failed := 0
+	for _, tc := range checks {
+		result := "ok"
+		if p := tc.problems(); len(p) > 0 {
+			failed++
+			result = strings.Join(p, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%.12s\t%s\t%s\t%s\t%s\n", tc.tag, tc.commit, yesNo(tc.gerrit), yesNo(tc.reachable), tc.signature, result)
+	}
+	tw.Flush()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tags on the mirror failed verification", failed, len(checks))
+	}
+	return nil
+}
+
+// verifyTag checks the tag ref fetched into g against the tags on Gerrit.
+func verifyTag(ctx context.Context, g gitcmd.Exec, ref string, mirrorTags, gerritTags map[string]string, checkSignature bool) (tagCheck, error) {
+	tc := tagCheck{
+		tag:       strings.TrimPrefix(ref, "refs/tags/"),
+		gerrit:    gerritTags[ref] == mirrorTags[ref],
+		signature: "unchecked",
+	}
+	commit, err := g.RevParse(ctx, ref)
+	if err != nil {
+		return tc, err
+	}
+	tc.commit = commit
+	out, err := g.Run(ctx, "for-each-ref", "--count=1", "--contains="+commit, "--format=%(refname)", "refs/gerrit/")
+	if err != nil {
+		return tc, err
+	}
+	tc.reachable = strings.TrimSpace(out) != ""
+	if checkSignature {
+		kind, err := g.Run(ctx, "cat-file", "-t", ref)
+		if err != nil {
+			return tc, err
+		}
+		switch {
+		case strings.TrimSpace(kind) != "tag":
+			// Lightweight tags cannot be signed.
+			tc.signature = "unsigned"
+		default:
+			if _, err := g.Run(ctx, "verify-tag", ref); err != nil {
+				tc.signature = "bad"
+				if body, err := g.Run(ctx, "cat-file", "tag", ref); err == nil && !strings.Contains(body, "-----BEGIN PGP SIGNATURE-----") {
+					tc.signature = "unsigned"
+				}
+			} else {
+				tc.signature = "good"
+			}
+		}
+	}
+	return tc, nil
+}
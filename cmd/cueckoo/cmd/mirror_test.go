@@ -0,0 +1,50 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLsRemote(t *testing.T) {
+	out := "1111\trefs/tags/v0.8.0\n2222\trefs/tags/v0.8.0^{}\n3333\trefs/tags/v0.8.1\n"
+	want := map[string]string{
+		"refs/tags/v0.8.0":    "1111",
+		"refs/tags/v0.8.0^{}": "2222",
+		"refs/tags/v0.8.1":    "3333",
+	}
+	if diff := cmp.Diff(want, parseLsRemote(out)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestTagCheckProblems(t *testing.T) {
+	cases := []struct {
+		tc   tagCheck
+		want []string
+	}{
+		{tagCheck{gerrit: true, reachable: true, signature: "good"}, nil},
+		{tagCheck{gerrit: true, reachable: true, signature: "unchecked"}, nil},
+		{tagCheck{gerrit: false, reachable: true, signature: "unsigned"}, []string{"differs from Gerrit", "not signed"}},
+		{tagCheck{gerrit: true, reachable: false, signature: "bad"}, []string{"commit not on a Gerrit branch", "bad signature"}},
+	}
+	for _, c := range cases {
+		if diff := cmp.Diff(c.want, c.tc.problems()); diff != "" {
+			t.Errorf("%+v (-want +got):\n%s", c.tc, diff)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/trailers"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNewTemplate flagName = "template"
+	flagNewNoEdit   flagName = "no-edit"
+)
+
+// changeTemplatesFile is the path, relative to the repository root, of the
+// CUE file which adds to or overrides the default change templates.
+const changeTemplatesFile = ".github/cueckoo-changes.cue"
+
+// changeTemplate is the skeleton of the commit message for a recurring type
+// of change. Projects can override the defaults, or add their own, via the
+// top-level fields of changeTemplatesFile, which are named after the
+// templates and have fields named after the JSON tags below, e.g.
+//
+//	docfix: {
+//		subject: "doc: fix WHAT"
+//		body:    "Explain what was wrong in the documentation."
+//		trailers: ["Signed-off-by"]
+//		checklist: ["Run go generate ./... if the docs are generated"]
+//	}
+type changeTemplate struct {
+	// Subject is the first line of the commit message, with placeholders in
+	// capitals for the author to replace.
+	Subject string `json:"subject"`
+
+	// Body follows the subject.
+	Body string `json:"body"`
+
+	// Trailers are the keys of the trailers which the commit message must
+	// have. Signed-off-by is filled in from the git identity of the author;
+	// others are left empty for the author to fill in. A Change-Id is always
+	// added.
+	Trailers []string `json:"trailers"`
+
+	// Checklist is shown as comment lines in the editor, and removed from
+	// the commit message.
+	Checklist []string `json:"checklist"`
+}
+
+var defaultChangeTemplates = map[string]changeTemplate{
+	"deprecation": {
+		Subject: "PKG: deprecate NAME",
+		Body: `NAME is deprecated in favour of REPLACEMENT, because REASON.
+
+The deprecated API keeps working until it is removed in a later release.
+
+Updates #ISSUE.`,
+		Trailers: []string{"Signed-off-by"},
+		Checklist: []string{
+			`Start the doc comment of NAME with a "Deprecated:" paragraph naming REPLACEMENT`,
+			"Update any uses of NAME in the repository",
+			"Add a line to the release notes of the next release",
+		},
+	},
+	"docfix": {
+		Subject:  "doc: fix WHAT",
+		Body:     "Explain what was wrong or unclear in the documentation.",
+		Trailers: []string{"Signed-off-by"},
+		Checklist: []string{
+			"Check the rendered result, such as on pkg.go.dev or cuelang.org",
+			"Regenerate any generated documentation with go generate ./...",
+		},
+	},
+	"bump": {
+		Subject: "all: update MODULE to VERSION",
+		Body: `Summarize the notable changes since the previous version, or link to
+its release notes.`,
+		Trailers: []string{"Signed-off-by"},
+		Checklist: []string{
+			"Run go mod tidy",
+			"Regenerate with go generate ./... and check the diff",
+			"Check the release notes of VERSION for breaking changes",
+		},
+	},
+}
+
+// newNewCmd creates a new new command
+func newNewCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "create a commit from a template for a common type of change",
+		Long: `
+Usage of new:
+
+	new --template NAME [--no-edit]
+
+new creates a commit whose message is the skeleton given by the template
+NAME, including any staged changes, and opens the editor to fill it in. The
+commit is created even if nothing is staged, such that the skeleton can be
+written before the change itself, which is then added with git codereview
+change.
+
+The skeleton has the structure of the commit messages for that type of
+change, the trailers it requires, and a checklist as comment lines which are
+removed from the message. A Change-Id is added, and Signed-off-by is filled in
+from the git identity of the author. After the commit is created, new fails
+if any required trailer is missing or empty.
+
+The default templates are:
+
+	deprecation    deprecate an API in favour of another
+	docfix         fix the documentation
+	bump           update a dependency
+
+Projects can override these, or add their own, via the top-level fields of
+` + changeTemplatesFile + `, such as:
+
+	docfix: {
+		subject: "doc: fix WHAT"
+		body:    "Explain what was wrong in the documentation."
+		trailers: ["Signed-off-by"]
+		checklist: ["Run go generate ./... if the docs are generated"]
+	}
+`,
+		RunE: mkRunE(c, newDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagNewTemplate, "", "the template of the commit message")
+	flags.Bool(flagNewNoEdit, false, "commit the skeleton without opening the editor")
+	return cmd
+}
+
+func newDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("new does not take any arguments")
+	}
+	ctx := cmd.Context()
+	gitRoot, err := run(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("failed to determine git root: %w", err)
+	}
+	tmpls, err := loadChangeTemplates(ctx, strings.TrimSpace(gitRoot))
+	if err != nil {
+		return err
+	}
+	name := flagNewTemplate.String(cmd)
+	t, ok := tmpls[name]
+	if !ok {
+		return fmt.Errorf("unknown template %q; want one of %s", name, strings.Join(sortedKeys(tmpls), ", "))
+	}
+
+	id, err := newChangeID()
+	if err != nil {
+		return err
+	}
+	fill := map[string]string{"Change-Id": id}
+	if slicesContains(t.Trailers, "Signed-off-by") {
+		out, err := run(ctx, "git", "var", "GIT_AUTHOR_IDENT")
+		if err != nil {
+			return err
+		}
+		// The identity is followed by the timestamp and timezone.
+		if i := strings.LastIndex(out, ">"); i >= 0 {
+			fill["Signed-off-by"] = out[:i+1]
+		}
+	}
+	msg := changeSkeleton(name, t, fill)
+
+	f, err := os.CreateTemp("", "cueckoo-new-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(msg)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	commitArgs := []string{"commit", "--quiet", "--allow-empty", "--cleanup=strip", "-F", f.Name()}
+	if !flagNewNoEdit.Bool(cmd) {
+		commitArgs = append(commitArgs, "--edit")
+	}
+	// Forward stdin/out/err for terminal editors like vim.
+	commitCmd := exec.CommandContext(ctx, "git", commitArgs...)
+	commitCmd.Stdin = os.Stdin
+	commitCmd.Stdout = os.Stdout
+	commitCmd.Stderr = os.Stderr
+	if err := commitCmd.Run(); err != nil {
+		return fmt.Errorf("failed to commit: %v", err)
+	}
+
+	got, err := run(ctx, "git", "log", "-1", "--format=%B", "HEAD")
+	if err != nil {
+		return err
+	}
+	required := append([]string{"Change-Id"}, t.Trailers...)
+	if missing := missingTrailers(got, required); len(missing) > 0 {
+		return fmt.Errorf("HEAD is missing the required trailers %s; add them with git commit --amend", strings.Join(missing, ", "))
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "created %s commit with Change-Id %s\n", name, id)
+	return nil
+}
+
+// loadChangeTemplates returns the change templates of the repository at
+// gitRoot, which are the defaults along with any added or overridden by
+// changeTemplatesFile.
+func loadChangeTemplates(ctx context.Context, gitRoot string) (map[string]changeTemplate, error) {
+	tmpls := make(map[string]changeTemplate, len(defaultChangeTemplates))
+	for name, t := range defaultChangeTemplates {
+		tmpls[name] = t
+	}
+	path := filepath.Join(gitRoot, changeTemplatesFile)
+	if fileExists(path) {
+		var overrides map[string]changeTemplate
+		if err := loadCUEFile(ctx, path, &overrides); err != nil {
+			return nil, err
+		}
+		for name, t := range overrides {
+			if t.Subject == "" {
+				return nil, fmt.Errorf("template %s in %s has no subject", name, changeTemplatesFile)
+			}
+			tmpls[name] = t
+		}
+	}
+	return tmpls, nil
+}
+
+// changeSkeleton returns the commit message skeleton of the template t
+// called name. fill gives the values of trailers, which are added in the
+// order of t.Trailers, followed by any others in fill in sorted order.
+func changeSkeleton(name string, t changeTemplate, fill map[string]string) string {
+	var ts []trailers.Trailer
+	for _, key := range t.Trailers {
+		ts = append(ts, trailers.Trailer{Key: key, Value: fill[key]})
+	}
+	keys := make([]string, 0, len(fill))
+	for key := range fill {
+		if !slicesContains(t.Trailers, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ts = append(ts, trailers.Trailer{Key: key, Value: fill[key]})
+	}
+	body := t.Subject
+	if t.Body != "" {
+		body += "\n\n" + strings.TrimSpace(t.Body)
+	}
+	msg := trailers.Format(body, ts)
+	if len(t.Checklist) > 0 {
+		msg += fmt.Sprintf("\n# Checklist for a %s change:\n#\n", name)
+		for _, item := range t.Checklist {
+			msg += "# - " + item + "\n"
+		}
+	}
+	return msg
+}
+
+// missingTrailers returns those of the required trailer keys which msg does
+// not have with a non-empty value.
+func missingTrailers(msg string, required []string) []string {
+	_, ts := trailers.Split(msg)
+	var missing []string
+	for _, key := range required {
+		found := false
+		for _, v := range trailers.Get(ts, key) {
+			if v != "" {
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
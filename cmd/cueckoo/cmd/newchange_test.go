@@ -0,0 +1,53 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangeSkeleton(t *testing.T) {
+	tmpl := changeTemplate{
+		Subject:   "doc: fix WHAT",
+		Body:      "Explain what was wrong.\n",
+		Trailers:  []string{"Signed-off-by", "Reported-by"},
+		Checklist: []string{"Regenerate the docs"},
+	}
+	got := changeSkeleton("docfix", tmpl, map[string]string{
+		"Change-Id":     "I0123",
+		"Signed-off-by": "Gopher <gopher@example.com>",
+	})
+	want := "doc: fix WHAT\n\n" +
+		"Explain what was wrong.\n\n" +
+		"Signed-off-by: Gopher <gopher@example.com>\n" +
+		"Reported-by: \n" +
+		"Change-Id: I0123\n\n" +
+		"# Checklist for a docfix change:\n" +
+		"#\n" +
+		"# - Regenerate the docs\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("skeleton (-want +got):\n%s", diff)
+	}
+}
+
+func TestMissingTrailers(t *testing.T) {
+	msg := "doc: fix typo\n\nBody.\n\nSigned-off-by: Gopher <gopher@example.com>\nReported-by:\nChange-Id: I0123\n"
+	got := missingTrailers(msg, []string{"Change-Id", "Signed-off-by", "Reported-by", "Updates"})
+	if diff := cmp.Diff([]string{"Reported-by", "Updates"}, got); diff != "" {
+		t.Errorf("missing (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// notifierKey is the user config key giving the default notifiers, in the
+// format accepted by newNotifier.
+const notifierKey = "notifier"
+
+// notifier delivers alerts to the user, for commands which watch for events
+// such as ci runners --watch.
+type notifier interface {
+	notify(ctx context.Context, title, body string) error
+}
+
+// newNotifier returns a notifier for spec, which is a comma-separated list
+// of:
+//
+//   - "desktop", for desktop notifications via notify-send on Linux or
+//     osascript on macOS;
+//   - "bell", to ring the terminal bell and print the alert to stderr;
+//   - an http or https URL, to which alerts are posted as JSON with "title"
+//     and "text" fields, as accepted by Slack and compatible webhooks.
+//
+// An empty spec uses the notifier entry in the user config, and otherwise
+// defaults to "bell".
+func newNotifier(spec string) (notifier, error) {
+	if spec == "" {
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return nil, err
+		}
+		spec = cfg[notifierKey]
+	}
+	if spec == "" {
+		spec = "bell"
+	}
+	var res multiNotifier
+	for _, s := range strings.Split(spec, ",") {
+		switch s = strings.TrimSpace(s); {
+		case s == "desktop":
+			res = append(res, desktopNotifier{})
+		case s == "bell":
+			res = append(res, bellNotifier{})
+		case strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://"):
+			res = append(res, webhookNotifier{url: s})
+		default:
+			return nil, fmt.Errorf("unknown notifier %q", s)
+		}
+	}
+	if len(res) == 1 {
+		return res[0], nil
+	}
+	return res, nil
+}
+
+// multiNotifier delivers alerts to all of its notifiers.
+type multiNotifier []notifier
+
+func (m multiNotifier) notify(ctx context.Context, title, body string) error {
+	var firstErr error
+	for _, n := range m {
+		if err := n.notify(ctx, title, body); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+type desktopNotifier struct{}
+
+func (desktopNotifier) notify(ctx context.Context, title, body string) error {
+	name, args := "notify-send", []string{"--app-name=cueckoo", title, body}
+	if runtime.GOOS == "darwin" {
+		name, args = "osascript", []string{"-e", fmt.Sprintf("display notification %q with title %q", body, title)}
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("desktop notifications need %s, which was not found", name)
+	}
+	_, err := run(ctx, name, args...)
+	return err
+}
+
+type bellNotifier struct{}
+
+func (bellNotifier) notify(ctx context.Context, title, body string) error {
+	_, err := fmt.Fprintf(os.Stderr, "\a%s: %s\n", title, body)
+	return err
+}
+
+type webhookNotifier struct {
+	url string
+}
+
+func (n webhookNotifier) notify(ctx context.Context, title, body string) error {
+	data, err := json.Marshal(map[string]string{
+		"title": title,
+		"text":  title + ": " + body,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to post notification: %s", resp.Status)
+	}
+	return nil
+}
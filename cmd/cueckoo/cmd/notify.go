@@ -0,0 +1,179 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNotifyMe       flagName = "me"
+	flagNotifyQuery    flagName = "query"
+	flagNotifyEvents   flagName = "events"
+	flagNotifyInterval flagName = "interval"
+	flagNotifyNotify   flagName = "notify"
+)
+
+// notifyEvents are the kinds of event which notify can alert on.
+var notifyEvents = []string{"comment", "vote", "trybot"}
+
+// newNotifyCmd creates a new notify command
+func newNotifyCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "send notifications for activity on CLs",
+		Long: `
+Usage of notify:
+
+	notify [--me] [--query QUERY] [--events LIST] [--interval DURATION] [--notify SPEC]
+
+notify watches CLs in Gerrit and sends a notification whenever one of them
+receives a comment, a vote, or a trybot result, until interrupted.
+
+With --me, the open CLs owned by the user are watched. --query watches the
+CLs matching a Gerrit search query instead, such as "reviewer:self
+status:open"; when both are given, both must match. Activity by the user
+themselves is never notified.
+
+--events is a comma-separated list of the kinds of event to notify, out of
+comment, vote and trybot; all of them by default. A message which carries a
+TryBot-Result vote is a trybot event, and any other message with a vote is a
+vote event.
+
+Gerrit is polled every --interval. Notifications are sent via --notify, which
+is a comma-separated list of notifiers: "desktop", "bell", or a webhook URL.
+It defaults to the "` + notifierKey + `" entry in the user config, or "bell".
+`,
+		RunE: mkRunE(c, notifyDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.Bool(flagNotifyMe, false, "watch the open CLs owned by the user")
+	flags.String(flagNotifyQuery, "", "watch the CLs matching this Gerrit search query")
+	flags.String(flagNotifyEvents, strings.Join(notifyEvents, ","), "kinds of event to notify")
+	flags.String(flagNotifyInterval, "1m", "how often to poll Gerrit")
+	flags.String(flagNotifyNotify, "", "notifiers to send notifications with")
+	return cmd
+}
+
+func notifyDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("notify does not take any arguments")
+	}
+	var query []string
+	if flagNotifyMe.Bool(cmd) {
+		query = append(query, "owner:self status:open")
+	}
+	if q := flagNotifyQuery.String(cmd); q != "" {
+		query = append(query, "("+q+")")
+	}
+	if len(query) == 0 {
+		return fmt.Errorf("use --%s or --%s to choose the CLs to watch", flagNotifyMe, flagNotifyQuery)
+	}
+	events := make(map[string]bool)
+	for _, e := range strings.Split(flagNotifyEvents.String(cmd), ",") {
+		if !slicesContains(notifyEvents, e) {
+			return fmt.Errorf("unknown event %q; expected one of %s", e, strings.Join(notifyEvents, ", "))
+		}
+		events[e] = true
+	}
+	interval, err := parseDuration(flagNotifyInterval.String(cmd))
+	if err != nil {
+		return err
+	}
+	n, err := newNotifier(flagNotifyNotify.String(cmd))
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	var self gerrit.AccountInfo
+	if err := cfg.gerritDo(http.MethodGet, "accounts/self", nil, &self); err != nil {
+		return fmt.Errorf("failed to get Gerrit account: %w", err)
+	}
+
+	// Only activity after we start watching is notified.
+	last := time.Now()
+	fmt.Fprintf(cmd.OutOrStdout(), "watching CLs matching %q\n", strings.Join(query, " "))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		now := time.Now()
+		changes, _, err := cfg.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+			QueryOptions: gerrit.QueryOptions{
+				Query: []string{fmt.Sprintf("%s after:%q", strings.Join(query, " "), gerritTime(last.Add(-time.Minute)))},
+			},
+			ChangeOptions: gerrit.ChangeOptions{
+				AdditionalFields: []string{"MESSAGES"},
+			},
+		})
+		if err != nil {
+			// Carry on through transient failures.
+			fmt.Fprintf(os.Stderr, "%s: failed to query changes: %v\n", now.Format(time.TimeOnly), err)
+			continue
+		}
+		for _, ch := range *changes {
+			for _, m := range ch.Messages {
+				if !m.Date.Time.After(last) || m.Author.AccountID == self.AccountID {
+					continue
+				}
+				if !events[messageEvent(m.Message)] {
+					continue
+				}
+				title := fmt.Sprintf("CL %d: %s", ch.Number, ch.Subject)
+				body := fmt.Sprintf("%s: %s\n%s", accountName(m.Author), strings.TrimSpace(m.Message), cfg.changeURL(ch.Number))
+				if err := n.notify(ctx, title, body); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to send notification: %v\n", err)
+				}
+			}
+		}
+		last = now
+	}
+}
+
+var voteMessageRegex = regexp.MustCompile(`^Patch Set \d+:( [A-Za-z0-9-]+[+-]\d+)+`)
+
+// messageEvent returns the kind of event, as in notifyEvents, which the
+// change message msg represents, or the empty string for messages which are
+// not notified, such as for uploads. Gerrit starts messages with a summary of
+// any votes, such as "Patch Set 2: Code-Review+2".
+func messageEvent(msg string) string {
+	summary := firstLine(msg)
+	switch {
+	case strings.HasPrefix(summary, "Uploaded patch set"),
+		strings.HasPrefix(summary, "Change has been successfully"):
+		return ""
+	case strings.Contains(summary, "TryBot-Result"):
+		return "trybot"
+	case voteMessageRegex.MatchString(summary):
+		return "vote"
+	}
+	return "comment"
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newOrgCmd creates a new org command, which groups the subcommands for
+// looking after a GitHub organisation as a whole.
+func newOrgCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "org",
+		Short: "look after the repositories of a GitHub organisation",
+	}
+	subCommands := []*cobra.Command{
+		newOrgReportCmd(c),
+	}
+	for _, sub := range subCommands {
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	flagOrgReportFormat   flagName = "format"
+	flagOrgReportArchived flagName = "archived"
+	flagOrgReportSince    flagName = "since"
+)
+
+// newOrgReportCmd creates a new org report command
+func newOrgReportCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "report on the state of every repository in an organisation",
+		Long: `
+Usage of org report:
+
+	org report [--format table|markdown|json] [--since DURATION] [--archived] ORG
+
+report lists the repositories of the GitHub organisation ORG along with:
+
+	pushed    when the repository was last pushed to
+	commits   commits to the default branch within the --since window
+	issues    open issues
+	prs       open pull requests
+	ci        conclusion of the latest CI run on the default branch
+	license   the SPDX identifier of the license, if any
+	cfg       whether the repository has a codereview.cfg
+
+This helps to spot repositories which are unmaintained or not set up like the
+others. Archived repositories are skipped unless --archived is given.
+
+The report is printed as a table, a Markdown table suitable for pasting into
+an issue, or as JSON.
+`,
+		RunE: mkRunE(c, orgReportDef),
+	}
+	c.registerFlags(cmd).String(flagOrgReportFormat, "table", "output format: table, markdown or json").OneOf("table", "markdown", "json")
+	flags := c.registerFlags(cmd)
+	flags.String(flagOrgReportSince, "90d", "window for counting recent commits")
+	flags.Bool(flagOrgReportArchived, false, "include archived repositories")
+	return cmd
+}
+
+// orgRepo is the report on a single repository.
+type orgRepo struct {
+	Name          string    `json:"name"`
+	Archived      bool      `json:"archived"`
+	DefaultBranch string    `json:"defaultBranch"`
+	PushedAt      time.Time `json:"pushedAt"`
+	Commits       int       `json:"commits"`
+	OpenIssues    int       `json:"openIssues"`
+	OpenPRs       int       `json:"openPRs"`
+	CI            string    `json:"ci"`
+	License       string    `json:"license"`
+	CodeReviewCfg bool      `json:"codereviewCfg"`
+}
+
+func orgReportDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single organisation")
+	}
+	org := args[0]
+	format := flagOrgReportFormat.String(cmd)
+	since, err := parseDuration(flagOrgReportSince.String(cmd))
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	var repos []*github.Repository
+	opts := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := cfg.githubClient.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list repositories of %s: %w", org, err)
+		}
+		for _, r := range page {
+			if !r.GetArchived() || flagOrgReportArchived.Bool(cmd) {
+				repos = append(repos, r)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].GetName() < repos[j].GetName() })
+
+	report := make([]orgRepo, len(repos))
+	prog := newProgress(os.Stderr, "inspected", len(repos))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(8)
+	for i, r := range repos {
+		i, r := i, r
+		g.Go(func() error {
+			prog.begin(r.GetName())
+			var err error
+			report[i], err = cfg.inspectRepo(gctx, r, time.Now().Add(-since))
+			prog.end(r.GetName(), err)
+			return err
+		})
+	}
+	err = g.Wait()
+	prog.finish()
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(report)
+	case "markdown":
+		printOrgReportMarkdown(w, report)
+	default:
+		printOrgReport(w, report)
+	}
+	return nil
+}
+
+// inspectRepo gathers the report on r, counting the commits since the given
+// time.
+func (c *config) inspectRepo(ctx context.Context, r *github.Repository, since time.Time) (orgRepo, error) {
+	owner, name, branch := r.GetOwner().GetLogin(), r.GetName(), r.GetDefaultBranch()
+	res := orgRepo{
+		Name:          name,
+		Archived:      r.GetArchived(),
+		DefaultBranch: branch,
+		PushedAt:      r.GetPushedAt().Time,
+		License:       r.GetLicense().GetSPDXID(),
+	}
+	fail := func(what string, err error) (orgRepo, error) {
+		return orgRepo{}, fmt.Errorf("failed to get %s of %s/%s: %w", what, owner, name, err)
+	}
+
+	// Ask for a single item per page, such that the number of pages is the
+	// number of items.
+	one := github.ListOptions{PerPage: 1}
+	commits, resp, err := c.githubClient.Repositories.ListCommits(ctx, owner, name, &github.CommitsListOptions{
+		SHA:         branch,
+		Since:       since,
+		ListOptions: one,
+	})
+	// An empty repository has no default branch to list commits of.
+	if err != nil && statusCode(err) != http.StatusConflict {
+		return fail("commits", err)
+	}
+	res.Commits = pageCount(resp, len(commits))
+
+	prs, resp, err := c.githubClient.PullRequests.List(ctx, owner, name, &github.PullRequestListOptions{
+		State:       "open",
+		ListOptions: one,
+	})
+	if err != nil {
+		return fail("pull requests", err)
+	}
+	res.OpenPRs = pageCount(resp, len(prs))
+	// GitHub counts pull requests as issues.
+	res.OpenIssues = r.GetOpenIssuesCount() - res.OpenPRs
+
+	runs, _, err := c.githubClient.Actions.ListRepositoryWorkflowRuns(ctx, owner, name, &github.ListWorkflowRunsOptions{
+		Branch:      branch,
+		Event:       "push",
+		ListOptions: one,
+	})
+	if err != nil {
+		return fail("workflow runs", err)
+	}
+	if len(runs.WorkflowRuns) > 0 {
+		run := runs.WorkflowRuns[0]
+		res.CI = run.GetConclusion()
+		if res.CI == "" {
+			res.CI = run.GetStatus()
+		}
+	}
+
+	_, _, _, err = c.githubClient.Repositories.GetContents(ctx, owner, name, "codereview.cfg", nil)
+	switch {
+	case err == nil:
+		res.CodeReviewCfg = true
+	case statusCode(err) != http.StatusNotFound:
+		return fail("codereview.cfg", err)
+	}
+	return res, nil
+}
+
+// pageCount returns the number of pages of a paginated list, given the
+// response for its first page holding n items.
+func pageCount(resp *github.Response, n int) int {
+	if resp != nil && resp.LastPage > 0 {
+		return resp.LastPage
+	}
+	return n
+}
+
+func printOrgReport(w io.Writer, report []orgRepo) {
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "REPO\tPUSHED\tCOMMITS\tISSUES\tPRS\tCI\tLICENSE\tCFG")
+	for _, r := range report {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\n", orgRepoName(r), r.PushedAt.Format(time.DateOnly),
+			r.Commits, r.OpenIssues, r.OpenPRs, orNone(r.CI), orNone(r.License), yesNo(r.CodeReviewCfg))
+	}
+	tw.Flush()
+}
+
+func printOrgReportMarkdown(w io.Writer, report []orgRepo) {
+	fmt.Fprintln(w, "| Repository | Last push | Commits | Issues | PRs | CI | License | codereview.cfg |")
+	fmt.Fprintln(w, "|---|---|--:|--:|--:|---|---|---|")
+	for _, r := range report {
+		fmt.Fprintf(w, "| %s | %s | %d | %d | %d | %s | %s | %s |\n", orgRepoName(r), r.PushedAt.Format(time.DateOnly),
+			r.Commits, r.OpenIssues, r.OpenPRs, orNone(r.CI), orNone(r.License), yesNo(r.CodeReviewCfg))
+	}
+}
+
+func orgRepoName(r orgRepo) string {
+	if r.Archived {
+		return r.Name + " (archived)"
+	}
+	return r.Name
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNoColor flagName = "no-color"
+	flagPlain   flagName = "plain"
+)
+
+// noColor and plainOutput are the values of the global --no-color and
+// --plain flags for the command being run. noColor is also set by a
+// non-empty NO_COLOR environment variable, per https://no-color.org, and by
+// TERM=dumb.
+var (
+	noColor     bool
+	plainOutput bool
+)
+
+// readOutputFlags sets noColor and plainOutput from the global flags and the
+// environment.
+func readOutputFlags(cmd *cobra.Command) {
+	noColor, _ = cmd.Flags().GetBool(string(flagNoColor))
+	plainOutput, _ = cmd.Flags().GetBool(string(flagPlain))
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || plainOutput {
+		noColor = true
+	}
+}
+
+// tableWriter writes a table whose cells are terminated by tabs and whose
+// rows are terminated by newlines.
+type tableWriter interface {
+	io.Writer
+	Flush() error
+}
+
+// newTableWriter returns a tableWriter writing to w. Columns are normally
+// aligned with spaces; with --plain, the cells are written as they are, as
+// tab-separated values, which are stable for scripts and easier to follow
+// with a screen reader.
+func newTableWriter(w io.Writer) tableWriter {
+	if plainOutput {
+		return plainTable{w}
+	}
+	return tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+}
+
+type plainTable struct {
+	io.Writer
+}
+
+func (plainTable) Flush() error { return nil }
+
+// useEscapes reports whether terminal control sequences, such as those used
+// to redraw progress in place, may be written to w.
+func useEscapes(w io.Writer) bool {
+	return !noColor && isTerminal(w)
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// flagTemplate is the flag by which users provide a Go template to format
+// the output of a command, e.g. for their own dashboards.
+const flagTemplate flagName = "template"
+
+// addTemplateFlag adds --template to the command of flags. The command's
+// documentation should describe the data passed to the template.
+func addTemplateFlag(flags *flagRegistry) {
+	flags.String(flagTemplate, "", "format the output with the Go template in this file")
+}
+
+// templateFuncs are the functions available to --template templates in
+// addition to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v any) (string, error) {
+		b, err := json.MarshalIndent(v, "", "\t")
+		return string(b), err
+	},
+}
+
+// execTemplateFlag executes the template given by --template, if any, with
+// data, writing the result to the command's output. It reports whether there
+// was a template, in which case the command's usual output is skipped.
+func execTemplateFlag(cmd *Command, data any) (bool, error) {
+	fn := flagTemplate.String(cmd)
+	if fn == "" {
+		return false, nil
+	}
+	src, err := os.ReadFile(fn)
+	if err != nil {
+		return true, err
+	}
+	t, err := template.New(fn).Funcs(templateFuncs).Parse(string(src))
+	if err != nil {
+		return true, fmt.Errorf("invalid template: %v", err)
+	}
+	if err := t.Execute(cmd.OutOrStdout(), data); err != nil {
+		return true, fmt.Errorf("failed to execute template %s: %v", fn, err)
+	}
+	return true, nil
+}
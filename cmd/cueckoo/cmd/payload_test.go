@@ -15,11 +15,15 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"flag"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -28,6 +32,30 @@ import (
 
 var fUpdate = flag.Bool("update", false, "whether to update golden files")
 
+// payloadCase is a dispatch built as cueckoo would send it for an event
+// type.
+type payloadCase struct {
+	event eventType
+	dro   github.DispatchRequestOptions
+}
+
+// downstreamFixtures gives the files declaring the fields of the client
+// payload which the downstream workflows for each event type rely on, as a
+// #dispatch definition. The trybot workflows are generated from this
+// repository, so their definition is used directly.
+var downstreamFixtures = map[eventType]string{
+	eventTypeTrybot:  "../../../internal/ci/base/gerrithub.cue",
+	eventTypeUnity:   "testdata/downstream/unity.cue",
+	eventTypeCompat:  "testdata/downstream/compat.cue",
+	eventTypePreview: "testdata/downstream/preview.cue",
+
+	eventTypePRTrybot: "testdata/downstream/prtrybot.cue",
+}
+
+// TestPayloads checks the payloads of each event type against golden files,
+// against their schema in payloads.cue, and against the fields expected by
+// the downstream workflows, such that schema drift is caught here rather
+// than by dispatches which silently do nothing.
 func TestPayloads(t *testing.T) {
 	must := func(dro github.DispatchRequestOptions, err error) github.DispatchRequestOptions {
 		if err != nil {
@@ -35,27 +63,68 @@ func TestPayloads(t *testing.T) {
 		}
 		return dro
 	}
-	testCases := map[string]github.DispatchRequestOptions{
-		"runtrybot": must(buildTryBotPayload(repositoryDispatchPayload{
+	testCases := map[string]payloadCase{
+		"runtrybot": {eventTypeTrybot, must(buildTryBotPayload(repositoryDispatchPayload{
+			Type:         string(eventTypeTrybot),
 			CL:           12345,
 			Patchset:     42,
 			Ref:          "refs/changes/52/551352/140",
 			TargetBranch: "master",
-		})),
-		"unity_versions": must(buildUnityPayload("hello", unityPayload{
+		}))},
+		"runtrybot_pr": {eventTypePRTrybot, must(buildPRTrybotPayload(prTrybotPayload{
+			repositoryDispatchPayload: repositoryDispatchPayload{
+				Type:         string(eventTypePRTrybot),
+				Ref:          prHeadRef(2891),
+				TargetBranch: "master",
+			},
+			PR:  2891,
+			SHA: "4c5f2a1b0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b",
+		}))},
+		"unity_versions": {eventTypeUnity, must(buildUnityPayload("hello", unityPayload{
+			repositoryDispatchPayload: repositoryDispatchPayload{
+				Type: string(eventTypeUnity),
+			},
 			Versions: "\"v0.3.0-beta.5\"",
-		})),
-		"unity_cl": must(buildUnityPayloadFromCLTrigger(repositoryDispatchPayload{
+		}))},
+		"unity_cl": {eventTypeUnity, must(buildUnityPayloadFromCLTrigger(repositoryDispatchPayload{
+			Type:         string(eventTypeUnity),
 			CL:           54321,
 			Patchset:     24,
 			Ref:          "refs/changes/25/551325/14",
 			TargetBranch: "master",
-		})),
+		}))},
+		"compat": {eventTypeCompat, must(buildCompatPayload(compatPayload{
+			repositoryDispatchPayload: repositoryDispatchPayload{
+				Type:         string(eventTypeCompat),
+				CL:           12345,
+				Patchset:     42,
+				Ref:          "refs/changes/52/551352/140",
+				TargetBranch: "master",
+			},
+			Base: "v0.8.2",
+		}))},
+		"preview": {eventTypePreview, must(buildPreviewPayload(repositoryDispatchPayload{
+			Type:         string(eventTypePreview),
+			CL:           12345,
+			Patchset:     42,
+			Ref:          "refs/changes/52/551352/140",
+			TargetBranch: "master",
+		}))},
+	}
+
+	covered := make(map[string]bool)
+	for _, tc := range testCases {
+		covered[string(tc.event)] = true
+	}
+	for _, ev := range sortedKeys(explainEvents) {
+		if !covered[ev] {
+			t.Errorf("no payload test case for event type %q", ev)
+		}
 	}
 
-	for key, dro := range testCases {
+	for key, tc := range testCases {
 		t.Run(key, func(t *testing.T) {
-			byts, err := json.MarshalIndent(dro, "", "  ")
+			byts, err := json.MarshalIndent(tc.dro, "", "  ")
 			if err != nil {
 				t.Fatalf("failed to marshal: %v", err)
 			}
@@ -73,6 +142,124 @@ func TestPayloads(t *testing.T) {
 					t.Fatalf("failed to update golden file %v: %v", fn, err)
 				}
 			}
+
+			var payload map[string]any
+			if err := json.Unmarshal(*tc.dro.ClientPayload, &payload); err != nil {
+				t.Fatal(err)
+			}
+			schema := schemaFields(t, payloadSchemas, "change", string(tc.event))
+			checkPayloadSchema(t, payload, schema)
+
+			fixture, err := os.ReadFile(downstreamFixtures[tc.event])
+			if err != nil {
+				t.Fatalf("failed to read downstream fixture: %v", err)
+			}
+			for name, f := range schemaFields(t, string(fixture), "dispatch") {
+				if _, ok := schema[name]; !ok {
+					t.Errorf("downstream field %q is not in the schema", name)
+				}
+				if _, ok := payload[name]; !ok && !f.optional {
+					t.Errorf("payload lacks field %q expected downstream", name)
+				}
+			}
+		})
+	}
+}
+
+// schemaField is a field of a definition in a CUE file.
+type schemaField struct {
+	// optional is set for optional fields, and for fields with a default.
+	optional bool
+
+	// value is the constraint of the field, such as int or "trybot".
+	value string
+}
+
+var schemaFieldRegex = regexp.MustCompile(`^\t(\w+)(\?)?:\s*(.+)$`)
+
+// schemaFields returns the top-level fields of the given definitions in the
+// CUE source src, with later definitions taking precedence. It is no CUE
+// evaluator; it relies on the definitions being cue fmt'ed and simple.
+func schemaFields(t *testing.T, src string, defs ...string) map[string]schemaField {
+	fields := make(map[string]schemaField)
+	for _, def := range defs {
+		d := cueDefinition(src, def)
+		if d == "" {
+			t.Fatalf("no definition #%s", def)
+		}
+		sc := bufio.NewScanner(strings.NewReader(d))
+		for sc.Scan() {
+			m := schemaFieldRegex.FindStringSubmatch(sc.Text())
+			if m == nil {
+				continue
+			}
+			fields[m[1]] = schemaField{
+				optional: m[2] != "" || strings.HasPrefix(m[3], "*"),
+				value:    m[3],
+			}
+		}
+	}
+	return fields
+}
+
+// checkPayloadSchema checks that the payload has every required field of the
+// schema and no other fields, and that each field has the right type.
+func checkPayloadSchema(t *testing.T, payload map[string]any, schema map[string]schemaField) {
+	t.Helper()
+	for name, f := range schema {
+		if _, ok := payload[name]; !ok && !f.optional {
+			t.Errorf("payload lacks required field %q", name)
+		}
+	}
+	for name, v := range payload {
+		f, ok := schema[name]
+		if !ok {
+			t.Errorf("payload field %q is not in the schema", name)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(f.value, "int"):
+			if n, ok := v.(float64); !ok || n != float64(int(n)) {
+				t.Errorf("payload field %q is %v; want an integer", name, v)
+			}
+		case f.value == "string":
+			if _, ok := v.(string); !ok {
+				t.Errorf("payload field %q is %v; want a string", name, v)
+			}
+		case strings.HasPrefix(f.value, `"`):
+			if want, _ := strconv.Unquote(f.value); v != want {
+				t.Errorf("payload field %q is %v; want %s", name, v, f.value)
+			}
+		default:
+			t.Errorf("schema field %q has unsupported constraint %s", name, f.value)
+		}
+	}
+}
+
+func TestCheckPayloadManifestEventType(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		event   eventType
+		wantErr bool
+	}{
+		{name: "no manifest", content: "", event: eventTypeTrybot},
+		{name: "no manifest opt-in", content: "", event: eventTypePRTrybot, wantErr: true},
+		{name: "undeclared opt-in", content: `{"maxPayloadVersion": 1}`, event: eventTypePRTrybot, wantErr: true},
+		{name: "declared opt-in", content: `{"eventTypes": ["prtrybot"]}`, event: eventTypePRTrybot},
+		{name: "undeclared", content: `{"eventTypes": ["prtrybot"]}`, event: eventTypeUnity},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := checkPayloadManifest("cue-lang", "cue", tc.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = checkManifestEventType("cue-lang", "cue", m, tc.event)
+			if got := err != nil; got != tc.wantErr {
+				t.Errorf("checkManifestEventType(%q) error = %v; want error: %v", tc.event, err, tc.wantErr)
+			}
 		})
 	}
 }
@@ -0,0 +1,104 @@
+// This file documents the client payloads of the repository dispatch events
+// sent by cueckoo, for the authors of the GitHub workflows which consume
+// them. It is embedded in cueckoo and printed by "cueckoo explain".
+//
+// The payload is available in a workflow as github.event.client_payload,
+// and the event type, which doubles as the display title of the workflow
+// run, as github.event.action.
+
+package payloads
+
+// #change holds the fields common to the payloads of events for a Gerrit
+// change.
+#change: {
+	// payloadVersion is the version of the payload schema. Repositories
+	// declare the versions they support in .github/cueckoo-payload.json.
+	payloadVersion: int & >=1
+
+	// type is the kind of event, distinguishing events sent to the same
+	// repository.
+	type?: string
+
+	// CL is the number of the Gerrit change.
+	CL?: int
+
+	// patchset is the number of the patchset of the change.
+	patchset?: int
+
+	// targetBranch is the branch the change is for, such as master.
+	targetBranch?: string
+
+	// ref is the Gerrit ref of the patchset, such as
+	// refs/changes/52/551352/140, which can be fetched from the Gerrit
+	// remote.
+	ref?: string
+}
+
+// #trybot is sent to the GitHub mirror of a Gerrit project by runtrybot and
+// friends, to run the trybot workflow for a patchset. The event type is
+// "trybot run for REF".
+#trybot: #change & {
+	type:     "trybot"
+	CL:       int
+	patchset: int
+	ref:      string
+}
+
+// #prtrybot is sent to the GitHub mirror by runtrybot --pr, to run the
+// trybot workflow for the head of a GitHub PR which has not been imported
+// into Gerrit, such as to test a community contribution before importing
+// it. It is only sent to repositories which list "prtrybot" in the
+// eventTypes of .github/cueckoo-payload.json, as the trybot dispatch
+// workflow does not handle it. None of the Gerrit change fields are set. The event type is
+// "trybot run for REF".
+#prtrybot: #change & {
+	type:         "prtrybot"
+	targetBranch: string
+
+	// ref is the GitHub ref of the head of the PR, such as
+	// refs/pull/12/head, which can be fetched from the GitHub mirror.
+	ref: string
+
+	// PR is the number of the GitHub pull request.
+	PR: int
+
+	// sha is the head commit of the PR as of the dispatch, as ref moves
+	// when the PR is updated.
+	sha: string
+}
+
+// #unity is sent to the unity repository by runtrybot and unity. The event
+// type is "unity run for REF" when testing a patchset, and "unity run for
+// versions VERSIONS" when testing released versions, in which case none of
+// the change fields are set.
+#unity: #change & {
+	type: "unity"
+
+	// versions is a space-separated list of quoted CUE versions to run
+	// unity against, such as "\"v0.3.0-beta.5\" \"v0.3.0-beta.4\"".
+	versions?: string
+}
+
+// #compat is sent to the GitHub mirror by compat, to check a patchset for
+// compatibility with a released version. The event type is
+// "compat run for REF".
+#compat: #change & {
+	type:     "compat"
+	CL:       int
+	patchset: int
+	ref:      string
+
+	// base is the version to check compatibility against, such as
+	// v0.8.2.
+	base: string
+}
+
+// #preview is sent to the GitHub mirror of the website repository by
+// preview, to build and deploy a preview of the website for a patchset. The
+// event type is "preview run for REF".
+#preview: #change & {
+	type:     "preview"
+	CL:       int
+	patchset: int
+	ref:      string
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect of the schemas exported by
+// explain, which is also the one used by OpenAPI 3.1.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// payloadJSONSchema returns the JSON Schema of the payload of event type ev,
+// derived from its CUE definition in payloads.cue.
+//
+// Only the subset of CUE used by payloads.cue is understood: a definition
+// is a struct, optionally the conjunction of another definition and a
+// struct, whose fields are of type int, string, a string literal, or int
+// with a lower bound, such as "int & >=1". Fields declared both in the
+// embedded definition and in the struct are merged, and are required if
+// either declaration is.
+func payloadJSONSchema(ev string) (map[string]any, error) {
+	src := cueDefinition(payloadSchemas, ev)
+	if src == "" {
+		return nil, fmt.Errorf("no definition #%s in payloads.cue", ev)
+	}
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	var doc []string
+	for len(lines) > 0 && strings.HasPrefix(lines[0], "//") {
+		doc = append(doc, strings.TrimSpace(strings.TrimPrefix(lines[0], "//")))
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("malformed definition #%s", ev)
+	}
+	header := strings.TrimSpace(strings.TrimPrefix(lines[0], "#"+ev+":"))
+
+	schema := map[string]any{"type": "object"}
+	props := make(map[string]any)
+	required := make(map[string]bool)
+	if base, ok := strings.CutSuffix(header, " & {"); ok {
+		baseSchema, err := payloadJSONSchema(strings.TrimPrefix(base, "#"))
+		if err != nil {
+			return nil, err
+		}
+		for name, p := range baseSchema["properties"].(map[string]any) {
+			props[name] = p
+		}
+		for _, name := range baseSchema["required"].([]string) {
+			required[name] = true
+		}
+	} else if header != "{" {
+		return nil, fmt.Errorf("unsupported definition #%s: %s", ev, header)
+	}
+	if len(doc) > 0 {
+		schema["description"] = strings.Join(doc, " ")
+	}
+
+	var fieldDoc []string
+	for _, line := range lines[1 : len(lines)-1] {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			fieldDoc = nil
+			continue
+		case strings.HasPrefix(line, "//"):
+			fieldDoc = append(fieldDoc, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+			continue
+		}
+		name, expr, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("unsupported line in #%s: %s", ev, line)
+		}
+		name, optional := strings.CutSuffix(name, "?")
+		p, err := cueTypeJSONSchema(strings.TrimSpace(expr))
+		if err != nil {
+			return nil, fmt.Errorf("field %s of #%s: %v", name, ev, err)
+		}
+		if prev, ok := props[name].(map[string]any); ok {
+			for k, v := range prev {
+				if _, ok := p[k]; !ok {
+					p[k] = v
+				}
+			}
+		}
+		if len(fieldDoc) > 0 {
+			p["description"] = strings.Join(fieldDoc, " ")
+		}
+		fieldDoc = nil
+		props[name] = p
+		if !optional {
+			required[name] = true
+		}
+	}
+	schema["properties"] = props
+	schema["required"] = sortedKeys(required)
+	return schema, nil
+}
+
+// cueTypeJSONSchema returns the JSON Schema for a CUE type expression in the
+// subset documented by payloadJSONSchema.
+func cueTypeJSONSchema(expr string) (map[string]any, error) {
+	switch expr {
+	case "int":
+		return map[string]any{"type": "integer"}, nil
+	case "string":
+		return map[string]any{"type": "string"}, nil
+	}
+	if s, err := strconv.Unquote(expr); err == nil {
+		return map[string]any{"type": "string", "const": s}, nil
+	}
+	if bound, ok := strings.CutPrefix(expr, "int & >="); ok {
+		n, err := strconv.Atoi(bound)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported bound %q", bound)
+		}
+		return map[string]any{"type": "integer", "minimum": n}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", expr)
+}
+
+// payloadSchemaDocument returns the JSON Schema or OpenAPI document, as
+// selected by format, describing the payloads of the given event types.
+// A JSON Schema document for a single event type is the schema of its
+// payload; otherwise the schemas are listed under $defs, or under
+// components.schemas for OpenAPI.
+func payloadSchemaDocument(format string, events []string) (map[string]any, error) {
+	sort.Strings(events)
+	schemas := make(map[string]any)
+	for _, ev := range events {
+		s, err := payloadJSONSchema(ev)
+		if err != nil {
+			return nil, err
+		}
+		schemas[ev] = s
+	}
+	switch format {
+	case "jsonschema":
+		if len(events) == 1 {
+			s := schemas[events[0]].(map[string]any)
+			s["$schema"] = jsonSchemaDraft
+			s["title"] = events[0]
+			return s, nil
+		}
+		return map[string]any{
+			"$schema":     jsonSchemaDraft,
+			"description": "The client payloads of the repository dispatch events sent by cueckoo.",
+			"$defs":       schemas,
+		}, nil
+	case "openapi":
+		return map[string]any{
+			"openapi": "3.1.0",
+			"info": map[string]any{
+				"title":       "cueckoo dispatch payloads",
+				"description": "The client payloads of the repository dispatch events sent by cueckoo.",
+				"version":     strconv.Itoa(payloadVersion),
+			},
+			"paths":      map[string]any{},
+			"components": map[string]any{"schemas": schemas},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// payloadVersion is the version of the schema of the dispatch payloads sent
+// by cueckoo. It must be incremented whenever a change to the payloads would
+// cause a workflow which expects the old schema to misbehave.
+const payloadVersion = 1
+
+// payloadManifestFile is the path of the file in a repository which declares
+// the range of payload versions that its workflows understand, and the event
+// types of optInEventTypes which they handle, such as:
+//
+//	{"minPayloadVersion": 1, "maxPayloadVersion": 1, "eventTypes": ["prtrybot"]}
+//
+// Repositories without the file are assumed to accept any version, and none
+// of optInEventTypes.
+const payloadManifestFile = ".github/cueckoo-payload.json"
+
+// optInEventTypes are the event types which the workflows of a repository must
+// declare in its payload manifest before cueckoo sends them. Workflows which
+// predate these types ignore them, so a dispatch would silently never run.
+var optInEventTypes = []eventType{eventTypePRTrybot}
+
+// payloadCheckMaxAge is how long the result of checkPayloadVersion is
+// remembered for a repository. A long-running daemon or serve then notices
+// when the workflows of a repository are updated.
+const payloadCheckMaxAge = 10 * time.Minute
+
+// payloadCheck is the result of checkPayloadVersion for a repository.
+type payloadCheck struct {
+	manifest payloadManifest
+	err      error
+	checked  time.Time
+}
+
+type payloadManifest struct {
+	MinPayloadVersion int      `json:"minPayloadVersion"`
+	MaxPayloadVersion int      `json:"maxPayloadVersion"`
+	EventTypes        []string `json:"eventTypes"`
+}
+
+// checkPayloadVersion checks that the workflows in owner/repo understand the
+// payloads sent by this version of cueckoo, as declared by the repository's
+// payload manifest. The result is remembered for each repository for up to
+// payloadCheckMaxAge; failures to fetch the manifest are not remembered.
+func (c *config) checkPayloadVersion(ctx context.Context, owner, repo string) error {
+	pc, err := c.payloadCheck(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	return pc.err
+}
+
+// checkPayloadEventType checks that the workflows in owner/repo handle
+// dispatches of type typ, as declared by the repository's payload manifest
+// for the types in optInEventTypes. Other types are assumed to be handled.
+func (c *config) checkPayloadEventType(ctx context.Context, owner, repo string, typ eventType) error {
+	pc, err := c.payloadCheck(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	return checkManifestEventType(owner, repo, pc.manifest, typ)
+}
+
+// payloadCheck returns the result of checking the payload manifest of
+// owner/repo, fetching it if it is not remembered.
+func (c *config) payloadCheck(ctx context.Context, owner, repo string) (payloadCheck, error) {
+	key := owner + "/" + repo
+	c.payloadChecksMu.Lock()
+	defer c.payloadChecksMu.Unlock()
+	if pc, ok := c.payloadChecks[key]; ok && time.Since(pc.checked) < payloadCheckMaxAge {
+		return pc, nil
+	}
+	_, content, err := c.defaultBranchFile(ctx, owner, repo, payloadManifestFile)
+	if err != nil {
+		return payloadCheck{}, fmt.Errorf("failed to check payload version: %w", err)
+	}
+	m, err := checkPayloadManifest(owner, repo, content)
+	pc := payloadCheck{manifest: m, err: err, checked: time.Now()}
+	if c.payloadChecks == nil {
+		c.payloadChecks = make(map[string]payloadCheck)
+	}
+	c.payloadChecks[key] = pc
+	return pc, nil
+}
+
+// checkPayloadManifest decodes and checks the payload manifest of owner/repo,
+// given its content, which is empty if the repository has none.
+func checkPayloadManifest(owner, repo, content string) (payloadManifest, error) {
+	var m payloadManifest
+	if content == "" {
+		debugf("%s/%s has no %s; assuming payload version %d is supported\n", owner, repo, payloadManifestFile, payloadVersion)
+		return m, nil
+	}
+	if err := json.Unmarshal([]byte(content), &m); err != nil {
+		return m, fmt.Errorf("failed to decode %s in %s/%s: %v", payloadManifestFile, owner, repo, err)
+	}
+	switch {
+	case m.MinPayloadVersion != 0 && payloadVersion < m.MinPayloadVersion:
+		return m, fmt.Errorf("the workflows in %s/%s require payload version %d or later, but this cueckoo sends version %d; upgrade with:\n\n\tgo install github.com/cue-lang/contrib-tools/cmd/cueckoo@latest",
+			owner, repo, m.MinPayloadVersion, payloadVersion)
+	case m.MaxPayloadVersion != 0 && payloadVersion > m.MaxPayloadVersion:
+		return m, fmt.Errorf("the workflows in %s/%s only support payload versions up to %d, but this cueckoo sends version %d; the workflows need updating, or use an older cueckoo",
+			owner, repo, m.MaxPayloadVersion, payloadVersion)
+	}
+	return m, nil
+}
+
+// checkManifestEventType checks that the payload manifest m of owner/repo
+// declares the event type typ, if it is one of optInEventTypes.
+func checkManifestEventType(owner, repo string, m payloadManifest, typ eventType) error {
+	optIn := false
+	for _, t := range optInEventTypes {
+		if t == typ {
+			optIn = true
+			break
+		}
+	}
+	if !optIn {
+		return nil
+	}
+	for _, t := range m.EventTypes {
+		if t == string(typ) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the workflows in %s/%s do not declare that they handle %q dispatches in the eventTypes of %s; add a workflow which handles them first",
+		owner, repo, typ, payloadManifestFile)
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const flagPingCount flagName = "count"
+
+// newPingCmd creates a new ping command
+func newPingCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ping",
+		Short: "check the reachability of, and credentials for, Gerrit and GitHub",
+		Long: `
+Usage of ping:
+
+	ping [--count N]
+
+ping probes the Gerrit and GitHub APIs configured in codereview.cfg, both
+anonymously and with your credentials, and prints the round-trip time of
+each probe. Failures are diagnosed where possible, for example as a DNS or TLS
+problem, rejected credentials (401), insufficient permissions (403), or the
+service being unavailable (5xx), to quickly tell apart problems with your
+setup from an outage.
+
+Each endpoint is probed --count times, and the minimum, average and maximum
+times are reported.
+`,
+		RunE: mkRunE(c, pingDef),
+	}
+	c.registerFlags(cmd).Int(flagPingCount, 3, "number of times to probe each endpoint")
+	return cmd
+}
+
+// pingProbe is a single endpoint checked by ping.
+type pingProbe struct {
+	name  string
+	probe func(context.Context) error
+}
+
+func pingDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("ping does not take any arguments")
+	}
+	count := flagPingCount.Int(cmd)
+	if count < 1 {
+		return fmt.Errorf("--%s must be at least 1", flagPingCount)
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	probes := []pingProbe{{
+		name:  "gerrit (anonymous)",
+		probe: func(ctx context.Context) error { return pingURL(ctx, cfg.gerritURL+"config/server/version") },
+	}, {
+		name: "gerrit (authenticated)",
+		probe: func(context.Context) error {
+			return cfg.gerritDo(http.MethodGet, "accounts/self", nil, nil)
+		},
+	}, {
+		name:  "github (anonymous)",
+		probe: func(ctx context.Context) error { return pingURL(ctx, cfg.githubClient.BaseURL.String()) },
+	}, {
+		name: "github (authenticated)",
+		probe: func(ctx context.Context) error {
+			_, _, err := cfg.githubClient.Users.Get(ctx, "")
+			return err
+		},
+	}}
+
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "ENDPOINT\tRESULT\tMIN\tAVG\tMAX\tDIAGNOSIS")
+	failed := 0
+	for _, p := range probes {
+		var min, max, total time.Duration
+		var err error
+		n := 0
+		for n < count && err == nil {
+			start := time.Now()
+			err = p.probe(ctx)
+			d := time.Since(start)
+			if n == 0 || d < min {
+				min = d
+			}
+			if d > max {
+				max = d
+			}
+			total += d
+			n++
+		}
+		result, diagnosis := "ok", ""
+		if err != nil {
+			failed++
+			result = "failed"
+			diagnosis = diagnosePing(err)
+			debugf("%s: %v\n", p.name, err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%v\t%v\t%s\n", p.name, result,
+			min.Round(time.Millisecond), (total / time.Duration(n)).Round(time.Millisecond), max.Round(time.Millisecond), diagnosis)
+	}
+	tw.Flush()
+	switch {
+	case failed == 0:
+		return nil
+	case failed < len(probes):
+		return &partialFailureError{failed: failed, total: len(probes)}
+	default:
+		return fmt.Errorf("all probes failed")
+	}
+}
+
+// pingURL makes an unauthenticated GET request to u, failing on a server
+// error. Client errors are expected, as some endpoints require
+// authentication, but still show the server to be up.
+func pingURL(ctx context.Context, u string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return &httpStatusError{code: resp.StatusCode, status: resp.Status}
+	}
+	return nil
+}
+
+type httpStatusError struct {
+	code   int
+	status string
+}
+
+func (e *httpStatusError) Error() string {
+	return "unexpected status " + e.status
+}
+
+// statusCode returns the HTTP status code of a failed API request, or zero if
+// err did not result from an HTTP response.
+func statusCode(err error) int {
+	var ghErr *github.ErrorResponse
+	var hsErr *httpStatusError
+	switch {
+	case errors.As(err, &ghErr) && ghErr.Response != nil:
+		return ghErr.Response.StatusCode
+	case errors.As(err, &hsErr):
+		return hsErr.code
+	}
+	return gerritStatus(err)
+}
+
+// diagnosePing returns a short explanation of a failed probe.
+func diagnosePing(err error) string {
+	var (
+		dnsErr      *net.DNSError
+		certErr     *tls.CertificateVerificationError
+		unknownAuth x509.UnknownAuthorityError
+		hostErr     x509.HostnameError
+		opErr       *net.OpError
+		netErr      net.Error
+	)
+	switch code := statusCode(err); {
+	case code == http.StatusUnauthorized:
+		return "credentials rejected; check your git credential helper or environment variables"
+	case code == http.StatusForbidden:
+		return "authenticated, but access forbidden; check token scopes and permissions"
+	case code == http.StatusTooManyRequests:
+		return "rate limited"
+	case code >= 500:
+		return fmt.Sprintf("server error %d; the service may be down", code)
+	case code != 0:
+		return fmt.Sprintf("unexpected HTTP status %d", code)
+	}
+	switch {
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("DNS lookup of %s failed", dnsErr.Name)
+	case errors.As(err, &certErr), errors.As(err, &unknownAuth), errors.As(err, &hostErr):
+		return "TLS certificate verification failed; check for an intercepting proxy"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timed out"
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return "could not connect; check your network or proxy settings"
+	}
+	return firstLine(err.Error())
+}
@@ -0,0 +1,216 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix is the prefix of the names of the executables on PATH which
+// extend cueckoo with subcommands; see runPlugin.
+const pluginPrefix = "cueckoo-"
+
+// pluginConfig is the resolved config passed to plugins as JSON in the
+// CUECKOO_CONFIG environment variable. Credentials themselves are not
+// passed; plugins are told where cueckoo found them so that they can do the
+// same.
+type pluginConfig struct {
+	GitRoot string `json:"gitRoot"`
+	Gerrit  struct {
+		URL         string `json:"url"`
+		Project     string `json:"project"`
+		Credentials string `json:"credentials"`
+	} `json:"gerrit"`
+	GitHub struct {
+		Owner       string `json:"owner"`
+		Repo        string `json:"repo"`
+		Credentials string `json:"credentials"`
+	} `json:"github"`
+	Unity *struct {
+		Owner string `json:"owner"`
+		Repo  string `json:"repo"`
+	} `json:"unity,omitempty"`
+}
+
+func (c *config) pluginConfig() pluginConfig {
+	var pc pluginConfig
+	pc.GitRoot = c.gitRoot
+	pc.Gerrit.URL = c.gerritURL
+	pc.Gerrit.Project = c.gerritProject()
+	pc.Gerrit.Credentials = c.gerritCredentials
+	pc.GitHub.Owner = c.githubOwner
+	pc.GitHub.Repo = c.githubRepo
+	pc.GitHub.Credentials = c.githubCredentials
+	if c.unityOwner != "" {
+		pc.Unity = &struct {
+			Owner string `json:"owner"`
+			Repo  string `json:"repo"`
+		}{c.unityOwner, c.unityRepo}
+	}
+	return pc
+}
+
+// runPlugin runs the plugin named by args[0], if it is not a cueckoo command
+// and an executable named cueckoo-NAME is found on PATH, reporting whether
+// it did along with the plugin's exit code.
+func runPlugin(ctx context.Context, args []string) (code int, ok bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isCommand(newRootCmd().root, args[0]) {
+		return 0, false
+	}
+	path, err := exec.LookPath(pluginPrefix + args[0])
+	if err != nil {
+		return 0, false
+	}
+
+	env := os.Environ()
+	if self, err := os.Executable(); err == nil {
+		env = append(env, "CUECKOO="+self)
+	}
+	// Plugins may well be useful outside of a repository configured for
+	// cueckoo, so failing to load the config is not fatal.
+	if cfg, err := loadConfig(ctx); err != nil {
+		debugf("not passing config to plugin: %v\n", err)
+	} else {
+		byts, err := json.Marshal(cfg.pluginConfig())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1, true
+		}
+		env = append(env, "CUECKOO_CONFIG="+string(byts))
+	}
+
+	cmd := exec.CommandContext(ctx, path, args[1:]...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), true
+		}
+		fmt.Fprintf(os.Stderr, "failed to run plugin %s: %v\n", path, err)
+		return 1, true
+	}
+	return 0, true
+}
+
+// isCommand reports whether name is a subcommand of root, including those
+// which cobra adds itself.
+func isCommand(root *cobra.Command, name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPlugins returns the paths of the plugins in the directories of the
+// given PATH list, by name. As with exec.LookPath, the first directory wins.
+func findPlugins(pathList string) map[string]string {
+	plugins := make(map[string]string)
+	for _, dir := range filepath.SplitList(pathList) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), pluginPrefix)
+			if !ok || e.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if _, ok := plugins[name]; ok {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if info, err := e.Info(); err != nil || (runtime.GOOS != "windows" && info.Mode()&0o111 == 0) {
+				continue
+			}
+			plugins[name] = path
+		}
+	}
+	return plugins
+}
+
+// newPluginsCmd creates a new plugins command
+func newPluginsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugins",
+		Short: "list the plugins found on PATH",
+		Long: `
+Usage of plugins:
+
+	plugins
+
+plugins lists the executables named cueckoo-NAME found on $PATH, which can be
+run as "cueckoo NAME". Plugins named after a cueckoo command are listed as
+shadowed, as the command takes precedence.
+
+cueckoo can be extended without forking it: running "cueckoo NAME ARGS..."
+for a NAME which is not a cueckoo command runs an executable named
+cueckoo-NAME found on $PATH, as with git and kubectl, passing it ARGS. The
+plugin inherits the standard input and output, and receives the resolved
+config of the repository as JSON in $CUECKOO_CONFIG, with fields:
+
+	gitRoot                the root directory of the git repository
+	gerrit.url             the base URL of the Gerrit instance
+	gerrit.project         the Gerrit project
+	gerrit.credentials     where the Gerrit credentials are taken from
+	github.owner           the owner of the GitHub repository
+	github.repo            the name of the GitHub repository
+	github.credentials     where the GitHub credentials are taken from
+	unity.owner            the owner of the unity repository, if any
+	unity.repo             the name of the unity repository, if any
+
+$CUECKOO_CONFIG is unset outside of a repository configured for cueckoo.
+$CUECKOO gives the path of the cueckoo executable itself.
+`,
+		RunE: mkRunE(c, pluginsDef),
+	}
+	return cmd
+}
+
+func pluginsDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("plugins does not take any arguments")
+	}
+	plugins := findPlugins(os.Getenv("PATH"))
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "NAME\tPATH\tNOTE")
+	for _, name := range sortedKeys(plugins) {
+		note := ""
+		if isCommand(cmd.Root(), name) {
+			note = "shadowed by a cueckoo command"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, plugins[name], orNone(note))
+	}
+	return tw.Flush()
+}
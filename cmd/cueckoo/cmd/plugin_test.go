@@ -0,0 +1,50 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on executable bits")
+	}
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	for path, mode := range map[string]os.FileMode{
+		filepath.Join(dir1, "cueckoo-stats"): 0o755,
+		filepath.Join(dir1, "cueckoo-notes"): 0o644,
+		filepath.Join(dir2, "cueckoo-stats"): 0o755,
+		filepath.Join(dir2, "cueckoo-lint"):  0o755,
+		filepath.Join(dir2, "other-command"): 0o755,
+	} {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := findPlugins(dir1 + string(filepath.ListSeparator) + filepath.Join(dir1, "missing") + string(filepath.ListSeparator) + dir2)
+	want := map[string]string{
+		"stats": filepath.Join(dir1, "cueckoo-stats"),
+		"lint":  filepath.Join(dir2, "cueckoo-lint"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("plugins mismatch (-want +got):\n%s", diff)
+	}
+}
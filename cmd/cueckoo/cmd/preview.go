@@ -0,0 +1,159 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagPreviewWait    flagName = "wait"
+	flagPreviewTimeout flagName = "timeout"
+
+	// previewURLArtifact is the name of the artifact in which the preview
+	// workflow uploads a file holding the URL of the deployed preview.
+	previewURLArtifact = "preview-url"
+
+	previewTag = "autogenerated:cueckoo-preview"
+)
+
+// newPreviewCmd creates a new preview command
+func newPreviewCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "build a preview of the website for a docs CL",
+		Long: `
+Usage of preview:
+
+	preview [--wait] [--timeout DURATION] CL
+
+preview dispatches a build and deploy of a preview of the website for the
+current patchset of the given CL, as used by the cue-lang/cuelang.org
+repository.
+
+The preview is built by a workflow in the GitHub repository which handles
+repository dispatch events of type "preview"; see "cueckoo explain preview".
+The workflow is expected to upload an artifact named "` + previewURLArtifact + `"
+holding a single file with the URL of the deployed preview.
+
+With --wait, preview waits for the workflow to complete, up to --timeout,
+prints the URL of the preview, and posts it to the CL as a comment.
+`,
+		RunE:              mkRunE(c, previewDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	flags := c.registerFlags(cmd)
+	flags.Bool(flagPreviewWait, false, "wait for the preview and post its URL to the CL")
+	flags.String(flagPreviewTimeout, "30m", "how long to wait for the preview")
+	flags.Bool(flagOverrideFreeze, false, "proceed even during a freeze window of the repository")
+	return cmd
+}
+
+// previewRunTitle returns the display title of the workflow run resulting
+// from a preview dispatch for the Gerrit ref.
+func previewRunTitle(ref string) string {
+	return fmt.Sprintf("preview run for %v", ref)
+}
+
+func buildPreviewPayload(payload repositoryDispatchPayload) (github.DispatchRequestOptions, error) {
+	payload.PayloadVersion = payloadVersion
+	return buildDispatchPayload(previewRunTitle(payload.Ref), payload)
+}
+
+func previewDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single CL")
+	}
+	timeout, err := parseDuration(flagPreviewTimeout.String(cmd))
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkFreeze(cmd); err != nil {
+		return err
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	ch, _, err := cfg.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+
+	rev := ch.Revisions[ch.CurrentRevision]
+	p, err := buildPreviewPayload(repositoryDispatchPayload{
+		Type:         string(eventTypePreview),
+		CL:           ch.Number,
+		Patchset:     rev.Number,
+		TargetBranch: ch.Branch,
+		Ref:          rev.Ref,
+	})
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := cfg.triggerRepositoryDispatch(ctx, cfg.githubOwner, cfg.githubRepo, p); err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "dispatched preview of CL %d patchset %d\n", ch.Number, rev.Number)
+	if !flagPreviewWait.Bool(cmd) {
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	run, err := cfg.waitForDispatchedRun(waitCtx, cfg.githubOwner, cfg.githubRepo, previewRunTitle(rev.Ref), start)
+	if err != nil {
+		return err
+	}
+	if run.GetConclusion() != "success" {
+		return fmt.Errorf("preview build %s concluded %s", run.GetHTMLURL(), run.GetConclusion())
+	}
+	files, err := cfg.runArtifact(ctx, cfg.githubOwner, cfg.githubRepo, run, previewURLArtifact)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("preview build %s did not upload a single file in the %s artifact", run.GetHTMLURL(), previewURLArtifact)
+	}
+	var url string
+	for _, content := range files {
+		url = strings.TrimSpace(string(content))
+	}
+	fmt.Fprintf(w, "preview deployed to %s\n", url)
+
+	if _, _, err := cfg.gerritClient.Changes.SetReview(fmt.Sprint(ch.Number), fmt.Sprint(rev.Number), &gerrit.ReviewInput{
+		Message: fmt.Sprintf("Preview of patchset %d: %s", rev.Number, url),
+		Tag:     previewTag,
+	}); err != nil {
+		return fmt.Errorf("failed to post preview URL to CL %d: %w", ch.Number, err)
+	}
+	return nil
+}
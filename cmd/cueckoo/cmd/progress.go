@@ -0,0 +1,162 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// progress reports the progress of a long-running operation made up of a
+// number of items, such as CLs being dispatched or pages being fetched.
+//
+// When writing to a terminal, a single status line is redrawn in place,
+// showing a spinner for each in-flight item and an estimate of the time
+// remaining. Otherwise, or with --no-color, a plain line is written as each
+// item completes, which is better suited to logs and screen readers.
+//
+// The methods of a nil *progress do nothing, which allows callers to only
+// report progress when there is enough work for it to be useful.
+type progress struct {
+	w    io.Writer
+	tty  bool
+	verb string // past tense, e.g. "dispatched"
+
+	mu     sync.Mutex
+	total  int
+	done   int
+	start  time.Time
+	active []string // labels of in-flight items, in the order they started
+	frame  int
+
+	stop     chan struct{}
+	stopped  chan struct{}
+	finished bool
+}
+
+// newProgress returns a progress reporting on w about total items.
+func newProgress(w io.Writer, verb string, total int) *progress {
+	p := &progress{
+		w:     w,
+		tty:   useEscapes(w),
+		verb:  verb,
+		total: total,
+		start: time.Now(),
+	}
+	if p.tty {
+		p.stop = make(chan struct{})
+		p.stopped = make(chan struct{})
+		go p.animate()
+	}
+	return p
+}
+
+// isTerminal reports whether w is a character device such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// begin marks the item identified by label as in-flight.
+func (p *progress) begin(label string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active = append(p.active, label)
+}
+
+// end marks the item identified by label as complete, with err recording
+// whether it failed.
+func (p *progress) end(label string, err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, a := range p.active {
+		if a == label {
+			p.active = append(p.active[:i], p.active[i+1:]...)
+			break
+		}
+	}
+	p.done++
+	if p.tty {
+		return
+	}
+	if err != nil {
+		msg, _, _ := strings.Cut(err.Error(), "\n")
+		fmt.Fprintf(p.w, "failed %d of %d: %s: %s\n", p.done, p.total, label, msg)
+		return
+	}
+	fmt.Fprintf(p.w, "%s %d of %d: %s\n", p.verb, p.done, p.total, label)
+}
+
+// finish stops any animation and clears the status line. It is safe to call
+// more than once.
+func (p *progress) finish() {
+	if p == nil || !p.tty || p.finished {
+		return
+	}
+	p.finished = true
+	close(p.stop)
+	<-p.stopped
+	fmt.Fprint(p.w, "\r\033[K")
+}
+
+func (p *progress) animate() {
+	defer close(p.stopped)
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+			p.mu.Lock()
+			p.frame++
+			line := p.statusLine()
+			p.mu.Unlock()
+			fmt.Fprint(p.w, "\r\033[K"+line)
+		}
+	}
+}
+
+// statusLine returns the line drawn on a terminal. p.mu must be held.
+func (p *progress) statusLine() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %d of %d", p.verb, p.done, p.total)
+	for i, a := range p.active {
+		frame := spinnerFrames[(p.frame+i)%len(spinnerFrames)]
+		fmt.Fprintf(&b, "  %s %s", frame, a)
+	}
+	if p.done > 0 && p.done < p.total {
+		elapsed := time.Since(p.start)
+		eta := elapsed / time.Duration(p.done) * time.Duration(p.total-p.done)
+		fmt.Fprintf(&b, "  (ETA %v)", eta.Round(time.Second))
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const flagReadOnly flagName = "read-only"
+
+// readOnly is whether requests which could modify state on Gerrit or GitHub
+// are refused, as set by the global --read-only flag or CUECKOO_READONLY.
+var readOnly bool
+
+// readReadOnly sets readOnly from the --read-only flag and the environment.
+func readReadOnly(cmd *cobra.Command) {
+	readOnly, _ = cmd.Flags().GetBool(string(flagReadOnly))
+	if env, err := strconv.ParseBool(os.Getenv("CUECKOO_READONLY")); err == nil && env {
+		readOnly = true
+	}
+}
+
+// readOnlyTransport refuses requests which could modify state while in
+// read-only mode, which are those with methods other than GET, HEAD and
+// OPTIONS. Wrapping the transports of the Gerrit and GitHub clients means
+// that every write is covered, whichever command or API makes it.
+type readOnlyTransport struct {
+	base http.RoundTripper
+}
+
+func newReadOnlyTransport(base http.RoundTripper) *readOnlyTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &readOnlyTransport{base: base}
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if readOnly {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, &readOnlyError{verb: req.Method, target: req.URL.Redacted()}
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// checkReadOnlyCommand returns a readOnlyError for running the command name
+// with args in read-only mode if it could modify Gerrit or GitHub, as git
+// push and git-codereview mail do. The helpers which run commands, such as
+// run and gitIn, call it such that every command is covered.
+func checkReadOnlyCommand(name string, args ...string) error {
+	if !readOnly {
+		return nil
+	}
+	sub := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+	modifies := false
+	switch name {
+	case "git":
+		modifies = sub(0) == "push" || (sub(0) == "codereview" && sub(1) == "mail")
+	case "git-codereview":
+		modifies = sub(0) == "mail"
+	}
+	if !modifies {
+		return nil
+	}
+	return &readOnlyError{verb: "run", target: strings.Join(append([]string{name}, args...), " ")}
+}
+
+// readOnlyError is returned for requests and commands refused in read-only
+// mode.
+type readOnlyError struct {
+	verb, target string
+}
+
+func (e *readOnlyError) Error() string {
+	return fmt.Sprintf("refusing to %s %s in read-only mode (--%s or CUECKOO_READONLY)", e.verb, e.target, flagReadOnly)
+}
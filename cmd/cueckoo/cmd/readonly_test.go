@@ -0,0 +1,87 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyTransport(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: newReadOnlyTransport(http.DefaultTransport)}
+
+	defer func(v bool) { readOnly = v }(readOnly)
+	readOnly = true
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET in read-only mode: %v", err)
+	}
+	resp.Body.Close()
+	_, err = client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+	var roErr *readOnlyError
+	if !errors.As(err, &roErr) {
+		t.Fatalf("got error %v for POST in read-only mode; want a readOnlyError", err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests; want only the GET", requests)
+	}
+
+	readOnly = false
+	resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatalf("POST outside read-only mode: %v", err)
+	}
+	resp.Body.Close()
+	if requests != 2 {
+		t.Errorf("got %d requests; want 2", requests)
+	}
+}
+
+func TestReadOnlyCommands(t *testing.T) {
+	defer func(v bool) { readOnly = v }(readOnly)
+	readOnly = true
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// The commands are refused before running them, such that they fail
+	// with a readOnlyError rather than because dir is not a repository.
+	var roErr *readOnlyError
+	if _, err := gitIn(ctx, dir, "push", "--quiet", "origin", "HEAD:refs/for/master"); !errors.As(err, &roErr) {
+		t.Errorf("got error %v for git push in read-only mode; want a readOnlyError", err)
+	}
+	if _, err := run(ctx, "git", "codereview", "mail", "HEAD"); !errors.As(err, &roErr) {
+		t.Errorf("got error %v for git codereview mail in read-only mode; want a readOnlyError", err)
+	}
+	if err := runInteractive(ctx, "git-codereview", "mail", "-r", "gopher@example.com"); !errors.As(err, &roErr) {
+		t.Errorf("got error %v for git-codereview mail in read-only mode; want a readOnlyError", err)
+	}
+	if _, err := gitIn(ctx, dir, "status"); errors.As(err, &roErr) {
+		t.Errorf("git status was refused in read-only mode: %v", err)
+	}
+
+	readOnly = false
+	if err := checkReadOnlyCommand("git", "push", "origin", "HEAD:refs/for/master"); err != nil {
+		t.Errorf("git push was refused outside read-only mode: %v", err)
+	}
+}
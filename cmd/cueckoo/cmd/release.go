@@ -0,0 +1,35 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newReleaseCmd creates a new release command, which groups the subcommands
+// which help with making releases of the project.
+func newReleaseCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "help with making releases of the project",
+	}
+	subCommands := []*cobra.Command{
+		newReleaseSuggestVersionCmd(c),
+	}
+	for _, sub := range subCommands {
+		cmd.AddCommand(sub)
+	}
+	return cmd
+}
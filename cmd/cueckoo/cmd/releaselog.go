@@ -15,7 +15,13 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/google/go-github/v53/github"
@@ -30,7 +36,7 @@ func newReleaselogCmd(c *Command) *cobra.Command {
 		Long: `
 Usage of releaselog:
 
-	releaselog RANGE_START RANGE_END
+	releaselog [--template FILE] [--since-draft] [--resume] RANGE_START RANGE_END
 
 releaselog generates a bullet list of commits similar to the GitHub change log
 that is automatically created for a release in a repository that uses pull
@@ -43,12 +49,95 @@ similar way to:
     git log $RANGE_START..$RANGE_END
 
 Like git log, commits are in reverse chronological order.
+
+The output can instead be formatted with the Go template given by --template.
+Its data has the fields From and To, the range, and Commits, each of which has
+the fields Subject, Message, Author (a GitHub login) and SHA. The functions
+join, upper, lower and json are available in addition to the builtins. For
+example:
+
+	{{range .Commits}}- {{.Subject}} ({{.Author}})
+	{{end}}
+
+While iterating on release notes, --since-draft only outputs the entries
+which are new or changed compared to the body of the draft GitHub release for
+RANGE_END, or the only draft release if none is for RANGE_END. An entry has
+changed if the draft mentions its commit in a different line, such as when
+the subject was reworded. This avoids re-reviewing the whole list each time.
+
+Entries are printed as the commits are fetched, a page at a time, unless
+--template or --since-draft is used. The commits fetched so far are saved,
+such that a run which fails part way through a large range, such as due to
+GitHub rate limits, can be continued with --resume, reprinting the saved
+entries rather than fetching them again.
 `,
 		RunE: mkRunE(c, releaseLog),
 	}
+	flags := c.registerFlags(cmd)
+	addTemplateFlag(flags)
+	flags.Bool(flagReleaselogSinceDraft, false, "only output entries which are new or changed since the draft release")
+	flags.Bool(flagReleaselogResume, false, "continue from the commits saved by a previous failed run")
 	return cmd
 }
 
+const (
+	flagReleaselogSinceDraft flagName = "since-draft"
+	flagReleaselogResume     flagName = "resume"
+)
+
+// releaseLogState is the progress of releaselog through the pages of
+// commits in a range, saved after each page such that a run interrupted by
+// rate limits or network failures can be resumed.
+type releaseLogState struct {
+	LastPage int                        `json:"lastPage"`
+	Pages    map[int][]releaseLogCommit `json:"pages"`
+}
+
+// load loads the state saved at path, if any.
+func (s *releaseLogState) load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return nil
+}
+
+func (s *releaseLogState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o666)
+}
+
+// releaseLogData is the data passed to a releaselog --template.
+type releaseLogData struct {
+	From    string
+	To      string
+	Commits []releaseLogCommit
+}
+
+type releaseLogCommit struct {
+	Subject string
+	Message string
+	Author  string
+	SHA     string
+}
+
+// line returns the bullet list entry for c.
+func (c releaseLogCommit) line() string {
+	return fmt.Sprintf("* %s by @%s in %s", c.Subject, c.Author, c.SHA)
+}
+
 func releaseLog(cmd *Command, args []string) error {
 	cmd.Flags()
 
@@ -62,39 +151,194 @@ func releaseLog(cmd *Command, args []string) error {
 		return err
 	}
 
-	var commits []*github.RepositoryCommit
-	opts := &github.ListOptions{
-		Page: 1,
+	// Authors whose commit email is not linked to their GitHub account have
+	// no login; the identity registry may know it.
+	idents, err := loadIdentities(cmd.Context(), cfg.gitRoot)
+	if err != nil {
+		return err
+	}
+	statePath, err := resumeStatePath("releaselog", []string{cfg.githubOwner + "/" + cfg.githubRepo, fromRef, toRef})
+	if err != nil {
+		return err
+	}
+	state := &releaseLogState{Pages: make(map[int][]releaseLogCommit)}
+	if flagReleaselogResume.Bool(cmd) {
+		if err := state.load(statePath); err != nil {
+			return err
+		}
+		if n := len(state.Pages); n > 0 {
+			fmt.Fprintf(os.Stderr, "resuming with %d of %d pages already fetched\n", n, state.LastPage)
+		}
 	}
 
-	// Gather commits and authors
-	for {
-		res, resp, err := cfg.githubClient.Repositories.CompareCommits(cmd.Context(), cfg.githubOwner, cfg.githubRepo, fromRef, toRef, opts)
-		// Check for any errors
-		if err != nil {
-			return fmt.Errorf("failed to compare commits: %w", err)
+	// The entries can be printed as they are fetched, unless they all need
+	// to be known first.
+	data := releaseLogData{From: fromRef, To: toRef}
+	stream := flagTemplate.String(cmd) == "" && !flagReleaselogSinceDraft.Bool(cmd)
+	emit := func(commits []releaseLogCommit) {
+		if !stream {
+			data.Commits = append(data.Commits, commits...)
+			return
+		}
+		for _, commit := range commits {
+			fmt.Println(commit.line())
 		}
+	}
 
-		// Extract the commits
-		commits = append(commits, res.Commits...)
+	// We only know the number of pages after the first request, at which
+	// point progress reporting starts if there are enough of them to
+	// warrant it.
+	var prog *progress
+	defer func() { prog.finish() }()
+	fetch := func(page int) ([]releaseLogCommit, error) {
+		if commits, ok := state.Pages[page]; ok {
+			return commits, nil
+		}
+		label := fmt.Sprintf("page %d", page)
+		prog.begin(label)
+		res, resp, err := cfg.githubClient.Repositories.CompareCommits(cmd.Context(), cfg.githubOwner, cfg.githubRepo, fromRef, toRef, &github.ListOptions{Page: page})
+		prog.end(label, err)
+		if err != nil {
+			err = fmt.Errorf("failed to compare commits: %w", err)
+			if len(state.Pages) > 0 {
+				err = fmt.Errorf("%w\n%d of %d pages were saved; use --%s to continue from there", err, len(state.Pages), state.LastPage, flagReleaselogResume)
+			}
+			return nil, err
+		}
+		if state.LastPage == 0 {
+			// For some reason, when there is just one page of results
+			// resp.LastPage is 0. Who would have thought?!
+			state.LastPage = resp.LastPage
+			if state.LastPage == 0 {
+				state.LastPage = 1
+			}
+			if state.LastPage > 1 {
+				prog = newProgress(os.Stderr, "fetched", state.LastPage)
+				prog.end(label, nil) // account for the first page
+			}
+		}
+		// Like git log, entries are in reverse chronological order, while
+		// GitHub lists the oldest commits first.
+		var commits []releaseLogCommit
+		for i := len(res.Commits) - 1; i >= 0; i-- {
+			commit := res.Commits[i]
+			msg := commit.Commit.GetMessage()
+			summary, _, _ := strings.Cut(msg, "\n")
+			author := commit.GetAuthor().GetLogin()
+			if author == "" {
+				author, _, _ = idents.ByEmail(commit.GetCommit().GetAuthor().GetEmail())
+			}
+			commits = append(commits, releaseLogCommit{
+				Subject: summary,
+				Message: msg,
+				Author:  author,
+				SHA:     commit.GetSHA(),
+			})
+		}
+		state.Pages[page] = commits
+		if err := state.save(statePath); err != nil {
+			debugf("failed to save releaselog state: %v\n", err)
+		}
+		return commits, nil
+	}
 
-		// Break if done. For some reason, when there is just one page of results
-		// resp.LastPage is 0. Who would have thought?!
-		if resp.LastPage <= opts.Page {
-			break
+	// The first page tells us how many there are. The newest commits are
+	// on the last page, so the remaining pages are fetched from last to
+	// first, such that entries can be printed as soon as they are fetched.
+	first, err := fetch(1)
+	if err != nil {
+		return err
+	}
+	if stream {
+		fmt.Printf("<details>\n\n<summary><b>Full list of changes since %s</b></summary>\n\n", fromRef)
+	}
+	for page := state.LastPage; page > 1; page-- {
+		commits, err := fetch(page)
+		if err != nil {
+			return err
 		}
-		opts.Page++
+		emit(commits)
+	}
+	emit(first)
+	prog.finish()
+	os.Remove(statePath)
+	if stream {
+		fmt.Printf("\n</details>\n")
+		return nil
 	}
 
+	if flagReleaselogSinceDraft.Bool(cmd) {
+		draft, err := cfg.draftRelease(cmd.Context(), toRef)
+		if err != nil {
+			return err
+		}
+		all := len(data.Commits)
+		data.Commits = draftDelta(draft.GetBody(), data.Commits)
+		fmt.Fprintf(os.Stderr, "%d of %d entries are new or changed since draft release %q\n", len(data.Commits), all, draft.GetName())
+	}
+	if ok, err := execTemplateFlag(cmd, data); ok {
+		return err
+	}
 	fmt.Printf("<details>\n\n<summary><b>Full list of changes since %s</b></summary>\n\n", fromRef)
-	for i := len(commits) - 1; i >= 0; i-- {
-		commit := commits[i]
-		msg := commit.Commit.GetMessage()
-		author := commit.GetAuthor().GetLogin()
-		summary, _, _ := strings.Cut(msg, "\n")
-		fmt.Printf("* %s by @%s in %s\n", summary, author, commit.GetSHA())
+	for _, commit := range data.Commits {
+		fmt.Println(commit.line())
 	}
 	fmt.Printf("\n</details>\n")
 
 	return nil
 }
+
+// draftRelease returns the draft release for the tag, or the only draft
+// release if there is none for the tag.
+func (c *config) draftRelease(ctx context.Context, tag string) (*github.RepositoryRelease, error) {
+	var drafts []*github.RepositoryRelease
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		releases, resp, err := c.githubClient.Repositories.ListReleases(ctx, c.githubOwner, c.githubRepo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases: %w", err)
+		}
+		for _, r := range releases {
+			if !r.GetDraft() {
+				continue
+			}
+			if r.GetTagName() == tag {
+				return r, nil
+			}
+			drafts = append(drafts, r)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	switch len(drafts) {
+	case 0:
+		return nil, fmt.Errorf("no draft release found in %s/%s", c.githubOwner, c.githubRepo)
+	case 1:
+		return drafts[0], nil
+	}
+	var names []string
+	for _, r := range drafts {
+		names = append(names, fmt.Sprintf("%q", r.GetName()))
+	}
+	return nil, fmt.Errorf("no draft release for %s, and several others: %s", tag, strings.Join(names, ", "))
+}
+
+// draftDelta returns the commits whose entries are not in the release body,
+// either because the body does not mention the commit, or because it does so
+// in a line other than the entry for the commit, such as when the entry was
+// generated before the commit's subject was reworded.
+func draftDelta(body string, commits []releaseLogCommit) []releaseLogCommit {
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(body, "\n") {
+		lines[strings.TrimSpace(line)] = true
+	}
+	var res []releaseLogCommit
+	for _, c := range commits {
+		if !lines[c.line()] {
+			res = append(res, c)
+		}
+	}
+	return res
+}
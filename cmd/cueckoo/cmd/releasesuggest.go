@@ -0,0 +1,260 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newReleaseSuggestVersionCmd creates a new release suggest-version command
+func newReleaseSuggestVersionCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suggest-version",
+		Short: "suggest the next version based on the changes since the last release",
+		Long: `
+Usage of release suggest-version:
+
+	release suggest-version [REV]
+
+suggest-version analyzes the commits between the latest release tag reachable
+from REV, which defaults to HEAD, and REV itself, and suggests the semantic
+version for the next release. The commits which call for a major, minor or
+patch version bump are listed, to inform the release decision.
+
+Pre-release tags such as v0.8.0-alpha.1 are not considered to be releases.
+
+Commits are classified as follows:
+
+	major: a "BREAKING CHANGE" paragraph or trailer, or a conventional
+	       commit type followed by "!", such as "feat!: ..."
+	minor: a "feat" conventional commit type, or changes to the files under
+	       api/next, which record additions to the API
+	patch: everything else
+
+Before v1.0.0, breaking changes only call for a minor version bump.
+
+Any draft security advisories of the repository are listed too, along with
+the versions they are to be patched in, as created by advisory new, such that
+an embargoed fix is not forgotten when making the release.
+`,
+		RunE: mkRunE(c, releaseSuggestVersionDef),
+	}
+	return cmd
+}
+
+// bumpKind is the kind of version bump that a commit calls for.
+type bumpKind int
+
+const (
+	bumpPatch bumpKind = iota
+	bumpMinor
+	bumpMajor
+)
+
+func (k bumpKind) String() string {
+	switch k {
+	case bumpMajor:
+		return "major"
+	case bumpMinor:
+		return "minor"
+	}
+	return "patch"
+}
+
+var (
+	conventionalRegex   = regexp.MustCompile(`^(\w+)(\([^)]*\))?(!)?: `)
+	breakingChangeRegex = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+	releaseVersionRegex = regexp.MustCompile(`^v(\d+)\.(\d+)\.(\d+)$`)
+)
+
+// classifyCommit returns the kind of version bump called for by a commit
+// with the given message which modifies files.
+func classifyCommit(msg string, files []string) bumpKind {
+	if breakingChangeRegex.MatchString(msg) {
+		return bumpMajor
+	}
+	if m := conventionalRegex.FindStringSubmatch(msg); m != nil {
+		if m[3] == "!" {
+			return bumpMajor
+		}
+		if m[1] == "feat" {
+			return bumpMinor
+		}
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f, "api/next/") {
+			return bumpMinor
+		}
+	}
+	return bumpPatch
+}
+
+// nextVersion returns the version following the release version v given a
+// bump of kind k.
+func nextVersion(v string, k bumpKind) (string, error) {
+	m := releaseVersionRegex.FindStringSubmatch(v)
+	if m == nil {
+		return "", fmt.Errorf("%q is not a release version", v)
+	}
+	var n [3]int
+	for i := range n {
+		n[i], _ = strconv.Atoi(m[i+1])
+	}
+	if k == bumpMajor && n[0] == 0 {
+		k = bumpMinor
+	}
+	switch k {
+	case bumpMajor:
+		n[0], n[1], n[2] = n[0]+1, 0, 0
+	case bumpMinor:
+		n[1], n[2] = n[1]+1, 0
+	default:
+		n[2]++
+	}
+	return fmt.Sprintf("v%d.%d.%d", n[0], n[1], n[2]), nil
+}
+
+type classifiedCommit struct {
+	hash    string
+	subject string
+	kind    bumpKind
+}
+
+func releaseSuggestVersionDef(cmd *Command, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("expected at most one revision")
+	}
+	rev := "HEAD"
+	if len(args) == 1 {
+		rev = args[0]
+	}
+	ctx := cmd.Context()
+	last, err := latestReleaseTag(ctx, rev)
+	if err != nil {
+		return err
+	}
+	commits, err := classifyCommits(ctx, last+".."+rev)
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "last release: %s\n", last)
+	if len(commits) == 0 {
+		fmt.Fprintf(w, "no commits since %s\n", last)
+		return nil
+	}
+	kind := bumpPatch
+	for _, c := range commits {
+		if c.kind > kind {
+			kind = c.kind
+		}
+	}
+	next, err := nextVersion(last, kind)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "suggested version: %s (%s)\n", next, kind)
+	for _, k := range []bumpKind{bumpMajor, bumpMinor, bumpPatch} {
+		var header bool
+		for _, c := range commits {
+			if c.kind != k {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(w, "\ncommits calling for a %s version bump:\n", k)
+				header = true
+			}
+			fmt.Fprintf(w, "\t%.12s %s\n", c.hash, c.subject)
+		}
+	}
+	listDraftAdvisories(ctx, w)
+	return nil
+}
+
+// listDraftAdvisories lists the draft security advisories of the repository
+// to w, if any. Failures are only reported as debug output, as suggesting a
+// version does not otherwise need GitHub credentials.
+func listDraftAdvisories(ctx context.Context, w io.Writer) {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		debugf("not listing draft advisories: %v\n", err)
+		return
+	}
+	advs, err := cfg.draftAdvisories(ctx)
+	if err != nil {
+		debugf("%v\n", err)
+		return
+	}
+	if len(advs) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\ndraft security advisories awaiting a release:\n")
+	for _, adv := range advs {
+		var patched []string
+		for _, v := range adv.Vulnerabilities {
+			if v.PatchedVersions != "" && !slicesContains(patched, v.PatchedVersions) {
+				patched = append(patched, v.PatchedVersions)
+			}
+		}
+		fmt.Fprintf(w, "\t%s %s (patched in %s)\n", adv.GHSAID, adv.Summary, orNone(strings.Join(patched, ", ")))
+	}
+}
+
+// latestReleaseTag returns the highest release version tag reachable from
+// rev, ignoring pre-release versions.
+func latestReleaseTag(ctx context.Context, rev string) (string, error) {
+	out, err := run(ctx, "git", "tag", "--merged", rev, "--list", "v*", "--sort=-v:refname")
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range strings.Fields(out) {
+		if releaseVersionRegex.MatchString(tag) {
+			return tag, nil
+		}
+	}
+	return "", fmt.Errorf("no release tags reachable from %s", rev)
+}
+
+// classifyCommits returns the commits in the range revs, newest first, along
+// with the kind of version bump each calls for.
+func classifyCommits(ctx context.Context, revs string) ([]classifiedCommit, error) {
+	// Separate the commits with a record separator, and the message from
+	// the list of files with a NUL.
+	out, err := run(ctx, "git", "log", "--format=%x1e%H%n%B%x00", "--name-only", revs)
+	if err != nil {
+		return nil, err
+	}
+	var res []classifiedCommit
+	for _, rec := range strings.Split(out, "\x1e") {
+		if strings.TrimSpace(rec) == "" {
+			continue
+		}
+		hash, rest, _ := strings.Cut(rec, "\n")
+		msg, names, _ := strings.Cut(rest, "\x00")
+		res = append(res, classifiedCommit{
+			hash:    hash,
+			subject: firstLine(msg),
+			kind:    classifyCommit(msg, strings.Fields(names)),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "testing"
+
+func TestClassifyCommit(t *testing.T) {
+	testCases := []struct {
+		msg   string
+		files []string
+		want  bumpKind
+	}{
+		{msg: "cmd/cue: fix crash on empty input\n", want: bumpPatch},
+		{msg: "fix: handle empty input\n", want: bumpPatch},
+		{msg: "feat: add --json flag\n", want: bumpMinor},
+		{msg: "feat(cmd/cue): add --json flag\n", want: bumpMinor},
+		{msg: "refactor!: drop Go 1.19 support\n", want: bumpMajor},
+		{msg: "feat(cue)!: rename Value.Lookup\n", want: bumpMajor},
+		{msg: "cue: rename Value.Lookup\n\nBREAKING CHANGE: Lookup is now LookupPath.\n", want: bumpMajor},
+		{msg: "cue: add Value.Foo\n", files: []string{"cue/types.go", "api/next/123.txt"}, want: bumpMinor},
+		{msg: "cue: tweak api docs\n", files: []string{"cue/api/doc.go"}, want: bumpPatch},
+		{msg: "features: are not feat\n", want: bumpPatch},
+	}
+	for _, tc := range testCases {
+		if got := classifyCommit(tc.msg, tc.files); got != tc.want {
+			t.Errorf("classifyCommit(%q, %q) = %v; want %v", tc.msg, tc.files, got, tc.want)
+		}
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	testCases := []struct {
+		v    string
+		kind bumpKind
+		want string
+	}{
+		{"v1.2.3", bumpPatch, "v1.2.4"},
+		{"v1.2.3", bumpMinor, "v1.3.0"},
+		{"v1.2.3", bumpMajor, "v2.0.0"},
+		{"v0.8.2", bumpMajor, "v0.9.0"},
+		{"v0.8.2", bumpPatch, "v0.8.3"},
+	}
+	for _, tc := range testCases {
+		got, err := nextVersion(tc.v, tc.kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("nextVersion(%q, %v) = %q; want %q", tc.v, tc.kind, got, tc.want)
+		}
+	}
+	if _, err := nextVersion("v0.8.0-alpha.1", bumpPatch); err == nil {
+		t.Errorf("expected an error for a pre-release version")
+	}
+}
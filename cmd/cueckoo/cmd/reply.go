@@ -0,0 +1,143 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagReplyFile    flagName = "file"
+	flagReplyLine    flagName = "line"
+	flagReplyMessage flagName = "message"
+	flagReplyResolve flagName = "resolve"
+)
+
+// newReplyCmd creates a new reply command
+func newReplyCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reply",
+		Short: "reply to an inline comment on a CL",
+		Long: `
+Usage of reply:
+
+	reply --file FILE [--line N] -m MESSAGE [--resolve] CL
+
+reply posts an inline comment on FILE, at line N or on the file as a whole if
+--line is not given, in reply to the latest comment on the same line. This
+allows straightforward feedback to be handled without the web UI.
+
+If there is no existing comment on the line, a new comment is posted on the
+latest patchset. If --resolve is given the thread is marked as resolved;
+otherwise the reply leaves the thread's resolved state unchanged.
+`,
+		RunE:              mkRunE(c, replyDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagReplyFile, "", "path of the file to comment on")
+	flags.Int(flagReplyLine, 0, "line to comment on; zero comments on the file")
+	flags.StringP(flagReplyMessage, "m", "", "the comment to post")
+	flags.Bool(flagReplyResolve, false, "mark the thread as resolved")
+	return cmd
+}
+
+// replyCommentInput is the subset of Gerrit's CommentInput entity that we
+// use. go-gerrit's version does not support resolving threads.
+type replyCommentInput struct {
+	Line       int    `json:"line,omitempty"`
+	InReplyTo  string `json:"in_reply_to,omitempty"`
+	Message    string `json:"message"`
+	Unresolved *bool  `json:"unresolved,omitempty"`
+}
+
+func replyDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single CL")
+	}
+	path := flagReplyFile.String(cmd)
+	if path == "" {
+		return fmt.Errorf("--%s is required", flagReplyFile)
+	}
+	msg := flagReplyMessage.String(cmd)
+	if msg == "" {
+		return fmt.Errorf("--%s is required", flagReplyMessage)
+	}
+	line := flagReplyLine.Int(cmd)
+
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	comments, err := cfg.listComments(id)
+	if err != nil {
+		return err
+	}
+
+	in := replyCommentInput{Line: line, Message: msg}
+	if flagReplyResolve.Bool(cmd) {
+		in.Unresolved = new(bool)
+	}
+	revision := "current"
+	if last := latestComment(comments[path], line); last != nil {
+		// Replies must be posted on the patchset of the comment they reply
+		// to, otherwise Gerrit does not thread them.
+		revision = strconv.Itoa(last.PatchSet)
+		in.InReplyTo = last.ID
+	}
+	review := map[string]any{
+		"comments": map[string][]replyCommentInput{path: {in}},
+	}
+	if err := cfg.gerritDo(http.MethodPost, "changes/"+id+"/revisions/"+revision+"/review", review, nil); err != nil {
+		return fmt.Errorf("failed to post comment: %w", err)
+	}
+	where := path
+	if line > 0 {
+		where = fmt.Sprintf("%s:%d", path, line)
+	}
+	if in.InReplyTo != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "replied on %s in patchset %s\n", where, revision)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "commented on %s\n", where)
+	}
+	return nil
+}
+
+// latestComment returns the most recent of comments on line, or nil if there
+// are none.
+func latestComment(comments []gerritComment, line int) *gerritComment {
+	var onLine []gerritComment
+	for _, cm := range comments {
+		if cm.Line == line {
+			onLine = append(onLine, cm)
+		}
+	}
+	if len(onLine) == 0 {
+		return nil
+	}
+	sort.SliceStable(onLine, func(i, j int) bool {
+		return onLine[i].Updated.Time.Before(onLine[j].Updated.Time)
+	})
+	return &onLine[len(onLine)-1]
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// repoState describes the state of a git repository which affects whether
+// pending commits can be derived from HEAD.
+type repoState struct {
+	// unborn means that the current branch has no commits yet.
+	unborn bool
+
+	// detached means that HEAD does not refer to a branch.
+	detached bool
+
+	// inProgress names an operation which is in progress, such as "rebase",
+	// or is empty.
+	inProgress string
+}
+
+// inProgressMarkers maps files in the git directory to the operation whose
+// progress they record.
+var inProgressMarkers = []struct {
+	file, op string
+}{
+	{"rebase-merge", "rebase"},
+	{"rebase-apply/applying", "git am"},
+	{"rebase-apply", "rebase"},
+	{"CHERRY_PICK_HEAD", "cherry-pick"},
+	{"REVERT_HEAD", "revert"},
+	{"MERGE_HEAD", "merge"},
+	{"BISECT_LOG", "bisect"},
+}
+
+// currentRepoState returns the state of the repository containing the
+// current directory.
+func currentRepoState(ctx context.Context) (repoState, error) {
+	var st repoState
+	gitDir, err := run(ctx, "git", "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return st, err
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	for _, m := range inProgressMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, m.file)); err == nil {
+			st.inProgress = m.op
+			break
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return st, err
+		}
+	}
+	// Both of these exit with a non-zero status and no output in the states
+	// we are looking for.
+	if _, err := run(ctx, "git", "symbolic-ref", "--quiet", "HEAD"); err != nil {
+		st.detached = true
+	}
+	if _, err := run(ctx, "git", "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		st.unborn = true
+	}
+	return st, nil
+}
+
+// err returns an error explaining why pending commits cannot be derived from
+// HEAD in state st, or nil if they can.
+func (st repoState) err() error {
+	switch {
+	case st.inProgress != "":
+		return fmt.Errorf("a %s is in progress; finish or abort it first, or use --ref to name the branch to use", st.inProgress)
+	case st.unborn:
+		return fmt.Errorf("the current branch has no commits yet")
+	case st.detached:
+		return fmt.Errorf("HEAD is detached; switch to a branch first, or use --ref to name the branch to use")
+	}
+	return nil
+}
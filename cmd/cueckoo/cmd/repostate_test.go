@@ -0,0 +1,130 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepoState(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	testCases := []struct {
+		name string
+
+		// setup holds git commands to run in a new repository, except that
+		// {"write", FILE, CONTENT} writes and stages a file.
+		setup [][]string
+		want  repoState
+		err   string
+	}{{
+		name: "Clean",
+		setup: [][]string{
+			{"commit", "--allow-empty", "-m", "initial"},
+		},
+	}, {
+		name: "Unborn",
+		want: repoState{unborn: true},
+		err:  "no commits yet",
+	}, {
+		name: "Detached",
+		setup: [][]string{
+			{"commit", "--allow-empty", "-m", "initial"},
+			{"switch", "--detach"},
+		},
+		want: repoState{detached: true},
+		err:  "HEAD is detached",
+	}, {
+		name: "Rebase",
+		setup: [][]string{
+			{"commit", "--allow-empty", "-m", "initial"},
+			{"commit", "--allow-empty", "-m", "second"},
+			{"-c", "sequence.editor=sed -i 1s/^pick/edit/", "rebase", "-i", "HEAD~1"},
+		},
+		// An interactive rebase stopped at an edit detaches HEAD, but the
+		// rebase is the thing to report.
+		want: repoState{detached: true, inProgress: "rebase"},
+		err:  "a rebase is in progress",
+	}, {
+		name: "CherryPick",
+		setup: [][]string{
+			{"commit", "--allow-empty", "-m", "initial"},
+			{"switch", "-c", "other"},
+			{"write", "f", "one"},
+			{"commit", "-a", "-m", "one"},
+			{"switch", "-"},
+			{"write", "f", "two"},
+			{"commit", "-a", "-m", "two"},
+			{"cherry-pick", "other"},
+		},
+		want: repoState{inProgress: "cherry-pick"},
+		err:  "a cherry-pick is in progress",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			git := func(args ...string) {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = dir
+				cmd.Env = append(os.Environ(),
+					"GIT_AUTHOR_NAME=gopher", "GIT_AUTHOR_EMAIL=gopher@example.com",
+					"GIT_COMMITTER_NAME=gopher", "GIT_COMMITTER_EMAIL=gopher@example.com",
+					"GIT_CONFIG_GLOBAL=/dev/null",
+				)
+				// A failed cherry-pick is how we get one in progress.
+				cmd.CombinedOutput()
+			}
+			git("init", "--quiet", "--initial-branch=main")
+			for _, args := range tc.setup {
+				if args[0] == "write" {
+					if err := os.WriteFile(filepath.Join(dir, args[1]), []byte(args[2]+"\n"), 0o666); err != nil {
+						t.Fatal(err)
+					}
+					git("add", args[1])
+					continue
+				}
+				git(args...)
+			}
+
+			wd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chdir(dir); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(wd)
+			st, err := currentRepoState(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st != tc.want {
+				t.Errorf("got state %+v; want %+v", st, tc.want)
+			}
+			switch err := st.err(); {
+			case tc.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+				t.Errorf("got error %v; want one containing %q", err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagRerunIfFlaky flagName = "if-flaky"
+	flagRerunFlakes  flagName = "flakes"
+
+	// flakeRetryTag is the Gerrit message tag used when annotating a CL whose
+	// trybot run was re-run as a suspected flake.
+	flakeRetryTag = "autogenerated:cueckoo-flake-retry"
+)
+
+// newRerunCmd creates a new rerun command
+func newRerunCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rerun",
+		Short: "re-run the failed jobs of the trybot run for a CL",
+		Long: `
+Usage of rerun:
+
+	rerun [--if-flaky] [--flakes FILE] CL
+
+rerun finds the trybot run for the current patchset of the given CL and re-runs
+its failed jobs. CL is either a CL number or a Change-Id. The trybot run is
+the one in the trybot repository, to which the trybot dispatch is relayed, as
+that is where the trybot jobs run.
+
+With --if-flaky, the failed jobs are only re-run if the log of every failed job
+matches a signature in the known-flake database, and only if the run has not
+already been re-run. The CL is then annotated to say that the failure was
+automatically retried as a suspected flake; the text of the annotation is the
+"flakeRetry" template of ` + messagesFile + ` if set.
+
+The known-flake database is read from --flakes, which defaults to
+` + defaultFlakesFile + ` in the repository. It is a CUE file with a
+list of flakes, each with a name, a regular expression matching the log of the
+failed job, and optionally a regular expression matching the job name:
+
+	flakes: [{
+		name:    "go test timeout on macOS"
+		job:     "macos"
+		pattern: "panic: test timed out after"
+	}]
+`,
+		RunE: mkRunE(c, rerunDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.Bool(flagRerunIfFlaky, false, "only re-run if all failures match a known flake")
+	flags.String(flagRerunFlakes, "", "path of the known-flake database (default "+defaultFlakesFile+")")
+	flags.Bool(flagOverrideFreeze, false, "proceed even during a freeze window of the repository")
+	return cmd
+}
+
+func rerunDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single CL number or Change-Id")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkFreeze(cmd); err != nil {
+		return err
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	ch, _, err := cfg.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	rev := ch.Revisions[ch.CurrentRevision]
+	run, err := cfg.findTrybotRun(ctx, ch.Number, rev.Number)
+	if err != nil {
+		return err
+	}
+	if run == nil {
+		return fmt.Errorf("no trybot run found for CL %d patchset %d", ch.Number, rev.Number)
+	}
+	w := cmd.OutOrStdout()
+	switch {
+	case run.GetStatus() != "completed":
+		return fmt.Errorf("trybot run %s is still %s", run.GetHTMLURL(), run.GetStatus())
+	case run.GetConclusion() == "success":
+		fmt.Fprintf(w, "trybot run %s passed; nothing to re-run\n", run.GetHTMLURL())
+		return nil
+	}
+
+	if !flagRerunIfFlaky.Bool(cmd) {
+		if _, err := cfg.githubClient.Actions.RerunFailedJobsByID(ctx, cfg.githubOwner, cfg.githubRepo, run.GetID()); err != nil {
+			return fmt.Errorf("failed to re-run %s: %w", run.GetHTMLURL(), err)
+		}
+		fmt.Fprintf(w, "re-running failed jobs of %s\n", run.GetHTMLURL())
+		return nil
+	}
+
+	path := flagRerunFlakes.String(cmd)
+	if path == "" {
+		path = filepath.Join(cfg.gitRoot, defaultFlakesFile)
+	}
+	db, err := loadFlakeDB(ctx, path)
+	if err != nil {
+		return err
+	}
+	_, msg, err := cfg.retryFlakyRun(ctx, db, *ch, run)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, msg)
+	return nil
+}
+
+// retryFlakyRun re-runs the failed jobs of the trybot run for ch if all of
+// them match a known flake in db and the run has not already been re-run,
+// annotating ch when it does so. It reports whether the run was re-run, along
+// with a description of the outcome. The run is re-run in the repository it
+// belongs to.
+func (c *config) retryFlakyRun(ctx context.Context, db *flakeDB, ch gerrit.ChangeInfo, run *github.WorkflowRun) (retried bool, _ string, _ error) {
+	if run.GetRunAttempt() > 1 {
+		return false, fmt.Sprintf("trybot run %s was already re-run; not retrying again", run.GetHTMLURL()), nil
+	}
+	owner, repo := run.GetRepository().GetOwner().GetLogin(), run.GetRepository().GetName()
+	jobs, allFlaky, err := c.matchFlakes(ctx, db, owner, repo, run)
+	if err != nil {
+		return false, "", err
+	}
+	if !allFlaky {
+		var unknown []string
+		for _, j := range jobs {
+			if j.flake == nil {
+				unknown = append(unknown, j.job.GetName())
+			}
+		}
+		return false, fmt.Sprintf("trybot run %s has failures not matching a known flake: %s", run.GetHTMLURL(), strings.Join(unknown, ", ")), nil
+	}
+	if _, err := c.githubClient.Actions.RerunFailedJobsByID(ctx, owner, repo, run.GetID()); err != nil {
+		return false, "", fmt.Errorf("failed to re-run %s: %w", run.GetHTMLURL(), err)
+	}
+
+	msgs, err := loadBotMessages(ctx, c.gitRoot)
+	if err != nil {
+		return true, "", err
+	}
+	data := flakeRetryData{URL: run.GetHTMLURL()}
+	for _, j := range jobs {
+		data.Jobs = append(data.Jobs, flakeRetryJob{Job: j.job.GetName(), Flake: j.flake.Name})
+	}
+	msg, err := msgs.exec("flakeRetry", data)
+	if err != nil {
+		return true, "", err
+	}
+	if _, _, err := c.gerritClient.Changes.SetReview(fmt.Sprint(ch.Number), "current", &gerrit.ReviewInput{
+		Message: msg,
+		Tag:     flakeRetryTag,
+	}); err != nil {
+		return true, "", fmt.Errorf("failed to annotate CL %d: %w", ch.Number, err)
+	}
+	return true, fmt.Sprintf("re-running failed jobs of %s as suspected flakes", run.GetHTMLURL()), nil
+}
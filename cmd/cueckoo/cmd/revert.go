@@ -0,0 +1,148 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagRevertReason flagName = "reason"
+	flagRevertTrybot flagName = "trybot"
+	flagRevertFeed   flagName = "feed"
+)
+
+// newRevertCmd creates a new revert command
+func newRevertCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revert",
+		Short: "create a CL reverting a merged CL",
+		Long: `
+Usage of revert:
+
+	revert --reason REASON [--trybot] [--feed] CL
+
+revert creates a CL which reverts the given merged CL, for use when a change
+needs to be rolled back quickly. The commit message of the revert refers to
+the original subject, CL and commit, and includes the reason given. The owner
+of the original CL is added to the revert as a CC.
+
+With --trybot, the trybots are also triggered on the revert, as runtrybot
+would do. With --feed, the revert is recorded in the activity feed of the
+repository; see the feed command.
+`,
+		RunE:              mkRunE(c, revertDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	flags := c.registerFlags(cmd)
+	flags.StringP(flagRevertReason, "r", "", "the reason for the revert")
+	flags.Bool(flagRevertTrybot, false, "trigger the trybots on the revert")
+	flags.Bool(flagRevertFeed, false, "record the revert in the activity feed")
+	return cmd
+}
+
+func revertDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single CL")
+	}
+	reason := flagRevertReason.String(cmd)
+	if reason == "" {
+		return fmt.Errorf("--%s is required", flagRevertReason)
+	}
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if flagRevertFeed.Bool(cmd) && cfg.activityFeed == "" {
+		return fmt.Errorf("no activity feed configured; add an %s entry to codereview.cfg", activityFeedKey)
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	orig, _, err := cfg.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION", "DETAILED_ACCOUNTS"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	if orig.Status != "MERGED" {
+		return fmt.Errorf("CL %d is %s; only merged CLs can be reverted", orig.Number, orig.Status)
+	}
+
+	if err := cfg.requireGerrit(featureRevert); err != nil {
+		return err
+	}
+	in := map[string]string{
+		"message": revertMessage(orig.Subject, cfg.changeURL(orig.Number), orig.CurrentRevision, reason),
+	}
+	var revert gerrit.ChangeInfo
+	if err := cfg.gerritDo(http.MethodPost, "changes/"+id+"/revert", in, &revert); err != nil {
+		return fmt.Errorf("failed to revert CL %d: %w", orig.Number, err)
+	}
+	revertID := strconv.Itoa(revert.Number)
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "created %s\n", cfg.changeURL(revert.Number))
+	if flagRevertFeed.Bool(cmd) {
+		text := fmt.Sprintf("%s reverting CL %d %q: %s", cfg.changeURL(revert.Number), orig.Number, orig.Subject, reason)
+		if err := cfg.recordActivity(cmd.Context(), "revert", text); err != nil {
+			return err
+		}
+	}
+
+	cc := map[string]string{
+		"reviewer": strconv.Itoa(orig.Owner.AccountID),
+		"state":    "CC",
+	}
+	if err := cfg.gerritDo(http.MethodPost, "changes/"+revertID+"/reviewers", cc, nil); err != nil {
+		return fmt.Errorf("failed to CC %s: %w", accountName(orig.Owner), err)
+	}
+
+	if flagRevertTrybot.Bool(cmd) {
+		ch, _, err := cfg.gerritClient.Changes.GetChange(revertID, &gerrit.ChangeOptions{
+			AdditionalFields: []string{"CURRENT_REVISION"},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get change %s: %w", revertID, err)
+		}
+		rev := ch.Revisions[ch.CurrentRevision]
+		p, err := buildTryBotPayload(repositoryDispatchPayload{
+			Type:         string(eventTypeTrybot),
+			CL:           ch.Number,
+			Patchset:     rev.Number,
+			TargetBranch: ch.Branch,
+			Ref:          rev.Ref,
+		})
+		if err != nil {
+			return err
+		}
+		if err := cfg.triggerRepositoryDispatch(cmd.Context(), cfg.githubOwner, cfg.githubRepo, p); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "triggered trybots for CL %d\n", ch.Number)
+	}
+	return nil
+}
+
+// revertMessage returns the commit message for a revert of the CL at url
+// with the given subject and commit.
+func revertMessage(subject, url, commit, reason string) string {
+	return fmt.Sprintf("Revert \"%s\"\n\nThis reverts %s (commit %s).\n\nReason for revert: %s\n", subject, url, commit, reason)
+}
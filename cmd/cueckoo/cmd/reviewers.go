@@ -0,0 +1,111 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/codereviewcfg"
+	"github.com/cue-lang/contrib-tools/internal/identity"
+	"github.com/google/go-github/v53/github"
+)
+
+// accountMapKey is the user config key giving the path of the account map,
+// which maps GitHub logins to Gerrit accounts in the same "key: value"
+// format as the user config, such as:
+//
+//	mvdan: mvdan@cue.works
+//
+// By default, it is the file "accounts" next to the user config.
+const accountMapKey = "account-map"
+
+// loadAccountMap returns the account map, falling back to the identity
+// registry reg of the repository for the logins which it does not map.
+func loadAccountMap(reg *identity.Registry) (map[string]string, error) {
+	ucfg, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	path := ucfg[accountMapKey]
+	if path == "" {
+		cfgPath, err := userConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(filepath.Dir(cfgPath), "accounts")
+	}
+	accounts := reg.AccountMap()
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return accounts, nil
+	}
+	user, err := codereviewcfg.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for login, acct := range user {
+		accounts[login] = acct
+	}
+	return accounts, nil
+}
+
+// prReviewRouting maps the requested reviewers of pr to Gerrit reviewers and
+// its assignees to CCs, using accounts. Logins without an entry in accounts
+// are returned as unmapped, as are requested teams, which have no Gerrit
+// equivalent.
+func prReviewRouting(pr *github.PullRequest, accounts map[string]string) (reviewers, cc, unmapped []string) {
+	seen := make(map[string]bool)
+	add := func(list *[]string, login string) {
+		acct := accounts[login]
+		switch {
+		case acct == "":
+			unmapped = append(unmapped, login)
+		case !seen[acct]:
+			seen[acct] = true
+			*list = append(*list, acct)
+		}
+	}
+	for _, u := range pr.RequestedReviewers {
+		add(&reviewers, u.GetLogin())
+	}
+	for _, u := range pr.Assignees {
+		add(&cc, u.GetLogin())
+	}
+	for _, t := range pr.RequestedTeams {
+		unmapped = append(unmapped, "team "+t.GetSlug())
+	}
+	return reviewers, cc, unmapped
+}
+
+// mailCommand returns the git-codereview mail command which adds reviewers
+// and cc to the CL when mailing it.
+func mailCommand(reviewers, cc []string) string {
+	return strings.Join(mailArgs(reviewers, cc), " ")
+}
+
+// mailArgs is like mailCommand, but returns the command as arguments.
+func mailArgs(reviewers, cc []string) []string {
+	args := []string{"git-codereview", "mail"}
+	if len(reviewers) > 0 {
+		args = append(args, "-r", strings.Join(reviewers, ","))
+	}
+	if len(cc) > 0 {
+		args = append(args, "-cc", strings.Join(cc, ","))
+	}
+	return args
+}
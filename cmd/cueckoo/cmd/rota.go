@@ -0,0 +1,289 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagRotaReportFormat flagName = "format"
+	flagRotaReportSince  flagName = "since"
+)
+
+// newRotaCmd creates a new rota command, which groups the subcommands for
+// informing the reviewer rotation.
+func newRotaCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rota",
+		Short: "inform the reviewer rotation",
+	}
+	cmd.AddCommand(newRotaReportCmd(c))
+	return cmd
+}
+
+// newRotaReportCmd creates a new rota report command
+func newRotaReportCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "summarise the review load per maintainer",
+		Long: `
+Usage of rota report:
+
+	rota report [--since DURATION] [--format table|markdown|json]
+
+report summarises the review activity on the Gerrit project over the --since
+window, 7 days by default, for each account which reviewed or submitted CLs:
+
+	reviews   CLs owned by others which the account commented on or voted on
+	merged    CLs which the account submitted
+	latency   the average time the account took to respond to the owner of a
+	          CL, from the owner's latest upload or reply
+
+Messages posted by bots, which Gerrit tags as autogenerated, are not counted.
+This informs the weekly reviewer rotation, such as to spread the load more
+evenly. The report is printed as a table, a Markdown table suitable for
+pasting into an issue, or as JSON.
+`,
+		RunE: mkRunE(c, rotaReportDef),
+	}
+	c.registerFlags(cmd).String(flagRotaReportFormat, "table", "output format: table, markdown or json").OneOf("table", "markdown", "json")
+	c.registerFlags(cmd).String(flagRotaReportSince, "7d", "window of review activity to report on")
+	return cmd
+}
+
+// rotaAccount is the report on the review activity of a single account.
+type rotaAccount struct {
+	Name    string `json:"name"`
+	Reviews int    `json:"reviews"`
+	Merged  int    `json:"merged"`
+
+	// Latency is the average response latency, or zero if the account did
+	// not respond to any owner. It is encoded in JSON as nanoseconds.
+	Latency   time.Duration `json:"latency"`
+	responses int
+}
+
+// rotaChange is the subset of Gerrit's ChangeInfo entity used by the rota
+// report.
+type rotaChange struct {
+	Number    int             `json:"_number"`
+	Owner     rotaAccountInfo `json:"owner"`
+	Status    string          `json:"status"`
+	Submitted string          `json:"submitted"`
+	Submitter rotaAccountInfo `json:"submitter"`
+	Messages  []struct {
+		Author rotaAccountInfo `json:"author"`
+		Date   string          `json:"date"`
+		Tag    string          `json:"tag"`
+	} `json:"messages"`
+	MoreChanges bool `json:"_more_changes"`
+}
+
+type rotaAccountInfo struct {
+	AccountID int    `json:"_account_id"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+}
+
+func (a rotaAccountInfo) String() string {
+	switch {
+	case a.Name != "":
+		return a.Name
+	case a.Username != "":
+		return a.Username
+	case a.Email != "":
+		return a.Email
+	}
+	return fmt.Sprintf("account %d", a.AccountID)
+}
+
+func rotaReportDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("rota report does not take any arguments")
+	}
+	format := flagRotaReportFormat.String(cmd)
+	window, err := parseDuration(flagRotaReportSince.String(cmd))
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	since := time.Now().Add(-window)
+	changes, err := cfg.rotaChanges(since)
+	if err != nil {
+		return err
+	}
+	report, err := rotaReport(changes, since)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(report)
+	case "markdown":
+		printRotaReportMarkdown(w, report)
+	default:
+		printRotaReport(w, report)
+	}
+	return nil
+}
+
+// rotaChanges returns the changes of the Gerrit project updated since the
+// given time, along with their messages.
+func (c *config) rotaChanges(since time.Time) ([]rotaChange, error) {
+	query := fmt.Sprintf("project:%s after:%q", c.gerritProject(), gerritTime(since))
+	var all []rotaChange
+	for {
+		var page []rotaChange
+		path := "changes/?q=" + url.QueryEscape(query) + "&o=MESSAGES&o=DETAILED_ACCOUNTS&S=" + strconv.Itoa(len(all))
+		if err := c.gerritDo(http.MethodGet, path, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to query changes: %w", err)
+		}
+		all = append(all, page...)
+		if len(page) == 0 || !page[len(page)-1].MoreChanges {
+			return all, nil
+		}
+	}
+}
+
+// rotaReport computes the review activity per account from the messages on
+// changes since the given time, ordered by the number of reviews.
+func rotaReport(changes []rotaChange, since time.Time) ([]rotaAccount, error) {
+	accounts := make(map[int]*rotaAccount)
+	account := func(a rotaAccountInfo) *rotaAccount {
+		ra := accounts[a.AccountID]
+		if ra == nil {
+			ra = &rotaAccount{Name: a.String()}
+			accounts[a.AccountID] = ra
+		}
+		return ra
+	}
+	for _, ch := range changes {
+		if ch.Status == "MERGED" && ch.Submitter.AccountID != 0 {
+			submitted, err := parseGerritTime(ch.Submitted)
+			if err != nil {
+				return nil, fmt.Errorf("CL %d: %v", ch.Number, err)
+			}
+			if !submitted.Before(since) {
+				account(ch.Submitter).Merged++
+			}
+		}
+
+		// waiting is when the owner last uploaded or replied, and responded
+		// records which reviewers responded since.
+		var waiting time.Time
+		responded := make(map[int]bool)
+		reviewed := make(map[int]bool)
+		for _, m := range ch.Messages {
+			// Uploads are tagged as autogenerated too, but count as
+			// activity by the owner.
+			id := m.Author.AccountID
+			upload := strings.HasPrefix(m.Tag, "autogenerated:gerrit:newPatchSet")
+			if strings.HasPrefix(m.Tag, "autogenerated:") && !(upload && id == ch.Owner.AccountID) {
+				continue
+			}
+			date, err := parseGerritTime(m.Date)
+			if err != nil {
+				return nil, fmt.Errorf("CL %d: %v", ch.Number, err)
+			}
+			if id == ch.Owner.AccountID {
+				waiting = date
+				responded = make(map[int]bool)
+				continue
+			}
+			if date.Before(since) || id == 0 {
+				continue
+			}
+			ra := account(m.Author)
+			if !reviewed[id] {
+				reviewed[id] = true
+				ra.Reviews++
+			}
+			if !waiting.IsZero() && !responded[id] {
+				responded[id] = true
+				ra.Latency += date.Sub(waiting)
+				ra.responses++
+			}
+		}
+	}
+
+	report := make([]rotaAccount, 0, len(accounts))
+	for _, ra := range accounts {
+		if ra.responses > 0 {
+			ra.Latency /= time.Duration(ra.responses)
+		}
+		report = append(report, *ra)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Reviews != report[j].Reviews {
+			return report[i].Reviews > report[j].Reviews
+		}
+		return report[i].Name < report[j].Name
+	})
+	return report, nil
+}
+
+// parseGerritTime parses a timestamp as returned by the Gerrit REST API.
+func parseGerritTime(s string) (time.Time, error) {
+	return time.Parse("2006-01-02 15:04:05.000000000", s)
+}
+
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dd", d/(24*time.Hour))
+}
+
+func printRotaReport(w io.Writer, report []rotaAccount) {
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "ACCOUNT\tREVIEWS\tMERGED\tLATENCY")
+	for _, ra := range report {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", ra.Name, ra.Reviews, ra.Merged, formatLatency(ra.Latency))
+	}
+	tw.Flush()
+}
+
+func printRotaReportMarkdown(w io.Writer, report []rotaAccount) {
+	fmt.Fprintln(w, "| Maintainer | Reviews | Merged | Average response |")
+	fmt.Fprintln(w, "|---|--:|--:|--:|")
+	for _, ra := range report {
+		fmt.Fprintf(w, "| %s | %d | %d | %s |\n", ra.Name, ra.Reviews, ra.Merged, formatLatency(ra.Latency))
+	}
+}
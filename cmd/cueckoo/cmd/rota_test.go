@@ -0,0 +1,70 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRotaReport(t *testing.T) {
+	var changes []rotaChange
+	err := json.Unmarshal([]byte(`[{
+	"_number": 1001,
+	"owner": {"_account_id": 1, "name": "Owner"},
+	"status": "MERGED",
+	"submitted": "2026-10-05 12:00:00.000000000",
+	"submitter": {"_account_id": 2, "name": "Alice"},
+	"messages": [
+		{"author": {"_account_id": 1}, "date": "2026-10-02 09:00:00.000000000", "tag": "autogenerated:gerrit:newPatchSet"},
+		{"author": {"_account_id": 3, "name": "Bob"}, "date": "2026-10-02 10:00:00.000000000"},
+		{"author": {"_account_id": 4, "name": "CI"}, "date": "2026-10-02 10:30:00.000000000", "tag": "autogenerated:trybot"},
+		{"author": {"_account_id": 1}, "date": "2026-10-03 09:00:00.000000000", "tag": "autogenerated:gerrit:newPatchSet"},
+		{"author": {"_account_id": 2, "name": "Alice"}, "date": "2026-10-03 13:00:00.000000000"},
+		{"author": {"_account_id": 3, "name": "Bob"}, "date": "2026-10-03 15:00:00.000000000"},
+		{"author": {"_account_id": 3, "name": "Bob"}, "date": "2026-10-03 16:00:00.000000000"}
+	]
+}, {
+	"_number": 1002,
+	"owner": {"_account_id": 2, "name": "Alice"},
+	"status": "NEW",
+	"messages": [
+		{"author": {"_account_id": 2}, "date": "2026-09-20 09:00:00.000000000", "tag": "autogenerated:gerrit:newPatchSet"},
+		{"author": {"_account_id": 3, "name": "Bob"}, "date": "2026-09-21 09:00:00.000000000"},
+		{"author": {"_account_id": 2}, "date": "2026-10-04 09:00:00.000000000"},
+		{"author": {"_account_id": 3, "name": "Bob"}, "date": "2026-10-04 09:30:00.000000000"}
+	]
+}]`), &changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	got, err := rotaReport(changes, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rotaAccount{
+		// Bob responded after 1h, 6h and 30m.
+		{Name: "Bob", Reviews: 2, Latency: (90*time.Minute + 6*time.Hour) / 3},
+		{Name: "Alice", Reviews: 1, Merged: 1, Latency: 4 * time.Hour},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(rotaAccount{})); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%s", diff)
+	}
+}
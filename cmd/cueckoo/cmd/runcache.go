@@ -0,0 +1,160 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const flagNoCache flagName = "no-cache"
+
+// Finding the workflow run for a dispatch means listing hundreds of runs, so
+// the ID of the run found for each display title, or for each patchset in the
+// trybot repository, is cached locally, along with its conclusion. A cached
+// run is fetched by its ID, which is a single request, and its entry is
+// updated when its conclusion changes. The entry for a title is dropped when
+// cueckoo dispatches an event with that title again, as that results in a new
+// run.
+
+// runCacheMaxAge is how long entries are kept in the run cache. Runs for
+// patchsets are rarely looked up after a few weeks.
+const runCacheMaxAge = 30 * 24 * time.Hour
+
+// noRunCache is the value of the global --no-cache flag for the command being
+// run.
+var noRunCache bool
+
+// readNoCache sets noRunCache from the --no-cache flag.
+func readNoCache(cmd *cobra.Command) {
+	noRunCache, _ = cmd.Flags().GetBool(string(flagNoCache))
+}
+
+// runCacheEntry records the workflow run found for the key of a runQuery.
+type runCacheEntry struct {
+	ID         int64     `json:"id"`
+	Conclusion string    `json:"conclusion,omitempty"`
+	Updated    time.Time `json:"updated"`
+}
+
+// runCacheMu serialises updates to the run cache files within the process,
+// as runs may be looked up concurrently.
+var runCacheMu sync.Mutex
+
+// runCachePath returns the path of the run cache for owner/repo, under
+// $XDG_CACHE_HOME or its platform equivalent.
+func runCachePath(owner, repo string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cueckoo", "runs-"+owner+"-"+repo+".json"), nil
+}
+
+// updateRunCache applies update to the run cache for owner/repo, keyed by
+// the keys of runQuery. Failures are only reported in debug mode, as the cache is
+// an optimisation.
+func updateRunCache(owner, repo string, update func(entries map[string]runCacheEntry)) {
+	runCacheMu.Lock()
+	defer runCacheMu.Unlock()
+	path, err := runCachePath(owner, repo)
+	if err != nil {
+		debugf("failed to locate run cache: %v\n", err)
+		return
+	}
+	entries := readRunCache(path)
+	update(entries)
+	for key, e := range entries {
+		if time.Since(e.Updated) > runCacheMaxAge {
+			delete(entries, key)
+		}
+	}
+	data, err := json.Marshal(entries)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o777)
+	}
+	if err == nil {
+		err = os.WriteFile(path, data, 0o666)
+	}
+	if err != nil {
+		debugf("failed to update run cache: %v\n", err)
+	}
+}
+
+// readRunCache returns the entries of the run cache at path, which are empty
+// if it does not exist or cannot be read.
+func readRunCache(path string) map[string]runCacheEntry {
+	entries := make(map[string]runCacheEntry)
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &entries)
+	}
+	return entries
+}
+
+// cachedRun returns the run cached for q in owner/repo, fetched afresh, or nil
+// if there is none or it cannot be fetched.
+func (c *config) cachedRun(ctx context.Context, owner, repo string, q runQuery) *github.WorkflowRun {
+	if noRunCache {
+		return nil
+	}
+	path, err := runCachePath(owner, repo)
+	if err != nil {
+		return nil
+	}
+	runCacheMu.Lock()
+	entry := readRunCache(path)[q.key]
+	runCacheMu.Unlock()
+	if entry.ID == 0 {
+		return nil
+	}
+	run, _, err := c.githubClient.Actions.GetWorkflowRunByID(ctx, owner, repo, entry.ID)
+	if err != nil || !q.match(run) {
+		debugf("dropping cached run %d for %q: %v\n", entry.ID, q.key, err)
+		forgetRun(owner, repo, q.key)
+		return nil
+	}
+	if run.GetConclusion() != entry.Conclusion {
+		recordRun(owner, repo, q.key, run)
+	}
+	return run
+}
+
+// recordRun caches run as the run for key in owner/repo.
+func recordRun(owner, repo, key string, run *github.WorkflowRun) {
+	if noRunCache {
+		return
+	}
+	updateRunCache(owner, repo, func(entries map[string]runCacheEntry) {
+		entries[key] = runCacheEntry{
+			ID:         run.GetID(),
+			Conclusion: run.GetConclusion(),
+			Updated:    time.Now(),
+		}
+	})
+}
+
+// forgetRun drops the cached run for key in owner/repo.
+func forgetRun(owner, repo, key string) {
+	updateRunCache(owner, repo, func(entries map[string]runCacheEntry) {
+		delete(entries, key)
+	})
+}
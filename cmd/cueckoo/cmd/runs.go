@@ -0,0 +1,331 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// runPollInterval is how often watchRun checks on a run.
+const runPollInterval = 15 * time.Second
+
+// trybotRunTitle returns the display title of the workflow run resulting from
+// a trybot dispatch for the Gerrit ref, such as refs/changes/45/12345/6.
+// It matches the event type used by buildTryBotPayload.
+func trybotRunTitle(ref string) string {
+	return fmt.Sprintf("trybot run for %v", ref)
+}
+
+// unityRunTitle is like trybotRunTitle, for unity dispatches; see
+// buildUnityPayloadFromCLTrigger.
+func unityRunTitle(ref string) string {
+	return fmt.Sprintf("unity run for %v", ref)
+}
+
+// dispatchTrailer is the key of the trailer holding the JSON-encoded payload
+// of a trybot dispatch, which the dispatch workflow adds to the commit it
+// pushes to the trybot repository; see trybotRepo.
+const dispatchTrailer = "Dispatch-Trailer"
+
+// trybotRepo returns the name of the repository, owned by c.githubOwner, in
+// which the trybots run. A trybot dispatch is relayed: its run in the
+// repository, with the display title trybotRunTitle, adds a Dispatch-Trailer
+// to the commit of the patchset and pushes it to the trybot repository,
+// whose trybot workflow then runs for the push. The outcome of the dispatch
+// is therefore that of the push run in the trybot repository.
+func (c *config) trybotRepo() string {
+	return c.githubRepo + "-trybot"
+}
+
+// parseDispatchTrailer returns the payload in the Dispatch-Trailer of the
+// commit message msg, reporting whether it has one.
+func parseDispatchTrailer(msg string) (repositoryDispatchPayload, bool) {
+	var p repositoryDispatchPayload
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		value, ok := strings.CutPrefix(lines[i], dispatchTrailer+":")
+		if !ok {
+			continue
+		}
+		err := json.Unmarshal([]byte(strings.TrimSpace(value)), &p)
+		return p, err == nil
+	}
+	return p, false
+}
+
+// runQuery identifies the workflow run sought in a repository.
+type runQuery struct {
+	// event is the event which triggered the run, such as "push".
+	event string
+
+	// key identifies the run in the run cache and in messages, such as the
+	// display title of a dispatched run.
+	key string
+
+	// match reports whether a run is the one sought.
+	match func(*github.WorkflowRun) bool
+}
+
+// dispatchedRunQuery returns the query for the run triggered by a repository
+// dispatch with the given display title.
+func dispatchedRunQuery(title string) runQuery {
+	return runQuery{
+		event: "repository_dispatch",
+		key:   title,
+		match: func(run *github.WorkflowRun) bool {
+			return run.GetDisplayTitle() == title
+		},
+	}
+}
+
+// trybotRunQuery returns the query for the run in the trybot repository for
+// the trybot dispatch for patchset of CL; see trybotRepo.
+func trybotRunQuery(cl, patchset int) runQuery {
+	return runQuery{
+		event: "push",
+		key:   trybotRunKey(cl, patchset),
+		match: func(run *github.WorkflowRun) bool {
+			p, ok := parseDispatchTrailer(run.GetHeadCommit().GetMessage())
+			return ok && p.Type == string(eventTypeTrybot) && p.CL == cl && p.Patchset == patchset
+		},
+	}
+}
+
+// trybotRunKey returns the key of the run for patchset of CL in the run cache
+// of the trybot repository.
+func trybotRunKey(cl, patchset int) string {
+	return fmt.Sprintf("trybot run for CL %d patchset %d", cl, patchset)
+}
+
+// findDispatchedRun returns the most recent workflow run in owner/repo which
+// was triggered by a repository dispatch with the given display title, or nil
+// if there is none. The run found is cached; see runCacheEntry.
+func (c *config) findDispatchedRun(ctx context.Context, owner, repo, title string) (*github.WorkflowRun, error) {
+	return c.findRun(ctx, owner, repo, dispatchedRunQuery(title))
+}
+
+// findTrybotRun returns the most recent run in the trybot repository for the
+// trybot dispatch for patchset of CL, or nil if there is none. This, rather
+// than the run of the dispatch, gives the outcome of the trybots.
+func (c *config) findTrybotRun(ctx context.Context, cl, patchset int) (*github.WorkflowRun, error) {
+	return c.findRun(ctx, c.githubOwner, c.trybotRepo(), trybotRunQuery(cl, patchset))
+}
+
+// findRun returns the most recent workflow run in owner/repo matching q, or
+// nil if there is none. The run found is cached; see runCacheEntry.
+func (c *config) findRun(ctx context.Context, owner, repo string, q runQuery) (*github.WorkflowRun, error) {
+	if run := c.cachedRun(ctx, owner, repo, q); run != nil {
+		return run, nil
+	}
+	run, err := c.listRun(ctx, owner, repo, q)
+	if run != nil {
+		recordRun(owner, repo, q.key, run)
+	}
+	return run, err
+}
+
+// listRun is like findRun, but always lists the runs rather than using the
+// run cache.
+func (c *config) listRun(ctx context.Context, owner, repo string, q runQuery) (*github.WorkflowRun, error) {
+	opts := &github.ListWorkflowRunsOptions{
+		Event:       q.event,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	// Runs are listed most recent first, and a run for a given patchset is
+	// typically recent, so only look at the first few pages.
+	for page := 0; page < 5; page++ {
+		runs, resp, err := c.githubClient.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workflow runs in %s/%s: %w", owner, repo, err)
+		}
+		for _, run := range runs.WorkflowRuns {
+			if q.match(run) {
+				return run, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil, nil
+}
+
+// waitForDispatchedRun waits for the workflow run in owner/repo with the given
+// display title, created no earlier than since, to complete, and returns it.
+// Use a context with a deadline to bound the wait.
+func (c *config) waitForDispatchedRun(ctx context.Context, owner, repo, title string, since time.Time) (*github.WorkflowRun, error) {
+	return c.watchRun(ctx, owner, repo, dispatchedRunQuery(title), since, nil)
+}
+
+// watchDispatchedRun is like waitForDispatchedRun, but also calls changed, if
+// not nil, with the run each time its status changes, starting with when it
+// is first found.
+func (c *config) watchDispatchedRun(ctx context.Context, owner, repo, title string, since time.Time, changed func(*github.WorkflowRun)) (*github.WorkflowRun, error) {
+	return c.watchRun(ctx, owner, repo, dispatchedRunQuery(title), since, changed)
+}
+
+// watchTrybotRun is like watchDispatchedRun, for the run in the trybot
+// repository for the trybot dispatch for patchset of CL.
+func (c *config) watchTrybotRun(ctx context.Context, cl, patchset int, since time.Time, changed func(*github.WorkflowRun)) (*github.WorkflowRun, error) {
+	return c.watchRun(ctx, c.githubOwner, c.trybotRepo(), trybotRunQuery(cl, patchset), since, changed)
+}
+
+// waitForTrybotRun waits for the trybot run for patchset of CL, whose ref is
+// given, dispatched no earlier than since: first for the run of the dispatch,
+// which relays it to the trybot repository, and then for the run there, which
+// it returns. Use a context with a deadline to bound the wait.
+func (c *config) waitForTrybotRun(ctx context.Context, ref string, cl, patchset int, since time.Time) (*github.WorkflowRun, error) {
+	relay, err := c.waitForDispatchedRun(ctx, c.githubOwner, c.githubRepo, trybotRunTitle(ref), since)
+	if err != nil {
+		return nil, err
+	}
+	if relay.GetConclusion() != "success" {
+		return nil, fmt.Errorf("trybot dispatch run %s concluded %s", relay.GetHTMLURL(), relay.GetConclusion())
+	}
+	return c.watchTrybotRun(ctx, cl, patchset, since, nil)
+}
+
+// watchRun waits for the workflow run in owner/repo matching q, created no
+// earlier than since, to complete, and returns it. It calls changed, if not
+// nil, with the run each time its status changes.
+func (c *config) watchRun(ctx context.Context, owner, repo string, q runQuery, since time.Time, changed func(*github.WorkflowRun)) (*github.WorkflowRun, error) {
+	// Allow for some clock skew between us and GitHub.
+	since = since.Add(-time.Minute)
+	ticker := time.NewTicker(runPollInterval)
+	defer ticker.Stop()
+	var run *github.WorkflowRun
+	var lastStatus string
+	for {
+		var err error
+		if run == nil {
+			// The run takes a few seconds to appear after the dispatch.
+			var r *github.WorkflowRun
+			r, err = c.listRun(ctx, owner, repo, q)
+			if r != nil && !r.GetCreatedAt().Time.Before(since) {
+				run = r
+			}
+		} else {
+			run, _, err = c.githubClient.Actions.GetWorkflowRunByID(ctx, owner, repo, run.GetID())
+			if err != nil {
+				err = fmt.Errorf("failed to get workflow run: %w", err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if run != nil && run.GetStatus() != lastStatus {
+			lastStatus = run.GetStatus()
+			if changed != nil {
+				changed(run)
+			}
+		}
+		if run.GetStatus() == "completed" {
+			recordRun(owner, repo, q.key, run)
+			return run, nil
+		}
+		select {
+		case <-ctx.Done():
+			if run == nil {
+				return nil, fmt.Errorf("no run %q started: %w", q.key, ctx.Err())
+			}
+			return nil, fmt.Errorf("run %s did not complete: %w", run.GetHTMLURL(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// runArtifact returns the files in the artifact called name uploaded by the
+// workflow run, keyed by their path within the artifact. It returns nil if
+// there is no such artifact.
+func (c *config) runArtifact(ctx context.Context, owner, repo string, run *github.WorkflowRun, name string) (map[string][]byte, error) {
+	list, _, err := c.githubClient.Actions.ListWorkflowRunArtifacts(ctx, owner, repo, run.GetID(), &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifacts of %s: %w", run.GetHTMLURL(), err)
+	}
+	var artifact *github.Artifact
+	for _, a := range list.Artifacts {
+		if a.GetName() == name {
+			artifact = a
+			break
+		}
+	}
+	if artifact == nil {
+		return nil, nil
+	}
+	u, _, err := c.githubClient.Actions.DownloadArtifact(ctx, owner, repo, artifact.GetID(), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL of artifact %s: %w", name, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download artifact %s: %s", name, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Artifacts are always zip archives.
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact %s: %v", name, err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s in artifact %s: %v", f.Name, name, err)
+		}
+		files[f.Name] = content
+	}
+	return files, nil
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
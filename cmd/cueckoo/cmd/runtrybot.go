@@ -15,7 +15,11 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/google/go-github/v53/github"
 	"github.com/spf13/cobra"
@@ -24,6 +28,8 @@ import (
 const (
 	flagRunTrybotNoUnity flagName = "nounity"
 	flagForce            flagName = "force"
+	flagRunTrybotRef     flagName = "ref"
+	flagRunTrybotPR      flagName = "pr"
 )
 
 // newRuntrybotCmd creates a new runtrybot command
@@ -34,7 +40,8 @@ func newRuntrybotCmd(c *Command) *cobra.Command {
 		Long: `
 Usage of runtrybot:
 
-	runtrybot [--nounity] [ARGS...]
+	runtrybot [--nounity] [--ref BRANCH] [--wait] [ARGS...]
+	runtrybot --pr N [--wait]
 
 Triggers trybot and unity runs for its arguments.
 
@@ -43,18 +50,75 @@ pending commit in the current branch. If multiple pending commits are found,
 you must either specify which commits or CLs to run, or specify HEAD to run the
 trybots for all of them.
 
+Arguments may also be the hashes of commits which are not pending locally,
+such as those copied from CI logs or the GitHub mirror, in which case the
+patchset with that commit is run.
+
+Pending commits are derived from the current branch, which cannot be done
+while HEAD is detached or a rebase, cherry-pick or similar is in progress.
+--ref names another branch or ref to derive pending commits from instead, in
+which case they are the commits since it branched from its upstream branch,
+or from the default branch of origin if it has none.
+
 runtrybot needs your GitHub username and a personal acccess token
 with the "repo" scope. You can configure them via your git credential helper,
 or by setting the GITHUB_USER and GITHUB_PAT environment variables.
+A token on its own can also be provided via GITHUB_TOKEN, as is typical in CI.
 Note that the personal access token should be "classic"; GitHub's new
 fine-grained tokens are still in beta and haven't been tested to work here.
 
-If the --nounity flag is provided, only a trybot run is triggered.
+With --pr, a trybot run is instead triggered for the head of the GitHub PR N,
+for repositories which accept PRs, such that maintainers can test a
+contribution before deciding to import it. The dispatch carries the PR
+number, the ref refs/pull/N/head and its current head commit; see "cueckoo
+explain ` + string(eventTypePRTrybot) + `". No unity run is triggered, as unity
+tests Gerrit changes. The GitHub repository must have a workflow which handles
+these dispatches, declared by listing "` + string(eventTypePRTrybot) + `" in the eventTypes of its
+` + payloadManifestFile + `; otherwise runtrybot --pr fails without dispatching.
+
+If the --nounity flag is provided, only a trybot run is triggered. The same
+happens, with a warning, when the unity repository is not accessible with your
+credentials, such as when it is private and your token lacks access; see
+"cueckoo doctor".
+
+Before triggering builds, a warning is printed if the pending commits are based
+on a commit which is far behind the default branch of origin, as of the last
+fetch. The thresholds default to 100 commits or 7 days, and can be changed via
+the "freshness-commits" and "freshness-age" entries in the user config.
+--no-freshness-check skips the check.
+
+When triggering builds for multiple CLs, a summary of the outcome for each CL
+is printed at the end. If builds could only be triggered for some of the CLs,
+runtrybot exits with status 2. Running the same command again within a day
+then only retries the CLs which failed, and CLs which gained new patchsets
+since; --no-resume triggers builds for all of them again. Builds are triggered
+for at most 8 CLs at a time, with requests spaced out to stay within the
+quotas of the Gerrit server.
+
+With --wait, runtrybot then waits for the dispatched workflow runs to
+complete, up to --wait-timeout, printing a line each time the status of a run
+changes. A trybot dispatch is followed through to the run in the trybot
+repository which it is relayed to. runtrybot exits with a non-zero status if
+any run did not succeed, such that scripts can gate further work on the
+trybots passing.
 `,
-		RunE: mkRunE(c, runtrybotDef),
+		RunE:              mkRunE(c, runtrybotDef),
+		ValidArgsFunction: completeChanges(0),
 	}
-	cmd.Flags().Bool(string(flagRunTrybotNoUnity), false, "do not simultaenously trigger unity build")
-	cmd.Flags().BoolP(string(flagForce), string(flagForce[0]), false, "force the trybots to run, ignoring any results")
+	flags := c.registerFlags(cmd)
+	flags.Bool(flagRunTrybotNoUnity, false, "do not simultaenously trigger unity build")
+	flags.String(flagRunTrybotRef, "", "derive pending commits from this ref rather than HEAD")
+	cmd.RegisterFlagCompletionFunc(string(flagRunTrybotRef), completeBranches)
+	flags.BoolP(flagForce, string(flagForce[0]), false, "force the trybots to run, ignoring any results")
+	flags.Bool(flagNoFreshnessCheck, false, "do not warn when pending commits are based on an outdated commit")
+	flags.Bool(flagOverrideFreeze, false, "proceed even during a freeze window of the repository")
+	flags.Bool(flagWait, false, "wait for the dispatched runs to complete")
+	flags.String(flagWaitTimeout, "1h", "how long to wait for the dispatched runs with --wait").Duration()
+	flags.Bool(flagNoResume, false, "do not skip CLs dispatched by a previous run which partially failed")
+	flags.Int(flagRunTrybotPR, 0, "run the trybot for the head of this GitHub PR")
+	flags.Exclusive(flagRunTrybotPR, flagRunTrybotRef)
+	flags.Exclusive(flagRunTrybotPR, flagRunTrybotNoUnity)
+	flags.NoArgsWith(flagRunTrybotPR)
 	return cmd
 }
 
@@ -63,33 +127,120 @@ func runtrybotDef(cmd *Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := cfg.checkFreeze(cmd); err != nil {
+		return err
+	}
+	waitTimeout, err := parseDuration(flagWaitTimeout.String(cmd))
+	if err != nil {
+		return err
+	}
+	watcher := &runWatcher{cfg: cfg}
+	if pr := flagRunTrybotPR.Int(cmd); pr != 0 {
+		err := cfg.runPRTrybot(cmd.Context(), pr, watcher)
+		if err == nil && flagWait.Bool(cmd) && len(watcher.runs) > 0 {
+			err = watcher.wait(cmd.Context(), cmd.OutOrStdout(), waitTimeout)
+		}
+		return err
+	}
+
+	// Check access to unity up front, rather than failing every dispatch
+	// part way through a batch.
+	withUnity := cfg.unityRepo != "" && !flagRunTrybotNoUnity.Bool(cmd)
+	if withUnity {
+		var accessErr *unityAccessError
+		if err := cfg.checkUnityAccess(cmd.Context()); errors.As(err, &accessErr) {
+			fmt.Fprintf(os.Stderr, "warning: %v; only triggering trybot runs\n", err)
+			withUnity = false
+		}
+	}
 	r := newCLTrigger(cmd, cfg, func(payload repositoryDispatchPayload) error {
+		start := time.Now()
 		trybotPayload := payload
 		trybotPayload.Type = string(eventTypeTrybot)
 		p, err := buildTryBotPayload(trybotPayload)
 		if err != nil {
 			return err
 		}
-		if err := cfg.triggerRepositoryDispatch(cfg.githubOwner, cfg.githubRepo, p); err != nil {
+		if err := cfg.triggerRepositoryDispatch(cmd.Context(), cfg.githubOwner, cfg.githubRepo, p); err != nil {
 			return err
 		}
-		if cfg.unityRepo != "" && !flagRunTrybotNoUnity.Bool(cmd) {
+		watcher.addTrybot(payload.Ref, payload.CL, payload.Patchset, start)
+		if withUnity {
 			unityPayload := payload
 			unityPayload.Type = string(eventTypeUnity)
 			p, err := buildUnityPayloadFromCLTrigger(unityPayload)
 			if err != nil {
 				return err
 			}
-			if err := cfg.triggerRepositoryDispatch(cfg.unityOwner, cfg.unityRepo, p); err != nil {
+			if err := cfg.triggerRepositoryDispatch(cmd.Context(), cfg.unityOwner, cfg.unityRepo, p); err != nil {
 				return err
 			}
+			watcher.add(cfg.unityOwner, cfg.unityRepo, unityRunTitle(payload.Ref), start)
 		}
 		return nil
 	})
-	return r.run()
+	err = r.run()
+	if flagWait.Bool(cmd) && len(watcher.runs) > 0 {
+		if werr := watcher.wait(cmd.Context(), cmd.OutOrStdout(), waitTimeout); err == nil {
+			err = werr
+		}
+	}
+	return err
 }
 
 func buildTryBotPayload(payload repositoryDispatchPayload) (github.DispatchRequestOptions, error) {
-	msg := fmt.Sprintf("trybot run for %v", payload.Ref)
+	msg := trybotRunTitle(payload.Ref)
+	payload.PayloadVersion = payloadVersion
 	return buildDispatchPayload(msg, payload)
 }
+
+// prTrybotPayload is the payload of a trybot dispatch for a GitHub PR.
+type prTrybotPayload struct {
+	repositoryDispatchPayload
+
+	PR int `json:"PR"`
+
+	// SHA is the head commit of the PR as of the dispatch.
+	SHA string `json:"sha"`
+}
+
+// prHeadRef returns the GitHub ref of the head of the PR number pr.
+func prHeadRef(pr int) string {
+	return fmt.Sprintf("refs/pull/%d/head", pr)
+}
+
+func buildPRTrybotPayload(payload prTrybotPayload) (github.DispatchRequestOptions, error) {
+	payload.PayloadVersion = payloadVersion
+	return buildDispatchPayload(trybotRunTitle(payload.Ref), payload)
+}
+
+// runPRTrybot dispatches a trybot run for the current head of the GitHub PR
+// number n, adding it to watcher.
+func (c *config) runPRTrybot(ctx context.Context, n int, watcher *runWatcher) error {
+	pr, _, err := c.githubClient.PullRequests.Get(ctx, c.githubOwner, c.githubRepo, n)
+	if err != nil {
+		return fmt.Errorf("could not get github PR: %v", err)
+	}
+	if state := pr.GetState(); state != "open" {
+		return fmt.Errorf("PR %d is %s", n, state)
+	}
+	start := time.Now()
+	ref := prHeadRef(n)
+	p, err := buildPRTrybotPayload(prTrybotPayload{
+		repositoryDispatchPayload: repositoryDispatchPayload{
+			Type:         string(eventTypePRTrybot),
+			TargetBranch: pr.GetBase().GetRef(),
+			Ref:          ref,
+		},
+		PR:  n,
+		SHA: pr.GetHead().GetSHA(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.triggerRepositoryDispatch(ctx, c.githubOwner, c.githubRepo, p); err != nil {
+		return err
+	}
+	watcher.add(c.githubOwner, c.githubRepo, trybotRunTitle(ref), start)
+	return nil
+}
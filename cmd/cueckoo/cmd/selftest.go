@@ -0,0 +1,289 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagSelftestGerritProject flagName = "gerrit-project"
+	flagSelftestGitHubRepo    flagName = "github-repo"
+	flagSelftestTimeout       flagName = "timeout"
+	flagSelftestKeep          flagName = "keep"
+)
+
+// newSelftestCmd creates a new selftest command
+func newSelftestCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "exercise the full CL pipeline against a sandbox project",
+		Long: `
+Usage of selftest:
+
+	selftest --gerrit-project PROJECT --github-repo OWNER/REPO [--timeout DURATION] [--keep]
+
+selftest verifies the CI infrastructure end-to-end using a sandbox Gerrit
+project and the GitHub repository it is mirrored to. It runs the following
+stages in order, stopping at the first which fails:
+
+	create    create a CL adding a file under selftest/
+	trybot    dispatch a trybot run for the CL
+	result    wait for the trybot run to complete and vote TryBot-Result
+	submit    vote Code-Review+2 and submit the CL
+	mirror    wait for the submitted commit to appear on GitHub
+
+The sandbox must be set up like the real repositories, with the dispatch
+workflow in the GitHub repository which relays trybot dispatches to the
+repository of the same name with a -trybot suffix, and the trybot workflow in
+the latter. The authenticated user must be allowed to approve and submit CLs
+in the Gerrit project. As a safeguard,
+selftest refuses to run against the project of the current repository.
+
+--timeout bounds each of the waiting stages. If a stage fails before the CL
+is submitted, the CL is abandoned unless --keep is given.
+`,
+		RunE: mkRunE(c, selftestDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagSelftestGerritProject, "", "sandbox Gerrit project")
+	flags.String(flagSelftestGitHubRepo, "", "sandbox GitHub repository, as OWNER/REPO")
+	flags.String(flagSelftestTimeout, "30m", "how long to wait for each stage")
+	flags.Bool(flagSelftestKeep, false, "do not abandon the CL after a failure")
+	return cmd
+}
+
+// selftest holds the state shared by the stages of a selftest run.
+type selftest struct {
+	cfg     *config
+	timeout time.Duration
+
+	branch     string
+	change     *gerrit.ChangeInfo
+	ref        string
+	patchset   int
+	dispatched time.Time
+	commit     string // merged commit
+}
+
+type selftestStage struct {
+	name string
+	run  func(st *selftest, ctx context.Context) error
+}
+
+var selftestStages = []selftestStage{
+	{"create", (*selftest).create},
+	{"trybot", (*selftest).trybot},
+	{"result", (*selftest).result},
+	{"submit", (*selftest).submit},
+	{"mirror", (*selftest).mirror},
+}
+
+func selftestDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("selftest does not take any arguments")
+	}
+	project := flagSelftestGerritProject.String(cmd)
+	owner, repo, ok := strings.Cut(flagSelftestGitHubRepo.String(cmd), "/")
+	if project == "" || !ok || owner == "" || repo == "" {
+		return fmt.Errorf("--gerrit-project and --github-repo OWNER/REPO are required")
+	}
+	timeout, err := parseDuration(flagSelftestTimeout.String(cmd))
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if project == cfg.gerritProject() || (owner == cfg.githubOwner && repo == cfg.githubRepo) {
+		return fmt.Errorf("refusing to run selftest against %s; use a sandbox project", cfg.gerritProject())
+	}
+	// Point the configuration at the sandbox, so that the helpers used by
+	// the other commands are exercised as-is.
+	cfg.gerritProjectName = project
+	cfg.githubOwner = owner
+	cfg.githubRepo = repo
+	cfg.githubURL = "https://github.com/" + owner + "/" + repo
+
+	st := &selftest{cfg: cfg, timeout: timeout}
+	w := newTableWriter(cmd.OutOrStdout())
+	var failed error
+	for _, stage := range selftestStages {
+		start := time.Now()
+		err := stage.run(st, ctx)
+		took := time.Since(start).Round(time.Second)
+		if err != nil {
+			fmt.Fprintf(w, "%s\tFAIL\t%v\t%v\n", stage.name, took, err)
+			failed = fmt.Errorf("selftest failed at stage %s", stage.name)
+			break
+		}
+		fmt.Fprintf(w, "%s\tok\t%v\t%s\n", stage.name, took, st.detail(stage.name))
+		w.Flush()
+	}
+	w.Flush()
+	if failed != nil && st.change != nil && st.commit == "" && !flagSelftestKeep.Bool(cmd) {
+		id := strconv.Itoa(st.change.Number)
+		in := map[string]string{"message": "Abandoned by cueckoo selftest after a failure."}
+		if err := cfg.gerritDo(http.MethodPost, "changes/"+id+"/abandon", in, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to abandon %s: %v\n", cfg.changeURL(st.change.Number), err)
+		}
+	}
+	return failed
+}
+
+// detail returns a description of the outcome of a successful stage.
+func (st *selftest) detail(stage string) string {
+	switch stage {
+	case "create":
+		return st.cfg.changeURL(st.change.Number)
+	case "trybot":
+		return st.ref
+	case "mirror":
+		return st.commit
+	}
+	return ""
+}
+
+func (st *selftest) create(ctx context.Context) error {
+	repo, _, err := st.cfg.githubClient.Repositories.Get(ctx, st.cfg.githubOwner, st.cfg.githubRepo)
+	if err != nil {
+		return fmt.Errorf("failed to get %s/%s: %w", st.cfg.githubOwner, st.cfg.githubRepo, err)
+	}
+	st.branch = repo.GetDefaultBranch()
+	now := time.Now().UTC()
+	path := fmt.Sprintf("selftest/%s.txt", now.Format("20060102-150405"))
+	msg := "selftest: add " + path + "\n\nThis CL was generated by cueckoo selftest.\n"
+	st.change, err = st.cfg.createFileChange(st.cfg.gerritProject(), st.branch, msg, path, []byte(now.Format(time.RFC3339)+"\n"))
+	return err
+}
+
+func (st *selftest) trybot(ctx context.Context) error {
+	id := strconv.Itoa(st.change.Number)
+	ch, _, err := st.cfg.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	rev := ch.Revisions[ch.CurrentRevision]
+	st.ref = rev.Ref
+	st.patchset = rev.Number
+	p, err := buildTryBotPayload(repositoryDispatchPayload{
+		Type:         string(eventTypeTrybot),
+		CL:           ch.Number,
+		Patchset:     rev.Number,
+		TargetBranch: ch.Branch,
+		Ref:          rev.Ref,
+	})
+	if err != nil {
+		return err
+	}
+	st.dispatched = time.Now()
+	return st.cfg.triggerRepositoryDispatch(ctx, st.cfg.githubOwner, st.cfg.githubRepo, p)
+}
+
+func (st *selftest) result(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, st.timeout)
+	defer cancel()
+	run, err := st.cfg.waitForTrybotRun(ctx, st.ref, st.change.Number, st.patchset, st.dispatched)
+	if err != nil {
+		return err
+	}
+	if c := run.GetConclusion(); c != "success" {
+		return fmt.Errorf("trybot run %s concluded %s", run.GetHTMLURL(), c)
+	}
+	// The workflow reports back to Gerrit as its final step, which may
+	// take a moment to be visible.
+	return pollUntil(ctx, func() (bool, error) {
+		ch, _, err := st.cfg.gerritClient.Changes.GetChange(strconv.Itoa(st.change.Number), &gerrit.ChangeOptions{
+			AdditionalFields: []string{"LABELS"},
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to get change: %w", err)
+		}
+		for _, approval := range ch.Labels["TryBot-Result"].All {
+			switch {
+			case approval.Value > 0:
+				return true, nil
+			case approval.Value < 0:
+				return false, fmt.Errorf("trybot run %s succeeded but voted TryBot-Result%+d", run.GetHTMLURL(), approval.Value)
+			}
+		}
+		return false, nil
+	})
+}
+
+func (st *selftest) submit(ctx context.Context) error {
+	id := strconv.Itoa(st.change.Number)
+	if _, _, err := st.cfg.gerritClient.Changes.SetReview(id, "current", &gerrit.ReviewInput{
+		Labels: map[string]string{"Code-Review": "+2"},
+	}); err != nil {
+		return fmt.Errorf("failed to approve CL: %w", err)
+	}
+	if err := st.cfg.submitWithRetry(id, 3); err != nil {
+		return fmt.Errorf("failed to submit CL: %w", err)
+	}
+	var branch struct {
+		Revision string `json:"revision"`
+	}
+	path := "projects/" + url.PathEscape(st.cfg.gerritProject()) + "/branches/" + url.PathEscape(st.branch)
+	if err := st.cfg.gerritDo(http.MethodGet, path, nil, &branch); err != nil {
+		return fmt.Errorf("failed to get branch %s: %w", st.branch, err)
+	}
+	st.commit = branch.Revision
+	return nil
+}
+
+func (st *selftest) mirror(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, st.timeout)
+	defer cancel()
+	return pollUntil(ctx, func() (bool, error) {
+		b, _, err := st.cfg.githubClient.Repositories.GetBranch(ctx, st.cfg.githubOwner, st.cfg.githubRepo, st.branch, true)
+		if err != nil {
+			return false, fmt.Errorf("failed to get GitHub branch %s: %w", st.branch, err)
+		}
+		return b.GetCommit().GetSHA() == st.commit, nil
+	})
+}
+
+// pollUntil calls done every runPollInterval until it reports true or fails,
+// or ctx is done.
+func pollUntil(ctx context.Context, done func() (bool, error)) error {
+	ticker := time.NewTicker(runPollInterval)
+	defer ticker.Stop()
+	for {
+		ok, err := done()
+		if ok || err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,477 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagServeAddr   flagName = "addr"
+	flagServeFlakes flagName = "flakes"
+	flagServeQueue  flagName = "submit-queue"
+	flagServeFeed   flagName = "feed-workflows"
+	flagServeSize   flagName = "size-labels"
+	flagServeChecks flagName = "checks"
+
+	// webhookSecretEnv is the environment variable holding the secret
+	// with which GitHub signs webhook deliveries.
+	webhookSecretEnv = "CUECKOO_WEBHOOK_SECRET"
+
+	// runResultTag is the Gerrit message tag used when reporting the result
+	// of a trybot or unity run.
+	runResultTag = "autogenerated:cueckoo-run-result"
+)
+
+// runTitleRegex matches the display titles of the workflow runs dispatched
+// for a Gerrit patchset; see trybotRunTitle and buildUnityPayloadFromCLTrigger.
+var runTitleRegex = regexp.MustCompile(`^(trybot|unity) run for refs/changes/\d+/(\d+)/(\d+)$`)
+
+// newServeCmd creates a new serve command
+func newServeCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "report trybot and unity results to Gerrit from GitHub webhooks",
+		Long: `
+Usage of serve:
+
+	serve [--addr ADDR] [--flakes FILE] [--submit-queue HASHTAG] [--feed-workflows REGEXP]
+	      [--size-labels] [--checks]
+
+serve listens on --addr for GitHub webhook deliveries of workflow_run events,
+and reports the results of completed trybot and unity runs to the CL and
+patchset they were dispatched for. The downstream workflows then do not need
+Gerrit credentials of their own.
+
+Trybot dispatches are relayed by the dispatch workflow of the repository,
+which pushes the patchset with a Dispatch-Trailer to the trybot repository,
+named after the repository with a "-trybot" suffix; the trybots then run for
+that push. For such a trybot run, the patchset is voted TryBot-Result+1 or
+TryBot-Result-1 depending on the outcome of the run, and a robot comment
+linking to the run is added. The run of the dispatch workflow itself is not
+reported. For a unity run dispatched in the unity repository, only the robot
+comment is added. No vote is cast on a patchset which is no longer current,
+and cancelled or skipped runs are ignored. The text of the comment is the
+"runResult" template of ` + messagesFile + ` if set.
+
+If a trybot run failed and every failure matches a known flake, its failed jobs
+are re-run instead, as "rerun --if-flaky" does. The known-flake database is
+read from --flakes, which defaults to ` + defaultFlakesFile + ` in the
+repository if it exists.
+
+Deliveries must be signed with the webhook secret, which is read from the
+` + webhookSecretEnv + ` environment variable. Configure the webhooks of both the
+trybot repository and the unity repository to send "Workflow runs" events to
+the address serve listens on, with content type application/json.
+
+With --submit-queue, serve also runs a submit queue, an approximation of
+GitHub merge queues: maintainers add the given hashtag to CLs which are ready,
+and serve works through them one at a time, lowest CL number first. Each CL
+is rebased on the tip of its branch, the trybots are run on the rebased
+patchset, and the CL is submitted once they pass. A CL is removed from the
+queue, with a comment saying why, if it cannot be rebased, if the trybots
+fail, or if it is not submittable once they pass. When talking to Gerrit or
+GitHub fails, the queue backs off for up to 15 minutes.
+
+With --feed-workflows, each completed run in the repository of a workflow
+whose name matches the regular expression, such as "^Release", is recorded in
+the activity feed of the repository; see the feed command.
+
+With --size-labels, serve also labels new and updated CLs by size every five
+minutes, warning about CLs exceeding the review-size guidance, as
+"size --apply" does.
+
+With --checks, trybot and unity runs are published as checks of Gerrit's
+checks plugin instead of as comments, showing as running once they start,
+and with their outcome, link and duration once they complete; see the checks
+command. The TryBot-Result vote is still cast. If the server lacks the plugin
+or the checkers, serve falls back to comments. Configure the webhooks to also
+send in-progress "Workflow runs" events.
+`,
+		RunE: mkRunE(c, serveDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagServeAddr, ":8080", "address to listen on for webhooks")
+	flags.String(flagServeFlakes, "", "path of the known-flake database (default "+defaultFlakesFile+")")
+	flags.String(flagServeQueue, "", "run a submit queue for the CLs with this hashtag")
+	flags.String(flagServeFeed, "", "record runs of the workflows matching this regular expression in the activity feed")
+	flags.Bool(flagServeSize, false, "label updated CLs by size")
+	flags.Bool(flagServeChecks, false, "publish runs as checks of Gerrit's checks plugin")
+	return cmd
+}
+
+func serveDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("serve does not take any arguments")
+	}
+	secret := os.Getenv(webhookSecretEnv)
+	if secret == "" {
+		return fmt.Errorf("%s must be set to the webhook secret", webhookSecretEnv)
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	// Without a known-flake database, failures are never retried.
+	var db *flakeDB
+	path := flagServeFlakes.String(cmd)
+	if path == "" {
+		path = filepath.Join(cfg.gitRoot, defaultFlakesFile)
+		if !fileExists(path) {
+			path = ""
+		}
+	}
+	if path != "" {
+		if db, err = loadFlakeDB(ctx, path); err != nil {
+			return err
+		}
+	}
+
+	var feed *regexp.Regexp
+	if expr := flagServeFeed.String(cmd); expr != "" {
+		if cfg.activityFeed == "" {
+			return fmt.Errorf("--%s requires an %s entry in codereview.cfg", flagServeFeed, activityFeedKey)
+		}
+		if feed, err = regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagServeFeed, err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	s := &webhookServer{cfg: cfg, flakes: db, feed: feed, checks: flagServeChecks.Bool(cmd), secret: []byte(secret), w: cmd.OutOrStdout(), ctx: ctx}
+	srv := &http.Server{
+		Addr:              flagServeAddr.String(cmd),
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	if hashtag := flagServeQueue.String(cmd); hashtag != "" {
+		q := &submitQueue{cfg: cfg, hashtag: hashtag, logf: s.logf, dispatched: make(map[int]int)}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			q.run(ctx)
+		}()
+	}
+	if flagServeSize.Bool(cmd) {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			cfg.runSizeLabels(ctx, s.logf)
+		}()
+	}
+	s.logf("listening on %s", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	// Let reports which are in progress finish.
+	s.wg.Wait()
+	return nil
+}
+
+// webhookServer handles GitHub webhook deliveries for serve.
+type webhookServer struct {
+	cfg    *config
+	flakes *flakeDB
+	secret []byte
+
+	// feed matches the names of the workflows whose runs are recorded in
+	// the activity feed, if any.
+	feed *regexp.Regexp
+
+	// checks is set to publish runs as checks rather than comments.
+	checks bool
+
+	// ctx is done when the server is shutting down.
+	ctx context.Context
+	wg  sync.WaitGroup
+
+	mu sync.Mutex // guards w
+	w  io.Writer
+}
+
+func (s *webhookServer) logf(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.w, "%s %s\n", time.Now().Format(time.DateTime), fmt.Sprintf(format, args...))
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payload, err := github.ValidatePayload(r, s.secret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event, err := github.ParseWebHook(github.WebHookType(r), payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ev, ok := event.(*github.WorkflowRunEvent)
+	started := s.checks && ev.GetAction() == "in_progress"
+	if !ok || (ev.GetAction() != "completed" && !started) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	// GitHub expects a response within ten seconds, which matching flakes
+	// can exceed, so report the result in the background.
+	w.WriteHeader(http.StatusAccepted)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if started {
+			msg, err := s.cfg.reportRunStarted(ev)
+			switch {
+			case err != nil:
+				s.logf("%s: %v", ev.GetWorkflowRun().GetHTMLURL(), err)
+			case msg != "":
+				s.logf("%s", msg)
+			}
+			return
+		}
+		if s.feed != nil {
+			if err := s.recordRun(ev); err != nil {
+				s.logf("%s: %v", ev.GetWorkflowRun().GetHTMLURL(), err)
+			}
+		}
+		msg, err := s.cfg.reportRun(s.ctx, s.flakes, s.checks, ev)
+		switch {
+		case err != nil:
+			s.logf("%s: %v", ev.GetWorkflowRun().GetHTMLURL(), err)
+		case msg != "":
+			s.logf("%s", msg)
+		}
+	}()
+}
+
+// recordRun records the completed workflow run in ev in the activity feed,
+// if it is a run in the repository of a workflow matched by s.feed.
+func (s *webhookServer) recordRun(ev *github.WorkflowRunEvent) error {
+	run := ev.GetWorkflowRun()
+	if ev.GetRepo().GetOwner().GetLogin() != s.cfg.githubOwner || ev.GetRepo().GetName() != s.cfg.githubRepo {
+		return nil
+	}
+	if !s.feed.MatchString(run.GetName()) {
+		return nil
+	}
+	text := fmt.Sprintf("%s on %s concluded %s: %s", run.GetName(), run.GetHeadBranch(), run.GetConclusion(), run.GetHTMLURL())
+	if err := s.cfg.recordActivity(s.ctx, "workflow", text); err != nil {
+		return err
+	}
+	s.logf("recorded %s run %s in the activity feed", run.GetName(), run.GetHTMLURL())
+	return nil
+}
+
+// parseRunTitle returns the kind of run, trybot or unity, and the CL and
+// patchset it was dispatched for, given the display title of a run.
+func parseRunTitle(title string) (kind string, cl, patchset int, ok bool) {
+	m := runTitleRegex.FindStringSubmatch(title)
+	if m == nil {
+		return "", 0, 0, false
+	}
+	cl, _ = strconv.Atoi(m[2])
+	patchset, _ = strconv.Atoi(m[3])
+	return m[1], cl, patchset, true
+}
+
+// runTarget returns the kind of the workflow run in ev, and the CL and
+// patchset it was dispatched for, if it is a trybot or unity run for this
+// repository.
+//
+// Trybot runs are the runs in the trybot repository for a push of a commit
+// with a Dispatch-Trailer, rather than the runs of the dispatch workflow
+// which relays the dispatch; see trybotRepo. Unity runs are those in the
+// unity repository triggered by a repository dispatch, whose display title
+// gives the patchset. Only those with write access to the repositories can
+// push or dispatch; the workflows also run for pull requests, whose titles
+// and commit messages anyone can choose to look like those of a dispatch.
+func (c *config) runTarget(ev *github.WorkflowRunEvent) (kind string, cl, patchset int, ok bool) {
+	run := ev.GetWorkflowRun()
+	owner, repo := ev.GetRepo().GetOwner().GetLogin(), ev.GetRepo().GetName()
+	switch {
+	case owner == c.githubOwner && repo == c.trybotRepo() && run.GetEvent() == "push":
+		p, ok := parseDispatchTrailer(run.GetHeadCommit().GetMessage())
+		if !ok || p.Type != string(eventTypeTrybot) || p.CL == 0 {
+			return "", 0, 0, false
+		}
+		return "trybot", p.CL, p.Patchset, true
+	case owner == c.unityOwner && repo == c.unityRepo && run.GetEvent() == "repository_dispatch":
+		kind, cl, patchset, ok = parseRunTitle(run.GetDisplayTitle())
+		if !ok || kind != "unity" {
+			return "", 0, 0, false
+		}
+		return kind, cl, patchset, true
+	}
+	return "", 0, 0, false
+}
+
+// reviewInput is the subset of Gerrit's ReviewInput entity used by
+// reportRun, which go-gerrit lacks robot comments for.
+type reviewInput struct {
+	Message       string                         `json:"message,omitempty"`
+	Tag           string                         `json:"tag,omitempty"`
+	Labels        map[string]int                 `json:"labels,omitempty"`
+	RobotComments map[string][]robotCommentInput `json:"robot_comments,omitempty"`
+}
+
+type robotCommentInput struct {
+	Message    string `json:"message"`
+	RobotID    string `json:"robot_id"`
+	RobotRunID string `json:"robot_run_id"`
+	URL        string `json:"url"`
+}
+
+// reportRunStarted publishes the workflow run in ev, which has started, as
+// a running check on the patchset it was dispatched for. Like reportRun, it
+// returns a description of what was done.
+func (c *config) reportRunStarted(ev *github.WorkflowRunEvent) (string, error) {
+	kind, cl, patchset, ok := c.runTarget(ev)
+	if !ok {
+		return "", nil
+	}
+	if err := c.postCheck(cl, patchset, runCheck(kind, ev.GetWorkflowRun())); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("reported %s run %s as running on CL %d patchset %d", kind, ev.GetWorkflowRun().GetHTMLURL(), cl, patchset), nil
+}
+
+// reportRun reports the result of the completed workflow run in ev to the
+// patchset it was dispatched for, as a check if checks is set. It returns a
+// description of what was done, which is empty if the run was not a trybot
+// or unity run for this repository.
+func (c *config) reportRun(ctx context.Context, db *flakeDB, checks bool, ev *github.WorkflowRunEvent) (string, error) {
+	run := ev.GetWorkflowRun()
+	kind, cl, patchset, ok := c.runTarget(ev)
+	if !ok {
+		return "", nil
+	}
+	var vote int
+	switch run.GetConclusion() {
+	case "success":
+		vote = 1
+	case "failure", "timed_out":
+		vote = -1
+	default:
+		// A check shown as running would otherwise never finish.
+		if checks {
+			if err := c.postCheck(cl, patchset, runCheck(kind, run)); err != nil && gerritStatus(err) != http.StatusNotFound {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("ignoring %s run %s which concluded %s", kind, run.GetHTMLURL(), run.GetConclusion()), nil
+	}
+
+	id := strconv.Itoa(cl)
+	ch, _, err := c.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	current := ch.Revisions[ch.CurrentRevision].Number == patchset
+	if kind == "trybot" && vote < 0 && current && db != nil {
+		retried, msg, err := c.retryFlakyRun(ctx, db, *ch, run)
+		if err != nil {
+			return "", err
+		}
+		if retried {
+			return msg, nil
+		}
+	}
+
+	msgs, err := loadBotMessages(ctx, c.gitRoot)
+	if err != nil {
+		return "", err
+	}
+	msg, err := msgs.exec("runResult", runResultData{
+		Kind:       kind,
+		Conclusion: run.GetConclusion(),
+		URL:        run.GetHTMLURL(),
+		CL:         cl,
+		Patchset:   patchset,
+	})
+	if err != nil {
+		return "", err
+	}
+	in := reviewInput{
+		Message: msg,
+		Tag:     runResultTag,
+		RobotComments: map[string][]robotCommentInput{
+			"/COMMIT_MSG": {{
+				Message:    msg,
+				RobotID:    "cueckoo-" + kind,
+				RobotRunID: strconv.FormatInt(run.GetID(), 10),
+				URL:        run.GetHTMLURL(),
+			}},
+		},
+	}
+	// Older Gerrit servers only get the message.
+	if !c.supports(featureRobotComments) {
+		in.RobotComments = nil
+	}
+	// A check replaces the comments, falling back to them when the checks
+	// plugin or the checker is missing.
+	checked := false
+	if checks {
+		err := c.postCheck(cl, patchset, runCheck(kind, run))
+		switch {
+		case err == nil:
+			checked = true
+			in.Message, in.RobotComments = "", nil
+		case gerritStatus(err) == http.StatusNotFound:
+			debugf("%v; falling back to comments\n", err)
+		default:
+			return "", err
+		}
+	}
+	// Gerrit refuses votes on outdated patchsets.
+	if kind == "trybot" && current {
+		in.Labels = map[string]int{"TryBot-Result": vote}
+	}
+	if checked && in.Labels == nil {
+		return fmt.Sprintf("reported %s run %s as a check on CL %d patchset %d", kind, run.GetHTMLURL(), cl, patchset), nil
+	}
+	path := "changes/" + id + "/revisions/" + strconv.Itoa(patchset) + "/review"
+	if err := c.gerritDo(http.MethodPost, path, in, nil); err != nil {
+		return "", fmt.Errorf("failed to report on CL %d patchset %d: %w", cl, patchset, err)
+	}
+	if in.Labels != nil {
+		return fmt.Sprintf("voted TryBot-Result%+d on CL %d patchset %d for %s", vote, cl, patchset, run.GetHTMLURL()), nil
+	}
+	return fmt.Sprintf("reported %s run %s on CL %d patchset %d", kind, run.GetHTMLURL(), cl, patchset), nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+)
+
+func TestParseRunTitle(t *testing.T) {
+	testCases := []struct {
+		title    string
+		kind     string
+		cl       int
+		patchset int
+	}{
+		{trybotRunTitle("refs/changes/45/12345/6"), "trybot", 12345, 6},
+		{"unity run for refs/changes/01/1001/12", "unity", 1001, 12},
+		{"unity run for versions \"v0.6.0\"", "", 0, 0},
+		{"compat run for refs/changes/45/12345/6", "", 0, 0},
+		{"Push on master", "", 0, 0},
+	}
+	for _, tc := range testCases {
+		kind, cl, patchset, ok := parseRunTitle(tc.title)
+		if ok != (tc.kind != "") || kind != tc.kind || cl != tc.cl || patchset != tc.patchset {
+			t.Errorf("parseRunTitle(%q) = %q, %d, %d, %v; want %q, %d, %d", tc.title, kind, cl, patchset, ok, tc.kind, tc.cl, tc.patchset)
+		}
+	}
+}
+
+func TestRunTarget(t *testing.T) {
+	c := &config{githubOwner: "cue-lang", githubRepo: "cue", unityOwner: "cue-unity", unityRepo: "unity"}
+	trailer := "cmd/cue: fix things\n\nChange-Id: I1234\n" +
+		`Dispatch-Trailer: {"type":"trybot","CL":12345,"patchset":6,"targetBranch":"master","ref":"refs/changes/45/12345/6"}` + "\n"
+	testCases := []struct {
+		name     string
+		event    string
+		repo     string
+		title    string
+		message  string
+		kind     string
+		cl       int
+		patchset int
+	}{
+		{"trybot", "push", "cue-lang/cue-trybot", "cmd/cue: fix things", trailer, "trybot", 12345, 6},
+		{"unity", "repository_dispatch", "cue-unity/unity", "unity run for refs/changes/45/12345/6", "", "unity", 12345, 6},
+		{"dispatch relay", "repository_dispatch", "cue-lang/cue", "trybot run for refs/changes/45/12345/6", "", "", 0, 0},
+		{"push without trailer", "push", "cue-lang/cue-trybot", "cmd/cue: fix things", "cmd/cue: fix things\n", "", 0, 0},
+		{"push to the repository", "push", "cue-lang/cue", "cmd/cue: fix things", trailer, "", 0, 0},
+		{"pull request", "pull_request", "cue-lang/cue-trybot", "cmd/cue: fix things", trailer, "", 0, 0},
+		{"other repository", "push", "cue-lang/cuelang.org-trybot", "cmd/cue: fix things", trailer, "", 0, 0},
+		{"unity pull request", "pull_request", "cue-unity/unity", "unity run for refs/changes/45/12345/6", "", "", 0, 0},
+	}
+	for _, tc := range testCases {
+		owner, repo, _ := strings.Cut(tc.repo, "/")
+		ev := &github.WorkflowRunEvent{
+			WorkflowRun: &github.WorkflowRun{
+				Event:        github.String(tc.event),
+				DisplayTitle: github.String(tc.title),
+				HeadCommit:   &github.HeadCommit{Message: github.String(tc.message)},
+			},
+			Repo: &github.Repository{
+				Owner: &github.User{Login: github.String(owner)},
+				Name:  github.String(repo),
+			},
+		}
+		kind, cl, patchset, ok := c.runTarget(ev)
+		if ok != (tc.kind != "") || kind != tc.kind || cl != tc.cl || patchset != tc.patchset {
+			t.Errorf("%s: runTarget() = %q, %d, %d, %v; want %q, %d, %d", tc.name, kind, cl, patchset, ok, tc.kind, tc.cl, tc.patchset)
+		}
+	}
+}
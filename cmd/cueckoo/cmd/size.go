@@ -0,0 +1,305 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagSizeApply flagName = "apply"
+	flagSizeSince flagName = "since"
+
+	// sizeHashtagPrefix prefixes the hashtags set by size, such as "size/M".
+	sizeHashtagPrefix = "size/"
+
+	// sizeTag is the Gerrit message tag of the comments posted by size on
+	// CLs exceeding the review-size guidance.
+	sizeTag = "autogenerated:cueckoo-size"
+
+	// sizeGuidanceLines and sizeGuidancePackages are the review-size
+	// guidance: CLs changing more lines, or touching more packages, should
+	// be split into smaller ones.
+	sizeGuidanceLines    = 500
+	sizeGuidancePackages = 5
+
+	// sizeLabelInterval is how often serve labels the CLs updated since.
+	sizeLabelInterval = 5 * time.Minute
+)
+
+// sizeClasses are the size classes of CLs by number of changed lines, each
+// being the class of CLs changing up to that many lines, in increasing order.
+var sizeClasses = []struct {
+	name  string
+	lines int
+}{
+	{"S", 50},
+	{"M", 200},
+	{"L", sizeGuidanceLines},
+}
+
+// newSizeCmd creates a new size command
+func newSizeCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "size",
+		Short: "label CLs by size and warn about CLs which are too large",
+		Long: `
+Usage of size:
+
+	size [--apply] [--since DURATION] [CL...]
+
+size computes the size of each of the given CLs, or else of the open CLs
+updated within --since, 24 hours by default: the number of lines changed, the
+number of files changed, and the number of packages, or directories, touched.
+Each CL is classed by the number of lines changed:
+
+	S   up to 50 lines
+	M   up to 200 lines
+	L   up to 500 lines
+	XL  more than 500 lines
+
+By default, the sizes are only printed. With --apply, the class is set as a
+hashtag of the form "size/M", replacing any previous one, and a comment is
+posted on CLs which exceed the review-size guidance of 500 lines or 5
+packages, suggesting that they be split before review starts. The comment is
+only posted once per CL.
+
+serve --size-labels does the same periodically for updated CLs.
+`,
+		RunE:              mkRunE(c, sizeDef),
+		ValidArgsFunction: completeChanges(0),
+	}
+	flags := c.registerFlags(cmd)
+	flags.Bool(flagSizeApply, false, "set the size hashtags and post comments")
+	flags.String(flagSizeSince, "24h", "how far back to look for updated CLs when none are given")
+	return cmd
+}
+
+// sizedChange is the subset of Gerrit's ChangeInfo entity used by size.
+type sizedChange struct {
+	Number   int      `json:"_number"`
+	Subject  string   `json:"subject"`
+	Hashtags []string `json:"hashtags"`
+	Messages []struct {
+		Tag string `json:"tag"`
+	} `json:"messages"`
+}
+
+// fileDiffStat is the subset of Gerrit's FileInfo entity used by size.
+type fileDiffStat struct {
+	LinesInserted int `json:"lines_inserted"`
+	LinesDeleted  int `json:"lines_deleted"`
+}
+
+// changeSize holds the size metrics of a CL.
+type changeSize struct {
+	Lines    int
+	Files    int
+	Packages int
+}
+
+// measureFiles returns the size of a CL given its modified files, as listed
+// by Gerrit. Gerrit's special files, such as /COMMIT_MSG, are not counted.
+func measureFiles(files map[string]fileDiffStat) changeSize {
+	var s changeSize
+	dirs := make(map[string]bool)
+	for name, f := range files {
+		if strings.HasPrefix(name, "/") {
+			continue
+		}
+		s.Lines += f.LinesInserted + f.LinesDeleted
+		s.Files++
+		dirs[path.Dir(name)] = true
+	}
+	s.Packages = len(dirs)
+	return s
+}
+
+// class returns the size class of s, such as "M".
+func (s changeSize) class() string {
+	for _, c := range sizeClasses {
+		if s.Lines <= c.lines {
+			return c.name
+		}
+	}
+	return "XL"
+}
+
+// overGuidance returns a description of how s exceeds the review-size
+// guidance, or the empty string if it does not.
+func (s changeSize) overGuidance() string {
+	var over []string
+	if s.Lines > sizeGuidanceLines {
+		over = append(over, fmt.Sprintf("changes %d lines, more than %d", s.Lines, sizeGuidanceLines))
+	}
+	if s.Packages > sizeGuidancePackages {
+		over = append(over, fmt.Sprintf("touches %d packages, more than %d", s.Packages, sizeGuidancePackages))
+	}
+	return strings.Join(over, " and ")
+}
+
+func sizeDef(cmd *Command, args []string) error {
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	var changes []sizedChange
+	if len(args) == 0 {
+		since, err := parseDuration(flagSizeSince.String(cmd))
+		if err != nil {
+			return err
+		}
+		if changes, err = cfg.updatedChanges(time.Now().Add(-since)); err != nil {
+			return err
+		}
+	}
+	for _, arg := range args {
+		id, err := cfg.resolveChangeID(arg)
+		if err != nil {
+			return err
+		}
+		var ch sizedChange
+		if err := cfg.gerritDo(http.MethodGet, "changes/"+id+"?o=MESSAGES", nil, &ch); err != nil {
+			return fmt.Errorf("failed to get change %s: %w", arg, err)
+		}
+		changes = append(changes, ch)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Number < changes[j].Number })
+
+	apply := flagSizeApply.Bool(cmd)
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "CL\tSIZE\tLINES\tFILES\tPACKAGES\tSUBJECT")
+	for _, ch := range changes {
+		size, err := cfg.changeSize(ch.Number)
+		if err != nil {
+			return err
+		}
+		if apply {
+			if err := cfg.labelSize(ctx, ch, size); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%s\n", ch.Number, size.class(), size.Lines, size.Files, size.Packages, ch.Subject)
+	}
+	return tw.Flush()
+}
+
+// updatedChanges returns the open CLs of the project updated since t.
+func (c *config) updatedChanges(t time.Time) ([]sizedChange, error) {
+	query := fmt.Sprintf("project:%s status:open after:%q", c.gerritProject(), gerritTime(t))
+	var changes []sizedChange
+	if err := c.gerritDo(http.MethodGet, "changes/?q="+url.QueryEscape(query)+"&o=MESSAGES", nil, &changes); err != nil {
+		return nil, fmt.Errorf("failed to query updated CLs: %w", err)
+	}
+	return changes, nil
+}
+
+// changeSize returns the size of the current patchset of CL cl.
+func (c *config) changeSize(cl int) (changeSize, error) {
+	var files map[string]fileDiffStat
+	if err := c.gerritDo(http.MethodGet, "changes/"+strconv.Itoa(cl)+"/revisions/current/files", nil, &files); err != nil {
+		return changeSize{}, fmt.Errorf("failed to list files of CL %d: %w", cl, err)
+	}
+	return measureFiles(files), nil
+}
+
+// labelSize sets the size hashtag of ch, replacing any stale one, and
+// comments on ch if it exceeds the review-size guidance and has not been
+// commented on for that before.
+func (c *config) labelSize(ctx context.Context, ch sizedChange, size changeSize) error {
+	id := strconv.Itoa(ch.Number)
+	want := sizeHashtagPrefix + size.class()
+	in := map[string][]string{}
+	for _, h := range ch.Hashtags {
+		if strings.HasPrefix(h, sizeHashtagPrefix) && h != want {
+			in["remove"] = append(in["remove"], h)
+		}
+	}
+	if !slicesContains(ch.Hashtags, want) {
+		in["add"] = []string{want}
+	}
+	if len(in) > 0 {
+		if err := c.gerritDo(http.MethodPost, "changes/"+id+"/hashtags", in, nil); err != nil {
+			return fmt.Errorf("failed to set hashtags on CL %d: %w", ch.Number, err)
+		}
+	}
+
+	over := size.overGuidance()
+	if over == "" {
+		return nil
+	}
+	for _, m := range ch.Messages {
+		if m.Tag == sizeTag {
+			return nil
+		}
+	}
+	msg := fmt.Sprintf("This CL %s, which exceeds our review-size guidance. "+
+		"Please consider splitting it into smaller CLs before review starts, "+
+		"as they are easier to review well and quicker to get merged.", over)
+	review := reviewInput{Message: msg, Tag: sizeTag}
+	if err := c.gerritDo(http.MethodPost, "changes/"+id+"/revisions/current/review", review, nil); err != nil {
+		return fmt.Errorf("failed to comment on CL %d: %w", ch.Number, err)
+	}
+	return nil
+}
+
+// runSizeLabels labels the CLs updated since the previous round by size
+// every sizeLabelInterval until ctx is done, as size --apply does.
+func (c *config) runSizeLabels(ctx context.Context, logf func(format string, args ...any)) {
+	logf("labelling CLs by size")
+	since := time.Now().Add(-sizeLabelInterval)
+	for {
+		start := time.Now()
+		if err := c.labelUpdatedChanges(ctx, since); err != nil {
+			logf("size labels: %v", err)
+		} else {
+			since = start
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sizeLabelInterval):
+		}
+	}
+}
+
+// labelUpdatedChanges labels the CLs updated since t by size.
+func (c *config) labelUpdatedChanges(ctx context.Context, t time.Time) error {
+	changes, err := c.updatedChanges(t)
+	if err != nil {
+		return err
+	}
+	for _, ch := range changes {
+		size, err := c.changeSize(ch.Number)
+		if err != nil {
+			return err
+		}
+		if err := c.labelSize(ctx, ch, size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "testing"
+
+func TestMeasureFiles(t *testing.T) {
+	files := map[string]fileDiffStat{
+		"/COMMIT_MSG":          {LinesInserted: 12},
+		"go.mod":               {LinesInserted: 1, LinesDeleted: 1},
+		"cmd/cue/cmd/root.go":  {LinesInserted: 30, LinesDeleted: 10},
+		"cmd/cue/cmd/fmt.go":   {LinesInserted: 5},
+		"internal/core/adt.go": {LinesDeleted: 20},
+	}
+	got := measureFiles(files)
+	want := changeSize{Lines: 67, Files: 4, Packages: 3}
+	if got != want {
+		t.Fatalf("got %+v; want %+v", got, want)
+	}
+	if c := got.class(); c != "M" {
+		t.Errorf("got class %s; want M", c)
+	}
+	if over := got.overGuidance(); over != "" {
+		t.Errorf("got over guidance %q; want none", over)
+	}
+}
+
+func TestChangeSizeClass(t *testing.T) {
+	cases := []struct {
+		size  changeSize
+		class string
+		over  string
+	}{
+		{changeSize{Lines: 0}, "S", ""},
+		{changeSize{Lines: 50}, "S", ""},
+		{changeSize{Lines: 51}, "M", ""},
+		{changeSize{Lines: 500, Packages: 5}, "L", ""},
+		{changeSize{Lines: 501}, "XL", "changes 501 lines, more than 500"},
+		{changeSize{Lines: 10, Packages: 8}, "S", "touches 8 packages, more than 5"},
+		{changeSize{Lines: 900, Packages: 6}, "XL", "changes 900 lines, more than 500 and touches 6 packages, more than 5"},
+	}
+	for _, c := range cases {
+		if got := c.size.class(); got != c.class {
+			t.Errorf("%+v: got class %s; want %s", c.size, got, c.class)
+		}
+		if got := c.size.overGuidance(); got != c.over {
+			t.Errorf("%+v: got over guidance %q; want %q", c.size, got, c.over)
+		}
+	}
+}
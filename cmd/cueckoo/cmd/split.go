@@ -0,0 +1,174 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/trailers"
+	"github.com/spf13/cobra"
+)
+
+// newSplitCmd creates a new split command
+func newSplitCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split",
+		Short: "interactively split the HEAD commit into multiple commits",
+		Long: `
+Usage of split:
+
+	split
+
+split splits the pending commit at HEAD into multiple commits, for when review
+asks for a large CL to be split up.
+
+The changes of the commit are taken out of it, and git add -p is run to select
+the hunks for the first commit. Its commit message is then edited, starting
+from the original message. This repeats until no changes remain. Files added
+by the original commit are offered to git add -p as well.
+
+The first commit keeps the original Change-Id, so that it continues the
+existing CL, while each of the others is given a fresh Change-Id. Mail the
+resulting commits with git codereview mail as usual.
+
+If anything goes wrong, the original commit can be restored with the
+git reset --hard command that split prints at the start.
+`,
+		RunE: mkRunE(c, splitDef),
+	}
+	return cmd
+}
+
+func splitDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("split does not take any arguments")
+	}
+	ctx := cmd.Context()
+	if !isTerminal(os.Stdin) {
+		return fmt.Errorf("split is interactive and must be run from a terminal")
+	}
+	bp, err := run(ctx, "git", "codereview", "branchpoint")
+	if err != nil {
+		return err
+	}
+	head, err := run(ctx, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	head = strings.TrimSpace(head)
+	if head == strings.TrimSpace(bp) {
+		return fmt.Errorf("no pending commit to split")
+	}
+	if status, err := run(ctx, "git", "status", "--porcelain", "--untracked-files=no"); err != nil {
+		return err
+	} else if status != "" {
+		return fmt.Errorf("the working tree has uncommitted changes; commit or stash them first")
+	}
+	msg, err := run(ctx, "git", "log", "-1", "--format=%B", "HEAD")
+	if err != nil {
+		return err
+	}
+	added, err := run(ctx, "git", "diff", "--name-only", "-z", "--diff-filter=A", "HEAD^", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "splitting %s; to restore it, run: git reset --hard %s\n", head[:12], head)
+	if _, err := run(ctx, "git", "reset", "--mixed", "HEAD^"); err != nil {
+		return err
+	}
+	if added := strings.Split(strings.TrimRight(added, "\x00"), "\x00"); added[0] != "" {
+		// Make the added files visible to git add -p.
+		if _, err := run(ctx, "git", append([]string{"add", "--intent-to-add", "--"}, added...)...); err != nil {
+			return err
+		}
+	}
+
+	body, ts := trailers.Split(msg)
+	for n := 1; ; n++ {
+		fmt.Fprintf(os.Stderr, "\nselect the changes for commit %d\n", n)
+		if err := runInteractive(ctx, "git", "add", "-p"); err != nil {
+			return err
+		}
+		if _, err := run(ctx, "git", "diff", "--cached", "--quiet"); err == nil {
+			if !confirm("nothing selected; put all remaining changes in this commit?") {
+				continue
+			}
+			if _, err := run(ctx, "git", "add", "--all"); err != nil {
+				return err
+			}
+		}
+
+		commitTrailers := ts
+		if n > 1 {
+			id, err := newChangeID()
+			if err != nil {
+				return err
+			}
+			commitTrailers = nil
+			for _, t := range ts {
+				if strings.EqualFold(t.Key, "Change-Id") {
+					t.Value = id
+				}
+				commitTrailers = append(commitTrailers, t)
+			}
+			if len(trailers.Get(ts, "Change-Id")) == 0 {
+				commitTrailers = append(commitTrailers, trailers.Trailer{Key: "Change-Id", Value: id})
+			}
+		}
+		f, err := os.CreateTemp("", "cueckoo-split-*.txt")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.WriteString(trailers.Format(body, commitTrailers)); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		if err := runInteractive(ctx, "git", "commit", "--edit", "--file", f.Name()); err != nil {
+			return fmt.Errorf("failed to commit part %d: %v", n, err)
+		}
+
+		// Are there any changes left, including files which were added
+		// with --intent-to-add?
+		if _, err := run(ctx, "git", "diff", "--quiet", "HEAD"); err == nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "split %s into %d commits\n", head[:12], n)
+			return nil
+		}
+	}
+}
+
+// runInteractive runs the named command attached to the standard input and
+// output of cueckoo, for commands which interact with the user.
+func runInteractive(ctx context.Context, name string, args ...string) error {
+	if err := checkReadOnlyCommand(name, args...); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %q: %v", cmd.Args, err)
+	}
+	return nil
+}
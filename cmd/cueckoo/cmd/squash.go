@@ -0,0 +1,198 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/trailers"
+	"github.com/spf13/cobra"
+)
+
+const flagSquashNoMail flagName = "no-mail"
+
+// issueRefRegex matches lines referring to issues which are combined when
+// merging commit messages, such as "Fixes #123." or "Updates cue-lang/cue#1".
+var issueRefRegex = regexp.MustCompile(`^(Fixes|Updates|Closes|For) ([\w.-]+/[\w.-]+)?#\d+\.?$`)
+
+// newSquashCmd creates a new squash command
+func newSquashCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "squash",
+		Short: "squash pending commits into a single CL",
+		Long: `
+Usage of squash:
+
+	squash [--no-mail] [BASE | BASE..HEAD]
+
+squash squashes the pending commits after BASE into a single commit, and then
+mails the result with git codereview mail, for when review asks for a stack of
+CLs to be consolidated. BASE defaults to the branchpoint, such that all the
+pending commits in the current branch are squashed.
+
+The commit messages are merged: the subject of the oldest commit is kept, the
+bodies of all the commits follow in order without repeated paragraphs, and
+lines referring to issues, such as "Fixes #123.", are combined at the end of
+the body. Trailers are deduplicated, and only the Change-Id of the oldest
+commit is kept, so the squashed commit is mailed as a new patchset of that
+CL. The CLs of the other commits should then be abandoned.
+`,
+		RunE: mkRunE(c, squashDef),
+	}
+	c.registerFlags(cmd).Bool(flagSquashNoMail, false, "do not mail the squashed commit")
+	return cmd
+}
+
+func squashDef(cmd *Command, args []string) error {
+	ctx := cmd.Context()
+	if len(args) > 1 {
+		return fmt.Errorf("expected at most one commit range")
+	}
+	bp, err := run(ctx, "git", "codereview", "branchpoint")
+	if err != nil {
+		return err
+	}
+	bp = strings.TrimSpace(bp)
+	base := bp
+	if len(args) == 1 {
+		var tip string
+		base, tip, _ = strings.Cut(args[0], "..")
+		if tip != "" && tip != "HEAD" {
+			return fmt.Errorf("the range to squash must end at HEAD")
+		}
+	}
+	if _, err := run(ctx, "git", "merge-base", "--is-ancestor", bp, base); err != nil {
+		return fmt.Errorf("%s is not a pending commit", base)
+	}
+	if status, err := run(ctx, "git", "status", "--porcelain", "--untracked-files=no"); err != nil {
+		return err
+	} else if status != "" {
+		return fmt.Errorf("the working tree has uncommitted changes; commit or stash them first")
+	}
+
+	commits, err := resolveCommits(ctx, "--reverse", base+"..HEAD")
+	if err != nil {
+		return err
+	}
+	if len(commits) < 2 {
+		return fmt.Errorf("need at least two commits to squash, found %d", len(commits))
+	}
+	var msgs []string
+	for _, c := range commits {
+		msgs = append(msgs, c.body)
+	}
+	msg := mergeCommitMessages(msgs)
+	author, err := run(ctx, "git", "log", "-1", "--format=%an <%ae>", commits[0].hash)
+	if err != nil {
+		return err
+	}
+
+	if _, err := run(ctx, "git", "reset", "--soft", base); err != nil {
+		return err
+	}
+	commitCmd := exec.CommandContext(ctx, "git", "commit", "--no-verify", "--author", strings.TrimSpace(author), "-F", "-")
+	commitCmd.Stdin = strings.NewReader(msg)
+	if out, err := commitCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit squashed changes: %v:\n%s\nthe previous HEAD was %s", err, out, commits[len(commits)-1].hash)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "squashed %d commits\n", len(commits))
+
+	if flagSquashNoMail.Bool(cmd) {
+		return nil
+	}
+	return mail(ctx)
+}
+
+// mail runs git codereview mail for HEAD.
+func mail(ctx context.Context) error {
+	out, err := run(ctx, "git", "codereview", "mail", "HEAD")
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// mergeCommitMessages merges the commit messages msgs, oldest first, into a
+// single commit message as described in the squash help text.
+func mergeCommitMessages(msgs []string) string {
+	var (
+		subject    string
+		paragraphs []string
+		refs       []string
+		all        []trailers.Trailer
+	)
+	seenPara := make(map[string]bool)
+	seenRef := make(map[string]bool)
+	for i, msg := range msgs {
+		body, ts := trailers.Split(msg)
+		s, rest, _ := strings.Cut(body, "\n")
+		if i == 0 {
+			subject = s
+		} else if !strings.HasPrefix(s, "fixup! ") && !strings.HasPrefix(s, "squash! ") && s != subject {
+			// Keep the subjects of later commits as part of the body, as
+			// they often describe what the commit added.
+			rest = s + "\n\n" + rest
+		}
+		for _, p := range strings.Split(strings.TrimSpace(rest), "\n\n") {
+			var lines []string
+			for _, l := range strings.Split(p, "\n") {
+				if issueRefRegex.MatchString(strings.TrimSpace(l)) {
+					ref := strings.TrimSuffix(strings.TrimSpace(l), ".") + "."
+					if !seenRef[ref] {
+						seenRef[ref] = true
+						refs = append(refs, ref)
+					}
+					continue
+				}
+				lines = append(lines, l)
+			}
+			p = strings.TrimSpace(strings.Join(lines, "\n"))
+			if p == "" || seenPara[p] {
+				continue
+			}
+			seenPara[p] = true
+			paragraphs = append(paragraphs, p)
+		}
+		all = append(all, ts...)
+	}
+
+	// Only keep the first Change-Id, so that the result is a new patchset of
+	// the oldest commit's CL.
+	var merged []trailers.Trailer
+	haveChangeID := false
+	for _, t := range trailers.Dedupe(all) {
+		if strings.EqualFold(t.Key, "Change-Id") {
+			if haveChangeID {
+				continue
+			}
+			haveChangeID = true
+		}
+		merged = append(merged, t)
+	}
+
+	body := subject
+	for _, p := range paragraphs {
+		body += "\n\n" + p
+	}
+	if len(refs) > 0 {
+		body += "\n\n" + strings.Join(refs, "\n")
+	}
+	return trailers.Format(body, merged)
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeCommitMessages(t *testing.T) {
+	msgs := []string{
+		`cue/load: support foo
+
+This adds support for foo.
+
+Fixes #12.
+
+Signed-off-by: Paul <p@example.com>
+Change-Id: I1111111111111111111111111111111111111111
+`,
+		`cue/load: test foo
+
+This adds support for foo.
+
+Fixes #12.
+Updates #34.
+
+Signed-off-by: Paul <p@example.com>
+Change-Id: I2222222222222222222222222222222222222222
+`,
+		`fixup! cue/load: support foo
+
+Signed-off-by: Marcel <m@example.com>
+Change-Id: I3333333333333333333333333333333333333333
+`,
+	}
+	want := `cue/load: support foo
+
+This adds support for foo.
+
+cue/load: test foo
+
+Fixes #12.
+Updates #34.
+
+Signed-off-by: Paul <p@example.com>
+Change-Id: I1111111111111111111111111111111111111111
+Signed-off-by: Marcel <m@example.com>
+`
+	got := mergeCommitMessages(msgs)
+	if got != want {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+// newStatusCmd creates a new status command
+func newStatusCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "show the review and CI state of the pending CLs",
+		Long: `
+Usage of status:
+
+	status [--ref REF]
+
+status shows a line for each pending commit of the current branch, or of REF,
+derived in the same way as runtrybot with HEAD as its argument. Each line
+gives:
+
+	CL             the number of the CL
+	PATCHSET       the patchset of the commit, with the current patchset if
+	               it is not the latest, or "unmailed" if it was not mailed
+	CODE-REVIEW    the Code-Review vote, the lowest if any is negative
+	TRYBOT-RESULT  the TryBot-Result vote, likewise
+	TRYBOT         the state of the trybot workflow run for the patchset
+	UNITY          the state of the unity workflow run, if unity is configured
+
+The state of a workflow run is its conclusion once completed, such as
+"success" or "failure", its status while queued or in progress, or "none" if
+no run was dispatched for the patchset. The trybot run is the one in the
+trybot repository, to which the dispatch workflow relays trybot dispatches.
+Until it starts, the state is "queued" while the dispatch is relayed, or the
+conclusion of the dispatch run, such as "dispatch failure", if it failed.
+`,
+		RunE: mkRunE(c, statusDef),
+	}
+	c.registerFlags(cmd).String(flagRunTrybotRef, "", "derive pending commits from this ref rather than HEAD")
+	cmd.RegisterFlagCompletionFunc(string(flagRunTrybotRef), completeBranches)
+	return cmd
+}
+
+func statusDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("status does not take any arguments")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	revs, err := newCLTrigger(cmd, cfg, nil).deriveChangeIDs([]string{"HEAD"})
+	if err != nil {
+		return err
+	}
+
+	tw := newTableWriter(cmd.OutOrStdout())
+	header := "CL\tPATCHSET\tCODE-REVIEW\tTRYBOT-RESULT\tTRYBOT"
+	if cfg.unityRepo != "" {
+		header += "\tUNITY"
+	}
+	fmt.Fprintln(tw, header)
+	ids := make([]string, len(revs))
+	for i, rev := range revs {
+		ids[i] = rev.changeID
+	}
+	changes, errs := cfg.getChanges(ids, "ALL_REVISIONS", "LABELS")
+	for i, rev := range revs {
+		if errs != nil && errs[i] != nil {
+			return fmt.Errorf("failed to get %s: %v", rev.short(), errs[i])
+		}
+		in := changes[i]
+		line := fmt.Sprintf("%d\t%s\t%s\t%s", in.Number,
+			patchsetState(in, rev.revision),
+			orNone(labelVote(in.Labels["Code-Review"])),
+			orNone(labelVote(in.Labels["TryBot-Result"])),
+		)
+		revision, mailed := in.Revisions[rev.revision]
+		trybot := "none"
+		if mailed {
+			trybot, err = cfg.trybotState(ctx, in.Number, revision.Number, revision.Ref)
+			if err != nil {
+				return err
+			}
+		}
+		line += "\t" + trybot
+		if cfg.unityRepo != "" {
+			unity := "none"
+			if mailed {
+				run, err := cfg.findDispatchedRun(ctx, cfg.unityOwner, cfg.unityRepo, unityRunTitle(revision.Ref))
+				if err != nil {
+					return err
+				}
+				unity = workflowRunState(run)
+			}
+			line += "\t" + unity
+		}
+		fmt.Fprintln(tw, line)
+	}
+	return tw.Flush()
+}
+
+// trybotState describes the state of the trybot run for patchset of CL, whose
+// ref is given. Until the run in the trybot repository exists, it describes
+// the run of the dispatch which relays it there.
+func (c *config) trybotState(ctx context.Context, cl, patchset int, ref string) (string, error) {
+	run, err := c.findTrybotRun(ctx, cl, patchset)
+	if err != nil || run != nil {
+		return workflowRunState(run), err
+	}
+	relay, err := c.findDispatchedRun(ctx, c.githubOwner, c.githubRepo, trybotRunTitle(ref))
+	switch {
+	case err != nil:
+		return "", err
+	case relay == nil:
+		return "none", nil
+	case relay.GetStatus() == "completed" && relay.GetConclusion() != "success":
+		return "dispatch " + relay.GetConclusion(), nil
+	}
+	return "queued", nil
+}
+
+// patchsetState describes the patchset of ch which is the commit rev.
+func patchsetState(ch *gerrit.ChangeInfo, rev string) string {
+	revision, ok := ch.Revisions[rev]
+	if !ok {
+		return "unmailed"
+	}
+	if rev == ch.CurrentRevision {
+		return strconv.Itoa(revision.Number)
+	}
+	return fmt.Sprintf("%d (current %d)", revision.Number, ch.Revisions[ch.CurrentRevision].Number)
+}
+
+// labelVote summarizes the votes on label: the lowest vote if any is
+// negative, as that blocks submission, and otherwise the highest. It returns
+// the empty string if there are no votes.
+func labelVote(label gerrit.LabelInfo) string {
+	vote, voted := 0, false
+	for _, approval := range label.All {
+		if approval.Value == 0 {
+			continue
+		}
+		switch {
+		case !voted,
+			approval.Value < 0 && approval.Value < vote,
+			vote > 0 && approval.Value > vote:
+			vote = approval.Value
+		}
+		voted = true
+	}
+	if !voted {
+		return ""
+	}
+	return fmt.Sprintf("%+d", vote)
+}
+
+// workflowRunState describes the state of run, which may be nil.
+func workflowRunState(run *github.WorkflowRun) string {
+	switch {
+	case run == nil:
+		return "none"
+	case run.GetStatus() == "completed":
+		return run.GetConclusion()
+	default:
+		return run.GetStatus()
+	}
+}
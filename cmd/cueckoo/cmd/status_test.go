@@ -0,0 +1,63 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-github/v53/github"
+)
+
+func TestLabelVote(t *testing.T) {
+	label := func(values ...int) gerrit.LabelInfo {
+		var l gerrit.LabelInfo
+		for _, v := range values {
+			l.All = append(l.All, gerrit.ApprovalInfo{Value: v})
+		}
+		return l
+	}
+	cases := []struct {
+		label gerrit.LabelInfo
+		want  string
+	}{
+		{label(), ""},
+		{label(0, 0), ""},
+		{label(1, 2, 0), "+2"},
+		{label(2, -1, 1), "-1"},
+		{label(-1, -2, 2), "-2"},
+	}
+	for _, c := range cases {
+		if got := labelVote(c.label); got != c.want {
+			t.Errorf("labelVote(%v) = %q; want %q", c.label.All, got, c.want)
+		}
+	}
+}
+
+func TestWorkflowRunState(t *testing.T) {
+	cases := []struct {
+		run  *github.WorkflowRun
+		want string
+	}{
+		{nil, "none"},
+		{&github.WorkflowRun{Status: github.String("queued")}, "queued"},
+		{&github.WorkflowRun{Status: github.String("completed"), Conclusion: github.String("failure")}, "failure"},
+	}
+	for _, c := range cases {
+		if got := workflowRunState(c.run); got != c.want {
+			t.Errorf("got %q; want %q", got, c.want)
+		}
+	}
+}
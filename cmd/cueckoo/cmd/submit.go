@@ -0,0 +1,278 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagSubmitTopic      flagName = "topic"
+	flagSubmitSequential flagName = "sequential"
+	flagSubmitRetries    flagName = "retries"
+)
+
+// newSubmitCmd creates a new submit command
+func newSubmitCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "submit",
+		Short: "submit all the CLs in a Gerrit topic",
+		Long: `
+Usage of submit:
+
+	submit --topic TOPIC [--sequential] [--retries N]
+
+submit checks that every open CL in the given Gerrit topic is submittable and
+then submits them, reporting the outcome for each CL.
+
+If the Gerrit server submits whole topics at once, a single submit request is
+made for the topic. Otherwise, or if the --sequential flag is provided, the
+CLs are submitted one at a time, ordered such that each CL is submitted after
+any CLs in the topic that it depends on.
+
+A submit that fails transiently, such as on a lock failure or when the CL needs
+rebasing because another change was merged at the same time, is retried up to
+--retries times. A CL which is not submittable or has merge conflicts fails
+straight away.
+`,
+		RunE: mkRunE(c, submitDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagSubmitTopic, "", "the Gerrit topic to submit")
+	flags.Bool(flagSubmitSequential, false, "submit the CLs one at a time even if the server supports submitting whole topics")
+	flags.Int(flagSubmitRetries, 3, "number of times to retry a submit which failed transiently")
+	flags.Bool(flagOverrideFreeze, false, "proceed even during a freeze window of the repository")
+	return cmd
+}
+
+func submitDef(cmd *Command, args []string) error {
+	topic := flagSubmitTopic.String(cmd)
+	if topic == "" {
+		return fmt.Errorf("the --topic flag is required")
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("submit does not take any arguments")
+	}
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkFreeze(cmd); err != nil {
+		return err
+	}
+
+	changes, err := cfg.topicChanges(topic)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return fmt.Errorf("no open CLs found in topic %q", topic)
+	}
+
+	// Refuse to submit anything unless everything can be submitted; a
+	// partially submitted topic is worse than an unsubmitted one.
+	var problems []string
+	for _, ch := range changes {
+		if !ch.Submittable {
+			problems = append(problems, fmt.Sprintf("CL %d (%s) is not submittable", ch.Number, ch.Subject))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("cannot submit topic %q:\n\t%s", topic, strings.Join(problems, "\n\t"))
+	}
+
+	info, err := cfg.serverInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get Gerrit server info: %w", err)
+	}
+	retries := flagSubmitRetries.Int(cmd)
+
+	results := make([]submitResult, len(changes))
+	for i, ch := range changes {
+		results[i].change = ch
+	}
+	if info.Change.SubmitWholeTopic && !flagSubmitSequential.Bool(cmd) {
+		// Submitting any change in the topic submits all of them. Use the
+		// last one, which depends on the others.
+		last := changes[len(changes)-1]
+		err := cfg.submitWithRetry(last.ID, retries)
+		for i := range results {
+			results[i].err = err
+		}
+		if err == nil {
+			// Check what actually happened to each CL.
+			merged, err := cfg.topicStatus(topic)
+			if err != nil {
+				return err
+			}
+			for i := range results {
+				if status := merged[results[i].change.Number]; status != "MERGED" {
+					results[i].err = fmt.Errorf("CL has status %s after submitting topic", status)
+				}
+			}
+		}
+	} else {
+		for i := range results {
+			if err := cfg.submitWithRetry(results[i].change.ID, retries); err != nil {
+				results[i].err = err
+				// Later CLs most likely depend on this one; stop here.
+				for j := i + 1; j < len(results); j++ {
+					results[j].err = fmt.Errorf("not attempted")
+				}
+				break
+			}
+		}
+	}
+	return printSubmitResults(cmd, results)
+}
+
+type submitResult struct {
+	change gerrit.ChangeInfo
+	err    error
+}
+
+func printSubmitResults(cmd *Command, results []submitResult) error {
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "CL\tSUBJECT\tRESULT\tERROR")
+	failed := 0
+	for _, r := range results {
+		result, msg := "submitted", ""
+		if r.err != nil {
+			failed++
+			result = "failed"
+			msg = firstLine(r.err.Error())
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", r.change.Number, r.change.Subject, result, msg)
+	}
+	tw.Flush()
+	switch {
+	case failed == 0:
+		return nil
+	case failed < len(results):
+		return &partialFailureError{failed: failed, total: len(results)}
+	default:
+		return fmt.Errorf("failed to submit topic")
+	}
+}
+
+// topicChanges returns the open changes in topic, ordered such that each
+// change comes after any other changes in the topic which it depends on.
+func (c *config) topicChanges(topic string) ([]gerrit.ChangeInfo, error) {
+	changes, _, err := c.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s topic:%q status:open", c.gerritProject(), topic)},
+		},
+		ChangeOptions: gerrit.ChangeOptions{
+			AdditionalFields: []string{"CURRENT_REVISION", "CURRENT_COMMIT", "SUBMITTABLE"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query topic %q: %w", topic, err)
+	}
+	return orderByDependencies(*changes), nil
+}
+
+// orderByDependencies sorts changes topologically according to the parents
+// of their current revisions. Changes unrelated to one another keep their
+// relative order.
+func orderByDependencies(changes []gerrit.ChangeInfo) []gerrit.ChangeInfo {
+	byCommit := make(map[string]int)
+	for i, ch := range changes {
+		byCommit[ch.CurrentRevision] = i
+	}
+	var res []gerrit.ChangeInfo
+	visited := make([]bool, len(changes))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		ch := changes[i]
+		for _, p := range ch.Revisions[ch.CurrentRevision].Commit.Parents {
+			if j, ok := byCommit[p.Commit]; ok {
+				visit(j)
+			}
+		}
+		res = append(res, ch)
+	}
+	for i := range changes {
+		visit(i)
+	}
+	return res
+}
+
+// topicStatus returns the status of each change in topic keyed by CL number.
+func (c *config) topicStatus(topic string) (map[int]string, error) {
+	changes, _, err := c.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s topic:%q", c.gerritProject(), topic)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query topic %q: %w", topic, err)
+	}
+	res := make(map[int]string)
+	for _, ch := range *changes {
+		res[ch.Number] = ch.Status
+	}
+	return res, nil
+}
+
+// submitWithRetry submits the change identified by id, retrying up to retries
+// times if the submit failed for a transient reason; see submitRetryable.
+func (c *config) submitWithRetry(id string, retries int) error {
+	for attempt := 0; ; attempt++ {
+		_, resp, err := c.gerritClient.Changes.SubmitChange(id, nil)
+		err = checkGerrit(resp, err)
+		if err == nil {
+			return nil
+		}
+		if !submitRetryable(err) || attempt >= retries {
+			if msg := gerritMessage(err); msg != "" {
+				return fmt.Errorf("%w: %s", err, msg)
+			}
+			return err
+		}
+		debugf("submit of %s failed transiently (%s); retrying (attempt %d of %d)\n", id, gerritMessage(err), attempt+1, retries)
+		time.Sleep(time.Duration(attempt+1) * 2 * time.Second)
+	}
+}
+
+// submitRetryable reports whether a submit which failed with err may succeed
+// if retried. Gerrit reports every failed submit as a conflict, so tell them
+// apart by their message: a lock failure, or a change which needs rebasing
+// after another change was merged at the same time, is transient, whereas a
+// change which is not submittable or has merge conflicts needs a human.
+func submitRetryable(err error) bool {
+	if gerritStatus(err) != http.StatusConflict {
+		return false
+	}
+	msg := strings.ToLower(gerritMessage(err))
+	switch {
+	case strings.Contains(msg, "not submittable"), strings.Contains(msg, "conflict"):
+		return false
+	case strings.Contains(msg, "lock failure"), strings.Contains(msg, "lock_failure"),
+		strings.Contains(msg, "needs rebase"), strings.Contains(msg, "needs to be rebased"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSubmitRetryable(t *testing.T) {
+	conflict := func(msg string) error {
+		return fmt.Errorf("failed to submit: %w", &gerritError{
+			statusCode: http.StatusConflict,
+			message:    msg,
+			err:        fmt.Errorf("409 Conflict"),
+		})
+	}
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{{
+		name: "LockFailure",
+		err:  conflict("Failed to submit 1 change due to the following problems:\nChange 123: update of refs/heads/master failed: LOCK_FAILURE"),
+		want: true,
+	}, {
+		name: "NeedsRebase",
+		err:  conflict("Change 123 needs rebase"),
+		want: true,
+	}, {
+		name: "NotSubmittable",
+		err:  conflict("change 123 is not submittable: submit requirement Code-Review is unsatisfied"),
+	}, {
+		name: "MergeConflict",
+		err:  conflict("Failed to submit 1 change due to the following problems:\nChange 123: Change could not be merged due to a path conflict. Please rebase the change locally and upload the rebased commit for review."),
+	}, {
+		name: "UnknownConflict",
+		err:  conflict("something else"),
+	}, {
+		name: "NotFound",
+		err:  &gerritError{statusCode: http.StatusNotFound, message: "Not found: 123", err: fmt.Errorf("404 Not Found")},
+	}, {
+		name: "Network",
+		err:  fmt.Errorf("connection reset by peer"),
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := submitRetryable(tc.err); got != tc.want {
+				t.Errorf("submitRetryable(%q) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
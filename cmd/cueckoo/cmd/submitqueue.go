@@ -0,0 +1,228 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// submitQueueInterval is how often the submit queue is checked when
+	// things are going well.
+	submitQueueInterval = time.Minute
+
+	// submitQueueMaxBackoff bounds how long the submit queue backs off for
+	// after repeated failures to talk to Gerrit or GitHub.
+	submitQueueMaxBackoff = 15 * time.Minute
+
+	submitQueueTag = "autogenerated:cueckoo-submit-queue"
+)
+
+// submitQueue approximates a merge queue for Gerrit. It works through the
+// open CLs with a hashtag one at a time, oldest first: each is rebased on
+// the tip of its branch, tested by the trybots, and submitted once they pass.
+// A CL which cannot be rebased, fails the trybots or cannot be submitted is
+// removed from the queue with a message saying why.
+//
+// The trybots are judged by their run in the trybot repository for the
+// rebased patchset; see findTrybotRun. Gerrit typically only considers a CL
+// submittable with the TryBot-Result vote, which the run casts as its last
+// step, so a CL whose run passed is given time for the vote to show.
+type submitQueue struct {
+	cfg     *config
+	hashtag string
+	logf    func(format string, args ...any)
+
+	// dispatched records the patchset of each CL for which trybots were
+	// dispatched, so that they are only dispatched once per patchset.
+	dispatched map[int]int
+}
+
+// queuedChange is the subset of Gerrit's ChangeInfo entity used by the
+// submit queue.
+type queuedChange struct {
+	Number          int    `json:"_number"`
+	Branch          string `json:"branch"`
+	Subject         string `json:"subject"`
+	CurrentRevision string `json:"current_revision"`
+	Submittable     bool   `json:"submittable"`
+	Revisions       map[string]struct {
+		Number int    `json:"_number"`
+		Ref    string `json:"ref"`
+	} `json:"revisions"`
+	Labels map[string]struct {
+		All []struct {
+			Value int `json:"value"`
+		} `json:"all"`
+	} `json:"labels"`
+}
+
+// labelVote returns the lowest vote on the label if any are negative, and
+// the highest vote otherwise.
+func (ch *queuedChange) labelVote(label string) int {
+	vote := 0
+	for _, a := range ch.Labels[label].All {
+		switch {
+		case a.Value < 0 && a.Value < vote:
+			vote = a.Value
+		case vote >= 0 && a.Value > vote:
+			vote = a.Value
+		}
+	}
+	return vote
+}
+
+// run works through the queue until ctx is done, backing off when steps
+// fail.
+func (q *submitQueue) run(ctx context.Context) {
+	q.logf("working through CLs with hashtag %q", q.hashtag)
+	delay := submitQueueInterval
+	for {
+		if err := q.step(ctx); err != nil {
+			q.logf("submit queue: %v", err)
+			if delay *= 2; delay > submitQueueMaxBackoff {
+				delay = submitQueueMaxBackoff
+			}
+		} else {
+			delay = submitQueueInterval
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// step moves the CL at the head of the queue along by one step.
+func (q *submitQueue) step(ctx context.Context) error {
+	if w, until, ok, err := q.cfg.activeFreeze(ctx); err != nil {
+		return err
+	} else if ok {
+		q.logf("submit queue paused until %s: %s", until.UTC().Format(time.DateTime), w.reason)
+		return nil
+	}
+	query := fmt.Sprintf("project:%s status:open hashtag:%s", q.cfg.gerritProject(), q.hashtag)
+	var changes []queuedChange
+	path := "changes/?q=" + url.QueryEscape(query) + "&o=CURRENT_REVISION&o=DETAILED_LABELS&o=SUBMITTABLE"
+	if err := q.cfg.gerritDo(http.MethodGet, path, nil, &changes); err != nil {
+		return fmt.Errorf("failed to query the queue: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Number < changes[j].Number })
+	ch := &changes[0]
+	rev := ch.Revisions[ch.CurrentRevision]
+
+	run, err := q.cfg.findTrybotRun(ctx, ch.Number, rev.Number)
+	if err != nil {
+		return err
+	}
+	switch {
+	case run == nil && q.dispatched[ch.Number] == rev.Number,
+		run != nil && run.GetStatus() != "completed":
+		// Waiting for the trybots.
+		return nil
+	case run != nil && run.GetConclusion() != "success":
+		return q.dequeue(ch, fmt.Sprintf("the trybots failed: %s", run.GetHTMLURL()))
+	case run != nil && !ch.Submittable:
+		switch vote := ch.labelVote("TryBot-Result"); {
+		case vote == 0:
+			// Waiting for the vote of the run.
+			return nil
+		case vote < 0:
+			return q.dequeue(ch, "it was voted TryBot-Result"+strconv.Itoa(vote))
+		}
+		return q.dequeue(ch, "it is not submittable, such as for lack of review")
+	case run != nil:
+		err := q.cfg.submitWithRetry(strconv.Itoa(ch.Number), 0)
+		if err == nil {
+			q.logf("submitted CL %d (%s)", ch.Number, ch.Subject)
+			return nil
+		}
+		if gerritStatus(err) != http.StatusConflict {
+			return fmt.Errorf("failed to submit CL %d: %w", ch.Number, err)
+		}
+		// The branch moved on in a conflicting way since the trybots
+		// ran; rebase and test again.
+		q.logf("submitting CL %d conflicted; rebasing", ch.Number)
+	}
+
+	// Test the CL on the tip of its branch.
+	rebased, err := q.rebase(ch)
+	if err != nil {
+		if gerritStatus(err) == http.StatusConflict {
+			return q.dequeue(ch, "it could not be rebased: "+firstLine(gerritMessage(err)))
+		}
+		return err
+	}
+	if rebased != nil {
+		ch = rebased
+		rev = ch.Revisions[ch.CurrentRevision]
+	}
+	p, err := buildTryBotPayload(repositoryDispatchPayload{
+		Type:         string(eventTypeTrybot),
+		CL:           ch.Number,
+		Patchset:     rev.Number,
+		TargetBranch: ch.Branch,
+		Ref:          rev.Ref,
+	})
+	if err != nil {
+		return err
+	}
+	if err := q.cfg.triggerRepositoryDispatch(ctx, q.cfg.githubOwner, q.cfg.githubRepo, p); err != nil {
+		return err
+	}
+	q.dispatched[ch.Number] = rev.Number
+	q.logf("testing CL %d patchset %d", ch.Number, rev.Number)
+	return nil
+}
+
+// rebase rebases ch on the tip of its branch, returning the rebased change,
+// or nil if it was already up to date.
+func (q *submitQueue) rebase(ch *queuedChange) (*queuedChange, error) {
+	var res queuedChange
+	err := q.cfg.gerritDo(http.MethodPost, "changes/"+strconv.Itoa(ch.Number)+"/rebase?o=CURRENT_REVISION", struct{}{}, &res)
+	if err != nil && gerritStatus(err) == http.StatusConflict && strings.Contains(gerritMessage(err), "up to date") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// dequeue removes ch from the queue, telling its owner why.
+func (q *submitQueue) dequeue(ch *queuedChange, why string) error {
+	id := strconv.Itoa(ch.Number)
+	if err := q.cfg.gerritDo(http.MethodPost, "changes/"+id+"/hashtags", map[string][]string{"remove": {q.hashtag}}, nil); err != nil {
+		return fmt.Errorf("failed to remove CL %d from the queue: %w", ch.Number, err)
+	}
+	msg := fmt.Sprintf("Removed from the submit queue as %s. Add the hashtag %q again to re-queue.", why, q.hashtag)
+	if err := q.cfg.gerritDo(http.MethodPost, "changes/"+id+"/revisions/current/review", reviewInput{Message: msg, Tag: submitQueueTag}, nil); err != nil {
+		return fmt.Errorf("failed to comment on CL %d: %w", ch.Number, err)
+	}
+	delete(q.dispatched, ch.Number)
+	q.logf("removed CL %d from the submit queue as %s", ch.Number, why)
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	rtdebug "runtime/debug"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// defaultTelemetryEndpoint is where usage events are posted when
+	// telemetry is on, unless overridden by the telemetry-endpoint key in
+	// the user config.
+	defaultTelemetryEndpoint = "https://telemetry.cuelang.org/cueckoo/v1/events"
+
+	// telemetryTimeout bounds how long reporting may delay the exit of
+	// cueckoo.
+	telemetryTimeout = time.Second
+)
+
+// telemetryEvent is the anonymous usage event reported for each invocation
+// when telemetry is on. It deliberately contains no identifiers: no
+// arguments, repositories, CLs or user names.
+type telemetryEvent struct {
+	Command    string `json:"command"` // e.g. "cueckoo ci cost"
+	Version    string `json:"version"`
+	GOOS       string `json:"goos"`
+	GOARCH     string `json:"goarch"`
+	DurationMS int64  `json:"durationMs"`
+	Result     string `json:"result"` // success, failure or partial
+}
+
+// newTelemetryCmd creates a new telemetry command
+func newTelemetryCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "telemetry",
+		Short: "control anonymous usage reporting",
+		Long: `
+Usage of telemetry:
+
+	telemetry on|off|status
+
+cueckoo can report anonymous usage events to the CUE project, to help the
+maintainers see which commands are actually used when prioritizing work.
+Reporting is off unless turned on with "telemetry on".
+
+Each event contains only the command that was run, such as "cueckoo ci cost",
+how long it took, whether it succeeded, the version of cueckoo, and the
+operating system and architecture. No arguments, repository names, CLs or
+user identifiers are ever reported.
+
+The setting is stored in the user config file; see "telemetry status".
+`,
+		RunE: mkRunE(c, telemetryDef),
+	}
+	return cmd
+}
+
+func telemetryDef(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected one of on, off or status")
+	}
+	switch args[0] {
+	case "on", "off":
+		return setUserConfig("telemetry", args[0])
+	case "status":
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		path, _ := userConfigPath()
+		w := cmd.OutOrStdout()
+		if telemetryEnabled(cfg) {
+			fmt.Fprintf(w, "telemetry is on, reporting to %s\n", telemetryEndpoint(cfg))
+		} else {
+			fmt.Fprintln(w, "telemetry is off")
+		}
+		fmt.Fprintf(w, "setting stored in %s\n", path)
+		return nil
+	}
+	return fmt.Errorf("unknown argument %q; expected one of on, off or status", args[0])
+}
+
+func telemetryEnabled(cfg map[string]string) bool {
+	return cfg["telemetry"] == "on"
+}
+
+func telemetryEndpoint(cfg map[string]string) string {
+	if e := cfg["telemetry-endpoint"]; e != "" {
+		return e
+	}
+	return defaultTelemetryEndpoint
+}
+
+// reportTelemetry reports the invocation of cmd, which started at start and
+// finished with err, if the user has turned telemetry on. Failures to report
+// are ignored.
+func reportTelemetry(ctx context.Context, cmd *Command, start time.Time, err error) {
+	cfg, cfgErr := loadUserConfig()
+	if cfgErr != nil || !telemetryEnabled(cfg) {
+		return
+	}
+	ev := telemetryEvent{
+		Command:    cmd.CommandPath(),
+		Version:    cueckooVersion(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		DurationMS: time.Since(start).Milliseconds(),
+		Result:     "success",
+	}
+	switch err {
+	case nil:
+	case errPrintedPartialFailure:
+		ev.Result = "partial"
+	default:
+		ev.Result = "failure"
+	}
+	body, _ := json.Marshal(ev)
+
+	ctx, cancel := context.WithTimeout(ctx, telemetryTimeout)
+	defer cancel()
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, telemetryEndpoint(cfg), bytes.NewReader(body))
+	if reqErr != nil {
+		debugf("failed to report telemetry: %v\n", reqErr)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, reqErr := http.DefaultClient.Do(req)
+	if reqErr != nil {
+		debugf("failed to report telemetry: %v\n", reqErr)
+		return
+	}
+	resp.Body.Close()
+}
+
+// cueckooVersion returns the module version of the running cueckoo binary,
+// or "(devel)" if it was not built from a released version.
+func cueckooVersion() string {
+	if bi, ok := rtdebug.ReadBuildInfo(); ok && bi.Main.Version != "" {
+		return bi.Main.Version
+	}
+	return "(devel)"
+}
@@ -0,0 +1,13 @@
+// The fields of the client payload read by compat workflows, which check out
+// the patchset and compare its API against the base version.
+
+package downstream
+
+#dispatch: {
+	type:          "compat"
+	CL:            int
+	patchset:      int
+	targetBranch?: string
+	ref:           string
+	base:          string
+}
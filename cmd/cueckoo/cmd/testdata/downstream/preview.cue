@@ -0,0 +1,11 @@
+// The fields of the client payload read by the preview workflow in the
+// cue-lang/cuelang.org repository.
+
+package downstream
+
+#dispatch: {
+	type:     "preview"
+	CL:       int
+	patchset: int
+	ref:      string
+}
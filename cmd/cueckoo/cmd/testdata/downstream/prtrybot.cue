@@ -0,0 +1,12 @@
+// The fields of the client payload read by trybot workflows which test
+// GitHub PRs, which check out the head commit of the PR from the mirror.
+
+package downstream
+
+#dispatch: {
+	type:          "prtrybot"
+	targetBranch?: string
+	ref:           string
+	PR:            int
+	sha:           string
+}
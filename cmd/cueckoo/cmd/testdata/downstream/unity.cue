@@ -0,0 +1,13 @@
+// The fields of the client payload read by the unity workflow in the
+// cue-unity/unity repository.
+
+package downstream
+
+#dispatch: {
+	type:          "unity"
+	versions?:     string
+	CL?:           int
+	patchset?:     int
+	targetBranch?: string
+	ref?:          string
+}
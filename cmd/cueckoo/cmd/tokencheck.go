@@ -0,0 +1,181 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A GitHub token which lacks a scope, or which has not been authorized for
+// an organization enforcing SAML single sign-on, results in obscure 403 and
+// 404 errors, such as from dispatches. So the first time a token is used
+// to write to a repository, it is checked up front; the tokens which passed
+// are recorded by fingerprint in the cueckoo directory of $XDG_CACHE_HOME,
+// or its platform equivalent, for tokenCheckMaxAge.
+
+// tokenCheckMaxAge is how long a token which passed the checks for a
+// repository is trusted for, as scopes and authorizations can be revoked.
+const tokenCheckMaxAge = 7 * 24 * time.Hour
+
+// githubSettingsTokens is where classic tokens and their scopes are managed.
+const githubSettingsTokens = "https://github.com/settings/tokens"
+
+// tokenFingerprint returns an identifier for token which does not reveal it.
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// checkGitHubToken checks that the GitHub token can write to owner/repo: that
+// it has the given OAuth scopes, such as "repo" and "workflow", and that
+// single sign-on has been authorized for owner if required. Scopes can only
+// be checked for classic tokens; fine-grained tokens have no scopes, and are
+// checked for write access alone. Each check is made once per process and
+// repository, and not repeated for a recently checked token.
+func (c *config) checkGitHubToken(ctx context.Context, owner, repo string, scopes ...string) error {
+	key := owner + "/" + repo + " " + strings.Join(scopes, ",")
+	c.tokenChecksMu.Lock()
+	defer c.tokenChecksMu.Unlock()
+	if err, ok := c.tokenChecks[key]; ok {
+		return err
+	}
+	var err error
+	if !tokenChecked(c.githubToken, key) {
+		if err = c.fetchAndCheckGitHubToken(ctx, owner, repo, scopes); err == nil {
+			recordTokenCheck(c.githubToken, key)
+		}
+	}
+	if c.tokenChecks == nil {
+		c.tokenChecks = make(map[string]error)
+	}
+	c.tokenChecks[key] = err
+	return err
+}
+
+func (c *config) fetchAndCheckGitHubToken(ctx context.Context, owner, repo string, scopes []string) error {
+	r, resp, err := c.githubClient.Repositories.Get(ctx, owner, repo)
+	if resp != nil {
+		if url := ssoAuthorizeURL(resp.Header); url != "" {
+			return fmt.Errorf("your GitHub token has not been authorized for %s, which enforces SAML single sign-on; authorize it at:\n\n\t%s", owner, url)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check GitHub token against %s/%s: %w", owner, repo, err)
+	}
+	// Classic tokens list their scopes in the X-OAuth-Scopes header, even
+	// if empty. Note that the repo scope implies public_repo.
+	if _, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok {
+		have := make(map[string]bool)
+		for _, s := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+			have[strings.TrimSpace(s)] = true
+		}
+		var missing []string
+		for _, s := range scopes {
+			if !have[s] && !(s == "repo" && !r.GetPrivate() && have["public_repo"]) {
+				missing = append(missing, s)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("your GitHub token lacks the %s scope needed to write to %s/%s; add it at %s",
+				strings.Join(missing, " and "), owner, repo, githubSettingsTokens)
+		}
+	}
+	if perms := r.GetPermissions(); perms != nil && !perms["push"] {
+		return fmt.Errorf("your GitHub account has no write access to %s/%s", owner, repo)
+	}
+	return nil
+}
+
+// ssoAuthorizeURL returns the URL at which to authorize a token for single
+// sign-on, as given by a header such as:
+//
+//	X-GitHub-SSO: required; url=https://github.com/orgs/cue-lang/sso?authorization_request=...
+//
+// It returns the empty string if no authorization is required.
+func ssoAuthorizeURL(h http.Header) string {
+	v := h.Get("X-GitHub-SSO")
+	rest, ok := strings.CutPrefix(v, "required;")
+	if !ok {
+		return ""
+	}
+	url, ok := strings.CutPrefix(strings.TrimSpace(rest), "url=")
+	if !ok {
+		return docGitHubSAML
+	}
+	return url
+}
+
+// tokenCheckPath returns the path of the record of checked tokens.
+func tokenCheckPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cueckoo", "tokens.json"), nil
+}
+
+// readTokenChecks returns when each token fingerprint last passed the
+// checks for each key, which is empty if there is no record.
+func readTokenChecks() (string, map[string]map[string]time.Time) {
+	checks := make(map[string]map[string]time.Time)
+	path, err := tokenCheckPath()
+	if err != nil {
+		return "", checks
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &checks)
+	}
+	return path, checks
+}
+
+// tokenChecked reports whether token recently passed the checks for key.
+func tokenChecked(token, key string) bool {
+	_, checks := readTokenChecks()
+	t, ok := checks[tokenFingerprint(token)][key]
+	return ok && time.Since(t) < tokenCheckMaxAge
+}
+
+// recordTokenCheck records that token passed the checks for key. Failures
+// are only reported in debug mode, as the record is an optimisation.
+func recordTokenCheck(token, key string) {
+	path, checks := readTokenChecks()
+	if path == "" {
+		return
+	}
+	fp := tokenFingerprint(token)
+	if checks[fp] == nil {
+		checks[fp] = make(map[string]time.Time)
+	}
+	checks[fp][key] = time.Now()
+	data, err := json.Marshal(checks)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o777)
+	}
+	if err == nil {
+		err = os.WriteFile(path, data, 0o600)
+	}
+	if err != nil {
+		debugf("failed to record token check: %v\n", err)
+	}
+}
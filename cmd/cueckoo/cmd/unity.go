@@ -15,9 +15,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v53/github"
 	"github.com/spf13/cobra"
@@ -35,25 +38,51 @@ func newUnityCmd(c *Command) *cobra.Command {
 		Long: `
 Usage of unity:
 
-	unity [--normal] [ARGS...]
+	unity [--versions] [--wait] [ARGS...]
 
 When run with no arguments, unity derives a revision and change ID for each
 pending commit in the current branch. If multiple pending commits are found,
 you must either specify which commits to run, or specify HEAD to run the
 unity for all of them.
 
-If the --normal flag is provided, then the list of arguments is interpreted as
-versions understood by unity.
+If the --versions flag is provided, then the list of arguments, of which
+there must be at least one, is interpreted as versions understood by unity.
 
 runtrybot needs your GitHub username and a personal acccess token
 with the "repo" scope. You can configure them via your git credential helper,
 or by setting the GITHUB_USER and GITHUB_PAT environment variables.
+A token on its own can also be provided via GITHUB_TOKEN, as is typical in CI.
 Note that the personal access token should be "classic"; GitHub's new
 fine-grained tokens are still in beta and haven't been tested to work here.
+
+As with runtrybot, a warning is printed if the pending commits are based on a
+commit which is far behind the default branch of origin; see "cueckoo help
+runtrybot". --no-freshness-check skips the check.
+
+The corpus of modules which unity tests against can be managed with the
+"unity corpus" subcommand. "unity bisect" finds the commit which introduced
+a regression found by unity.
+
+With --wait, unity then waits for the dispatched workflow runs to complete,
+up to --wait-timeout, printing a line each time the status of a run changes,
+and exits with a non-zero status if any run did not succeed.
+
+As with runtrybot, rerunning the same command after a partial failure only
+retries the CLs which failed, unless --no-resume is given.
 `,
-		RunE: mkRunE(c, unityDef),
+		RunE:              mkRunE(c, unityDef),
+		ValidArgsFunction: completeChanges(0),
 	}
-	cmd.Flags().Bool(string(flagUnityVersions), false, "pass arguments to unity as versions")
+	flags := c.registerFlags(cmd)
+	flags.Bool(flagUnityVersions, false, "pass arguments to unity as versions")
+	flags.ArgsWith(flagUnityVersions, "version")
+	flags.Bool(flagNoFreshnessCheck, false, "do not warn when pending commits are based on an outdated commit")
+	flags.Bool(flagOverrideFreeze, false, "proceed even during a freeze window of the repository")
+	flags.Bool(flagWait, false, "wait for the dispatched runs to complete")
+	flags.String(flagWaitTimeout, "1h", "how long to wait for the dispatched runs with --wait").Duration()
+	flags.Bool(flagNoResume, false, "do not skip CLs dispatched by a previous run which partially failed")
+	cmd.AddCommand(newUnityCorpusCmd(c))
+	cmd.AddCommand(newUnityBisectCmd(c))
 	return cmd
 }
 
@@ -62,8 +91,16 @@ func unityDef(cmd *Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := cfg.checkFreeze(cmd); err != nil {
+		return err
+	}
+	waitTimeout, err := parseDuration(flagWaitTimeout.String(cmd))
+	if err != nil {
+		return err
+	}
+	watcher := &runWatcher{cfg: cfg}
 
-	// If we are passed --normal, interpret all args as versions to be passed to
+	// If we are passed --versions, interpret all args as versions to be passed to
 	// unity
 	if flagUnityVersions.Bool(cmd) {
 		unquoted := strings.Join(args, " ")
@@ -73,27 +110,39 @@ func unityDef(cmd *Command, args []string) error {
 		var up unityPayload
 		up.Type = string(eventTypeUnity)
 		up.Versions = strings.Join(args, " ")
-		payload, err := buildUnityPayload(fmt.Sprintf("unity run for versions %s", unquoted), up)
+		title := fmt.Sprintf("unity run for versions %s", unquoted)
+		payload, err := buildUnityPayload(title, up)
 		if err != nil {
 			return err
 		}
-		return cfg.triggerRepositoryDispatch(cfg.unityOwner, cfg.unityRepo, payload)
-	}
-
-	// Interpret as a request to test CLs
-
-	r := newCLTrigger(cmd, cfg, func(payload repositoryDispatchPayload) error {
-		payload.Type = string(eventTypeUnity)
-		p, err := buildUnityPayloadFromCLTrigger(payload)
-		if err != nil {
+		start := time.Now()
+		if err := cfg.triggerRepositoryDispatch(cmd.Context(), cfg.unityOwner, cfg.unityRepo, payload); err != nil {
 			return err
 		}
-		if err := cfg.triggerRepositoryDispatch(cfg.unityOwner, cfg.unityRepo, p); err != nil {
-			return err
+		watcher.add(cfg.unityOwner, cfg.unityRepo, title, start)
+	} else {
+		// Interpret as a request to test CLs
+		r := newCLTrigger(cmd, cfg, func(payload repositoryDispatchPayload) error {
+			start := time.Now()
+			payload.Type = string(eventTypeUnity)
+			p, err := buildUnityPayloadFromCLTrigger(payload)
+			if err != nil {
+				return err
+			}
+			if err := cfg.triggerRepositoryDispatch(cmd.Context(), cfg.unityOwner, cfg.unityRepo, p); err != nil {
+				return err
+			}
+			watcher.add(cfg.unityOwner, cfg.unityRepo, unityRunTitle(payload.Ref), start)
+			return nil
+		})
+		err = r.run()
+	}
+	if flagWait.Bool(cmd) && len(watcher.runs) > 0 {
+		if werr := watcher.wait(cmd.Context(), cmd.OutOrStdout(), waitTimeout); err == nil {
+			err = werr
 		}
-		return nil
-	})
-	return r.run()
+	}
+	return err
 }
 
 type unityPayload struct {
@@ -108,12 +157,41 @@ type unityPayload struct {
 }
 
 func buildUnityPayload(msg string, payload unityPayload) (github.DispatchRequestOptions, error) {
+	payload.PayloadVersion = payloadVersion
 	return buildDispatchPayload(msg, payload)
 }
 
 func buildUnityPayloadFromCLTrigger(payload repositoryDispatchPayload) (github.DispatchRequestOptions, error) {
-	msg := fmt.Sprintf("unity run for %v", payload.Ref)
+	msg := unityRunTitle(payload.Ref)
+	payload.PayloadVersion = payloadVersion
 	return buildDispatchPayload(msg, unityPayload{
 		repositoryDispatchPayload: payload,
 	})
 }
+
+// checkUnityAccess checks that the configured unity repository can be
+// accessed with the user's GitHub credentials. When the repository is
+// private and the token lacks access, GitHub responds with 404 rather than
+// 403, which would otherwise surface as a confusing dispatch failure; that
+// case is reported as a *unityAccessError.
+func (c *config) checkUnityAccess(ctx context.Context) error {
+	_, _, err := c.githubClient.Repositories.Get(ctx, c.unityOwner, c.unityRepo)
+	switch code := statusCode(err); {
+	case err == nil:
+		return nil
+	case code == http.StatusNotFound || code == http.StatusForbidden:
+		return &unityAccessError{repo: c.unityOwner + "/" + c.unityRepo}
+	default:
+		return fmt.Errorf("failed to check access to the unity repository %s/%s: %w", c.unityOwner, c.unityRepo, err)
+	}
+}
+
+// unityAccessError reports that the unity repository is not accessible with
+// the user's credentials.
+type unityAccessError struct {
+	repo string
+}
+
+func (e *unityAccessError) Error() string {
+	return fmt.Sprintf("the unity repository %s is not accessible with your GitHub credentials; if it is private, your token needs access to it", e.repo)
+}
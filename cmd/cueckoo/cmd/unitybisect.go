@@ -0,0 +1,263 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const flagUnityBisectTimeout flagName = "timeout"
+
+// newUnityBisectCmd creates a new unity bisect command
+func newUnityBisectCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bisect",
+		Short: "find the commit which introduced a unity regression",
+		Long: `
+Usage of unity bisect:
+
+	unity bisect [--timeout DURATION] CL
+	unity bisect [--timeout DURATION] GOOD BAD
+
+unity bisect searches for the first commit for which unity fails, by
+triggering unity runs one at a time and waiting for each to complete, and
+prints that commit. It speeds up hunting for evaluator regressions.
+
+Given a CL, the commits searched are those of the open CLs in its relation
+chain, from the bottom of the chain up to and including the CL. The base of
+the chain is assumed to be good, and the CL itself to be bad.
+
+Given two versions of CUE, such as two releases, the commits searched are
+those after GOOD up to and including BAD, as listed by GitHub. GOOD is
+assumed to be good, and BAD to be bad. Each commit is passed to unity as a
+version by its hash.
+
+Only runs which succeed count as good, and only runs which fail count as bad;
+any other outcome, such as a cancelled run, stops the search. Each run may
+take up to --timeout.
+`,
+		RunE:              mkRunE(c, unityBisectDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagUnityBisectTimeout, "1h", "how long to wait for each unity run")
+	flags.Bool(flagOverrideFreeze, false, "proceed even during a freeze window of the repository")
+	return cmd
+}
+
+// bisectCandidate is a commit which unity bisect may test.
+type bisectCandidate struct {
+	// desc describes the commit for the user, such as "CL 1234 patchset 5".
+	desc string
+
+	// payload builds the dispatch which runs unity for the commit.
+	payload func() (github.DispatchRequestOptions, error)
+}
+
+func unityBisectDef(cmd *Command, args []string) error {
+	timeout, err := parseDuration(flagUnityBisectTimeout.String(cmd))
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkFreeze(cmd); err != nil {
+		return err
+	}
+	if cfg.unityRepo == "" {
+		return fmt.Errorf("no unity repository configured in codereview.cfg")
+	}
+	var candidates []bisectCandidate
+	switch len(args) {
+	case 1:
+		candidates, err = cfg.chainBisectCandidates(args[0])
+	case 2:
+		candidates, err = cfg.versionBisectCandidates(ctx, args[0], args[1])
+	default:
+		return fmt.Errorf("expected a CL, or a good and a bad version")
+	}
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no commits to bisect")
+	}
+
+	w := cmd.OutOrStdout()
+	first, err := bisect(len(candidates), func(i int) (bool, error) {
+		c := candidates[i]
+		fmt.Fprintf(os.Stderr, "running unity for %s\n", c.desc)
+		p, err := c.payload()
+		if err != nil {
+			return false, err
+		}
+		start := time.Now()
+		if err := cfg.triggerRepositoryDispatch(ctx, cfg.unityOwner, cfg.unityRepo, p); err != nil {
+			return false, err
+		}
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		run, err := cfg.waitForDispatchedRun(waitCtx, cfg.unityOwner, cfg.unityRepo, p.EventType, start)
+		if err != nil {
+			return false, err
+		}
+		switch run.GetConclusion() {
+		case "success":
+			fmt.Fprintf(w, "good: %s (%s)\n", c.desc, run.GetHTMLURL())
+			return true, nil
+		case "failure":
+			fmt.Fprintf(w, "bad:  %s (%s)\n", c.desc, run.GetHTMLURL())
+			return false, nil
+		}
+		return false, fmt.Errorf("unity run %s for %s concluded %s", run.GetHTMLURL(), c.desc, run.GetConclusion())
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "first bad commit: %s\n", candidates[first].desc)
+	return nil
+}
+
+// bisect returns the index of the first bad one of n candidates, given that
+// the candidates before it are good and the last one is bad. good reports
+// whether a candidate is good.
+func bisect(n int, good func(i int) (bool, error)) (int, error) {
+	lo, hi := -1, n-1
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ok, err := good(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi, nil
+}
+
+// relatedChanges is the subset of Gerrit's RelatedChangesInfo entity that we
+// use.
+type relatedChanges struct {
+	Changes []struct {
+		Number int    `json:"_change_number"`
+		Status string `json:"status"`
+	} `json:"changes"`
+}
+
+// chainBisectCandidates returns the current patchsets of the open CLs in the
+// relation chain of the given CL, from the bottom of the chain up to and
+// including the CL.
+func (c *config) chainBisectCandidates(cl string) ([]bisectCandidate, error) {
+	id, err := c.resolveChangeID(cl)
+	if err != nil {
+		return nil, err
+	}
+	var related relatedChanges
+	if err := c.gerritDo("GET", "changes/"+id+"/revisions/current/related", nil, &related); err != nil {
+		return nil, fmt.Errorf("failed to get related changes of %s: %w", cl, err)
+	}
+	ch, _, err := c.gerritClient.Changes.GetChange(id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	// Related changes are listed from the top of the chain down. A CL
+	// without any related changes is a chain of its own.
+	numbers := []int{ch.Number}
+	for i, r := range related.Changes {
+		if r.Number != ch.Number {
+			continue
+		}
+		numbers = nil
+		for _, r := range related.Changes[i:] {
+			if r.Status == "NEW" {
+				numbers = append([]int{r.Number}, numbers...)
+			}
+		}
+		break
+	}
+
+	ids := make([]string, len(numbers))
+	for i, n := range numbers {
+		ids[i] = strconv.Itoa(n)
+	}
+	changes, errs := c.getChanges(ids, "CURRENT_REVISION")
+	var res []bisectCandidate
+	for i, ch := range changes {
+		if errs != nil && errs[i] != nil {
+			return nil, errs[i]
+		}
+		rev := ch.Revisions[ch.CurrentRevision]
+		payload := repositoryDispatchPayload{
+			Type:         string(eventTypeUnity),
+			CL:           ch.Number,
+			Patchset:     rev.Number,
+			TargetBranch: ch.Branch,
+			Ref:          rev.Ref,
+		}
+		res = append(res, bisectCandidate{
+			desc: fmt.Sprintf("CL %d patchset %d (%s)", ch.Number, rev.Number, ch.Subject),
+			payload: func() (github.DispatchRequestOptions, error) {
+				return buildUnityPayloadFromCLTrigger(payload)
+			},
+		})
+	}
+	return res, nil
+}
+
+// versionBisectCandidates returns the commits after good up to and including
+// bad, oldest first.
+func (c *config) versionBisectCandidates(ctx context.Context, good, bad string) ([]bisectCandidate, error) {
+	var res []bisectCandidate
+	opts := &github.ListOptions{Page: 1}
+	for {
+		cmp, resp, err := c.githubClient.Repositories.CompareCommits(ctx, c.githubOwner, c.githubRepo, good, bad, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare commits: %w", err)
+		}
+		for _, commit := range cmp.Commits {
+			sha := commit.GetSHA()
+			res = append(res, bisectCandidate{
+				desc: fmt.Sprintf("%s (%s)", sha[:12], firstLine(commit.GetCommit().GetMessage())),
+				payload: func() (github.DispatchRequestOptions, error) {
+					return buildUnityPayload(fmt.Sprintf("unity run for versions %s", sha), unityPayload{
+						repositoryDispatchPayload: repositoryDispatchPayload{
+							Type: string(eventTypeUnity),
+						},
+						Versions: strconv.Quote(sha),
+					})
+				},
+			})
+		}
+		// As in releaselog, LastPage is zero when there is a single page.
+		if resp.LastPage <= opts.Page {
+			break
+		}
+		opts.Page++
+	}
+	return res, nil
+}
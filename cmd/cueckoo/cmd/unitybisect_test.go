@@ -0,0 +1,51 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "testing"
+
+func TestBisect(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		for firstBad := 0; firstBad < n; firstBad++ {
+			tested := 0
+			got, err := bisect(n, func(i int) (bool, error) {
+				if i < 0 || i >= n-1 {
+					t.Fatalf("n=%d: tested candidate %d, which is out of range or known to be bad", n, i)
+				}
+				tested++
+				return i < firstBad, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != firstBad {
+				t.Errorf("n=%d: got first bad %d; want %d", n, got, firstBad)
+			}
+			if limit := bitsLen(n); tested > limit {
+				t.Errorf("n=%d: tested %d candidates; want at most %d", n, tested, limit)
+			}
+		}
+	}
+}
+
+// bitsLen returns the number of bits needed to represent n-1, the most
+// steps bisect should take for n candidates.
+func bitsLen(n int) int {
+	b := 0
+	for n--; n > 0; n >>= 1 {
+		b++
+	}
+	return b
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagUnityCorpusFile flagName = "file"
+
+	defaultUnityCorpusFile = "corpus.cue"
+)
+
+// corpusEntry is a downstream module which unity tests against.
+type corpusEntry struct {
+	// Module is the module path, e.g. github.com/cue-unity/example.
+	Module string `json:"module"`
+
+	// Repo is the URL of the git repository containing the module, if it
+	// cannot be derived from the module path.
+	Repo string `json:"repo,omitempty"`
+}
+
+// unityCorpus is the data held in the corpus file of the unity repository.
+type unityCorpus struct {
+	Corpus []corpusEntry `json:"corpus"`
+}
+
+// newUnityCorpusCmd creates a new unity corpus command
+func newUnityCorpusCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "corpus",
+		Short: "manage the corpus of modules that unity tests against",
+		Long: `
+Usage of unity corpus:
+
+	unity corpus list
+	unity corpus add MODULE [REPO]
+	unity corpus remove MODULE...
+
+The corpus is the set of downstream modules which unity tests against. It is
+stored as CUE data in the unity repository configured in codereview.cfg, in
+the file given by --file, of the form:
+
+	corpus: [{
+		module: "github.com/cue-unity/example"
+		repo:   "https://github.com/cue-unity/example"
+	}]
+
+list prints the corpus on the default branch of the unity repository. add and
+remove create a CL against the unity repository which updates the corpus,
+keeping it sorted by module path. The CL can then be reviewed and submitted
+as usual.
+`,
+	}
+	c.registerPersistentFlags(cmd).String(flagUnityCorpusFile, defaultUnityCorpusFile, "path of the corpus file in the unity repository")
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "list the modules in the corpus",
+			RunE:  mkRunE(c, unityCorpusListDef),
+		},
+		&cobra.Command{
+			Use:   "add",
+			Short: "create a CL adding a module to the corpus",
+			RunE:  mkRunE(c, unityCorpusAddDef),
+		},
+		&cobra.Command{
+			Use:   "remove",
+			Short: "create a CL removing modules from the corpus",
+			RunE:  mkRunE(c, unityCorpusRemoveDef),
+		},
+	)
+	return cmd
+}
+
+func unityCorpusListDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("list does not take any arguments")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadUnityConfig(ctx)
+	if err != nil {
+		return err
+	}
+	_, corpus, err := cfg.unityCorpus(ctx, flagUnityCorpusFile.String(cmd))
+	if err != nil {
+		return err
+	}
+	tw := newTableWriter(cmd.OutOrStdout())
+	fmt.Fprintln(tw, "MODULE\tREPO")
+	for _, e := range corpus.Corpus {
+		fmt.Fprintf(tw, "%s\t%s\n", e.Module, e.Repo)
+	}
+	return tw.Flush()
+}
+
+func unityCorpusAddDef(cmd *Command, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("expected a module path and an optional repository URL")
+	}
+	entry := corpusEntry{Module: args[0]}
+	if len(args) == 2 {
+		entry.Repo = args[1]
+	}
+	return updateUnityCorpus(cmd, fmt.Sprintf("corpus: add %s", entry.Module), func(corpus *unityCorpus) error {
+		for _, e := range corpus.Corpus {
+			if e.Module == entry.Module {
+				return fmt.Errorf("%s is already in the corpus", entry.Module)
+			}
+		}
+		corpus.Corpus = append(corpus.Corpus, entry)
+		return nil
+	})
+}
+
+func unityCorpusRemoveDef(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected at least one module path")
+	}
+	return updateUnityCorpus(cmd, fmt.Sprintf("corpus: remove %s", strings.Join(args, ", ")), func(corpus *unityCorpus) error {
+		for _, m := range args {
+			found := false
+			for i, e := range corpus.Corpus {
+				if e.Module == m {
+					corpus.Corpus = append(corpus.Corpus[:i], corpus.Corpus[i+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%s is not in the corpus", m)
+			}
+		}
+		return nil
+	})
+}
+
+// updateUnityCorpus applies update to the corpus on the default branch of the
+// unity repository, and creates a CL with the result using subject as the
+// first line of the commit message.
+func updateUnityCorpus(cmd *Command, subject string, update func(*unityCorpus) error) error {
+	ctx := cmd.Context()
+	cfg, err := loadUnityConfig(ctx)
+	if err != nil {
+		return err
+	}
+	path := flagUnityCorpusFile.String(cmd)
+	branch, corpus, err := cfg.unityCorpus(ctx, path)
+	if err != nil {
+		return err
+	}
+	if err := update(corpus); err != nil {
+		return err
+	}
+	content, err := formatUnityCorpus(corpus)
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("%s\n\nThis CL was generated by cueckoo unity corpus.\n", subject)
+	ch, err := cfg.createFileChange(cfg.unityOwner+"/"+cfg.unityRepo, branch, msg, path, content)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "created %s\n", cfg.projectChangeURL(ch))
+	return nil
+}
+
+// loadUnityConfig is like loadConfig, but fails if no unity repository is
+// configured.
+func loadUnityConfig(ctx context.Context) (*config, error) {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.unityRepo == "" {
+		return nil, fmt.Errorf("no unity repository configured in codereview config")
+	}
+	return cfg, nil
+}
+
+// unityCorpus returns the default branch of the unity repository along with
+// the corpus at path on that branch, sorted by module path.
+func (c *config) unityCorpus(ctx context.Context, path string) (string, *unityCorpus, error) {
+	branch, content, err := c.defaultBranchFile(ctx, c.unityOwner, c.unityRepo, path)
+	if err != nil {
+		return "", nil, err
+	}
+	var corpus unityCorpus
+	if content != "" {
+		if err := decodeCUE(ctx, path, []byte(content), &corpus); err != nil {
+			return "", nil, err
+		}
+	}
+	return branch, &corpus, nil
+}
+
+// formatUnityCorpus returns the content of a corpus file for corpus, sorted
+// by module path.
+func formatUnityCorpus(corpus *unityCorpus) ([]byte, error) {
+	sort.Slice(corpus.Corpus, func(i, j int) bool {
+		return corpus.Corpus[i].Module < corpus.Corpus[j].Module
+	})
+	var b strings.Builder
+	b.WriteString("// Code generated by cueckoo unity corpus; DO NOT EDIT.\n\n")
+	b.WriteString("corpus: [")
+	for i, e := range corpus.Corpus {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		module, err := json.Marshal(e.Module)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "{\n\tmodule: %s", module)
+		if e.Repo != "" {
+			repo, err := json.Marshal(e.Repo)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&b, "\n\trepo:   %s", repo)
+		}
+		b.WriteString("\n}")
+	}
+	b.WriteString("]\n")
+	return []byte(b.String()), nil
+}
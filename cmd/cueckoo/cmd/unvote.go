@@ -0,0 +1,120 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagUnvoteAccount flagName = "account"
+)
+
+// newUnvoteCmd creates a new unvote command
+func newUnvoteCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unvote",
+		Short: "remove a vote from a CL",
+		Long: `
+Usage of unvote:
+
+	unvote [--account ACCOUNT] CL [LABEL]
+
+unvote removes the vote on LABEL by the current user from the given CL. If no
+LABEL is given, all of the user's votes on the CL are removed.
+
+--account removes the votes of another account instead, given by its numeric
+ID, username or email address. This needs the permission to remove reviewers
+on the CL, and is useful for removing a stale TryBot-Result-1 posted by the
+trybot after a flaky failure, which would otherwise block submitting:
+
+	cueckoo unvote --account cueckoo@cuelang.org 12345 TryBot-Result
+`,
+		RunE:              mkRunE(c, unvoteDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	c.registerFlags(cmd).String(flagUnvoteAccount, "self", "the account whose votes to remove")
+	return cmd
+}
+
+func unvoteDef(cmd *Command, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("expected a CL and an optional label")
+	}
+	cfg, err := loadConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+	id, err := cfg.resolveChangeID(args[0])
+	if err != nil {
+		return err
+	}
+	var account gerrit.AccountInfo
+	accountID := flagUnvoteAccount.String(cmd)
+	if err := cfg.gerritDo(http.MethodGet, "accounts/"+url.PathEscape(accountID), nil, &account); err != nil {
+		return fmt.Errorf("failed to look up account %s: %w", accountID, err)
+	}
+	ch, _, err := cfg.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"DETAILED_LABELS"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+
+	votes := accountVotes(ch.Labels, account.AccountID)
+	if len(args) == 2 {
+		label := args[1]
+		if _, ok := ch.Labels[label]; !ok {
+			return fmt.Errorf("CL %d has no label %s", ch.Number, label)
+		}
+		if votes[label] == 0 {
+			return fmt.Errorf("%s has not voted on %s on CL %d", accountName(account), label, ch.Number)
+		}
+		votes = map[string]int{label: votes[label]}
+	} else if len(votes) == 0 {
+		return fmt.Errorf("%s has not voted on CL %d", accountName(account), ch.Number)
+	}
+
+	w := cmd.OutOrStdout()
+	reviewer := strconv.Itoa(account.AccountID)
+	for _, label := range sortedKeys(votes) {
+		path := "changes/" + id + "/reviewers/" + reviewer + "/votes/" + url.PathEscape(label)
+		if err := cfg.gerritDo(http.MethodDelete, path, nil, nil); err != nil {
+			return fmt.Errorf("failed to remove %s%+d: %w", label, votes[label], err)
+		}
+		fmt.Fprintf(w, "removed %s%+d by %s from CL %d\n", label, votes[label], accountName(account), ch.Number)
+	}
+	return nil
+}
+
+// accountVotes returns the non-zero votes of the account with the given ID,
+// keyed by label.
+func accountVotes(labels map[string]gerrit.LabelInfo, accountID int) map[string]int {
+	votes := make(map[string]int)
+	for label, info := range labels {
+		for _, approval := range info.All {
+			if approval.AccountID == accountID && approval.Value != 0 {
+				votes[label] = approval.Value
+			}
+		}
+	}
+	return votes
+}
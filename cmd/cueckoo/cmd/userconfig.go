@@ -0,0 +1,98 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cue-lang/contrib-tools/internal/codereviewcfg"
+)
+
+// The user config holds per-user settings for cueckoo, as opposed to the
+// per-repository settings in codereview.cfg. It uses the same "key: value"
+// format, and lives in the cueckoo directory of the user's config directory,
+// e.g. ~/.config/cueckoo/config on Linux.
+
+// userConfigPath returns the path of the user config file.
+func userConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cueckoo", "config"), nil
+}
+
+// loadUserConfig returns the user config, which is empty if the file does
+// not exist.
+func loadUserConfig() (map[string]string, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	cfg, err := codereviewcfg.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := validateConfig("user", path, cfg)
+	printConfigWarnings(warnings)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// setUserConfig sets key to value in the user config, creating the file if
+// needed. An existing line for key is replaced in place, such that comments
+// and the order of other lines are kept.
+func setUserConfig(key, value string) error {
+	path, err := userConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	entry := key + ": " + value
+	found := false
+	for i, l := range lines {
+		if k, _, ok := strings.Cut(l, ":"); ok && !strings.HasPrefix(strings.TrimSpace(l), "#") && strings.TrimSpace(k) == key {
+			lines[i] = entry
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, entry)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o666); err != nil {
+		return fmt.Errorf("failed to write user config: %v", err)
+	}
+	return nil
+}
@@ -19,14 +19,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/andygrunwald/go-gerrit"
 	"github.com/cue-lang/contrib-tools/internal/codereviewcfg"
 	"github.com/google/go-github/v53/github"
+	"golang.org/x/oauth2"
 )
 
 // eventType values define an enumeration of the various
@@ -42,16 +48,36 @@ const (
 	eventTypeTrybot   eventType = "trybot"
 	eventTypeImportPR eventType = "importpr"
 	eventTypeUnity    eventType = "unity"
+	eventTypeCompat   eventType = "compat"
+	eventTypePreview  eventType = "preview"
+
+	eventTypePRTrybot eventType = "prtrybot"
 )
 
+// importTrailersKey is the codereview config key selecting the provenance
+// trailers added by importpr.
+const importTrailersKey = "import-trailers"
+
+// dependentKeyPrefix prefixes the codereview config keys which name the
+// repositories depending on this one; see bump.
+const dependentKeyPrefix = "dependent-"
+
 // config holds the configuration that is loaded from the codereview config
 // found within the root of the git directory that contains the working
 // directory. Put another way, cueckoo needs to be run from within the main
 // cue repo.
 type config struct {
-	// gerritURL is the URL of the Gerrit instance
+	// gitRoot is the root directory of the git repository
+	gitRoot string
+
+	// gerritURL is the base URL of the Gerrit instance, with a trailing slash
 	gerritURL string
 
+	// gerritProjectName is the Gerrit project as given in the codereview
+	// config, either explicitly or as part of the Gerrit URL; see
+	// gerritProject.
+	gerritProjectName string
+
 	// githubURL is the URL for the GitHub repo
 	githubURL string
 
@@ -67,11 +93,61 @@ type config struct {
 	// unityRepo is the name of the unity repo
 	unityRepo string
 
+	// dependents maps the names of repositories which depend on this one to
+	// their clone URLs, as given by "dependent-NAME" entries in the
+	// codereview config.
+	dependents map[string]string
+
+	// importTrailers are the keys of the provenance trailers which importpr
+	// adds to imported commits, as given by the import-trailers entry in the
+	// codereview config; see provenanceTrailers.
+	importTrailers []string
+
+	// freeze is where the freeze windows of the repository are published,
+	// as given by the freeze entry in the codereview config; see
+	// loadFreezeWindows.
+	freeze string
+
+	// activityFeed is where release-critical events are recorded, as given
+	// by the activity-feed entry in the codereview config; see
+	// recordActivity.
+	activityFeed string
+
 	// githubClient is the client for using the GitHub API
 	githubClient *github.Client
 
+	// githubCredentials and gerritCredentials describe where the
+	// credentials for GitHub and Gerrit were taken from, such as
+	// "GITHUB_TOKEN" or "git credential helper".
+	githubCredentials string
+	gerritCredentials string
+
+	// githubUser is the GitHub username used for authentication. It is empty
+	// when authenticating with a token alone; see githubLogin.
+	githubUser string
+
 	// gerritClient is the client for using the Gerrit API
 	gerritClient *gerrit.Client
+
+	// gerritVersion is the version of the Gerrit server, if known; see
+	// gerritServerVersion.
+	gerritVersionOnce sync.Once
+	gerritVersion     gerritVersion
+	gerritVersionOK   bool
+
+	// githubToken is the GitHub token or password, kept to recognise
+	// tokens which passed checkGitHubToken before.
+	githubToken string
+
+	// tokenChecks records the result of checkGitHubToken per repository
+	// and set of scopes.
+	tokenChecksMu sync.Mutex
+	tokenChecks   map[string]error
+
+	// payloadChecks records the result of checkPayloadVersion per
+	// repository, as builds may be triggered concurrently.
+	payloadChecksMu sync.Mutex
+	payloadChecks   map[string]payloadCheck
 }
 
 // loadConfig loads the repository configuration from codereview.cfg, using
@@ -85,18 +161,38 @@ func loadConfig(ctx context.Context) (*config, error) {
 		return nil, fmt.Errorf("failed to determine git root: %w", err)
 	}
 
-	cfg, err := codereviewcfg.Config(strings.TrimSpace(gitRoot))
+	res.gitRoot = strings.TrimSpace(gitRoot)
+	cfg, err := codereviewcfg.Config(res.gitRoot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load codereview config: %v", err)
 	}
+	warnings, err := validateConfig("codereview", "codereview.cfg", cfg)
+	printConfigWarnings(warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	// When running as a daemon, reuse a previously loaded config along with
+	// its authenticated clients.
+	cacheKey := configCacheKey(res.gitRoot, cfg)
+	if cached := cachedConfig(cacheKey); cached != nil {
+		return cached, nil
+	}
 
 	gerritURL := cfg["gerrit"]
 	if gerritURL == "" {
 		return nil, fmt.Errorf("missing Gerrit server in codereview config")
 	}
-	res.gerritURL, err = codereviewcfg.GerritURLToServer(gerritURL)
+	gerritServer, err := codereviewcfg.ParseGerritURL(gerritURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to derived Gerrit server from %v: %v", gerritURL, err)
+		return nil, fmt.Errorf("failed to derive Gerrit server from %v: %v", gerritURL, err)
+	}
+	res.gerritURL = gerritServer.URL
+	res.gerritProjectName = gerritServer.Project
+	// An explicit project takes precedence, for repositories whose Gerrit
+	// project does not match their GitHub repository.
+	if project := cfg["gerrit-project"]; project != "" {
+		res.gerritProjectName = project
 	}
 
 	githubURL := cfg["github"]
@@ -121,36 +217,126 @@ func loadConfig(ctx context.Context) (*config, error) {
 		}
 	}
 
-	// Prefer the manual env vars if both are set.
+	for k, v := range cfg {
+		if name, ok := strings.CutPrefix(k, dependentKeyPrefix); ok {
+			if res.dependents == nil {
+				res.dependents = make(map[string]string)
+			}
+			res.dependents[name] = v
+		}
+	}
+
+	res.freeze = cfg[freezeKey]
+	res.activityFeed = cfg[activityFeedKey]
+
+	res.importTrailers = provenanceTrailers
+	if v, ok := cfg[importTrailersKey]; ok {
+		res.importTrailers = nil
+		for _, key := range strings.Split(v, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				res.importTrailers = append(res.importTrailers, key)
+			}
+		}
+	}
+
+	// Prefer the manual env vars if both are set. A token on its own is also
+	// sufficient, as is typically the case in CI where only GITHUB_TOKEN is
+	// available; see githubLogin for how the username is then determined.
 	githubUser := os.Getenv("GITHUB_USER")
 	githubPassword := os.Getenv("GITHUB_PAT")
+	res.githubCredentials = "GITHUB_USER and GITHUB_PAT"
 	if githubUser == "" || githubPassword == "" {
-		githubUser, githubPassword, err = gitCredentials(ctx, githubURL)
-		if err != nil {
-			return nil, fmt.Errorf("configure a git credential helper or set GITHUB_USER and GITHUB_PAT")
+		githubUser = ""
+		res.githubCredentials = "GITHUB_PAT"
+		if githubPassword == "" {
+			githubPassword = os.Getenv("GITHUB_TOKEN")
+			res.githubCredentials = "GITHUB_TOKEN"
+		}
+		if githubPassword == "" {
+			res.githubCredentials = "git credential helper"
+			githubUser, githubPassword, err = gitCredentials(ctx, githubURL)
+			if err != nil || githubPassword == "" {
+				return nil, fmt.Errorf("configure a git credential helper or set GITHUB_USER and GITHUB_PAT (or just GITHUB_TOKEN)")
+			}
+		}
+	}
+	var githubHTTP *http.Client
+	if githubUser != "" {
+		githubAuth := github.BasicAuthTransport{Username: githubUser, Password: githubPassword}
+		githubHTTP = githubAuth.Client()
+	} else {
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: githubPassword})
+		githubHTTP = oauth2.NewClient(ctx, ts)
+	}
+	githubHTTP.Transport = newReadOnlyTransport(githubHTTP.Transport)
+	res.githubClient = github.NewClient(githubHTTP)
+	res.githubUser = githubUser
+	res.githubToken = githubPassword
+	if debug {
+		if login, err := res.githubLogin(ctx); err == nil {
+			debugf("authenticated to GitHub as %s\n", login)
 		}
 	}
-	githubAuth := github.BasicAuthTransport{Username: githubUser, Password: githubPassword}
-	res.githubClient = github.NewClient(githubAuth.Client())
 
 	// Prefer the manual env vars if both are set.
 	gerritUser := os.Getenv("GERRIT_USER")
 	gerritPassword := os.Getenv("GERRIT_PASSWORD")
+	res.gerritCredentials = "GERRIT_USER and GERRIT_PASSWORD"
 	if gerritUser == "" || gerritPassword == "" {
+		res.gerritCredentials = "git credential helper"
 		gerritUser, gerritPassword, err = gitCredentials(ctx, gerritURL)
 		if err != nil {
 			return nil, fmt.Errorf("configure a git credential helper or set GERRIT_USER and GERRIT_PASSWORD")
 		}
 	}
-	res.gerritClient, err = gerrit.NewClient(res.gerritURL, nil)
+	res.gerritClient, err = gerrit.NewClient(res.gerritURL, &http.Client{
+		Transport: newReadOnlyTransport(newMaintenanceTransport(http.DefaultTransport)),
+	})
 	if err != nil {
 		return nil, err
 	}
 	res.gerritClient.Authentication.SetBasicAuth(gerritUser, gerritPassword)
 
+	storeConfig(cacheKey, &res)
 	return &res, nil
 }
 
+// gerritProject returns the name of the Gerrit project for the repository.
+// It is taken from the gerrit-project entry in the codereview config, or from
+// the Gerrit URL when that includes a project, and is otherwise assumed to
+// match the GitHub repository.
+func (c *config) gerritProject() string {
+	if c.gerritProjectName != "" {
+		return c.gerritProjectName
+	}
+	return c.githubOwner + "/" + c.githubRepo
+}
+
+// changeURL returns the URL of the Gerrit web UI page for CL number cl.
+func (c *config) changeURL(cl int) string {
+	return fmt.Sprintf("%sc/%s/+/%d", c.gerritURL, c.gerritProject(), cl)
+}
+
+// projectChangeURL is like changeURL, but for a change which may belong to a
+// project other than that of the repository, such as the unity repository.
+func (c *config) projectChangeURL(ch *gerrit.ChangeInfo) string {
+	return fmt.Sprintf("%sc/%s/+/%d", c.gerritURL, ch.Project, ch.Number)
+}
+
+// githubLogin returns the login of the authenticated GitHub user, querying
+// the API for it when only a token was configured.
+func (c *config) githubLogin(ctx context.Context) (string, error) {
+	if c.githubUser != "" {
+		return c.githubUser, nil
+	}
+	u, _, err := c.githubClient.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine GitHub user for token: %w", err)
+	}
+	c.githubUser = u.GetLogin()
+	return c.githubUser, nil
+}
+
 func gitCredentials(ctx context.Context, repoURL string) (username, password string, _ error) {
 	// For example:
 	//
@@ -206,11 +392,29 @@ func gitCredentials(ctx context.Context, repoURL string) (username, password str
 	return username, password, nil
 }
 
-func (c *config) triggerRepositoryDispatch(owner, repo string, payload github.DispatchRequestOptions) error {
+func (c *config) triggerRepositoryDispatch(ctx context.Context, owner, repo string, payload github.DispatchRequestOptions) error {
+	if dryRun {
+		return printDispatch(os.Stdout, owner, repo, payload)
+	}
+	if err := c.checkGitHubToken(ctx, owner, repo, "repo"); err != nil {
+		return err
+	}
+	if err := c.checkPayloadVersion(ctx, owner, repo); err != nil {
+		return err
+	}
+	var p repositoryDispatchPayload
+	if payload.ClientPayload != nil {
+		if err := json.Unmarshal(*payload.ClientPayload, &p); err != nil {
+			return fmt.Errorf("failed to decode dispatch payload: %v", err)
+		}
+	}
+	if err := c.checkPayloadEventType(ctx, owner, repo, eventType(p.Type)); err != nil {
+		return err
+	}
 	debugf("triggerRepositoryDispatch in %s/%s with payload:\n%s\n", owner, repo, payload.ClientPayload)
-	_, resp, err := c.githubClient.Repositories.Dispatch(context.Background(), owner, repo, payload)
+	_, resp, err := c.githubClient.Repositories.Dispatch(ctx, owner, repo, payload)
 	if err != nil {
-		return fmt.Errorf("failed to send dispatch event: %v", err)
+		return fmt.Errorf("failed to send dispatch event: %w", err)
 	}
 	if resp.StatusCode/100 != 2 {
 		body, err := io.ReadAll(resp.Body)
@@ -219,6 +423,13 @@ func (c *config) triggerRepositoryDispatch(owner, repo string, payload github.Di
 		}
 		return fmt.Errorf("dispatch call did not succeed; status code %v\n%s", resp.StatusCode, body)
 	}
+	recordDispatch(owner, repo, payload)
+	// The dispatch results in a new run with the same title, and a trybot
+	// dispatch in a new run in the trybot repository.
+	forgetRun(owner, repo, payload.EventType)
+	if owner == c.githubOwner && repo == c.githubRepo && p.Type == string(eventTypeTrybot) {
+		forgetRun(owner, c.trybotRepo(), trybotRunKey(p.CL, p.Patchset))
+	}
 	return nil
 }
 
@@ -234,3 +445,132 @@ func buildDispatchPayload(msg string, payload interface{}) (ro github.DispatchRe
 
 	return
 }
+
+// defaultBranchFile returns the default branch of the GitHub repository
+// owner/repo along with the content of the file at path on that branch. The
+// content is empty if the file does not exist.
+func (c *config) defaultBranchFile(ctx context.Context, owner, repo, path string) (branch, content string, _ error) {
+	r, _, err := c.githubClient.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get repository %s/%s: %w", owner, repo, err)
+	}
+	branch = r.GetDefaultBranch()
+	file, _, resp, err := c.githubClient.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return branch, "", nil
+		}
+		return "", "", fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if content, err = file.GetContent(); err != nil {
+		return "", "", fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return branch, content, nil
+}
+
+// parseDuration is like time.ParseDuration, but additionally accepts a whole
+// number of days such as "30d".
+func parseDuration(s string) (time.Duration, error) {
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		days, err := strconv.Atoi(n)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// resolveChangeID returns an identifier for the change with the given
+// Change-Id in the Gerrit project of the repository. Gerrit resolves a bare
+// Change-Id across all projects, so one which is shared with a change in
+// another project, as happens for forks and subprojects, would otherwise be
+// ambiguous. A commit hash, such as one copied from CI logs, is resolved to
+// the change with a patchset for that commit; see changeForCommit. Other forms
+// of change identifier are returned unchanged.
+func (c *config) resolveChangeID(id string) (string, error) {
+	if looksLikeCommit(id) {
+		cl, _, err := c.changeForCommit(id)
+		return cl, err
+	}
+	if !strings.HasPrefix(id, "I") || strings.Contains(id, "~") {
+		return id, nil
+	}
+	changes, _, err := c.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s change:%s", c.gerritProject(), id)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query change %s: %w", id, err)
+	}
+	switch n := len(*changes); n {
+	case 0:
+		return "", fmt.Errorf("no change %s found in project %s", id, c.gerritProject())
+	case 1:
+		return strconv.Itoa((*changes)[0].Number), nil
+	default:
+		var cls []string
+		for _, ch := range *changes {
+			cls = append(cls, fmt.Sprintf("%d (%s)", ch.Number, ch.Branch))
+		}
+		return "", fmt.Errorf("change %s is ambiguous in project %s; use one of the CL numbers %s", id, c.gerritProject(), strings.Join(cls, ", "))
+	}
+}
+
+// rxCommit matches full or abbreviated commit hashes. Hashes consisting only
+// of digits are not matched, as they cannot be told apart from CL numbers.
+var rxCommit = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// looksLikeCommit reports whether s is a full or abbreviated commit hash
+// rather than a CL number.
+func looksLikeCommit(s string) bool {
+	return rxCommit.MatchString(s) && strings.ContainsAny(s, "abcdef")
+}
+
+// changeForCommit returns the CL number of the change in the Gerrit project
+// of the repository which has a patchset for commit, a full or abbreviated
+// commit hash, along with the full hash of that patchset.
+func (c *config) changeForCommit(commit string) (cl, revision string, _ error) {
+	changes, _, err := c.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s commit:%s", c.gerritProject(), commit)},
+		},
+		ChangeOptions: gerrit.ChangeOptions{
+			AdditionalFields: []string{"ALL_REVISIONS"},
+		},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to query changes with commit %s: %w", commit, err)
+	}
+	switch n := len(*changes); n {
+	case 0:
+		return "", "", fmt.Errorf("no change with commit %s found in project %s", commit, c.gerritProject())
+	case 1:
+	default:
+		var cls []string
+		for _, ch := range *changes {
+			cls = append(cls, strconv.Itoa(ch.Number))
+		}
+		return "", "", fmt.Errorf("commit %s is ambiguous in project %s; use one of the CL numbers %s", commit, c.gerritProject(), strings.Join(cls, ", "))
+	}
+	ch := (*changes)[0]
+	for hash := range ch.Revisions {
+		if strings.HasPrefix(hash, commit) {
+			revision = hash
+		}
+	}
+	return strconv.Itoa(ch.Number), revision, nil
+}
+
+// firstLine returns the first line of s, such as an error message, for use
+// in tabular output.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// gerritTime formats t for use in Gerrit search operators such as after:.
+func gerritTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/cue-lang/contrib-tools/internal/gitcmd"
+	"github.com/spf13/cobra"
+)
+
+// vetChecksFile is the path, relative to the root of the repository, of the
+// file configuring the checks run by vet.
+const vetChecksFile = ".github/cueckoo-vet.cue"
+
+// vetCheck is a check run by vet.
+type vetCheck struct {
+	// Name identifies the check in the report.
+	Name string `json:"name"`
+
+	// Run is the command to run, and its arguments.
+	Run []string `json:"run"`
+
+	// FailOnOutput means that the check fails if the command prints
+	// anything, as is the case for gofmt -l.
+	FailOnOutput bool `json:"failOnOutput,omitempty"`
+
+	// Drift means that the command is a generator, and that the check fails
+	// if running it modifies any files.
+	Drift bool `json:"drift,omitempty"`
+}
+
+// defaultVetChecks are the checks run by vet if the repository does not
+// configure any.
+var defaultVetChecks = []vetCheck{
+	{Name: "gofmt", Run: []string{"gofmt", "-l", "."}, FailOnOutput: true},
+	{Name: "go vet", Run: []string{"go", "vet", "./..."}},
+	{Name: "go generate", Run: []string{"go", "generate", "./..."}, Drift: true},
+	{Name: "cue fmt", Run: []string{"cue", "fmt", "./..."}, Drift: true},
+}
+
+// newVetCmd creates a new vet command
+func newVetCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vet",
+		Short: "run the project's checks against a CL or the working tree",
+		Long: `
+Usage of vet:
+
+	vet [CL]
+
+vet runs the mechanical checks which CI runs, such as gofmt, go vet and checks
+that generated files are up to date, and prints a report. This avoids trybot
+round-trips for failures which are easily caught locally.
+
+When a CL number or Change-Id is given, the latest patchset of the CL is
+fetched from Gerrit. Otherwise the working tree, including any uncommitted
+changes to tracked files, is checked. Either way, the checks are run in a
+temporary git worktree, leaving the working tree untouched.
+
+The checks are read from ` + vetChecksFile + ` in the repository if it exists,
+which is a CUE file of the form:
+
+	checks: [{
+		name: "gofmt"
+		run: ["gofmt", "-l", "."]
+		failOnOutput: true
+	}, {
+		name: "go generate"
+		run: ["go", "generate", "./..."]
+		drift: true
+	}]
+
+A check fails if its command fails, or if it prints anything when failOnOutput
+is set, or if it modifies any files when drift is set. Without the file, vet
+runs gofmt, go vet, go generate and cue fmt.
+`,
+		RunE:              mkRunE(c, vetDef),
+		ValidArgsFunction: completeChanges(1),
+	}
+	return cmd
+}
+
+type vetResult struct {
+	check  vetCheck
+	err    error
+	output string
+}
+
+func vetDef(cmd *Command, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("expected at most one CL")
+	}
+	ctx := cmd.Context()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	checks := defaultVetChecks
+	if fn := filepath.Join(cfg.gitRoot, vetChecksFile); fileExists(fn) {
+		var data struct {
+			Checks []vetCheck `json:"checks"`
+		}
+		if err := loadCUEFile(ctx, fn, &data); err != nil {
+			return err
+		}
+		checks = data.Checks
+	}
+
+	var rev string
+	if len(args) == 1 {
+		rev, err = cfg.fetchChange(ctx, args[0])
+	} else {
+		rev, err = workingTreeCommit(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "cueckoo-vet-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if _, err := run(ctx, "git", "worktree", "add", "--detach", dir, rev); err != nil {
+		return err
+	}
+	defer run(context.Background(), "git", "worktree", "remove", "--force", dir)
+
+	var results []vetResult
+	var prog *progress
+	if len(checks) > 1 {
+		prog = newProgress(os.Stderr, "ran", len(checks))
+	}
+	for _, check := range checks {
+		prog.begin(check.Name)
+		output, err := runVetCheck(ctx, dir, check)
+		prog.end(check.Name, err)
+		results = append(results, vetResult{check: check, err: err, output: output})
+	}
+	prog.finish()
+	return printVetReport(cmd, results)
+}
+
+// runVetCheck runs check in dir, returning its output and whether it failed.
+func runVetCheck(ctx context.Context, dir string, check vetCheck) (string, error) {
+	if len(check.Run) == 0 {
+		return "", fmt.Errorf("check has no command")
+	}
+	c := exec.CommandContext(ctx, check.Run[0], check.Run[1:]...)
+	c.Dir = dir
+	out, err := c.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	var execErr *exec.Error
+	switch {
+	case errors.As(err, &execErr):
+		return "", fmt.Errorf("%s not found", check.Run[0])
+	case err != nil:
+		return output, fmt.Errorf("failed: %v", err)
+	case check.FailOnOutput && output != "":
+		return output, fmt.Errorf("unexpected output")
+	}
+	if check.Drift {
+		drift, err := gitIn(ctx, dir, "status", "--porcelain")
+		if err != nil {
+			return "", err
+		}
+		if drift != "" {
+			// Restore the tree for the following checks.
+			if _, err := gitIn(ctx, dir, "reset", "--hard"); err != nil {
+				return "", err
+			}
+			if _, err := gitIn(ctx, dir, "clean", "-fdq"); err != nil {
+				return "", err
+			}
+			return strings.TrimRight(drift, "\n"), fmt.Errorf("modified files")
+		}
+	}
+	return output, nil
+}
+
+func printVetReport(cmd *Command, results []vetResult) error {
+	w := cmd.OutOrStdout()
+	tw := newTableWriter(w)
+	fmt.Fprintln(tw, "CHECK\tRESULT\tDETAILS")
+	failed := 0
+	for _, r := range results {
+		result, details := "ok", ""
+		if r.err != nil {
+			failed++
+			result, details = "failed", r.err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.check.Name, result, details)
+	}
+	tw.Flush()
+	for _, r := range results {
+		if r.err != nil && r.output != "" {
+			fmt.Fprintf(w, "\n%s:\n%s\n", r.check.Name, r.output)
+		}
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed < len(results):
+		return &partialFailureError{failed: failed, total: len(results)}
+	default:
+		return fmt.Errorf("all checks failed")
+	}
+}
+
+// fetchChange fetches the latest patchset of the change identified by id and
+// returns its commit hash.
+func (c *config) fetchChange(ctx context.Context, id string) (string, error) {
+	id, err := c.resolveChangeID(id)
+	if err != nil {
+		return "", err
+	}
+	ch, _, err := c.gerritClient.Changes.GetChange(id, &gerrit.ChangeOptions{
+		AdditionalFields: []string{"CURRENT_REVISION"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get change %s: %w", id, err)
+	}
+	ref := ch.Revisions[ch.CurrentRevision].Ref
+	if _, err := run(ctx, "git", "fetch", "--quiet", c.gerritURL+c.gerritProject(), ref); err != nil {
+		return "", err
+	}
+	return ch.CurrentRevision, nil
+}
+
+// workingTreeCommit returns a commit with the current contents of the
+// tracked files in the working tree, without modifying the working tree or
+// any branch. It is HEAD if there are no uncommitted changes.
+func workingTreeCommit(ctx context.Context) (string, error) {
+	stash, err := run(ctx, "git", "stash", "create")
+	if err != nil {
+		return "", err
+	}
+	if stash = strings.TrimSpace(stash); stash != "" {
+		return stash, nil
+	}
+	return "HEAD", nil
+}
+
+// gitIn runs git with args in dir.
+func gitIn(ctx context.Context, dir string, args ...string) (string, error) {
+	if err := checkReadOnlyCommand("git", args...); err != nil {
+		return "", err
+	}
+	return gitcmd.New(dir).Run(ctx, args...)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cue-lang/contrib-tools/internal/codereviewcfg"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagWaitRefRef      flagName = "ref"
+	flagWaitRefRemote   flagName = "remote"
+	flagWaitRefTimeout  flagName = "timeout"
+	flagWaitRefInterval flagName = "interval"
+)
+
+// newWaitRefCmd creates a new wait-ref command
+func newWaitRefCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait-ref",
+		Short: "wait for a git ref to exist on Gerrit and print its commit",
+		Long: `
+Usage of wait-ref:
+
+	wait-ref --ref REF [--remote URL] [--timeout 5m] [--interval 5s]
+
+wait-ref polls the remote with git ls-remote until REF exists, such as
+refs/changes/52/551352/140, and prints the commit it points to. Gerrit
+replicates refs asynchronously, so a workflow dispatched for a new patchset
+can run before its ref is visible; wait-ref lets any workflow step wait for
+it, and then check out the resolved commit, such as with actions/checkout.
+
+When run in GitHub Actions, the commit is also written to $GITHUB_OUTPUT as
+the "sha" output of the step.
+
+The remote defaults to the Gerrit project of the current repository, as given
+by codereview.cfg. No credentials are needed for public projects.
+`,
+		RunE: mkRunE(c, waitRefDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagWaitRefRef, "", "the ref to wait for").Required().Validate(func(ref string) error {
+		if !strings.HasPrefix(ref, "refs/") {
+			return fmt.Errorf("must be a full ref such as refs/changes/52/551352/140")
+		}
+		return nil
+	})
+	flags.String(flagWaitRefRemote, "", "the git remote URL (default the Gerrit project)")
+	flags.String(flagWaitRefTimeout, "5m", "how long to wait for the ref").Duration()
+	flags.String(flagWaitRefInterval, "5s", "how long to wait between attempts").Duration()
+	return cmd
+}
+
+func waitRefDef(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("wait-ref does not take any arguments")
+	}
+	ref := flagWaitRefRef.String(cmd)
+	timeout, err := parseDuration(flagWaitRefTimeout.String(cmd))
+	if err != nil {
+		return err
+	}
+	interval, err := parseDuration(flagWaitRefInterval.String(cmd))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+	remote := flagWaitRefRemote.String(cmd)
+	if remote == "" {
+		if remote, err = gerritRemote(ctx); err != nil {
+			return err
+		}
+	}
+
+	sha, err := waitForRef(ctx, remote, ref, interval)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), sha)
+	if path := os.Getenv("GITHUB_OUTPUT"); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o666)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(f, "sha=%s\n", sha)
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gerritRemote returns the git URL of the Gerrit project of the current
+// repository. Unlike loadConfig, it needs no credentials, such that it
+// works in any workflow step.
+func gerritRemote(ctx context.Context) (string, error) {
+	gitRoot, err := run(ctx, "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine git root: %w", err)
+	}
+	cfg, err := codereviewcfg.Config(strings.TrimSpace(gitRoot))
+	if err != nil {
+		return "", fmt.Errorf("failed to load codereview config: %v", err)
+	}
+	server, err := codereviewcfg.ParseGerritURL(cfg["gerrit"])
+	if err != nil {
+		return "", err
+	}
+	project := server.Project
+	if p := cfg["gerrit-project"]; p != "" {
+		project = p
+	}
+	if project == "" {
+		owner, repo, err := codereviewcfg.GithubURLToParts(cfg["github"])
+		if err != nil {
+			return "", err
+		}
+		project = owner + "/" + repo
+	}
+	return server.URL + project, nil
+}
+
+// waitForRef polls remote every interval until ref exists, returning the
+// commit it points to, or an error once ctx is done.
+func waitForRef(ctx context.Context, remote, ref string, interval time.Duration) (string, error) {
+	for {
+		out, err := run(ctx, "git", "ls-remote", remote, ref)
+		if err == nil {
+			for _, line := range strings.Split(out, "\n") {
+				if sha, name, ok := strings.Cut(line, "\t"); ok && name == ref {
+					return sha, nil
+				}
+			}
+		} else if ctx.Err() == nil {
+			debugf("git ls-remote %s %s failed: %v\n", remote, ref, err)
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return "", fmt.Errorf("gave up waiting for %s in %s: %w", ref, remote, err)
+			}
+			return "", fmt.Errorf("gave up waiting for %s in %s", ref, remote)
+		case <-time.After(interval):
+		}
+	}
+}
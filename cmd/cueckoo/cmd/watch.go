@@ -0,0 +1,130 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+const (
+	flagWait        flagName = "wait"
+	flagWaitTimeout flagName = "wait-timeout"
+)
+
+// dispatchedRun is a workflow run which was requested by a repository
+// dispatch, to be waited for by a runWatcher.
+type dispatchedRun struct {
+	owner, repo string
+	title       string
+	dispatched  time.Time
+
+	// cl and patchset are set for trybot dispatches, whose outcome is that
+	// of the run in the trybot repository which the dispatched run relays
+	// to; see trybotRepo.
+	cl, patchset int
+}
+
+// runWatcher collects the runs dispatched by a command, such as runtrybot,
+// and waits for them to complete when run with --wait. It is safe for
+// concurrent use, as builds for multiple CLs are triggered concurrently.
+type runWatcher struct {
+	cfg  *config
+	mu   sync.Mutex
+	runs []dispatchedRun
+}
+
+// add records that the run with the display title was dispatched in
+// owner/repo at the time dispatched, unless this is a dry run.
+func (w *runWatcher) add(owner, repo, title string, dispatched time.Time) {
+	if dryRun {
+		// Nothing was dispatched, so there is nothing to wait for.
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.runs = append(w.runs, dispatchedRun{owner: owner, repo: repo, title: title, dispatched: dispatched})
+}
+
+// addTrybot records that a trybot run for patchset of CL, whose ref is given,
+// was dispatched at the time dispatched, unless this is a dry run. Waiting
+// for it follows the dispatch through to the trybot repository.
+func (w *runWatcher) addTrybot(ref string, cl, patchset int, dispatched time.Time) {
+	if dryRun {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.runs = append(w.runs, dispatchedRun{
+		owner:      w.cfg.githubOwner,
+		repo:       w.cfg.githubRepo,
+		title:      trybotRunTitle(ref),
+		dispatched: dispatched,
+		cl:         cl,
+		patchset:   patchset,
+	})
+}
+
+// wait waits for all the runs added to complete, up to timeout, writing a
+// line to out each time the status of a run changes. It returns an error if
+// any run did not succeed.
+func (w *runWatcher) wait(ctx context.Context, out io.Writer, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var (
+		wg     sync.WaitGroup
+		outMu  sync.Mutex
+		failed int
+	)
+	printf := func(format string, args ...any) {
+		outMu.Lock()
+		defer outMu.Unlock()
+		fmt.Fprintf(out, format, args...)
+	}
+	for _, r := range w.runs {
+		r := r
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			changed := func(run *github.WorkflowRun) {
+				printf("%s: %s %s\n", r.title, workflowRunState(run), run.GetHTMLURL())
+			}
+			run, err := w.cfg.watchDispatchedRun(ctx, r.owner, r.repo, r.title, r.dispatched, changed)
+			if err == nil && run.GetConclusion() == "success" && r.cl != 0 {
+				// The dispatch was relayed; follow it to the trybots.
+				run, err = w.cfg.watchTrybotRun(ctx, r.cl, r.patchset, r.dispatched, changed)
+			}
+			if err == nil && run.GetConclusion() != "success" {
+				err = fmt.Errorf("run %s concluded with %s", run.GetHTMLURL(), run.GetConclusion())
+			}
+			if err != nil {
+				printf("%s: %v\n", r.title, err)
+				outMu.Lock()
+				failed++
+				outMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d runs did not succeed", failed, len(w.runs))
+	}
+	return nil
+}
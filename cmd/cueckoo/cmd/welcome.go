@@ -0,0 +1,319 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/andygrunwald/go-gerrit"
+	"github.com/google/go-github/v53/github"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagWelcomeSince        flagName = "since"
+	flagWelcomeTemplates    flagName = "templates"
+	flagWelcomeContributors flagName = "contributors"
+
+	// welcomeTag is the Gerrit message tag used for welcome comments, such
+	// that we can tell which CLs have already been handled.
+	welcomeTag = "autogenerated:cueckoo-welcome"
+)
+
+var closesPRRegex = regexp.MustCompile(`(?m)^Closes #(\d+) as merged`)
+
+// welcomeTemplates are the Go text/template strings used by the welcome
+// command. They can be overridden via a CUE file passed to --templates whose
+// top-level fields are named after the JSON tags below.
+type welcomeTemplates struct {
+	// Comment is posted on the contributor's first merged CL.
+	Comment string `json:"comment"`
+
+	// PRComment is posted on the GitHub PR when the CL was imported.
+	PRComment string `json:"prComment"`
+
+	// Contributor is the line added to the contributors file.
+	Contributor string `json:"contributor"`
+}
+
+var defaultWelcomeTemplates = welcomeTemplates{
+	Comment: `Congratulations on your first merged contribution to {{.Project}}, {{.Name}}, and thank you!
+
+We hope to see more CLs from you in the future.`,
+	PRComment:   `Thank you for your first contribution, @{{.Login}}! This PR was imported and merged as {{.URL}}.`,
+	Contributor: `{{.Name}} <{{.Email}}>`,
+}
+
+// welcomeData is the data made available to the welcome templates.
+type welcomeData struct {
+	Project string
+	Name    string
+	Email   string
+	CL      int
+	URL     string
+	Subject string
+
+	// PR is only set for CLs imported from a GitHub PR. Login is the
+	// GitHub login of the owner, which is known for imported CLs, and for
+	// others when the owner is in the identity registry.
+	PR    int
+	Login string
+}
+
+// newWelcomeCmd creates a new welcome command
+func newWelcomeCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "welcome",
+		Short: "thank new contributors for their first merged CL",
+		Long: `
+Usage of welcome:
+
+	welcome [--since DURATION] [--templates FILE] [--contributors PATH]
+
+welcome looks for CLs merged within the --since window which are the first
+merged CL of their owner, and posts a welcome comment on each. If the CL was
+imported from a GitHub PR, a comment is also posted on the PR.
+
+If --contributors is provided, a CL is created which adds the new contributors
+to the file at that path in the repository, keeping it sorted.
+
+The text of the comments and contributor lines are Go templates. The defaults
+can be overridden by a CUE file passed to --templates, which may set any of
+the fields "comment", "prComment" and "contributor". The default comment can
+also be set for the repository by the "welcome" field of
+` + messagesFile + `.
+
+Each CL is only welcomed once, so welcome is suitable for running on a
+schedule with a --since window larger than the interval between runs.
+`,
+		RunE: mkRunE(c, welcomeDef),
+	}
+	flags := c.registerFlags(cmd)
+	flags.String(flagWelcomeSince, "24h", "how far back to look for merged CLs")
+	flags.String(flagWelcomeTemplates, "", "CUE file overriding the default templates")
+	flags.String(flagWelcomeContributors, "", "path of the contributors file to update in the repository")
+	return cmd
+}
+
+func welcomeDef(cmd *Command, args []string) error {
+	ctx := cmd.Context()
+	since, err := parseDuration(flagWelcomeSince.String(cmd))
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	msgs, err := loadBotMessages(ctx, cfg.gitRoot)
+	if err != nil {
+		return err
+	}
+	idents, err := loadIdentities(ctx, cfg.gitRoot)
+	if err != nil {
+		return err
+	}
+	tmpls := defaultWelcomeTemplates
+	tmpls.Comment = msgs.Welcome
+	if fn := flagWelcomeTemplates.String(cmd); fn != "" {
+		if err := loadCUEFile(ctx, fn, &tmpls); err != nil {
+			return err
+		}
+	}
+	comment, err := template.New("comment").Parse(tmpls.Comment)
+	if err != nil {
+		return fmt.Errorf("invalid comment template: %v", err)
+	}
+	prComment, err := template.New("prComment").Parse(tmpls.PRComment)
+	if err != nil {
+		return fmt.Errorf("invalid prComment template: %v", err)
+	}
+	contributor, err := template.New("contributor").Parse(tmpls.Contributor)
+	if err != nil {
+		return fmt.Errorf("invalid contributor template: %v", err)
+	}
+
+	changes, _, err := cfg.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s status:merged after:%q", cfg.gerritProject(), gerritTime(time.Now().Add(-since)))},
+		},
+		ChangeOptions: gerrit.ChangeOptions{
+			AdditionalFields: []string{"DETAILED_ACCOUNTS", "MESSAGES", "CURRENT_REVISION", "CURRENT_COMMIT"},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query merged CLs: %w", err)
+	}
+
+	var newContributors []string
+	for _, ch := range *changes {
+		if welcomed(ch) {
+			continue
+		}
+		first, err := cfg.isFirstMergedChange(ch)
+		if err != nil {
+			return err
+		}
+		if !first {
+			continue
+		}
+		data := welcomeData{
+			Project: cfg.gerritProject(),
+			Name:    ch.Owner.Name,
+			Email:   ch.Owner.Email,
+			CL:      ch.Number,
+			URL:     cfg.changeURL(ch.Number),
+			Subject: ch.Subject,
+		}
+		msg := ch.Revisions[ch.CurrentRevision].Commit.Message
+		if m := closesPRRegex.FindStringSubmatch(msg); m != nil {
+			data.PR, _ = strconv.Atoi(m[1])
+		}
+		if login, _, ok := idents.ByGerrit(ch.Owner.AccountID); ok {
+			data.Login = login
+		} else if login, _, ok := idents.ByEmail(ch.Owner.Email); ok {
+			data.Login = login
+		}
+		if data.PR != 0 {
+			pr, _, err := cfg.githubClient.PullRequests.Get(ctx, cfg.githubOwner, cfg.githubRepo, data.PR)
+			if err != nil {
+				return fmt.Errorf("failed to get PR %d: %w", data.PR, err)
+			}
+			data.Login = pr.GetUser().GetLogin()
+		}
+		body, err := execTemplate(comment, data)
+		if err != nil {
+			return err
+		}
+		var prBody string
+		if data.PR != 0 {
+			if prBody, err = execTemplate(prComment, data); err != nil {
+				return err
+			}
+		}
+		// Comment on the CL first, as its welcomeTag is what records that
+		// the CL was handled; a failure to comment on the PR afterwards
+		// then cannot lead to welcoming the contributor twice on the PR.
+		if _, _, err := cfg.gerritClient.Changes.SetReview(strconv.Itoa(ch.Number), "current", &gerrit.ReviewInput{
+			Message: body,
+			Tag:     welcomeTag,
+		}); err != nil {
+			return fmt.Errorf("failed to comment on CL %d: %w", ch.Number, err)
+		}
+		if data.PR != 0 {
+			if _, _, err := cfg.githubClient.Issues.CreateComment(ctx, cfg.githubOwner, cfg.githubRepo, data.PR, &github.IssueComment{Body: &prBody}); err != nil {
+				return fmt.Errorf("welcomed CL %d, but failed to comment on PR %d: %w", ch.Number, data.PR, err)
+			}
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "welcomed %s for CL %d\n", data.Name, ch.Number)
+
+		line, err := execTemplate(contributor, data)
+		if err != nil {
+			return err
+		}
+		newContributors = append(newContributors, line)
+	}
+
+	if path := flagWelcomeContributors.String(cmd); path != "" && len(newContributors) > 0 {
+		ch, err := cfg.addContributors(ctx, path, newContributors)
+		if err != nil {
+			return err
+		}
+		if ch != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "created %s to update %s\n", cfg.changeURL(ch.Number), path)
+		}
+	}
+	return nil
+}
+
+func execTemplate(t *template.Template, data any) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %v", t.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// welcomed reports whether ch already has a welcome comment.
+func welcomed(ch gerrit.ChangeInfo) bool {
+	for _, m := range ch.Messages {
+		if m.Tag == welcomeTag {
+			return true
+		}
+	}
+	return false
+}
+
+// isFirstMergedChange reports whether ch is the only merged change of its
+// owner in the project.
+func (c *config) isFirstMergedChange(ch gerrit.ChangeInfo) (bool, error) {
+	owned, _, err := c.gerritClient.Changes.QueryChanges(&gerrit.QueryChangeOptions{
+		QueryOptions: gerrit.QueryOptions{
+			Query: []string{fmt.Sprintf("project:%s owner:%d status:merged", c.gerritProject(), ch.Owner.AccountID)},
+			Limit: 2,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to query CLs of %s: %w", ch.Owner.Name, err)
+	}
+	return len(*owned) == 1, nil
+}
+
+// addContributors creates a CL which adds lines to the contributors file at
+// path on the default branch, keeping the file sorted. It returns nil if all
+// the lines were already present.
+func (c *config) addContributors(ctx context.Context, path string, lines []string) (*gerrit.ChangeInfo, error) {
+	branch, existing, err := c.defaultBranchFile(ctx, c.githubOwner, c.githubRepo, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep any header of comments or blank lines at the top of the file
+	// as-is, and sort the remainder.
+	var header, entries []string
+	if existing != "" {
+		for _, l := range strings.Split(strings.TrimSuffix(existing, "\n"), "\n") {
+			if len(entries) == 0 && (l == "" || strings.HasPrefix(l, "#")) {
+				header = append(header, l)
+				continue
+			}
+			entries = append(entries, l)
+		}
+	}
+	added := false
+	for _, l := range lines {
+		if !slicesContains(entries, l) {
+			entries = append(entries, l)
+			added = true
+		}
+	}
+	if !added {
+		return nil, nil
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i]) < strings.ToLower(entries[j])
+	})
+	content := strings.Join(append(header, entries...), "\n") + "\n"
+
+	msg := fmt.Sprintf("%s: add new contributors\n\nThis CL was generated by cueckoo welcome.\n", path)
+	return c.createFileChange(c.gerritProject(), branch, msg, path, []byte(content))
+}
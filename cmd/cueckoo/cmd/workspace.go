@@ -0,0 +1,168 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagRepoDir   flagName = "repo-dir"
+	flagWorkspace flagName = "workspace"
+
+	// workspaceKeyPrefix prefixes the user config keys which define named
+	// workspaces, e.g. "workspace.unity: ~/src/unity".
+	workspaceKeyPrefix = "workspace."
+)
+
+// addRepoDirFlags adds the global flags which select the repository that
+// cueckoo operates on, given the persistent flags of the root command.
+func addRepoDirFlags(flags *flagRegistry) {
+	flags.String(flagRepoDir, "", "run as if cueckoo was started in this directory")
+	flags.StringP(flagWorkspace, "w", "", "run in the directory of this named workspace")
+}
+
+// enterRepoDir changes the working directory as requested by the
+// --repo-dir and --workspace flags. Like git -C, any relative paths given to
+// the command are then interpreted relative to the new directory.
+func enterRepoDir(cmd *cobra.Command) error {
+	dir, _ := cmd.Flags().GetString(string(flagRepoDir))
+	name, _ := cmd.Flags().GetString(string(flagWorkspace))
+	switch {
+	case dir != "" && name != "":
+		return fmt.Errorf("--%s and --%s are mutually exclusive", flagRepoDir, flagWorkspace)
+	case name != "":
+		workspaces, err := loadWorkspaces()
+		if err != nil {
+			return err
+		}
+		if dir = workspaces[name]; dir == "" {
+			return fmt.Errorf("unknown workspace %q; see cueckoo workspace", name)
+		}
+	case dir == "":
+		return nil
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("failed to enter repository directory: %v", err)
+	}
+	return nil
+}
+
+// loadWorkspaces returns the named workspaces defined in the user config,
+// mapping names to directories.
+func loadWorkspaces() (map[string]string, error) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]string)
+	for k, v := range cfg {
+		if name, ok := strings.CutPrefix(k, workspaceKeyPrefix); ok {
+			res[name] = expandHome(v)
+		}
+	}
+	return res, nil
+}
+
+// expandHome expands a leading ~ in path to the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// newWorkspaceCmd creates a new workspace command
+func newWorkspaceCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "list or add named workspaces",
+		Long: `
+Usage of workspace:
+
+	workspace
+	workspace add NAME [DIR]
+
+Workspaces are named repository checkouts, such that cueckoo commands can be
+run against a repository other than the one containing the current directory
+using the global --workspace flag. For example, to trigger unity from a cue
+checkout while referring to the unity checkout:
+
+	cueckoo workspace add unity ~/src/unity
+	cueckoo -w unity ...
+
+The global --repo-dir flag does the same for a directory given directly.
+
+Without arguments, workspace lists the defined workspaces. workspace add
+defines NAME as DIR, which defaults to the root of the current repository.
+Workspaces are kept in the user config as "workspace.NAME" entries.
+`,
+		RunE: mkRunE(c, workspaceDef),
+	}
+	return cmd
+}
+
+func workspaceDef(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		workspaces, err := loadWorkspaces()
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(workspaces))
+		for name := range workspaces {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		tw := newTableWriter(cmd.OutOrStdout())
+		fmt.Fprintln(tw, "NAME\tDIRECTORY")
+		for _, name := range names {
+			fmt.Fprintf(tw, "%s\t%s\n", name, workspaces[name])
+		}
+		return tw.Flush()
+	}
+	if args[0] != "add" || len(args) < 2 || len(args) > 3 {
+		return fmt.Errorf("usage: workspace add NAME [DIR]")
+	}
+	name := args[1]
+	var dir string
+	if len(args) == 3 {
+		dir = expandHome(args[2])
+	} else {
+		root, err := run(cmd.Context(), "git", "rev-parse", "--show-toplevel")
+		if err != nil {
+			return fmt.Errorf("failed to determine git root: %w", err)
+		}
+		dir = strings.TrimSpace(root)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := setUserConfig(workspaceKeyPrefix+name, dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "workspace %s is %s\n", name, dir)
+	return nil
+}
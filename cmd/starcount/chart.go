@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// buckets maps the names accepted by -bucket to functions returning the start
+// of the bucket containing a time.
+var buckets = map[string]func(time.Time) time.Time{
+	"week": func(t time.Time) time.Time {
+		t = t.UTC().Truncate(24 * time.Hour)
+		// Weeks start on Monday.
+		return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	},
+	"month": func(t time.Time) time.Time {
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	},
+}
+
+// trajectory is the cumulative number of stargazers of the old and new repos
+// at the end of each time bucket.
+type trajectory struct {
+	buckets  []time.Time
+	old, new []int
+}
+
+// newTrajectory buckets the times at which the old and new repos were starred
+// into consecutive buckets, from the first star of either until the last.
+func newTrajectory(bucket func(time.Time) time.Time, old, new map[string]time.Time) *trajectory {
+	oldCounts, newCounts := make(map[time.Time]int), make(map[time.Time]int)
+	var first, last time.Time
+	count := func(counts map[time.Time]int, stars map[string]time.Time) {
+		for _, t := range stars {
+			b := bucket(t)
+			counts[b]++
+			if first.IsZero() || b.Before(first) {
+				first = b
+			}
+			if b.After(last) {
+				last = b
+			}
+		}
+	}
+	count(oldCounts, old)
+	count(newCounts, new)
+
+	tr := &trajectory{}
+	if first.IsZero() {
+		return tr
+	}
+	var oldTotal, newTotal int
+	// Step by a day and rely on bucket to skip to the next bucket, which
+	// works for buckets of any length.
+	for b := first; !b.After(last); {
+		oldTotal += oldCounts[b]
+		newTotal += newCounts[b]
+		tr.buckets = append(tr.buckets, b)
+		tr.old = append(tr.old, oldTotal)
+		tr.new = append(tr.new, newTotal)
+		next := b
+		for bucket(next).Equal(b) {
+			next = next.AddDate(0, 0, 1)
+		}
+		b = bucket(next)
+	}
+	return tr
+}
+
+func (tr *trajectory) max() int {
+	m := 1
+	for i := range tr.buckets {
+		if tr.old[i] > m {
+			m = tr.old[i]
+		}
+		if tr.new[i] > m {
+			m = tr.new[i]
+		}
+	}
+	return m
+}
+
+// writeCSV writes the trajectory as CSV with a header line.
+func (tr *trajectory) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "old", "new"})
+	for i, b := range tr.buckets {
+		cw.Write([]string{b.Format(time.DateOnly), strconv.Itoa(tr.old[i]), strconv.Itoa(tr.new[i])})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// asciiHeight is the number of rows of the ASCII chart.
+const asciiHeight = 20
+
+// writeASCII writes a chart of the trajectory using one column per bucket,
+// marking the old repo with 'o', the new repo with 'n', and both with '*'.
+func (tr *trajectory) writeASCII(w io.Writer, oldName, newName string) {
+	if len(tr.buckets) == 0 {
+		fmt.Fprintln(w, "no stars")
+		return
+	}
+	top := tr.max()
+	row := func(n int) int {
+		return n * (asciiHeight - 1) / top
+	}
+	width := len(strconv.Itoa(top))
+	for r := asciiHeight - 1; r >= 0; r-- {
+		var line strings.Builder
+		for i := range tr.buckets {
+			o, n := row(tr.old[i]) == r, row(tr.new[i]) == r
+			switch {
+			case o && n:
+				line.WriteByte('*')
+			case o:
+				line.WriteByte('o')
+			case n:
+				line.WriteByte('n')
+			default:
+				line.WriteByte(' ')
+			}
+		}
+		label := ""
+		if r == asciiHeight-1 {
+			label = strconv.Itoa(top)
+		} else if r == 0 {
+			label = "0"
+		}
+		fmt.Fprintf(w, "%*s |%s\n", width, label, strings.TrimRight(line.String(), " "))
+	}
+	fmt.Fprintf(w, "%*s +%s\n", width, "", strings.Repeat("-", len(tr.buckets)))
+	first, last := tr.buckets[0].Format(time.DateOnly), tr.buckets[len(tr.buckets)-1].Format(time.DateOnly)
+	pad := len(tr.buckets) - len(first) - len(last)
+	if pad < 1 {
+		pad = 1
+	}
+	fmt.Fprintf(w, "%*s  %s%s%s\n", width, "", first, strings.Repeat(" ", pad), last)
+	fmt.Fprintf(w, "\no: %s, n: %s, *: both\n", oldName, newName)
+}
+
+// writeSVG writes a line chart of the trajectory.
+func (tr *trajectory) writeSVG(w io.Writer, oldName, newName string) error {
+	const (
+		width, height = 800, 400
+		margin        = 50
+	)
+	top := tr.max()
+	x := func(i int) float64 {
+		if len(tr.buckets) < 2 {
+			return margin
+		}
+		return margin + float64(i)*(width-2*margin)/float64(len(tr.buckets)-1)
+	}
+	y := func(n int) float64 {
+		return height - margin - float64(n)*(height-2*margin)/float64(top)
+	}
+	polyline := func(counts []int, color string) string {
+		var pts []string
+		for i, n := range counts {
+			pts = append(pts, fmt.Sprintf("%.1f,%.1f", x(i), y(n)))
+		}
+		return fmt.Sprintf(`<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, color, strings.Join(pts, " "))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", margin, height-margin, width-margin, height-margin)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", margin, margin, margin, height-margin)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n", margin-5, margin+4, top)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">0</text>`+"\n", margin-5, height-margin+4)
+	if len(tr.buckets) > 0 {
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n", margin, height-margin+18, tr.buckets[0].Format(time.DateOnly))
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", width-margin, height-margin+18, tr.buckets[len(tr.buckets)-1].Format(time.DateOnly))
+		b.WriteString(polyline(tr.old, "#999999") + "\n")
+		b.WriteString(polyline(tr.new, "#3b7dd8") + "\n")
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#999999">%s</text>`+"\n", margin+10, margin-20, html.EscapeString(oldName))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#3b7dd8">%s</text>`+"\n", margin+10, margin-6, html.EscapeString(newName))
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
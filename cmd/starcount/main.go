@@ -4,40 +4,68 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/shurcooL/graphql"
+	"github.com/google/go-github/v53/github"
 	"golang.org/x/oauth2"
 	"golang.org/x/sync/errgroup"
 )
 
 var (
-	fOldRepo = flag.String("old", "cuelang/cue", "old repo")
-	fNewRepo = flag.String("new", "cue-lang/cue", "old repo")
+	fOldRepo     = flag.String("old", "cuelang/cue", "old repo")
+	fNewRepo     = flag.String("new", "cue-lang/cue", "new repo")
+	fConcurrency = flag.Int("concurrency", 8, "number of pages of stargazers to fetch at once")
+	fBucket      = flag.String("bucket", "month", "time bucket for the trajectory: week or month")
+	fCSV         = flag.String("csv", "", "write the star trajectory as CSV to this file")
+	fSVG         = flag.String("svg", "", "write a chart of the star trajectory as SVG to this file")
+	fChart       = flag.Bool("chart", false, "print an ASCII chart of the star trajectory")
+)
+
+const (
+	// perPage is the maximum page size of the stargazers endpoint.
+	perPage = 100
+
+	// maxPages is the number of pages beyond which the stargazers endpoint
+	// refuses to paginate.
+	maxPages = 400
+
+	// pageAttempts is how many times fetching a page is attempted, as long
+	// runs tend to hit the odd timeout or server error.
+	pageAttempts = 3
 )
 
 func main() {
 	flag.Parse()
+	bucket, ok := buckets[*fBucket]
+	if !ok {
+		log.Fatalf("unknown bucket %q; use week or month", *fBucket)
+	}
 
 	ctx := context.Background()
 	src := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: os.Getenv("GITHUB_PAT")},
 	)
-	httpClient := oauth2.NewClient(ctx, src)
-	client := graphql.NewClient("https://api.github.com/graphql", httpClient)
-
-	oldGazers := make(map[string]bool)
-	newGazers := make(map[string]bool)
-	eg, _ := errgroup.WithContext(ctx)
-	eg.Go(func() error {
-		return query(client, *fOldRepo, oldGazers)
+	client := github.NewClient(oauth2.NewClient(ctx, src))
+
+	p := newProgress()
+	var oldGazers, newGazers map[string]time.Time
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() (err error) {
+		oldGazers, err = query(ctx, client, *fOldRepo, p)
+		return err
 	})
-	eg.Go(func() error {
-		return query(client, *fNewRepo, newGazers)
+	eg.Go(func() (err error) {
+		newGazers, err = query(ctx, client, *fNewRepo, p)
+		return err
 	})
-	if err := eg.Wait(); err != nil {
+	err := eg.Wait()
+	p.done()
+	if err != nil {
 		log.Fatalf("failed to query gazers: %v", err)
 	}
 	allGazers := make(map[string]bool)
@@ -50,53 +78,143 @@ func main() {
 	fmt.Printf("old stargazers: %v\n", len(oldGazers))
 	fmt.Printf("new stargazers: %v\n", len(newGazers))
 	fmt.Printf("all stargazers: %v\n", len(allGazers))
-}
 
-func query(client *graphql.Client, repo string, gazers map[string]bool) error {
-	parts := strings.Split(repo, "/")
-	if len(parts) != 2 {
-		return fmt.Errorf("repo not expected format: %q", repo)
-	}
-	owner, repo := parts[0], parts[1]
-	var after *graphql.String
-	for {
-		var q stargazersQuery
-		args := map[string]interface{}{
-			"owner": graphql.String(owner),
-			"repo":  graphql.String(repo),
-			"after": after,
+	if *fCSV == "" && *fSVG == "" && !*fChart {
+		return
+	}
+	t := newTrajectory(bucket, oldGazers, newGazers)
+	if *fCSV != "" {
+		if err := writeFile(*fCSV, t.writeCSV); err != nil {
+			log.Fatalf("failed to write CSV: %v", err)
 		}
-		if err := client.Query(context.Background(), &q, args); err != nil {
-			return fmt.Errorf("query failed: %v", err)
+	}
+	if *fSVG != "" {
+		if err := writeFile(*fSVG, func(w io.Writer) error {
+			return t.writeSVG(w, *fOldRepo, *fNewRepo)
+		}); err != nil {
+			log.Fatalf("failed to write SVG: %v", err)
 		}
-		for _, e := range q.Repository.Stargazers.Edges {
-			gazers[string(e.Node.Login)] = true
-			after = &e.Cursor
+	}
+	if *fChart {
+		fmt.Println()
+		t.writeASCII(os.Stdout, *fOldRepo, *fNewRepo)
+	}
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// query returns the logins of the stargazers of repo, along with when they
+// starred it. The number of pages is known up front from the stargazer count,
+// so the pages are fetched concurrently rather than by following cursors one
+// page at a time.
+func query(ctx context.Context, client *github.Client, repo string, p *progress) (map[string]time.Time, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return nil, fmt.Errorf("repo not expected format: %q", repo)
+	}
+	r, _, err := client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %v", repo, err)
+	}
+	pages := (r.GetStargazersCount() + perPage - 1) / perPage
+	if pages > maxPages {
+		return nil, fmt.Errorf("%s has %d stargazers; GitHub only lists the first %d", repo, r.GetStargazersCount(), maxPages*perPage)
+	}
+	p.add(repo, pages)
+
+	results := make([][]*github.Stargazer, pages)
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(*fConcurrency)
+	for i := range results {
+		i := i
+		eg.Go(func() error {
+			gazers, err := fetchPage(ctx, client, owner, name, i+1)
+			if err != nil {
+				return fmt.Errorf("failed to get page %d of stargazers of %s: %v", i+1, repo, err)
+			}
+			results[i] = gazers
+			p.inc(repo)
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	gazers := make(map[string]time.Time)
+	for _, page := range results {
+		for _, g := range page {
+			gazers[g.GetUser().GetLogin()] = g.GetStarredAt().Time
 		}
-		if !q.Repository.Stargazers.PageInfo.HasNextPage {
-			break
+	}
+	return gazers, nil
+}
+
+// fetchPage fetches a page of stargazers, retrying on failure.
+func fetchPage(ctx context.Context, client *github.Client, owner, repo string, page int) ([]*github.Stargazer, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		var gazers []*github.Stargazer
+		gazers, _, err = client.Activity.ListStargazers(ctx, owner, repo, &github.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err == nil || attempt == pageAttempts || ctx.Err() != nil {
+			return gazers, err
 		}
+		time.Sleep(time.Duration(attempt) * time.Second)
 	}
-	return nil
 }
 
-// discussionsQuery is the query that gives us discussions + their comments + the
-// comments' replies
-type stargazersQuery struct {
-	Repository struct {
-		ID         graphql.String
-		Stargazers struct {
-			PageInfo PageInfo
-			Edges    []*struct {
-				Cursor graphql.String
-				Node   struct {
-					Login graphql.String
-				}
-			}
-		} `graphql:"stargazers(first:100, after:$after)"`
-	} `graphql:"repository(name: $repo, owner: $owner)"`
+// progress reports the number of pages fetched per repo on stderr.
+type progress struct {
+	mu      sync.Mutex
+	repos   []string
+	fetched map[string]int
+	total   map[string]int
 }
 
-type PageInfo struct {
-	HasNextPage graphql.Boolean
+func newProgress() *progress {
+	return &progress{fetched: make(map[string]int), total: make(map[string]int)}
+}
+
+func (p *progress) add(repo string, pages int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.repos = append(p.repos, repo)
+	p.total[repo] = pages
+	p.print()
+}
+
+func (p *progress) inc(repo string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fetched[repo]++
+	p.print()
+}
+
+// print must be called with p.mu held.
+func (p *progress) print() {
+	var parts []string
+	for _, repo := range p.repos {
+		parts = append(parts, fmt.Sprintf("%s: %d/%d pages", repo, p.fetched[repo], p.total[repo]))
+	}
+	fmt.Fprintf(os.Stderr, "\r%s", strings.Join(parts, ", "))
+}
+
+func (p *progress) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.repos) > 0 {
+		fmt.Fprintln(os.Stderr)
+	}
 }
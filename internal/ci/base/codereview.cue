@@ -12,9 +12,20 @@ import (
 // file that drives golang.org/x/review/git-codereview. This config
 // file is also used by github.com/cue-lang/contrib-tools/cmd/cueckoo.
 #codeReview: {
-	gerrit?:      string
-	github?:      string
-	"cue-unity"?: string
+	gerrit?:           string
+	"gerrit-project"?: string
+	github?:           string
+	"cue-unity"?:      string
+
+	// import-trailers is a comma-separated list of the provenance
+	// trailers which cueckoo importpr adds to imported commits: any of
+	// PR-URL, Imported-By and Original-Author. All of them are added by
+	// default; an empty list adds none.
+	"import-trailers"?: =~"^((PR-URL|Imported-By|Original-Author)(, *(PR-URL|Imported-By|Original-Author))*)?$"
+
+	// dependent-NAME entries give the clone URLs of repositories which
+	// depend on this one, for use by cueckoo bump.
+	[=~"^dependent-"]: string
 }
 
 // #toCodeReviewCfg converts a #codeReview instance to
@@ -26,10 +26,15 @@ import (
 // lines of the form "key: value". Lines beginning with # are comments. If
 // there is no config or the config is malformed, an error is returned.
 func Config(root string) (map[string]string, error) {
-	configPath := filepath.Join(root, "codereview.cfg")
-	b, err := os.ReadFile(configPath)
+	return ParseFile(filepath.Join(root, "codereview.cfg"))
+}
+
+// ParseFile parses the file at path, which is in the same format as a code
+// review config. See Config.
+func ParseFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config from %v: %v", configPath, err)
+		return nil, fmt.Errorf("failed to load config from %v: %v", path, err)
 	}
 	cfg := make(map[string]string)
 	for _, line := range nonBlankLines(string(b)) {
@@ -40,7 +45,7 @@ func Config(root string) (map[string]string, error) {
 		}
 		fields := strings.SplitN(line, ":", 2)
 		if len(fields) != 2 {
-			return nil, fmt.Errorf("bad config line in %v; expected 'key: value': %q", configPath, line)
+			return nil, fmt.Errorf("bad config line in %v; expected 'key: value': %q", path, line)
 		}
 		cfg[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
 	}
@@ -68,13 +73,71 @@ func nonBlankLines(text string) []string {
 	return out
 }
 
-func GerritURLToServer(urlString string) (string, error) {
+// Server identifies a Gerrit server, and optionally a project on it, as
+// derived from the gerrit entry of a codereview config.
+type Server struct {
+	// URL is the base URL of the Gerrit server, including any path prefix
+	// under which the server is hosted. It always ends in a slash, as
+	// expected by the Gerrit client.
+	URL string
+
+	// Project is the name of the Gerrit project, or empty if the config
+	// entry did not include one.
+	Project string
+}
+
+// ParseGerritURL parses the URL of a Gerrit server as found in a codereview
+// config, for example:
+//
+//	https://review.gerrithub.io/a/cue-lang/cue
+//	https://review.gerrithub.io/cue-lang/cue
+//	https://example.com/gerrit/a/myproject
+//	http://localhost:8080/
+//
+// The "a" path element, which Gerrit uses as the prefix for authenticated
+// access, separates the path prefix under which the server is hosted from
+// the project name. Without it, the server is taken to be hosted at the root,
+// and the whole path is taken to be the project, if any.
+func ParseGerritURL(urlString string) (Server, error) {
 	u, err := url.Parse(urlString)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse URL from %q: %v", urlString, err)
+		return Server{}, fmt.Errorf("failed to parse URL from %q: %v", urlString, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Server{}, fmt.Errorf("unexpected scheme in Gerrit URL %q; expected http or https", urlString)
+	}
+	if u.Host == "" {
+		return Server{}, fmt.Errorf("missing host in Gerrit URL %q", urlString)
+	}
+	var prefix string
+	project := strings.Trim(u.Path, "/")
+	elems := strings.Split(project, "/")
+	for i, e := range elems {
+		if e == "a" {
+			prefix = strings.Join(elems[:i], "/")
+			project = strings.Join(elems[i+1:], "/")
+			break
+		}
+	}
+	u.Path = "/"
+	if prefix != "" {
+		u.Path = "/" + prefix + "/"
+	}
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.User = nil
+	return Server{URL: u.String(), Project: project}, nil
+}
+
+// GerritURLToServer returns the base URL of the Gerrit server identified by
+// urlString. See ParseGerritURL.
+func GerritURLToServer(urlString string) (string, error) {
+	s, err := ParseGerritURL(urlString)
+	if err != nil {
+		return "", err
 	}
-	u.Path = ""
-	return u.String(), nil
+	return s.URL, nil
 }
 
 func GithubURLToParts(urlString string) (string, string, error) {
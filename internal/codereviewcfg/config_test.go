@@ -0,0 +1,83 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codereviewcfg
+
+import "testing"
+
+func TestParseGerritURL(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Server
+		wantErr bool
+	}{
+		{
+			in:   "https://review.gerrithub.io/a/cue-lang/cue",
+			want: Server{URL: "https://review.gerrithub.io/", Project: "cue-lang/cue"},
+		},
+		{
+			in:   "https://review.gerrithub.io/a/cue-lang/cue/",
+			want: Server{URL: "https://review.gerrithub.io/", Project: "cue-lang/cue"},
+		},
+		{
+			in:   "https://example.com/gerrit/a/myproject",
+			want: Server{URL: "https://example.com/gerrit/", Project: "myproject"},
+		},
+		{
+			in:   "https://review.gerrithub.io/cue-lang/cue",
+			want: Server{URL: "https://review.gerrithub.io/", Project: "cue-lang/cue"},
+		},
+		{
+			in:   "https://review.gerrithub.io/cue-lang/cue/",
+			want: Server{URL: "https://review.gerrithub.io/", Project: "cue-lang/cue"},
+		},
+		{
+			in:   "https://example.com/gerrit/a/",
+			want: Server{URL: "https://example.com/gerrit/"},
+		},
+		{
+			in:   "http://localhost:8080",
+			want: Server{URL: "http://localhost:8080/"},
+		},
+		{
+			in:   "http://localhost:8080/a/test/project",
+			want: Server{URL: "http://localhost:8080/", Project: "test/project"},
+		},
+		{
+			in:      "ssh://review.gerrithub.io:29418/cue-lang/cue",
+			wantErr: true,
+		},
+		{
+			in:      "review.gerrithub.io/a/cue-lang/cue",
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			got, err := ParseGerritURL(c.in)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error when none expected: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gitcmd runs git on behalf of cueckoo.
+//
+// Exec runs the git binary, with helpers for fetching and checking out refs
+// in the one way that callers should agree on. Reimplementations of git such
+// as go-git differ from git proper in their handling of refspecs and shallow
+// clones, which has led to the wrong commit being checked out in CI, so they
+// are deliberately not used.
+package gitcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// New returns an Exec for the repository in dir, or the current directory if
+// dir is empty.
+func New(dir string) Exec {
+	return Exec{Dir: dir}
+}
+
+// Exec runs git in a repository.
+type Exec struct {
+	// Dir is the directory to run git in. If empty, the current directory
+	// is used.
+	Dir string
+
+	// Env holds extra environment variables of the form "KEY=value" for
+	// git, added to those of the current process.
+	Env []string
+}
+
+// Run runs git with args, returning its standard output.
+func (g Exec) Run(ctx context.Context, args ...string) (string, error) {
+	if g.Dir != "" {
+		args = append([]string{"-C", g.Dir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if len(g.Env) > 0 {
+		cmd.Env = append(os.Environ(), g.Env...)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		if err, _ := err.(*exec.ExitError); err != nil {
+			// Cmd.Output populates ExitError.Stderr.
+			return "", fmt.Errorf("failed to run %q: %v:\n%s", cmd.Args, err, err.Stderr)
+		}
+		return "", fmt.Errorf("failed to run %q: %v", cmd.Args, err)
+	}
+	return string(out), nil
+}
+
+// Fetch fetches refspecs from remote, which is a remote name or URL. If depth
+// is positive, the fetch is shallow, limited to that many commits.
+func (g Exec) Fetch(ctx context.Context, remote string, depth int, refspecs ...string) error {
+	args := []string{"fetch", "--quiet"}
+	if depth > 0 {
+		args = append(args, "--depth="+strconv.Itoa(depth))
+	}
+	args = append(args, remote)
+	_, err := g.Run(ctx, append(args, refspecs...)...)
+	return err
+}
+
+// Checkout checks out rev with a detached HEAD.
+func (g Exec) Checkout(ctx context.Context, rev string) error {
+	_, err := g.Run(ctx, "checkout", "--quiet", "--detach", rev)
+	return err
+}
+
+// RevParse resolves rev to the full hash of a commit.
+func (g Exec) RevParse(ctx context.Context, rev string) (string, error) {
+	out, err := g.Run(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s to a commit: %w", rev, err)
+	}
+	return strings.TrimSpace(out), nil
+}
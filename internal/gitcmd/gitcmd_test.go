@@ -0,0 +1,69 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitcmd
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+	env := []string{
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_AUTHOR_NAME=Gopher", "GIT_AUTHOR_EMAIL=gopher@example.com",
+		"GIT_COMMITTER_NAME=Gopher", "GIT_COMMITTER_EMAIL=gopher@example.com",
+	}
+	upstream := Exec{Dir: t.TempDir(), Env: env}
+	mustRun := func(g Exec, args ...string) {
+		t.Helper()
+		if _, err := g.Run(ctx, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustRun(upstream, "init", "--quiet")
+	mustRun(upstream, "commit", "--quiet", "--allow-empty", "-m", "first")
+	first, err := upstream.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustRun(upstream, "commit", "--quiet", "--allow-empty", "-m", "second")
+	mustRun(upstream, "update-ref", "refs/changes/01/1/1", first)
+
+	// A change ref is not fetched by default, so fetch it explicitly into
+	// a shallow clone and check it out, as the trybots would.
+	clone := Exec{Dir: t.TempDir(), Env: env}
+	mustRun(clone, "init", "--quiet")
+	if err := clone.Fetch(ctx, "file://"+upstream.Dir, 1, "refs/changes/01/1/1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.Checkout(ctx, "FETCH_HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := clone.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != first {
+		t.Errorf("checked out %s; want %s", got, first)
+	}
+	if _, err := clone.RevParse(ctx, "HEAD^"); err == nil {
+		t.Errorf("want an error resolving HEAD^ in a shallow clone of depth 1")
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package identity links the GitHub logins of contributors to their Gerrit
+// accounts and email addresses.
+//
+// The links are kept in a registry which is stored in a repository as CUE
+// data, so that it is shared by its maintainers, and looked up by cueckoo
+// wherever an identity on one side is known but the other is needed, such as
+// to add a PR's reviewers to the CL it is imported as.
+package identity
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Identity is a contributor known by a GitHub login.
+type Identity struct {
+	// Name is the contributor's full name, if known.
+	Name string `json:"name,omitempty"`
+
+	// Email is the preferred email address of the contributor's Gerrit
+	// account.
+	Email string `json:"email"`
+
+	// OtherEmails are any other email addresses the contributor commits
+	// with, such as before a change of employer.
+	OtherEmails []string `json:"otherEmails,omitempty"`
+
+	// Gerrit is the ID of the contributor's Gerrit account, if known.
+	Gerrit int `json:"gerrit,omitempty"`
+}
+
+// NameEmail returns the identity in the "Name <email>" form used by git, or
+// just the email address if the name is not known.
+func (id Identity) NameEmail() string {
+	if id.Name == "" {
+		return id.Email
+	}
+	return fmt.Sprintf("%s <%s>", id.Name, id.Email)
+}
+
+// Registry is a set of identities, keyed by GitHub login. The zero value is
+// an empty registry ready to use. It can be decoded from the JSON export of
+// its CUE form.
+type Registry struct {
+	Identities map[string]Identity `json:"identities"`
+}
+
+// ByGitHub returns the identity with the given GitHub login. Logins are
+// case-insensitive, as on GitHub.
+func (r *Registry) ByGitHub(login string) (Identity, bool) {
+	if id, ok := r.Identities[login]; ok {
+		return id, true
+	}
+	for l, id := range r.Identities {
+		if strings.EqualFold(l, login) {
+			return id, true
+		}
+	}
+	return Identity{}, false
+}
+
+// ByEmail returns the GitHub login and identity with the given email
+// address, which may be any of its addresses.
+func (r *Registry) ByEmail(email string) (login string, _ Identity, _ bool) {
+	for _, l := range r.logins() {
+		id := r.Identities[l]
+		if strings.EqualFold(id.Email, email) {
+			return l, id, true
+		}
+		for _, e := range id.OtherEmails {
+			if strings.EqualFold(e, email) {
+				return l, id, true
+			}
+		}
+	}
+	return "", Identity{}, false
+}
+
+// ByGerrit returns the GitHub login and identity with the given Gerrit
+// account ID.
+func (r *Registry) ByGerrit(account int) (login string, _ Identity, _ bool) {
+	for _, l := range r.logins() {
+		if id := r.Identities[l]; id.Gerrit == account && account != 0 {
+			return l, id, true
+		}
+	}
+	return "", Identity{}, false
+}
+
+// Add adds or replaces the identity for login. Fields left empty in id are
+// kept from any existing identity for login.
+func (r *Registry) Add(login string, id Identity) error {
+	if login == "" || strings.ContainsAny(login, " \t\n\"@/") {
+		return fmt.Errorf("invalid GitHub login %q", login)
+	}
+	old := r.Identities[login]
+	if id.Name == "" {
+		id.Name = old.Name
+	}
+	if id.Email == "" {
+		id.Email = old.Email
+	}
+	if id.Gerrit == 0 {
+		id.Gerrit = old.Gerrit
+	}
+	// Keep a replaced email as another address of the contributor.
+	emails := append(id.OtherEmails, old.OtherEmails...)
+	if old.Email != "" && !strings.EqualFold(old.Email, id.Email) {
+		emails = append(emails, old.Email)
+	}
+	id.OtherEmails = nil
+	seen := map[string]bool{strings.ToLower(id.Email): true}
+	for _, e := range emails {
+		if !seen[strings.ToLower(e)] {
+			seen[strings.ToLower(e)] = true
+			id.OtherEmails = append(id.OtherEmails, e)
+		}
+	}
+	if !strings.Contains(id.Email, "@") {
+		return fmt.Errorf("invalid email address %q for %s", id.Email, login)
+	}
+	if other, _, ok := r.ByEmail(id.Email); ok && other != login {
+		return fmt.Errorf("%s is already the email address of %s", id.Email, other)
+	}
+	if other, _, ok := r.ByGerrit(id.Gerrit); ok && other != login {
+		return fmt.Errorf("Gerrit account %d is already linked to %s", id.Gerrit, other)
+	}
+	if r.Identities == nil {
+		r.Identities = make(map[string]Identity)
+	}
+	r.Identities[login] = id
+	return nil
+}
+
+// AccountMap returns the preferred email address of each GitHub login.
+func (r *Registry) AccountMap() map[string]string {
+	m := make(map[string]string, len(r.Identities))
+	for l, id := range r.Identities {
+		m[l] = id.Email
+	}
+	return m
+}
+
+func (r *Registry) logins() []string {
+	logins := make([]string, 0, len(r.Identities))
+	for l := range r.Identities {
+		logins = append(logins, l)
+	}
+	sort.Strings(logins)
+	return logins
+}
+
+// Format returns the registry as a CUE file, in the form which cue fmt would
+// produce, with the identities sorted by login. header, if not empty, is
+// added as a comment at the top of the file.
+func (r *Registry) Format(header string) []byte {
+	var buf bytes.Buffer
+	if header != "" {
+		for _, line := range strings.Split(strings.TrimSpace(header), "\n") {
+			fmt.Fprintf(&buf, "// %s\n", line)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("identities: {\n")
+	for _, l := range r.logins() {
+		id := r.Identities[l]
+		var fields [][2]string
+		if id.Name != "" {
+			fields = append(fields, [2]string{"name", strconv.Quote(id.Name)})
+		}
+		fields = append(fields, [2]string{"email", strconv.Quote(id.Email)})
+		if len(id.OtherEmails) > 0 {
+			quoted := make([]string, len(id.OtherEmails))
+			for i, e := range id.OtherEmails {
+				quoted[i] = strconv.Quote(e)
+			}
+			fields = append(fields, [2]string{"otherEmails", "[" + strings.Join(quoted, ", ") + "]"})
+		}
+		if id.Gerrit != 0 {
+			fields = append(fields, [2]string{"gerrit", strconv.Itoa(id.Gerrit)})
+		}
+		width := 0
+		for _, f := range fields {
+			if len(f[0]) > width {
+				width = len(f[0])
+			}
+		}
+		fmt.Fprintf(&buf, "\t%s: {\n", cueLabel(l))
+		for _, f := range fields {
+			fmt.Fprintf(&buf, "\t\t%s:%s%s\n", f[0], strings.Repeat(" ", width-len(f[0])+1), f[1])
+		}
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// cueLabel returns login as a CUE label, quoting it unless it is a valid
+// identifier. GitHub logins may contain dashes, which identifiers may not,
+// or be keywords.
+func cueLabel(login string) string {
+	switch login {
+	case "if", "for", "in", "let", "true", "false", "null", "package", "import":
+		return strconv.Quote(login)
+	}
+	for i, r := range login {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return strconv.Quote(login)
+		}
+	}
+	return login
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistry(t *testing.T) {
+	var r Registry
+	if err := r.Add("mvdan", Identity{Name: "Daniel Martí", Email: "mvdan@mvdan.cc", Gerrit: 1000001}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add("some-contributor", Identity{Email: "some@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	// A new email keeps the old one as another address.
+	if err := r.Add("mvdan", Identity{Email: "mvdan@cue.works"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Add("other", Identity{Email: "MVDAN@mvdan.cc"}); err == nil {
+		t.Errorf("adding a second identity with the same email succeeded")
+	}
+	if err := r.Add("other", Identity{Email: "other@example.com", Gerrit: 1000001}); err == nil {
+		t.Errorf("adding a second identity with the same Gerrit account succeeded")
+	}
+	if err := r.Add("bad login", Identity{Email: "x@example.com"}); err == nil {
+		t.Errorf("adding an invalid login succeeded")
+	}
+
+	want := Identity{Name: "Daniel Martí", Email: "mvdan@cue.works", OtherEmails: []string{"mvdan@mvdan.cc"}, Gerrit: 1000001}
+	if id, ok := r.ByGitHub("MvDan"); !ok || !cmp.Equal(id, want) {
+		t.Errorf("ByGitHub: got %+v, %v", id, ok)
+	}
+	if login, _, ok := r.ByEmail("mvdan@mvdan.cc"); !ok || login != "mvdan" {
+		t.Errorf("ByEmail: got %q, %v", login, ok)
+	}
+	if login, _, ok := r.ByGerrit(1000001); !ok || login != "mvdan" {
+		t.Errorf("ByGerrit: got %q, %v", login, ok)
+	}
+	if _, _, ok := r.ByGerrit(0); ok {
+		t.Errorf("ByGerrit(0) found an identity")
+	}
+	if got := want.NameEmail(); got != "Daniel Martí <mvdan@cue.works>" {
+		t.Errorf("NameEmail: got %q", got)
+	}
+
+	wantCUE := `// Maintained with cueckoo identity add.
+
+identities: {
+	mvdan: {
+		name:        "Daniel Martí"
+		email:       "mvdan@cue.works"
+		otherEmails: ["mvdan@mvdan.cc"]
+		gerrit:      1000001
+	}
+	"some-contributor": {
+		email: "some@example.com"
+	}
+}
+`
+	if diff := cmp.Diff(wantCUE, string(r.Format("Maintained with cueckoo identity add."))); diff != "" {
+		t.Errorf("Format mismatch (-want +got):\n%s", diff)
+	}
+
+	// The registry is loaded from the JSON export of its CUE form.
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r2 Registry
+	if err := json.Unmarshal(data, &r2); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(r, r2); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
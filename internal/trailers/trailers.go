@@ -0,0 +1,120 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package trailers parses and formats the trailers of git commit messages,
+// such as Signed-off-by and Change-Id.
+//
+// Like git interpret-trailers, only the last paragraph of a message is
+// considered, and only if every line in it is a trailer or the continuation
+// of one. The first paragraph, the subject, is never a trailer block.
+package trailers
+
+import (
+	"strings"
+)
+
+// Trailer is a single "Key: Value" trailer.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// Split splits msg into its body, which is everything before the trailer
+// block with trailing whitespace removed, and its trailers. Continuation
+// lines of folded trailers are unfolded.
+func Split(msg string) (body string, trailers []Trailer) {
+	msg = strings.TrimRight(strings.ReplaceAll(msg, "\r\n", "\n"), " \t\n")
+	i := strings.LastIndex(msg, "\n\n")
+	if i < 0 {
+		// Only a subject.
+		return msg, nil
+	}
+	block := msg[i+2:]
+	for _, line := range strings.Split(block, "\n") {
+		if line != "" && (line[0] == ' ' || line[0] == '\t') && len(trailers) > 0 {
+			t := &trailers[len(trailers)-1]
+			t.Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		t, ok := parse(line)
+		if !ok {
+			return msg, nil
+		}
+		trailers = append(trailers, t)
+	}
+	return strings.TrimRight(msg[:i], " \t\n"), trailers
+}
+
+// parse parses line as a trailer.
+func parse(line string) (Trailer, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok || key == "" {
+		return Trailer{}, false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return Trailer{}, false
+		}
+	}
+	return Trailer{Key: key, Value: strings.TrimSpace(value)}, true
+}
+
+// Format joins body and trailers into a commit message ending in a newline,
+// separating the trailer block from the body with a blank line.
+func Format(body string, trailers []Trailer) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(body, " \t\n"))
+	b.WriteString("\n")
+	for i, t := range trailers {
+		if i == 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(t.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Dedupe returns trailers with duplicates removed, keeping the first of each.
+// Trailers are duplicates if their keys are equal ignoring case and their
+// values are equal.
+func Dedupe(trailers []Trailer) []Trailer {
+	type key struct{ k, v string }
+	seen := make(map[key]bool)
+	var res []Trailer
+	for _, t := range trailers {
+		k := key{strings.ToLower(t.Key), t.Value}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		res = append(res, t)
+	}
+	return res
+}
+
+// Get returns the values of the trailers with the given key, ignoring case.
+func Get(trailers []Trailer, key string) []string {
+	var res []string
+	for _, t := range trailers {
+		if strings.EqualFold(t.Key, key) {
+			res = append(res, t.Value)
+		}
+	}
+	return res
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trailers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		body     string
+		trailers []Trailer
+	}{
+		{
+			name: "subject only",
+			in:   "pkg: fix thing\n",
+			body: "pkg: fix thing",
+		},
+		{
+			name: "subject that looks like a trailer",
+			in:   "Change-Id: I1234\n",
+			body: "Change-Id: I1234",
+		},
+		{
+			name: "no trailers",
+			in:   "pkg: fix thing\n\nSome explanation.\n\n",
+			body: "pkg: fix thing\n\nSome explanation.",
+		},
+		{
+			name: "trailers",
+			in:   "pkg: fix thing\n\nSome explanation.\n\nSigned-off-by: Paul <p@example.com>\nChange-Id: I1234\n",
+			body: "pkg: fix thing\n\nSome explanation.",
+			trailers: []Trailer{
+				{Key: "Signed-off-by", Value: "Paul <p@example.com>"},
+				{Key: "Change-Id", Value: "I1234"},
+			},
+		},
+		{
+			name: "trailers directly after subject",
+			in:   "pkg: fix thing\n\nChange-Id: I1234\n",
+			body: "pkg: fix thing",
+			trailers: []Trailer{
+				{Key: "Change-Id", Value: "I1234"},
+			},
+		},
+		{
+			name: "last paragraph is not all trailers",
+			in:   "pkg: fix thing\n\nFixes #12.\nChange-Id: I1234\n",
+			body: "pkg: fix thing\n\nFixes #12.\nChange-Id: I1234",
+		},
+		{
+			name: "folded trailer",
+			in:   "pkg: fix thing\n\nCo-authored-by: A Very Long\n  Name <a@example.com>\n",
+			body: "pkg: fix thing",
+			trailers: []Trailer{
+				{Key: "Co-authored-by", Value: "A Very Long Name <a@example.com>"},
+			},
+		},
+		{
+			name: "carriage returns",
+			in:   "pkg: fix thing\r\n\r\nChange-Id: I1234\r\n",
+			body: "pkg: fix thing",
+			trailers: []Trailer{
+				{Key: "Change-Id", Value: "I1234"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body, trailers := Split(c.in)
+			if body != c.body {
+				t.Errorf("got body %q, want %q", body, c.body)
+			}
+			if diff := cmp.Diff(c.trailers, trailers); diff != "" {
+				t.Errorf("trailers mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	in := "pkg: fix thing\n\nSome explanation.\n\nSigned-off-by: Paul <p@example.com>\nChange-Id: I1234\n"
+	if got := Format(Split(in)); got != in {
+		t.Errorf("got %q, want %q", got, in)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	in := []Trailer{
+		{Key: "Signed-off-by", Value: "Paul"},
+		{Key: "Change-Id", Value: "I1234"},
+		{Key: "signed-off-by", Value: "Paul"},
+		{Key: "Signed-off-by", Value: "Marcel"},
+	}
+	want := []Trailer{
+		{Key: "Signed-off-by", Value: "Paul"},
+		{Key: "Change-Id", Value: "I1234"},
+		{Key: "Signed-off-by", Value: "Marcel"},
+	}
+	if diff := cmp.Diff(want, Dedupe(in)); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
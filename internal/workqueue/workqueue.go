@@ -0,0 +1,226 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workqueue runs bulk operations against services such as Gerrit and
+// GitHub, with bounded concurrency and a per-host limit on the rate at which
+// tasks start, so that commands acting on many changes at once stay within
+// the quotas of those services.
+//
+// A queue can also record which tasks succeeded in a state file, such that
+// running the same tasks again after a partial failure only retries the
+// tasks which did not succeed.
+package workqueue
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCompleted is the error reported for a task which was skipped because
+// the state file records it as having succeeded in a previous run.
+var ErrCompleted = errors.New("completed in a previous run")
+
+// Task is a unit of work in a queue.
+type Task struct {
+	// Name identifies the task in the state file and in calls to the Begin
+	// and End hooks. Names should be unique within a run.
+	Name string
+
+	// Host is the host the task talks to, for the purpose of rate limiting.
+	// Tasks with an empty Host are not rate limited.
+	Host string
+
+	Run func(ctx context.Context) error
+}
+
+// Queue runs tasks. The zero value runs tasks one at a time with no rate
+// limit and no state file.
+type Queue struct {
+	// Concurrency is the maximum number of tasks which run at once.
+	// Values less than one mean one.
+	Concurrency int
+
+	// Interval is the minimum time between the starts of two tasks for the
+	// same host.
+	Interval time.Duration
+
+	// Begin and End, if non-nil, are called as each task starts and
+	// finishes, e.g. to report progress. They may be called concurrently.
+	// They are not called for tasks skipped due to the state file.
+	Begin func(name string)
+	End   func(name string, err error)
+
+	// StateFile, if non-empty, is where the names of the tasks which
+	// succeeded are recorded. Tasks already recorded there are skipped.
+	// The file is removed once every task has succeeded, so that a later
+	// run starts afresh.
+	StateFile string
+}
+
+// Run runs tasks and returns their errors, indexed like tasks. Tasks which
+// do not start before ctx is done fail with the error of ctx.
+//
+// The returned error is only non-nil if the state file could not be read or
+// written, in which case no tasks are run.
+func (q *Queue) Run(ctx context.Context, tasks []Task) ([]error, error) {
+	done, err := q.readState()
+	if err != nil {
+		return nil, err
+	}
+	var state *os.File
+	if q.StateFile != "" {
+		if err := os.MkdirAll(filepath.Dir(q.StateFile), 0o777); err != nil {
+			return nil, err
+		}
+		state, err = os.OpenFile(q.StateFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o666)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	n := q.Concurrency
+	if n < 1 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+	lim := &limiter{interval: q.Interval, next: make(map[string]time.Time)}
+	errs := make([]error, len(tasks))
+	var (
+		mu        sync.Mutex // protects state, stateErr and remaining
+		stateErr  error
+		wg        sync.WaitGroup
+		remaining = len(tasks)
+	)
+	for i, t := range tasks {
+		if done[t.Name] {
+			errs[i] = ErrCompleted
+			mu.Lock()
+			remaining--
+			mu.Unlock()
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		i, t := i, t
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := lim.wait(ctx, t.Host); err != nil {
+				errs[i] = err
+				return
+			}
+			if q.Begin != nil {
+				q.Begin(t.Name)
+			}
+			err := t.Run(ctx)
+			if q.End != nil {
+				q.End(t.Name, err)
+			}
+			errs[i] = err
+			if err != nil || state == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			remaining--
+			if _, err := fmt.Fprintln(state, t.Name); err != nil && stateErr == nil {
+				stateErr = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	if state != nil {
+		if err := state.Close(); err != nil && stateErr == nil {
+			stateErr = err
+		}
+		if stateErr == nil && remaining == 0 {
+			stateErr = os.Remove(q.StateFile)
+		}
+	}
+	return errs, stateErr
+}
+
+// readState returns the set of task names recorded in the state file.
+func (q *Queue) readState() (map[string]bool, error) {
+	done := make(map[string]bool)
+	if q.StateFile == "" {
+		return done, nil
+	}
+	f, err := os.Open(q.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return done, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if name := strings.TrimSpace(sc.Text()); name != "" {
+			done[name] = true
+		}
+	}
+	return done, sc.Err()
+}
+
+// limiter spaces out the starts of tasks for each host.
+type limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time // earliest time the next task for a host may start
+}
+
+// wait blocks until a task for host may start, or ctx is done.
+func (l *limiter) wait(ctx context.Context, host string) error {
+	if host == "" || l.interval <= 0 {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next[host]
+	if start.Before(now) {
+		start = now
+	}
+	l.next[host] = start.Add(l.interval)
+	l.mu.Unlock()
+
+	d := time.Until(start)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
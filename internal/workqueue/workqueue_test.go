@@ -0,0 +1,135 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueueConcurrency(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		running, peak int
+	)
+	tasks := make([]Task, 10)
+	for i := range tasks {
+		tasks[i] = Task{Name: string(rune('a' + i)), Run: func(ctx context.Context) error {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		}}
+	}
+	q := &Queue{Concurrency: 3}
+	errs, err := q.Run(context.Background(), tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("task %d: %v", i, err)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("got %d tasks running at once; want at most 3", peak)
+	}
+}
+
+func TestQueueInterval(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		starts = make(map[string][]time.Time)
+	)
+	var tasks []Task
+	for _, host := range []string{"a", "a", "a", "b", "b", "b"} {
+		host := host
+		tasks = append(tasks, Task{Name: host, Host: host, Run: func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			starts[host] = append(starts[host], time.Now())
+			return nil
+		}})
+	}
+	const interval = 20 * time.Millisecond
+	q := &Queue{Concurrency: len(tasks), Interval: interval}
+	if _, err := q.Run(context.Background(), tasks); err != nil {
+		t.Fatal(err)
+	}
+	for host, ts := range starts {
+		for i := 1; i < len(ts); i++ {
+			// Allow for timer imprecision, but not for tasks starting
+			// together.
+			if d := ts[i].Sub(ts[i-1]); d < interval/2 {
+				t.Errorf("host %s: tasks started %v apart; want at least %v", host, d, interval)
+			}
+		}
+	}
+}
+
+func TestQueueResume(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state")
+	fail := map[string]bool{"b": true}
+	var ran []string
+	var tasks []Task
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		tasks = append(tasks, Task{Name: name, Run: func(ctx context.Context) error {
+			ran = append(ran, name)
+			if fail[name] {
+				return errors.New("failed")
+			}
+			return nil
+		}})
+	}
+	q := &Queue{StateFile: state}
+	errs, err := q.Run(context.Background(), tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Fatalf("first run: got errors %v; want only b to fail", errs)
+	}
+
+	// Only the failed task runs again, and the state file is removed once
+	// it succeeds.
+	ran = nil
+	delete(fail, "b")
+	errs, err = q.Run(context.Background(), tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 1 || ran[0] != "b" {
+		t.Errorf("second run: ran %v; want [b]", ran)
+	}
+	if errs[0] != ErrCompleted || errs[1] != nil || errs[2] != ErrCompleted {
+		t.Errorf("second run: got errors %v", errs)
+	}
+	if _, err := os.Stat(state); !os.IsNotExist(err) {
+		t.Errorf("state file still exists after all tasks succeeded: %v", err)
+	}
+}